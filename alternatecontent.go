@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MarkupCompatibilityNamespace is the namespace of markup compatibility (mc:AlternateContent, mc:Ignorable).
+const MarkupCompatibilityNamespace = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+
+var (
+	// IgnorableAttributeRegex matches the mc:Ignorable attribute of a start tag, the group contains its prefixes
+	IgnorableAttributeRegex = regexp.MustCompile(`\s[A-Za-z0-9_]+:Ignorable\s*=\s*"([^"]*)"`)
+
+	// markupCompatibilityPrefixRegex matches the namespace declaration of markup compatibility, the group contains
+	// the prefix
+	markupCompatibilityPrefixRegex = regexp.MustCompile(`\sxmlns:([A-Za-z0-9_.-]+)\s*=\s*"` + regexp.QuoteMeta(MarkupCompatibilityNamespace) + `"`)
+)
+
+// markupCompatibilityPrefix returns the prefix of the markup compatibility namespace, 'mc' if it is not declared.
+func markupCompatibilityPrefix(data []byte) string {
+	if match := markupCompatibilityPrefixRegex.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return "mc"
+}
+
+// alternateContentFallbacks returns the positions of all outermost mc:Fallback elements, in ascending order.
+func alternateContentFallbacks(data []byte) []Position {
+	return alternateContentElements(data, "Fallback")
+}
+
+// alternateContentElements returns the positions of all outermost markup compatibility elements with the given
+// local name (e.g. 'AlternateContent'), in ascending order.
+func alternateContentElements(data []byte, name string) []Position {
+	prefix := regexp.QuoteMeta(markupCompatibilityPrefix(data))
+	re := regexp.MustCompile(`<` + prefix + `:` + name + `(?:\s[^>]*)?/?>|</` + prefix + `:` + name + `>`)
+
+	var (
+		elements []Position
+		start    int
+		depth    int
+	)
+	for _, loc := range re.FindAllIndex(data, -1) {
+		tag := data[loc[0]:loc[1]]
+		switch {
+		case bytes.HasPrefix(tag, []byte("</")):
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				elements = append(elements, Position{Start: int64(start), End: int64(loc[1])})
+			}
+		case bytes.HasSuffix(tag, []byte("/>")):
+			continue
+		default:
+			if depth == 0 {
+				start = loc[0]
+			}
+			depth++
+		}
+	}
+	return elements
+}
+
+// positionInside returns true if the position is inside one of the given (ascending) positions.
+func positionInside(positions []Position, pos int64) bool {
+	i := sort.Search(len(positions), func(i int) bool { return positions[i].End > pos })
+	return i < len(positions) && positions[i].Start <= pos
+}
+
+// alternateContentBoundaries returns the start positions of all open and close tags of mc:AlternateContent,
+// mc:Choice and mc:Fallback elements, in ascending order. The prefix is taken from the namespace declaration.
+func alternateContentBoundaries(data []byte) []int64 {
+	re := regexp.MustCompile(`</?` + regexp.QuoteMeta(markupCompatibilityPrefix(data)) + `:(?:AlternateContent|Choice|Fallback)[\s/>]`)
+
+	var boundaries []int64
+	for _, loc := range re.FindAllIndex(data, -1) {
+		boundaries = append(boundaries, int64(loc[0]))
+	}
+	return boundaries
+}
+
+// dropCrossingPlaceholders removes all placeholders which start and end in different branches of alternate
+// content, e.g. in the mc:Choice of a text box and in the text after it. Replacing them would move text
+// across the boundary, so that the content differs depending on which branch is chosen. A warning is recorded
+// for every dropped placeholder, and they are not expected to be replaced (see countPlaceholders).
+func (d *Document) dropCrossingPlaceholders(file string, data []byte, placeholders []*Placeholder) []*Placeholder {
+	delete(d.ignoredPlaceholders, file)
+	boundaries := alternateContentBoundaries(data)
+	if len(boundaries) == 0 {
+		return placeholders
+	}
+
+	kept := placeholders[:0]
+	for _, placeholder := range placeholders {
+		start, end := placeholder.StartPos(), placeholder.EndPos()
+		i := sort.Search(len(boundaries), func(i int) bool { return boundaries[i] >= start })
+		if i < len(boundaries) && boundaries[i] < end {
+			text := placeholder.Text(data)
+			d.ignoredPlaceholders[file] = append(d.ignoredPlaceholders[file], text)
+			d.warnOnce(fmt.Sprintf("placeholder %s in %s crosses an mc:AlternateContent boundary and is ignored", text, file))
+			continue
+		}
+		kept = append(kept, placeholder)
+	}
+	return kept
+}
+
+// checkIgnorableNamespaces returns an error if a prefix listed in an mc:Ignorable attribute is not declared,
+// Word refuses to open such parts.
+func checkIgnorableNamespaces(data []byte) error {
+	for _, loc := range IgnorableAttributeRegex.FindAllSubmatchIndex(data, -1) {
+		tagEnd := loc[1]
+		if i := strings.IndexByte(string(data[loc[1]:]), '>'); i >= 0 {
+			tagEnd += i
+		}
+		for _, prefix := range strings.Fields(string(data[loc[2]:loc[3]])) {
+			declaration := regexp.MustCompile(`\sxmlns:` + regexp.QuoteMeta(prefix) + `\s*=`)
+			if !declaration.Match(data[:tagEnd]) {
+				return fmt.Errorf("ignorable namespace prefix %s is not declared", prefix)
+			}
+		}
+	}
+	return nil
+}
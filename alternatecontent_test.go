@@ -0,0 +1,122 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// extensionDocumentXml is a document with forward-compatibility content written by recent versions of Word.
+func extensionDocumentXml(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" ` +
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" ` +
+		`xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml" ` +
+		`xmlns:w16cex="http://schemas.microsoft.com/office/word/2018/wordml/cex" ` +
+		`xmlns:w16se="http://schemas.microsoft.com/office/word/2015/wordml/symex" ` +
+		`xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape" ` +
+		`xmlns:v="urn:schemas-microsoft-com:vml" mc:Ignorable="w14 w15 w16cex w16se wps"><w:body>` +
+		body + `</w:body></w:document>`
+}
+
+func TestDocument_Write_PreservesExtensions(t *testing.T) {
+	body := `<w:p w14:paraId="1A2B3C4D" w14:textId="77777777"><w:pPr><w:rPr><w:ins w:id="1" w:author="x"/></w:rPr></w:pPr>` +
+		`<w:commentRangeStart w:id="0"/><w:r><w:t xml:space="preserve">Dear {name}, </w:t></w:r>` +
+		`<w:r><mc:AlternateContent><mc:Choice Requires="w16se"><w16se:symEx w16se:font="Segoe UI Emoji" w16se:char="1F600"/></mc:Choice>` +
+		`<mc:Fallback><w:t>😀</w:t></mc:Fallback></mc:AlternateContent></w:r><w:commentRangeEnd w:id="0"/></w:p>` +
+		`<w:p><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:drawing><wps:txbx><w:txbxContent>` +
+		`<w:p><w:r><w:t>{name}</w:t></w:r></w:p></w:txbxContent></wps:txbx></w:drawing></mc:Choice>` +
+		`<mc:Fallback><w:pict><v:textbox><w:txbxContent><w:p><w:r><w:t>{name}</w:t></w:r></w:p></w:txbxContent></v:textbox></w:pict>` +
+		`</mc:Fallback></mc:AlternateContent></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:extLst><w:ext w:uri="{D8E3C3B0-5AA0-4E41-8A7F-F6F2F6C1D6E1}">` +
+		`<w15:extra w15:val="1"/></w:ext></w:extLst></w:sectPr>`
+	parts := map[string]string{
+		DocumentXml: extensionDocumentXml(body),
+		"word/commentsExtensible.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+			`<w16cex:commentsExtensible xmlns:w16cex="http://schemas.microsoft.com/office/word/2018/wordml/cex" ` +
+			`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="w16cex">` +
+			`<w16cex:commentExtensible w16cex:durableId="2A3B4C5D" w16cex:dateUtc="2023-01-01T00:00:00Z"/></w16cex:commentsExtensible>`,
+		"word/ink/ink1.xml": `<inkml:ink xmlns:inkml="http://www.w3.org/2003/InkML"><inkml:trace contextRef="#ctx0">` +
+			`10 0, 9 14, 8 29 {name}</inkml:trace></inkml:ink>`,
+		"customXml/item1.xml":          `<b:Sources xmlns:b="http://schemas.openxmlformats.org/officeDocument/2006/bibliography"/>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"/>`,
+	}
+	doc := openTestDocx(t, parts)
+	original := make(map[string]string)
+	for name, data := range parts {
+		original[name] = data
+	}
+
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range written.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := original[file.Name]
+		if file.Name == DocumentXml {
+			expected = strings.Replace(expected, "{name}", "Jane", -1)
+		}
+		if string(data) != expected {
+			t.Errorf("%s changed\nwant=%s\nhave=%s", file.Name, expected, data)
+		}
+		delete(original, file.Name)
+	}
+	if len(original) != 0 {
+		t.Errorf("parts are missing: %v", original)
+	}
+}
+
+func TestDocument_ReplaceAll_AlternateContentBoundary(t *testing.T) {
+	body := `<w:p><w:r><w:t>{na</w:t></w:r><w:r><mc:AlternateContent><mc:Choice Requires="wps">` +
+		`<w:r><w:t>me}</w:t></w:r></mc:Choice><mc:Fallback><w:r><w:t>x</w:t></w:r></mc:Fallback></mc:AlternateContent></w:r>` +
+		`<w:r><w:t>{name}</w:t></w:r></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: extensionDocumentXml(body)})
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := extensionDocumentXml(`<w:p><w:r><w:t>{na</w:t></w:r><w:r><mc:AlternateContent><mc:Choice Requires="wps">` +
+		`<w:r><w:t>me}</w:t></w:r></mc:Choice><mc:Fallback><w:r><w:t>x</w:t></w:r></mc:Fallback></mc:AlternateContent></w:r>` +
+		`<w:r><w:t>Jane</w:t></w:r></w:p>`)
+	if result := string(doc.GetFile(DocumentXml)); result != expected {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+	if len(doc.Warnings()) != 1 {
+		t.Errorf("expected one warning, got %v", doc.Warnings())
+	}
+}
+
+func TestCheckIgnorableNamespaces(t *testing.T) {
+	if err := checkIgnorableNamespaces([]byte(extensionDocumentXml(`<w:p/>`))); err != nil {
+		t.Error(err)
+	}
+	undeclared := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="w14"><w:body/></w:document>`
+	if err := checkIgnorableNamespaces([]byte(undeclared)); err == nil {
+		t.Error("expected an error for the undeclared prefix w14")
+	}
+}
@@ -0,0 +1,113 @@
+package docx
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxBookmarkNameLength is the maximum length of bookmark names which Word accepts.
+const MaxBookmarkNameLength = 40
+
+// WithAnchorsForReplacements surrounds every replaced placeholder with a bookmark, so other systems can link to the
+// values of a generated document. The bookmarks are named 'prefix_key_occurrence', e.g. 'val_liability_1' for the
+// first replacement of {liability} with the prefix 'val'. The occurrences of every key are counted in document
+// order, starting with 1. Characters which are not allowed in bookmark names are replaced by '_', names longer than
+// MaxBookmarkNameLength are truncated and end with a hash of the full name to stay unique. The prefix should start
+// with a letter, otherwise Word hides the bookmarks. An empty prefix disables the bookmarks, which is the default.
+//
+// The ids of the bookmarks are allocated after the highest id of the existing bookmarks. The names are returned by
+// Anchors and are part of the ReplaceReport. Bookmarks are range markup which may span revisions, thus
+// replacements inside inserted or deleted runs (<w:ins>, <w:del>) are bookmarked the same way. Placeholders inside
+// field instructions and equations are not bookmarked. The parts are replaced one after another.
+func WithAnchorsForReplacements(prefix string) Option {
+	return func(d *Document) {
+		d.anchorPrefix = prefix
+	}
+}
+
+// Anchors returns the names of the bookmarks of all replacements so far by placeholder key, in document order,
+// see WithAnchorsForReplacements.
+func (d *Document) Anchors() map[string][]string {
+	anchors := make(map[string][]string, len(d.anchors))
+	for key, names := range d.anchors {
+		anchors[key] = append([]string(nil), names...)
+	}
+	return anchors
+}
+
+// replaceAnchored replaces all occurrences of the literal with the value like the Replacer does and surrounds each
+// of them with a bookmark, see WithAnchorsForReplacements.
+func (d *Document) replaceAnchored(replacer *Replacer, literal, key string, value interface{}) error {
+	return replacer.replace(literal, func(placeholder *Placeholder) string {
+		fragment := placeholder.Fragments[placeholder.valueFragment(replacer.document, replacer.formatting)]
+		runProperties := fragment.Run.GetProperties(replacer.document)
+		var markup string
+		switch v := value.(type) {
+		case MarkupValue:
+			markup = v.Markup(runProperties)
+		default:
+			markup = strings.Replace(fmt.Sprint(v), "\n", "</w:t><w:br/><w:t>", -1)
+		}
+		// bookmarks cannot be placed inside field instructions or equations
+		if !fragment.Run.Text.OpenTag.Match(TextOpenTagRegex, replacer.document) {
+			return markup
+		}
+
+		id := d.allocateBookmarkID()
+		name := d.anchorName(key)
+		reopen := "<w:r>"
+		if runProperties != "" {
+			reopen += fmt.Sprintf("<w:rPr>%s</w:rPr>", runProperties)
+		}
+		reopen += `<w:t xml:space="preserve">`
+		return fmt.Sprintf(`</w:t></w:r><w:bookmarkStart w:id="%d" w:name="%s"/>%s%s</w:t></w:r><w:bookmarkEnd w:id="%d"/>%s`,
+			id, xmlEscape(name), reopen, markup, id, reopen)
+	})
+}
+
+// anchorName returns the name of the bookmark of the next occurrence of the key and records it.
+func (d *Document) anchorName(key string) string {
+	if d.anchors == nil {
+		d.anchors = make(map[string][]string)
+	}
+	name := bookmarkName(fmt.Sprintf("%s_%s_%d", d.anchorPrefix, key, len(d.anchors[key])+1))
+	d.anchors[key] = append(d.anchors[key], name)
+	return name
+}
+
+// allocateBookmarkID returns an unused bookmark id. The first call starts after the highest id of the bookmarks
+// of all parsed files.
+func (d *Document) allocateBookmarkID() int {
+	if d.nextBookmarkID == 0 {
+		for _, name := range d.fileNames() {
+			for _, tag := range BookmarkStartTagRegex.FindAll(d.GetFile(name), -1) {
+				value, _ := attributeValue(tag, "w:id")
+				if id, err := strconv.Atoi(value); err == nil && id >= d.nextBookmarkID {
+					d.nextBookmarkID = id + 1
+				}
+			}
+		}
+	}
+	id := d.nextBookmarkID
+	d.nextBookmarkID++
+	return id
+}
+
+// bookmarkName returns the name with all characters except letters, digits and '_' replaced by '_'. Names which
+// are longer than MaxBookmarkNameLength are truncated and end with a hash of the full name.
+func bookmarkName(name string) string {
+	runes := []rune(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, name))
+	if len(runes) <= MaxBookmarkNameLength {
+		return string(runes)
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:8]
+	return string(runes[:MaxBookmarkNameLength-len(hash)-1]) + "_" + hash
+}
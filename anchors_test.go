@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithAnchorsForReplacements(t *testing.T) {
+	longKey := "the_liability_clause_of_the_contract_value"
+	body := `<w:p><w:bookmarkStart w:id="4" w:name="intro"/><w:r><w:rPr><w:b/></w:rPr><w:t>Dear {name},</w:t></w:r><w:bookmarkEnd w:id="4"/></w:p>` +
+		`<w:p><w:ins w:id="7" w:author="Jane"><w:r><w:t>{name} accepts {` + longKey + `}.</w:t></w:r></w:ins></w:p>`
+	archive := newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	doc, err := OpenBytes(archive, WithAnchorsForReplacements("val"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := PlaceholderMap{"name": "Jane", longKey: RichText{Bold("unlimited")}}
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+
+	longName := bookmarkName("val_" + longKey + "_1")
+	expected := map[string][]string{"name": {"val_name_1", "val_name_2"}, longKey: {longName}}
+	if anchors := doc.Anchors(); !reflect.DeepEqual(anchors, expected) {
+		t.Errorf("expected the anchors %v, got %v", expected, anchors)
+	}
+	if len(longName) != MaxBookmarkNameLength || !strings.HasPrefix(longName, "val_the_liability_clause_of_the_") {
+		t.Errorf("expected the long name to be truncated to %d characters, got %s", MaxBookmarkNameLength, longName)
+	}
+
+	data, _ := reopen(t, doc).getPart(DocumentXml)
+	if err := checkWellFormed(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		`<w:t>Dear </w:t></w:r><w:bookmarkStart w:id="5" w:name="val_name_1"/><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Jane</w:t></w:r><w:bookmarkEnd w:id="5"/>`,
+		`<w:ins w:id="7" w:author="Jane"><w:r><w:t></w:t></w:r><w:bookmarkStart w:id="6" w:name="val_name_2"/>`,
+	} {
+		if !strings.Contains(string(data), fragment) {
+			t.Errorf("expected %s in %s", fragment, data)
+		}
+	}
+	contents := make(map[string]string)
+	for _, bookmark := range FindBookmarks(data) {
+		content := bookmark.Content()
+		contents[bookmark.Name] = string(data[content.Start:content.End])
+	}
+	for name, text := range map[string]string{"val_name_1": "Jane", "val_name_2": "Jane", longName: "unlimited"} {
+		if !strings.Contains(contents[name], ">"+text+"<") {
+			t.Errorf("expected the bookmark %s to contain %s, got %s", name, text, contents[name])
+		}
+	}
+
+	doc, err = OpenBytes(archive, WithAnchorsForReplacements("val"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.render("letter", map[string]interface{}{"name": "Jane", longKey: "unlimited"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Anchors, expected) {
+		t.Errorf("expected the anchors %v in the report, got %v", expected, report.Anchors)
+	}
+}
+
+func TestBookmarkName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"val_customer.name_1", "val_customer_name_1"},
+		{"val_Größe_2", "val_Größe_2"},
+		{strings.Repeat("a", 40), strings.Repeat("a", 40)},
+	}
+	for _, tt := range tests {
+		if name := bookmarkName(tt.name); name != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, name)
+		}
+	}
+	if a, b := bookmarkName(strings.Repeat("a", 41)), bookmarkName(strings.Repeat("a", 42)); a == b || len(a) != 40 {
+		t.Errorf("expected long names to stay unique, got %s and %s", a, b)
+	}
+}
@@ -0,0 +1,300 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// CoreXml is the relative path of the core properties part inside the docx-archive.
+	CoreXml = "docProps/core.xml"
+)
+
+var (
+	// ErrAnonymizationChangedRuns is returned by Anonymize if the anonymized document would not be parsed into
+	// the same runs as the original one.
+	ErrAnonymizationChangedRuns = errors.New("anonymization changed the runs of the document")
+
+	// anonymizedTextRegex matches the elements whose text is anonymized, the group contains the text
+	anonymizedTextRegex = regexp.MustCompile(`<(?:w:t|w:delText|a:t|vt:lpstr|vt:lpwstr)(?:\s[^>]*)?>([^<]*)<`)
+	// anonymizedInstructionRegex matches field instructions which contain quoted arguments
+	anonymizedInstructionRegex = regexp.MustCompile(`<w:instrText(?:\s[^>]*)?>[^<"]*(?:"[^"<]*"[^<"]*)*<`)
+	// quotedArgumentRegex matches a quoted argument of a field instruction, the group contains the argument
+	quotedArgumentRegex = regexp.MustCompile(`"([^"]*)"`)
+	// textNodeRegex matches the text between two tags, the group contains the text
+	textNodeRegex = regexp.MustCompile(`>([^<]+)<`)
+	// authorAttributeRegex matches the attributes which name the author of a comment or a revision, the group
+	// contains the value
+	authorAttributeRegex = regexp.MustCompile(`\s(?:w:author|w:initials|w15:author|w15:userId)\s*=\s*"([^"]*)"`)
+	// alternativeTextAttributeRegex matches the alternative text attributes of drawings and shapes, the group
+	// contains the value
+	alternativeTextAttributeRegex = regexp.MustCompile(`\s(?:descr|title|alt)\s*=\s*"([^"]*)"`)
+	// corePropertyRegex matches the core and extended properties which identify the document or its authors,
+	// the groups contain the tags around the value
+	corePropertyRegex = regexp.MustCompile(`(<(?:dc:creator|cp:lastModifiedBy|dc:title|dc:subject|dc:description|` +
+		`cp:keywords|cp:category|cp:contentStatus|Company|Manager|HyperlinkBase)(?:\s[^>]*)?>)[^<]*(<)`)
+	// externalTargetRegex matches the target of an external relationship after its scheme, the group contains it
+	externalTargetRegex = regexp.MustCompile(`\sTarget\s*=\s*"(?:[A-Za-z][A-Za-z0-9+.-]*:(?://)?)?([^"]*)"`)
+)
+
+// anonymizedLetters are the replacements of lower and upper case letters by the length of their UTF-8 encoding,
+// so that the anonymized text has the same byte length.
+var anonymizedLetters = map[int][2]rune{1: {'x', 'X'}, 2: {'х', 'Х'}, 3: {'ｘ', 'Ｘ'}, 4: {'𝐱', '𝐗'}}
+
+// anonymizedDigits are the replacements of digits by the length of their UTF-8 encoding.
+var anonymizedDigits = map[int]rune{1: '9', 2: '٩', 3: '９', 4: '𝟗'}
+
+// AnonymizeOptions configure Anonymize.
+type AnonymizeOptions struct {
+	// KeepProperties keeps the core, extended and custom properties of the document.
+	KeepProperties bool
+	// KeepImages keeps the images of the document.
+	KeepImages bool
+}
+
+// Anonymize replaces all content of the document which may identify a customer, so that the document can be
+// shared, e.g. as the fixture of a bug report. The structure is kept intact: every part keeps its markup, styles
+// and relationships, and the parsed parts keep the exact byte positions of all runs. This way parser bugs
+// reproduce on the anonymized document.
+//
+// The following is anonymized:
+//   - The text of all parts: letters become 'x' or 'X', digits become '9'. Whitespace, punctuation and symbols are
+//     kept, so placeholders like '{name}' stay placeholders ('{xxxx}'). Characters are replaced by characters of the
+//     same UTF-8 length, e.g. Cyrillic letters by 'х'.
+//   - The quoted arguments of field instructions (e.g. the url of a HYPERLINK field), the alternative texts of
+//     drawings, the authors of comments and revisions and the data of bound content controls in the same way.
+//     Field types and switches are kept.
+//   - The targets of external relationships (e.g. hyperlinks) after their scheme.
+//   - The core and custom properties which identify the document or its authors, unless KeepProperties is set.
+//   - PNG and JPEG images become gray images of the same dimensions, unless KeepImages is set. Other images are kept
+//     and reported as a warning.
+//
+// After anonymizing, all parsed parts are parsed again and their runs are compared with the original ones.
+// If the positions differ, ErrAnonymizationChangedRuns is returned and the document is not modified.
+func Anonymize(doc *Document, opts AnonymizeOptions) error {
+	parts := make(map[string][]byte)
+	var unsupported []string
+	for _, name := range doc.packagePartNames() {
+		if !doc.partExists(name) {
+			continue
+		}
+		data, err := doc.getPart(name)
+		if err != nil {
+			return err
+		}
+
+		var anonymized []byte
+		switch {
+		case strings.HasPrefix(name, "word/media/"):
+			if opts.KeepImages {
+				continue
+			}
+			var ok bool
+			if anonymized, ok = anonymizeImage(data); !ok {
+				unsupported = append(unsupported, name)
+				continue
+			}
+		case strings.HasPrefix(name, "customXml/item") && !strings.HasPrefix(name, "customXml/itemProps"):
+			// the data of content controls which are bound to custom XML
+			anonymized = replaceGroup(textNodeRegex, data, anonymizeText)
+		case strings.HasSuffix(name, ".rels"):
+			anonymized = anonymizeRelationships(data)
+		case strings.HasPrefix(name, "docProps/"):
+			if opts.KeepProperties {
+				continue
+			}
+			anonymized = corePropertyRegex.ReplaceAll(anonymizeMarkup(data), []byte("$1$2"))
+		case strings.HasSuffix(name, ".xml"):
+			anonymized = anonymizeMarkup(data)
+		}
+		if anonymized != nil && !bytes.Equal(anonymized, data) {
+			parts[name] = anonymized
+		}
+	}
+
+	// parse the anonymized parts before applying them, the runs must not move
+	for name, data := range parts {
+		parser, parsed := doc.runParsers[name]
+		if !parsed {
+			continue
+		}
+		anonymizedParser := NewRunParser(data)
+		anonymizedParser.fieldInstructions = parser.fieldInstructions
+		anonymizedParser.decoderSettings = parser.decoderSettings
+		anonymizedParser.maxNesting = parser.maxNesting
+		anonymizedParser.mathText = parser.mathText
+		if err := anonymizedParser.Execute(); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrAnonymizationChangedRuns, name, err)
+		}
+		if !sameRuns(parser.Runs(), anonymizedParser.Runs()) {
+			return fmt.Errorf("%w: %s", ErrAnonymizationChangedRuns, name)
+		}
+	}
+
+	for _, name := range sortedPartNames(parts) {
+		if err := doc.updatePart(name, parts[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range unsupported {
+		doc.warnOnce(fmt.Sprintf("image %s has an unsupported format and was not anonymized", name))
+	}
+	return nil
+}
+
+// anonymizeMarkup anonymizes the text, the field arguments, the alternative texts and the authors of the markup.
+func anonymizeMarkup(data []byte) []byte {
+	data = replaceGroup(anonymizedTextRegex, data, anonymizeText)
+	data = replaceGroup(authorAttributeRegex, data, anonymizeText)
+	data = anonymizedInstructionRegex.ReplaceAllFunc(data, func(instruction []byte) []byte {
+		return replaceGroup(quotedArgumentRegex, instruction, anonymizeText)
+	})
+	for _, tagRegex := range []*regexp.Regexp{DrawingPropertiesTagRegex, ShapeTagRegex} {
+		data = tagRegex.ReplaceAllFunc(data, func(tag []byte) []byte {
+			return replaceGroup(alternativeTextAttributeRegex, tag, anonymizeText)
+		})
+	}
+	return data
+}
+
+// anonymizeRelationships anonymizes the targets of all external relationships.
+func anonymizeRelationships(data []byte) []byte {
+	return RelationshipTagRegex.ReplaceAllFunc(data, func(tag []byte) []byte {
+		if mode, _ := attributeValue(tag, "TargetMode"); mode != "External" {
+			return tag
+		}
+		return replaceGroup(externalTargetRegex, tag, anonymizeText)
+	})
+}
+
+// replaceGroup returns a copy of data in which the first group of every match of the regex is replaced.
+func replaceGroup(regex *regexp.Regexp, data []byte, replace func(string) string) []byte {
+	var result []byte
+	last := 0
+	for _, loc := range regex.FindAllSubmatchIndex(data, -1) {
+		result = append(result, data[last:loc[2]]...)
+		result = append(result, replace(string(data[loc[2]:loc[3]]))...)
+		last = loc[3]
+	}
+	return append(result, data[last:]...)
+}
+
+// anonymizeText replaces all letters and digits of the escaped text by characters of the same class and the same
+// UTF-8 length. Entities are kept, numeric character references are replaced if they fit into the reference.
+func anonymizeText(text string) string {
+	var result strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] == '&' {
+			if end := strings.IndexByte(text[i:], ';'); end > 0 {
+				result.WriteString(anonymizeReference(text[i : i+end+1]))
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		result.WriteRune(anonymizeRune(r, size))
+		i += size
+	}
+	return result.String()
+}
+
+// anonymizeRune returns the replacement of the character, which has the given UTF-8 length.
+func anonymizeRune(r rune, size int) rune {
+	switch {
+	case r == utf8.RuneError || unicode.IsSpace(r):
+		return r
+	case unicode.IsLetter(r):
+		if unicode.IsUpper(r) {
+			return anonymizedLetters[size][1]
+		}
+		return anonymizedLetters[size][0]
+	case unicode.IsDigit(r):
+		return anonymizedDigits[size]
+	}
+	return r
+}
+
+// anonymizeReference returns the replacement of an entity. Named entities (e.g. '&amp;') are kept, numeric character
+// references are replaced by the reference of the replacement character, padded to the same length with zeros.
+func anonymizeReference(reference string) string {
+	number := strings.TrimSuffix(strings.TrimPrefix(reference, "&#"), ";")
+	if number == reference[1:len(reference)-1] {
+		return reference
+	}
+	base, format := 10, "%0*d"
+	if strings.HasPrefix(number, "x") || strings.HasPrefix(number, "X") {
+		base, format = 16, number[:1]+"%0*x"
+		number = number[1:]
+	}
+	code, err := strconv.ParseInt(number, base, 32)
+	if err != nil {
+		return reference
+	}
+
+	r := rune(code)
+	replacement := anonymizeRune(r, 1)
+	if replacement == r {
+		return reference
+	}
+	digits := fmt.Sprintf(format, len(number), replacement)
+	if len(digits) > len(reference)-3 {
+		return reference
+	}
+	return "&#" + digits + ";"
+}
+
+// anonymizeImage returns a gray image of the same dimensions and format as the given PNG or JPEG image.
+func anonymizeImage(data []byte) ([]byte, bool) {
+	width, height, format, err := ImageDimensions(data)
+	if err != nil {
+		return nil, false
+	}
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(gray, gray.Bounds(), image.NewUniform(color.Gray{Y: 0xc0}), image.Point{}, draw.Src)
+
+	buf := new(bytes.Buffer)
+	if format == "png" {
+		err = png.Encode(buf, gray)
+	} else {
+		err = jpeg.Encode(buf, gray, nil)
+	}
+	if err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// sameRuns returns true if both lists contain runs at the same positions.
+func sameRuns(a, b []*Run) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].TagPair != b[i].TagPair || a[i].Text != b[i].Text || a[i].HasText != b[i].HasText {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedPartNames returns the names of the parts in lexical order.
+func sortedPartNames(parts map[string][]byte) []string {
+	var names []string
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
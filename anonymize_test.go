@@ -0,0 +1,138 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Jane Doe, ул. Абая 15</w:t></w:r><w:r><w:t xml:space="preserve"> &amp; caf&#233; {cus</w:t></w:r>` +
+		`<w:r><w:t>tomer}</w:t></w:r></w:p>` +
+		`<w:p><w:ins w:id="1" w:author="John Smith" w:date="2021-01-01T00:00:00Z"><w:r><w:t>inserted</w:t></w:r></w:ins></w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> HYPERLINK "https://jane.example.com" \o "Jane" </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>site</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:r><w:drawing><wp:inline><wp:extent cx="100" cy="100"/><wp:docPr id="1" name="Picture 1" descr="Photo of Jane"/></wp:inline></w:drawing></w:r></w:p>`
+	original := testImage(t, "png", 20, 10)
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(body),
+		"word/comments.xml": `<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:comment w:id="0" w:author="John Smith" w:initials="JS"><w:p><w:r><w:t>Call Jane</w:t></w:r></w:p></w:comment></w:comments>`,
+		RelsPath(DocumentXml): `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="mailto:jane@example.com" TargetMode="External"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/></Relationships>`,
+		"word/media/image1.png": string(original),
+		"word/media/image2.emf": "EMF",
+		CoreXml: `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title>Contract Jane</dc:title><dc:creator>John Smith</dc:creator><cp:revision>3</cp:revision></cp:coreProperties>`,
+	})
+	runsBefore := doc.Runs()
+
+	if err := Anonymize(doc, AnonymizeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{
+		`<w:t>Xxxx Xxx, хх. Хххх 99</w:t>`,
+		`<w:t xml:space="preserve"> &amp; xxx&#120; {xxx</w:t></w:r><w:r><w:t>xxxxx}</w:t>`,
+		`w:author="Xxxx Xxxxx" w:date="2021-01-01T00:00:00Z"`,
+		`HYPERLINK "xxxxx://xxxx.xxxxxxx.xxx" \o "Xxxx" `,
+		`<wp:docPr id="1" name="Picture 1" descr="Xxxxx xx Xxxx"/>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+	if count := len(doc.Placeholders()); count != 1 {
+		t.Errorf("expected the placeholder to be kept, have=%d", count)
+	}
+	if !sameRuns(runsBefore, doc.Runs()) {
+		t.Error("the runs have been moved")
+	}
+
+	for part, expected := range map[string]string{
+		"word/comments.xml":   `<w:comment w:id="0" w:author="Xxxx Xxxxx" w:initials="XX"><w:p><w:r><w:t>Xxxx Xxxx</w:t>`,
+		RelsPath(DocumentXml): `Target="mailto:xxxx@xxxxxxx.xxx" TargetMode="External"`,
+		CoreXml:               `<dc:title></dc:title><dc:creator></dc:creator><cp:revision>3</cp:revision>`,
+	} {
+		data, err := doc.getPart(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in %s", expected, data)
+		}
+	}
+
+	data, err := doc.getPart("word/media/image1.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, _ := img.At(5, 5).RGBA(); format != "png" || img.Bounds().Dx() != 20 || img.Bounds().Dy() != 10 || r != g || g != b {
+		t.Errorf("expected a gray png of 20x10 pixels, got a %s of %v with %v", format, img.Bounds(), img.At(5, 5))
+	}
+	if warnings := doc.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "word/media/image2.emf") {
+		t.Errorf("expected a warning about the unsupported image, got %q", warnings)
+	}
+}
+
+func TestAnonymize_Keep(t *testing.T) {
+	original := testImage(t, "jpeg", 8, 8)
+	core := `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+		`<dc:creator>John Smith</dc:creator></cp:coreProperties>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:              testDocumentXml(`<w:p><w:r><w:t>Jane</w:t></w:r></w:p>`),
+		"word/media/image1.jpeg": string(original),
+		CoreXml:                  core,
+	})
+	if err := Anonymize(doc, AnonymizeOptions{KeepProperties: true, KeepImages: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := doc.getPart(CoreXml); string(data) != core {
+		t.Errorf("the properties were changed: %s", data)
+	}
+	if data, _ := doc.getPart("word/media/image1.jpeg"); !bytes.Equal(data, original) {
+		t.Error("the image was changed")
+	}
+	if !strings.Contains(string(doc.GetFile(DocumentXml)), "<w:t>Xxxx</w:t>") {
+		t.Errorf("the text was not anonymized: %s", doc.GetFile(DocumentXml))
+	}
+}
+
+func TestAnonymize_ChangedRuns(t *testing.T) {
+	// the runs of the stale parser differ from the runs of the anonymized data, as if anonymizing had moved them
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Jane</w:t></w:r></w:p>`)})
+	doc.files[DocumentXml] = []byte(testDocumentXml(`<w:p><w:r><w:t>Jane</w:t></w:r><w:r><w:t>Doe</w:t></w:r></w:p>`))
+
+	before := string(doc.GetFile(DocumentXml))
+	if err := Anonymize(doc, AnonymizeOptions{}); !errors.Is(err, ErrAnonymizationChangedRuns) {
+		t.Fatalf("expected ErrAnonymizationChangedRuns, got %v", err)
+	}
+	if string(doc.GetFile(DocumentXml)) != before {
+		t.Error("the document was modified")
+	}
+}
+
+func TestAnonymizeText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Hello, World 2021!", "Xxxxx, Xxxxx 9999!"},
+		{"Алматы ٣ 東京 ３", "Хххххх ٩ ｘｘ ９"},
+		{"&lt;a&gt; &#65;&#x42;&#x1F600;&#9;", "&lt;x&gt; &#88;&#x58;&#x1F600;&#9;"},
+		{"x\ty\n", "x\tx\n"},
+	}
+	for _, tt := range tests {
+		if result := anonymizeText(tt.text); result != tt.expected || len(result) != len(tt.text) {
+			t.Errorf("%q: expected %q, got %q", tt.text, tt.expected, result)
+		}
+	}
+}
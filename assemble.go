@@ -0,0 +1,449 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrInvalidAssemblyItem is returned by Assemble if an item of the manifest is not exactly one of a clause, a page
+	// break or a heading.
+	ErrInvalidAssemblyItem = errors.New("invalid assembly item")
+
+	// AbstractNumberingRegex matches an abstract numbering definition (<w:abstractNum>) of the numbering part
+	AbstractNumberingRegex = regexp.MustCompile(`(?s)<w:abstractNum\s[^>]*>.*?</w:abstractNum>`)
+	// NumberingInstanceRegex matches a numbering definition instance (<w:num>) of the numbering part
+	NumberingInstanceRegex = regexp.MustCompile(`(?s)<w:num\s[^>]*/>|<w:num\s[^>]*[^/]>.*?</w:num>`)
+
+	// abstractNumberingIdTagRegex matches the reference of a numbering instance to its abstract numbering
+	abstractNumberingIdTagRegex = regexp.MustCompile(`<w:abstractNumId(?:\s[^>]*)?/>`)
+	// numberingInstancesEndRegex matches the position after the last numbering instance of the numbering part
+	numberingInstancesEndRegex = regexp.MustCompile(`<w:numIdMacAtCleanup[\s/>]|</w:numbering>`)
+	// numberingInstanceStartRegex matches the start of the first numbering instance of the numbering part
+	numberingInstanceStartRegex = regexp.MustCompile(`<w:num[\s>]|<w:numIdMacAtCleanup[\s/>]|</w:numbering>`)
+)
+
+// pageBreakParagraph is the markup of a paragraph which contains a page break.
+const pageBreakParagraph = `<w:p><w:r><w:br w:type="page"/></w:r></w:p>`
+
+// AssemblyItem is a single entry of the manifest of Assemble. Exactly one of Document, PageBreak and Heading
+// must be set.
+type AssemblyItem struct {
+	// Document is a clause, its body is inserted after its placeholders were replaced with Values.
+	// The document itself is not modified, the same clause may be included several times.
+	Document *Document
+	// Values are the placeholder values of the clause.
+	Values PlaceholderMap
+	// PageBreak inserts a paragraph with a page break.
+	PageBreak bool
+	// Heading inserts a paragraph with the text, formatted with the heading style of HeadingLevel ('Heading1').
+	Heading string
+	// HeadingLevel is the level of the heading, from 1 to 9. Level 1 is used if it is not set.
+	HeadingLevel int
+}
+
+// AssembleOptions configure Assemble.
+type AssembleOptions struct {
+	// Base is the document to which the items are appended, e.g. a template with the letterhead and the section
+	// properties. Its body is kept and the base itself is not modified. If it is not set, the first clause of the
+	// manifest is the base.
+	Base *Document
+	// Options are applied when the base and the clauses are opened for rendering, e.g. WithTypography.
+	Options []Option
+}
+
+// Assemble builds a new document from the ordered manifest, e.g. a contract from reusable clauses.
+// The placeholders of every clause are replaced with its own values before the body of the clause is inserted,
+// the items are inserted in order at the end of the body of the base, before its section properties.
+//
+// The clauses are merged like ReplaceWithDocument does, with these differences:
+//   - Images and other parts are copied only once: a part with the same content and type as a part which the
+//     document already references is shared, so that including a clause several times does not duplicate its media.
+//   - List numbering is carried over: the numbering definitions the clause uses are added to the numbering of the
+//     result with new ids, so that each inclusion of a clause has its own lists.
+//
+// The assembled document is validated using Validate before it is returned.
+func Assemble(manifest []AssemblyItem, opts AssembleOptions) (*Document, error) {
+	baseItem := -1
+	for i, item := range manifest {
+		kinds := 0
+		if item.Document != nil {
+			kinds++
+			if baseItem < 0 && opts.Base == nil {
+				baseItem = i
+			}
+		}
+		if item.PageBreak {
+			kinds++
+		}
+		if item.Heading != "" {
+			kinds++
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("%w: item %d must be exactly one of a clause, a page break or a heading", ErrInvalidAssemblyItem, i+1)
+		}
+		if item.HeadingLevel < 0 || item.HeadingLevel > maxNumberingLevels {
+			return nil, fmt.Errorf("%w: item %d has the invalid heading level %d", ErrInvalidAssemblyItem, i+1, item.HeadingLevel)
+		}
+	}
+
+	base, values := opts.Base, PlaceholderMap(nil)
+	if base == nil {
+		if baseItem < 0 {
+			return nil, fmt.Errorf("%w: the manifest contains no clause and no base is given", ErrInvalidAssemblyItem)
+		}
+		base, values = manifest[baseItem].Document, manifest[baseItem].Values
+	}
+	result, err := renderClause(base, values, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render the base: %w", err)
+	}
+
+	a, err := newAssembler(result)
+	if err != nil {
+		return nil, err
+	}
+	var before, after []byte
+	for i, item := range manifest {
+		if i == baseItem {
+			continue
+		}
+		markup, err := a.markup(item, opts.Options)
+		if err != nil {
+			return nil, fmt.Errorf("unable to assemble item %d: %w", i+1, err)
+		}
+		if i < baseItem {
+			before = append(before, markup...)
+		} else {
+			after = append(after, markup...)
+		}
+	}
+
+	data := result.GetFile(DocumentXml)
+	elements, err := BodyElements(data)
+	if err != nil {
+		return nil, err
+	}
+	bodyEnd := bytes.LastIndex(data, []byte("</w:body>"))
+	if bodyEnd < 0 {
+		return nil, fmt.Errorf("missing body element %s", DocumentXml)
+	}
+	start, end := int64(bodyEnd), int64(bodyEnd)
+	if len(elements) > 0 {
+		start = elements[0].Position.Start
+		if last := elements[len(elements)-1]; last.Name == SectionPropertiesElementName {
+			end = last.Position.Start
+		}
+	}
+	data = applyEdits(data, []edit{
+		{Position: Position{Start: start, End: start}, Replacement: before},
+		{Position: Position{Start: end, End: end}, Replacement: after},
+	})
+	for _, source := range a.sources {
+		if data, err = declareNamespaces(data, source); err != nil {
+			return nil, err
+		}
+	}
+	if err := result.SetFile(DocumentXml, data); err != nil {
+		return nil, err
+	}
+	if err := result.parseFile(DocumentXml); err != nil {
+		return nil, err
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// renderClause opens a copy of the document and replaces its placeholders with the values.
+func renderClause(doc *Document, values PlaceholderMap, opts []Option) (*Document, error) {
+	buf := new(bytes.Buffer)
+	if err := doc.Write(buf); err != nil {
+		return nil, err
+	}
+	clause, err := OpenBytes(buf.Bytes(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) > 0 {
+		if err := clause.ReplaceAll(values); err != nil {
+			return nil, err
+		}
+	}
+	return clause, nil
+}
+
+// assembler inserts the items of a manifest into the assembled document.
+type assembler struct {
+	result *Document
+	// parts maps the content of the parts which the result references to their relationship IDs
+	parts map[string]string
+	// nextDrawingID, nextNumID and nextAbstractNumID are the next free ids of the result
+	nextDrawingID     int
+	nextNumID         int
+	nextAbstractNumID int
+	// sources are the main documents of the inserted clauses, whose namespaces must be declared
+	sources [][]byte
+}
+
+func newAssembler(result *Document) (*assembler, error) {
+	a := &assembler{
+		result:        result,
+		parts:         make(map[string]string),
+		nextDrawingID: maxDrawingID(result.GetFile(DocumentXml)) + 1,
+	}
+	rels, err := result.Relationships(DocumentXml)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		target := resolveTarget(DocumentXml, rel.Target)
+		if rel.IsExternal() || !result.partExists(target) {
+			continue
+		}
+		key, err := partKey(result, rel, target)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := a.parts[key]; !exists {
+			a.parts[key] = rel.ID
+		}
+	}
+
+	if result.partExists(NumberingXml) {
+		numbering, err := result.getPart(NumberingXml)
+		if err != nil {
+			return nil, err
+		}
+		a.nextNumID = maxAttribute(NumberingInstanceRegex.FindAll(numbering, -1), "w:numId") + 1
+		a.nextAbstractNumID = maxAttribute(AbstractNumberingRegex.FindAll(numbering, -1), "w:abstractNumId") + 1
+	}
+	if a.nextNumID < 1 {
+		a.nextNumID = 1
+	}
+	return a, nil
+}
+
+// markup returns the markup of the item for the body of the result.
+func (a *assembler) markup(item AssemblyItem, opts []Option) ([]byte, error) {
+	switch {
+	case item.PageBreak:
+		return []byte(pageBreakParagraph), nil
+	case item.Heading != "":
+		level := item.HeadingLevel
+		if level == 0 {
+			level = 1
+		}
+		style := "Heading" + strconv.Itoa(level)
+		styles, err := a.result.styleDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		if styles.style(style) == nil {
+			a.result.warnOnce(fmt.Sprintf("the heading style %s is not defined", style))
+		}
+		return []byte(`<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr><w:r><w:t xml:space="preserve">` +
+			xmlEscape(item.Heading) + `</w:t></w:r></w:p>`), nil
+	}
+
+	clause, err := renderClause(item.Document, item.Values, opts)
+	if err != nil {
+		return nil, err
+	}
+	body, err := clause.bodyContent()
+	if err != nil {
+		return nil, err
+	}
+	if body, err = a.importNumbering(clause, body); err != nil {
+		return nil, err
+	}
+	copyRelationship := func(rel Relationship) (string, error) { return a.copyRelationship(clause, rel) }
+	if body, err = a.result.relocateRelationships(clause, body, copyRelationship); err != nil {
+		return nil, err
+	}
+	if err := a.result.copyStyles(clause, body); err != nil {
+		return nil, err
+	}
+	body, a.nextDrawingID = renumberDrawings(body, a.nextDrawingID)
+	a.sources = append(a.sources, clause.GetFile(DocumentXml))
+	return body, nil
+}
+
+// copyRelationship adds the relationship of the clause to the result, unless the result already references a part
+// with the same content and type.
+func (a *assembler) copyRelationship(clause *Document, rel Relationship) (string, error) {
+	if rel.IsExternal() {
+		return a.result.copyRelationship(clause, rel)
+	}
+	key, err := partKey(clause, rel, resolveTarget(DocumentXml, rel.Target))
+	if err != nil {
+		return "", err
+	}
+	if id, exists := a.parts[key]; exists {
+		return id, nil
+	}
+	id, err := a.result.copyRelationship(clause, rel)
+	if err != nil {
+		return "", err
+	}
+	a.parts[key] = id
+	return id, nil
+}
+
+// partKey identifies the target part of the internal relationship by its relationship type, content type and content.
+func partKey(doc *Document, rel Relationship, part string) (string, error) {
+	data, err := doc.getPart(part)
+	if err != nil {
+		return "", err
+	}
+	contentType, err := doc.ContentType(part)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %x", rel.Type, contentType, sha256.Sum256(data)), nil
+}
+
+// importNumbering adds the numbering definitions which the body and the styles of the clause use to the result
+// with new ids, and returns the body with the new ids. The styles of the clause are updated as well, so that the
+// styles copied afterwards reference the new definitions.
+func (a *assembler) importNumbering(clause *Document, body []byte) ([]byte, error) {
+	if !clause.partExists(NumberingXml) {
+		return body, nil
+	}
+	numbering, err := clause.getPart(NumberingXml)
+	if err != nil {
+		return nil, err
+	}
+	var styles []byte
+	if clause.partExists(StylesXml) {
+		if styles, err = clause.getPart(StylesXml); err != nil {
+			return nil, err
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, markup := range [][]byte{body, styles} {
+		for _, tag := range NumberingIdTagRegex.FindAll(markup, -1) {
+			if id, _ := attributeValue(tag, "w:val"); id != "" && id != "0" {
+				used[id] = true
+			}
+		}
+	}
+	if len(used) == 0 {
+		return body, nil
+	}
+
+	abstracts := make(map[string][]byte)
+	for _, definition := range AbstractNumberingRegex.FindAll(numbering, -1) {
+		id, _ := attributeValue(definition[:bytes.IndexByte(definition, '>')+1], "w:abstractNumId")
+		abstracts[id] = definition
+	}
+	var (
+		numIDs          = make(map[string]string)
+		abstractIDs     = make(map[string]string)
+		abstractMarkup  []byte
+		instancesMarkup []byte
+	)
+	for _, instance := range NumberingInstanceRegex.FindAll(numbering, -1) {
+		openTag := string(instance[:bytes.IndexByte(instance, '>')+1])
+		id, _ := attributeValue([]byte(openTag), "w:numId")
+		if !used[id] {
+			continue
+		}
+		numIDs[id] = strconv.Itoa(a.nextNumID)
+		a.nextNumID++
+
+		abstractTag := abstractNumberingIdTagRegex.Find(instance)
+		abstractID, _ := attributeValue(abstractTag, "w:val")
+		newAbstractID, imported := abstractIDs[abstractID]
+		if !imported {
+			definition, exists := abstracts[abstractID]
+			if !exists {
+				return nil, fmt.Errorf("abstract numbering %s of the clause does not exist", abstractID)
+			}
+			newAbstractID = strconv.Itoa(a.nextAbstractNumID)
+			a.nextAbstractNumID++
+			abstractIDs[abstractID] = newAbstractID
+			definitionTag := string(definition[:bytes.IndexByte(definition, '>')+1])
+			abstractMarkup = append(abstractMarkup, setAttribute(definitionTag, "w:abstractNumId", newAbstractID)...)
+			abstractMarkup = append(abstractMarkup, definition[len(definitionTag):]...)
+		}
+
+		renumbered := setAttribute(openTag, "w:numId", numIDs[id]) + string(instance[len(openTag):])
+		renumbered = abstractNumberingIdTagRegex.ReplaceAllLiteralString(renumbered, setAttribute(string(abstractTag), "w:val", newAbstractID))
+		instancesMarkup = append(instancesMarkup, renumbered...)
+	}
+
+	renumber := func(markup []byte) []byte {
+		return NumberingIdTagRegex.ReplaceAllFunc(markup, func(tag []byte) []byte {
+			id, _ := attributeValue(tag, "w:val")
+			if newID, exists := numIDs[id]; exists {
+				return []byte(setAttribute(string(tag), "w:val", newID))
+			}
+			return tag
+		})
+	}
+	if styles != nil {
+		if err := clause.setPart(StylesXml, renumber(styles)); err != nil {
+			return nil, err
+		}
+	}
+	if len(numIDs) > 0 {
+		if err := a.appendNumbering(abstractMarkup, instancesMarkup); err != nil {
+			return nil, err
+		}
+	}
+	return renumber(body), nil
+}
+
+// appendNumbering adds the abstract numbering definitions and the numbering instances to the numbering part of the
+// result. If the result has no numbering part, one is added.
+func (a *assembler) appendNumbering(abstracts, instances []byte) error {
+	var numbering []byte
+	if a.result.partExists(NumberingXml) {
+		var err error
+		if numbering, err = a.result.getPart(NumberingXml); err != nil {
+			return err
+		}
+	} else {
+		numbering = []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+			`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:numbering>`)
+		if err := a.result.ensureContentType(NumberingXml, NumberingContentType); err != nil {
+			return err
+		}
+		if _, err := a.result.addRelationship(DocumentXml, Relationship{
+			Type:   NumberingRelationshipType,
+			Target: relativeTarget(DocumentXml, NumberingXml),
+		}); err != nil {
+			return err
+		}
+	}
+
+	// the schema requires all abstract numbering definitions before the numbering instances
+	abstractsLoc := numberingInstanceStartRegex.FindIndex(numbering)
+	instancesLoc := numberingInstancesEndRegex.FindIndex(numbering)
+	if abstractsLoc == nil || instancesLoc == nil {
+		return fmt.Errorf("invalid numbering part %s", NumberingXml)
+	}
+	return a.result.setPart(NumberingXml, applyEdits(numbering, []edit{
+		{Position: Position{Start: int64(abstractsLoc[0]), End: int64(abstractsLoc[0])}, Replacement: abstracts},
+		{Position: Position{Start: int64(instancesLoc[0]), End: int64(instancesLoc[0])}, Replacement: instances},
+	}))
+}
+
+// maxAttribute returns the highest numeric value of the attribute of the open tags of the elements.
+func maxAttribute(elements [][]byte, attribute string) int {
+	maxValue := 0
+	for _, element := range elements {
+		value, _ := attributeValue(element[:bytes.IndexByte(element, '>')+1], attribute)
+		if number, _ := strconv.Atoi(value); number > maxValue {
+			maxValue = number
+		}
+	}
+	return maxValue
+}
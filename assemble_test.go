@@ -0,0 +1,155 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testNumberingXml(definitions string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + definitions + `</w:numbering>`
+}
+
+func TestAssemble(t *testing.T) {
+	base := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Letterhead</w:t></w:r></w:p>` +
+			`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`),
+		StylesXml: testStylesXml(`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>`),
+		NumberingXml: testNumberingXml(`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>` +
+			`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>`),
+		RelsPath(DocumentXml): `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + NumberingRelationshipType + `" Target="numbering.xml"/></Relationships>`,
+	})
+	clause := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:pPr><w:pStyle w:val="ListClause"/></w:pPr><w:r><w:t>The party {party} agrees.</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="5"/></w:numPr></w:pPr><w:r><w:drawing><wp:inline>` +
+			`<wp:docPr id="1" name="Seal"/><a:blip r:embed="rId7"/></wp:inline></w:drawing></w:r></w:p>` +
+			`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr>`),
+		StylesXml: testStylesXml(`<w:style w:type="paragraph" w:styleId="ListClause"><w:name w:val="List Clause"/>` +
+			`<w:pPr><w:numPr><w:numId w:val="6"/></w:numPr></w:pPr></w:style>`),
+		NumberingXml: testNumberingXml(`<w:abstractNum w:abstractNumId="3"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+			`<w:abstractNum w:abstractNumId="4"><w:lvl w:ilvl="0"><w:numFmt w:val="upperLetter"/></w:lvl></w:abstractNum>` +
+			`<w:num w:numId="5"><w:abstractNumId w:val="3"/></w:num><w:num w:numId="6"><w:abstractNumId w:val="3"/></w:num>` +
+			`<w:num w:numId="7"><w:abstractNumId w:val="4"/></w:num>`),
+		RelsPath(DocumentXml): `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId7" Type="` + ImageRelationshipType + `" Target="media/image1.png"/></Relationships>`,
+		"word/media/image1.png": "seal",
+	})
+	signature := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Signed by {name}</w:t></w:r></w:p>`),
+	})
+
+	doc, err := Assemble([]AssemblyItem{
+		{Heading: "Parties & terms"},
+		{Document: clause, Values: PlaceholderMap{"party": "Jane"}},
+		{Document: clause, Values: PlaceholderMap{"party": "John"}},
+		{PageBreak: true},
+		{Heading: "Signatures", HeadingLevel: 2},
+		{Document: signature, Values: PlaceholderMap{"name": "Jane"}},
+	}, AssembleOptions{Base: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = reopen(t, doc)
+
+	document := string(doc.GetFile(DocumentXml))
+	expected := `<w:t>Letterhead</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">Parties &amp; terms</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="ListClause"/></w:pPr><w:r><w:t>The party Jane agrees.</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="2"/></w:numPr></w:pPr><w:r><w:drawing><wp:inline>` +
+		`<wp:docPr id="1" name="Seal"/><a:blip r:embed="rId2"/></wp:inline></w:drawing></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="ListClause"/></w:pPr><w:r><w:t>The party John agrees.</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="4"/></w:numPr></w:pPr><w:r><w:drawing><wp:inline>` +
+		`<wp:docPr id="2" name="Seal"/><a:blip r:embed="rId2"/></wp:inline></w:drawing></w:r></w:p>` +
+		pageBreakParagraph +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t xml:space="preserve">Signatures</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Signed by Jane</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`
+	if !strings.Contains(document, expected) {
+		t.Errorf("expected %s in %s", expected, document)
+	}
+
+	var media []string
+	for _, name := range doc.packagePartNames() {
+		if strings.HasPrefix(name, "word/media/") {
+			media = append(media, name)
+		}
+	}
+	if len(media) != 1 {
+		t.Errorf("expected the image to be copied once, got %v", media)
+	}
+
+	numbering, err := doc.getPart(NumberingXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every inclusion has its own lists, the unused definitions are not copied
+	expectedNumbering := `<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl></w:abstractNum>` +
+		`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+		`<w:abstractNum w:abstractNumId="2"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+		`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>` +
+		`<w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num><w:num w:numId="3"><w:abstractNumId w:val="1"/></w:num>` +
+		`<w:num w:numId="4"><w:abstractNumId w:val="2"/></w:num><w:num w:numId="5"><w:abstractNumId w:val="2"/></w:num>`
+	if !strings.Contains(string(numbering), expectedNumbering) {
+		t.Errorf("expected %s in %s", expectedNumbering, numbering)
+	}
+	styles, err := doc.getPart(StylesXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<w:style w:type="paragraph" w:styleId="ListClause"><w:name w:val="List Clause"/><w:pPr><w:numPr><w:numId w:val="3"/>`; !strings.Contains(string(styles), expected) {
+		t.Errorf("expected %s in %s", expected, styles)
+	}
+
+	if warnings := doc.Warnings(); len(warnings) != 0 {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+	if !strings.Contains(string(clause.GetFile(DocumentXml)), "{party}") {
+		t.Error("the clause was modified")
+	}
+}
+
+func TestAssemble_FirstClauseIsBase(t *testing.T) {
+	clause := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p><w:sectPr/>`),
+	})
+	doc, err := Assemble([]AssemblyItem{
+		{Heading: "Letter"},
+		{Document: clause, Values: PlaceholderMap{"name": "Jane"}},
+		{Document: clause, Values: PlaceholderMap{"name": "John"}},
+	}, AssembleOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<w:body><w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t xml:space="preserve">Letter</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>Dear Jane,</w:t></w:r></w:p><w:p><w:r><w:t>Dear John,</w:t></w:r></w:p><w:sectPr/></w:body>`
+	if document := string(doc.GetFile(DocumentXml)); !strings.Contains(document, expected) {
+		t.Errorf("expected %s in %s", expected, document)
+	}
+	if _, err := doc.getPart(NumberingXml); err == nil {
+		t.Error("unexpected numbering part")
+	}
+	if warnings := doc.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "Heading1") {
+		t.Errorf("expected a warning about the missing heading style, got %q", warnings)
+	}
+}
+
+func TestAssemble_InvalidItems(t *testing.T) {
+	clause := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p/>`)})
+	tests := []struct {
+		name     string
+		manifest []AssemblyItem
+	}{
+		{"empty item", []AssemblyItem{{Document: clause}, {}}},
+		{"several kinds", []AssemblyItem{{Document: clause, PageBreak: true}}},
+		{"heading level", []AssemblyItem{{Document: clause}, {Heading: "Title", HeadingLevel: 10}}},
+		{"no clause", []AssemblyItem{{Heading: "Title"}}},
+	}
+	for _, tt := range tests {
+		if _, err := Assemble(tt.manifest, AssembleOptions{}); !errors.Is(err, ErrInvalidAssemblyItem) {
+			t.Errorf("%s: expected ErrInvalidAssemblyItem, got %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package docx
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+)
+
+var (
+	// DrawingPropertiesTagRegex matches the non-visual properties of a drawing (<wp:docPr>), which hold the name and
+	// the alternative text of pictures, charts and embedded objects
+	DrawingPropertiesTagRegex = regexp.MustCompile(`<wp:docPr` + attributesPattern + `/?>`)
+	// ShapeTagRegex matches the open tag of a VML shape (<v:shape>), e.g. the preview of an embedded OLE object
+	ShapeTagRegex = regexp.MustCompile(`<v:shape` + attributesPattern + `/?>`)
+)
+
+// placeholderAttributes are the attributes which are scanned for placeholders by WithAttributePlaceholders.
+var placeholderAttributes = []struct {
+	tag        *regexp.Regexp
+	attributes []string
+}{
+	{DrawingPropertiesTagRegex, []string{"name", "descr", "title"}},
+	{ShapeTagRegex, []string{"alt", "title"}},
+}
+
+// WithAttributePlaceholders replaces placeholders inside the names and alternative texts of drawings as well, i.e.
+// the name, descr and title attributes of <wp:docPr> and the alt and title attributes of VML shapes. This covers
+// pictures, charts and embedded objects (e.g. spreadsheets), including OLE objects which are shown by a VML shape.
+//
+// The values are taken like text values: ReplaceAll expects them to be escaped already, Render escapes them.
+// Placeholders without a value are kept, they are not reported as missing.
+func WithAttributePlaceholders() Option {
+	return func(d *Document) {
+		d.attributePlaceholders = true
+	}
+}
+
+// replaceAttributes replaces the placeholders inside the attributes of all parsed parts, if enabled, and returns the
+// keys of the replaced placeholders. Escaped values are unescaped before they are escaped for the attribute.
+func (d *Document) replaceAttributes(placeholderMap PlaceholderMap, escaped bool) ([]string, error) {
+	if !d.attributePlaceholders {
+		return nil, nil
+	}
+	var replacedKeys []string
+	for _, name := range d.fileNames() {
+		data := d.GetFile(name)
+		var edits []edit
+		for _, element := range placeholderAttributes {
+			for _, loc := range element.tag.FindAllIndex(data, -1) {
+				tag := string(data[loc[0]:loc[1]])
+				replaced := tag
+				for _, attribute := range element.attributes {
+					raw, exists := attributeValue([]byte(replaced), attribute)
+					if !exists {
+						continue
+					}
+					value, keys, err := d.replaceReferences(placeholderMap, html.UnescapeString(raw), func(value string) string {
+						if escaped {
+							return html.UnescapeString(value)
+						}
+						return value
+					})
+					if err != nil {
+						return nil, fmt.Errorf("unable to replace attribute %s in %s: %w", attribute, name, err)
+					}
+					if len(keys) > 0 {
+						replaced = setAttribute(replaced, attribute, value)
+						replacedKeys = append(replacedKeys, keys...)
+					}
+				}
+				if replaced != tag {
+					edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[1])}, Replacement: []byte(replaced)})
+				}
+			}
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		sort.Slice(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+		if err := d.updatePart(name, applyEdits(data, edits)); err != nil {
+			return nil, err
+		}
+	}
+	return replacedKeys, nil
+}
@@ -0,0 +1,61 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_AttributePlaceholders(t *testing.T) {
+	chart := `<w:p><w:r><w:drawing><wp:inline><wp:extent cx="5486400" cy="3200400"/>` +
+		`<wp:docPr id="1" name="Chart {year}" descr="Revenue of {company} in {year}, see {appendix}"/>` +
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/chart"><c:chart r:id="rId5"/></a:graphicData></a:graphic>` +
+		`</wp:inline></w:drawing></w:r></w:p>`
+	ole := `<w:p><w:r><w:object><v:shape id="_x0000_i1025" type="#_x0000_t75" alt="Spreadsheet of {company}" style="width:300pt"/>` +
+		`<o:OLEObject Type="Embed" ProgID="Excel.Sheet.12" ShapeID="_x0000_i1025" r:id="rId6"/></w:object></w:r></w:p>`
+	body := chart + ole + `<w:p><w:r><w:t>{company}</w:t></w:r></w:p>`
+
+	tests := []struct {
+		name     string
+		options  []Option
+		expected []string
+	}{
+		{
+			name: "disabled by default",
+			expected: []string{
+				`<wp:docPr id="1" name="Chart {year}" descr="Revenue of {company} in {year}, see {appendix}"/>`,
+				`alt="Spreadsheet of {company}"`,
+			},
+		},
+		{
+			name:    "embedded objects",
+			options: []Option{WithAttributePlaceholders()},
+			expected: []string{
+				`<wp:docPr id="1" name="Chart 2021" descr="Revenue of Smith &amp; Sons in 2021, see {appendix}"/>`,
+				`<v:shape id="_x0000_i1025" type="#_x0000_t75" alt="Spreadsheet of Smith &amp; Sons" style="width:300pt"/>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"company": "Smith &amp; Sons", "year": 2021}); err != nil {
+				t.Fatal(err)
+			}
+			document := string(doc.GetFile(DocumentXml))
+			for _, expected := range tt.expected {
+				if !strings.Contains(document, expected) {
+					t.Errorf("expected %s in %s", expected, document)
+				}
+			}
+			if !strings.Contains(document, `<w:t>Smith &amp; Sons</w:t>`) {
+				t.Errorf("the text was not replaced: %s", document)
+			}
+			if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
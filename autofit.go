@@ -0,0 +1,301 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// AutoFitMode defines how Table.AutoFit sizes the columns of a table.
+type AutoFitMode int
+
+const (
+	// AutoFitContents recomputes the column widths from the estimated widths of the cell contents.
+	AutoFitContents AutoFitMode = iota
+	// AutoFitWindow makes the table span the full width of the page, the columns are sized by Word.
+	AutoFitWindow
+)
+
+const (
+	// defaultCellMargins is the sum of the default left and right cell margins in twips
+	defaultCellMargins = 216
+)
+
+var (
+	// tablePropertyOrder is the sequence in which the children of <w:tblPr> must occur according to the schema.
+	tablePropertyOrder = []string{
+		"tblStyle", "tblpPr", "tblOverlap", "bidiVisual", "tblStyleRowBandSize", "tblStyleColBandSize", "tblW",
+		"jc", "tblCellSpacing", "tblInd", "tblBorders", "shd", "tblLayout", "tblCellMar", "tblLook",
+		"tblCaption", "tblDescription", "tblPrChange",
+	}
+	// cellPropertyOrder is the sequence in which the children of <w:tcPr> must occur according to the schema.
+	cellPropertyOrder = []string{
+		"cnfStyle", "tcW", "gridSpan", "hMerge", "vMerge", "tcBorders", "shd", "noWrap", "tcMar",
+		"textDirection", "tcFitText", "vAlign", "hideMark", "headers", "cellIns", "cellDel", "cellMerge", "tcPrChange",
+	}
+
+	// TableCellPropertiesRegex matches the properties of a table cell (<w:tcPr>) at the beginning of the given data
+	TableCellPropertiesRegex = regexp.MustCompile(`^(?s:<w:tcPr(?:\s[^>]*)?>.*?</w:tcPr>|<w:tcPr\s*/>)`)
+	// GridSpanTagRegex matches the amount of grid columns a cell spans
+	GridSpanTagRegex = regexp.MustCompile(`<w:gridSpan(?:\s[^>]*)?/?>`)
+	// GridBeforeTagRegex matches the amount of grid columns which are skipped before the first cell of a row
+	GridBeforeTagRegex = regexp.MustCompile(`<w:gridBefore(?:\s[^>]*)?/?>`)
+)
+
+// tableCell is a cell of a table while computing its layout
+type tableCell struct {
+	element Element // relative to the table (or row)
+	column  int     // first grid column
+	span    int
+	width   int // estimated content width in twips, including the cell margins
+}
+
+// AutoFit changes the layout of the table. With AutoFitWindow the table spans the full page width, while
+// AutoFitContents computes the widths of all grid columns (and cells) from the estimated content widths using
+// the FontMetrics of the runs. Cells which span multiple grid columns distribute their width across these columns.
+// The total width never exceeds the content width of the page.
+//
+// Word versions before 2013 lay out tables differently, therefore a warning is recorded if the document
+// is in compatibility mode (see Document.Warnings).
+func (t *Table) AutoFit(mode AutoFitMode) error {
+	markup, err := t.Bytes()
+	if err != nil {
+		return err
+	}
+	t.doc.warnCompatibility("table auto fit")
+	markup = append([]byte(nil), markup...)
+
+	children, err := childElements(markup)
+	if err != nil {
+		return fmt.Errorf("unable to parse table: %s", err)
+	}
+
+	var edits []edit
+	tblPrInner := ""
+	tblPrPos := Position{Start: int64(len(TableOpenTagRegex.Find(markup)))}
+	tblPrPos.End = tblPrPos.Start
+	for _, child := range children {
+		if child.Name == "tblPr" {
+			tblPrInner = innerMarkup(child.Bytes(markup))
+			tblPrPos = child.Position
+		}
+	}
+
+	layout := `<w:tblLayout w:type="autofit"/>`
+	switch mode {
+	case AutoFitWindow:
+		tblPrInner = setProperty(tblPrInner, tablePropertyOrder, "w:tblW", `<w:tblW w:w="5000" w:type="pct"/>`)
+		tblPrInner = setProperty(tblPrInner, tablePropertyOrder, "w:tblLayout", layout)
+		edits = append(edits, edit{Position: tblPrPos, Replacement: []byte("<w:tblPr>" + tblPrInner + "</w:tblPr>")})
+	case AutoFitContents:
+		tblPrInner = setProperty(tblPrInner, tablePropertyOrder, "w:tblW", `<w:tblW w:w="0" w:type="auto"/>`)
+		tblPrInner = setProperty(tblPrInner, tablePropertyOrder, "w:tblLayout", layout)
+		edits = append(edits, edit{Position: tblPrPos, Replacement: []byte("<w:tblPr>" + tblPrInner + "</w:tblPr>")})
+
+		contentEdits, err := t.fitContents(markup, children, tblPrPos.End)
+		if err != nil {
+			return err
+		}
+		edits = append(edits, contentEdits...)
+	default:
+		return fmt.Errorf("unknown auto fit mode %d", mode)
+	}
+
+	// a missing grid is inserted right after the properties, at the same position
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Position.Start < edits[j].Position.Start
+	})
+	return t.update(applyEdits(markup, edits))
+}
+
+// fitContents returns the edits which replace the grid and the widths of all cells with the estimated widths.
+// If the table has no grid, it is inserted at gridPos.
+func (t *Table) fitContents(markup []byte, children []Element, gridPos int64) ([]edit, error) {
+	font, size := t.doc.defaultRunFont()
+
+	var cells []*tableCell
+	columns := 0
+	grid := Position{Start: gridPos, End: gridPos}
+	for _, child := range children {
+		switch child.Name {
+		case "tblGrid":
+			grid = child.Position
+			continue
+		case "tr":
+		default:
+			continue
+		}
+
+		row := child.Bytes(markup)
+		rowCells, column, err := tableRowCells(row)
+		if err != nil {
+			return nil, err
+		}
+		for _, cell := range rowCells {
+			cell.element.Position.Start += child.Position.Start
+			cell.element.Position.End += child.Position.Start
+			cell.width = contentWidth(cell.element.Bytes(markup), font, size) + defaultCellMargins
+			cells = append(cells, cell)
+		}
+		if column > columns {
+			columns = column
+		}
+	}
+	if columns == 0 {
+		return nil, nil
+	}
+
+	widths := distributeWidths(cells, columns)
+
+	// the table must fit onto the page
+	available := DefaultPageSetup.ContentWidth()
+	if sections := SectionPropertiesRegex.FindAll(t.doc.GetFile(DocumentXml), -1); len(sections) > 0 {
+		available = ParsePageSetup(sections[len(sections)-1]).ContentWidth()
+	}
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if total > available {
+		for i := range widths {
+			widths[i] = widths[i] * available / total
+		}
+	}
+
+	var gridMarkup bytes.Buffer
+	gridMarkup.WriteString("<w:tblGrid>")
+	for _, w := range widths {
+		gridMarkup.WriteString(fmt.Sprintf(`<w:gridCol w:w="%d"/>`, w))
+	}
+	gridMarkup.WriteString("</w:tblGrid>")
+	edits := []edit{{Position: grid, Replacement: gridMarkup.Bytes()}}
+
+	for _, cell := range cells {
+		width := 0
+		for c := cell.column; c < cell.column+cell.span && c < len(widths); c++ {
+			width += widths[c]
+		}
+		tcW := fmt.Sprintf(`<w:tcW w:w="%d" w:type="dxa"/>`, width)
+		edits = append(edits, cellPropertiesEdit(markup, cell.element, func(props string) string {
+			return setProperty(props, cellPropertyOrder, "w:tcW", tcW)
+		}))
+	}
+	return edits, nil
+}
+
+// cellPropertiesEdit returns the edit which replaces the inner properties of the given cell with the result of modify.
+// If the cell has no properties yet, they are inserted.
+func cellPropertiesEdit(markup []byte, cell Element, modify func(props string) string) edit {
+	cellMarkup := cell.Bytes(markup)
+	openTagEnd := int64(bytes.IndexByte(cellMarkup, '>') + 1)
+	pos := Position{Start: cell.Position.Start + openTagEnd, End: cell.Position.Start + openTagEnd}
+	inner := ""
+	if tcPr := TableCellPropertiesRegex.Find(cellMarkup[openTagEnd:]); tcPr != nil {
+		inner = innerMarkup(tcPr)
+		pos.End += int64(len(tcPr))
+	}
+	return edit{Position: pos, Replacement: []byte("<w:tcPr>" + modify(inner) + "</w:tcPr>")}
+}
+
+// tableRowCells returns all cells of the given row with their grid columns, the positions are relative to the row.
+// The amount of grid columns used by the row, excluding w:gridAfter, is returned as well.
+func tableRowCells(row []byte) ([]*tableCell, int, error) {
+	rowChildren, err := childElements(row)
+	if err != nil {
+		return nil, 0, err
+	}
+	var cells []*tableCell
+	column := 0
+	for _, rowChild := range rowChildren {
+		switch rowChild.Name {
+		case "trPr":
+			column += intTagValue(GridBeforeTagRegex, rowChild.Bytes(row), 0)
+		case "tc":
+			cell := &tableCell{element: rowChild, column: column, span: 1}
+			cellMarkup := rowChild.Bytes(row)
+			if tcPr := TableCellPropertiesRegex.Find(cellMarkup[bytes.IndexByte(cellMarkup, '>')+1:]); tcPr != nil {
+				cell.span = intTagValue(GridSpanTagRegex, tcPr, 1)
+			}
+			cells = append(cells, cell)
+			column += cell.span
+		}
+	}
+	return cells, column, nil
+}
+
+// distributeWidths returns the width of every grid column. Columns are as wide as their widest single-column cell,
+// cells spanning multiple columns widen their columns proportionally if they do not fit, or equally if the
+// columns are empty.
+func distributeWidths(cells []*tableCell, columns int) []int {
+	widths := make([]int, columns)
+	var spanning []*tableCell
+	for _, cell := range cells {
+		if cell.span > 1 {
+			spanning = append(spanning, cell)
+			continue
+		}
+		if cell.column < columns && cell.width > widths[cell.column] {
+			widths[cell.column] = cell.width
+		}
+	}
+
+	// narrow spans first, so that wider spans can take the already widened columns into account
+	sort.SliceStable(spanning, func(i, j int) bool {
+		return spanning[i].span < spanning[j].span
+	})
+	for _, cell := range spanning {
+		end := cell.column + cell.span
+		if end > columns {
+			end = columns
+		}
+		current := 0
+		for c := cell.column; c < end; c++ {
+			current += widths[c]
+		}
+		missing := cell.width - current
+		if missing <= 0 || end <= cell.column {
+			continue
+		}
+		for c := cell.column; c < end; c++ {
+			if current > 0 {
+				widths[c] += missing * widths[c] / current
+			} else {
+				widths[c] += missing / (end - cell.column)
+			}
+		}
+	}
+
+	for i := range widths {
+		if widths[i] < defaultCellMargins {
+			widths[i] = defaultCellMargins
+		}
+	}
+	return widths
+}
+
+// contentWidth estimates the width of the widest paragraph inside the given cell in twips.
+func contentWidth(cell []byte, defaultFont string, defaultSize float64) int {
+	widest := 0
+	for _, paragraph := range ParagraphRegex.FindAll(cell, -1) {
+		block := &textBlock{defaultFont: defaultFont, defaultSize: defaultSize}
+		for _, run := range RunRegex.FindAll(paragraph, -1) {
+			block.addRun(run, run)
+		}
+		if block.width > widest {
+			widest = block.width
+		}
+	}
+	return widest
+}
+
+// intTagValue returns the numeric w:val attribute of the first tag inside data which matches the given regex.
+// If there is no such tag or value, the fallback is returned.
+func intTagValue(tagRegex *regexp.Regexp, data []byte, fallback int) int {
+	value, err := strconv.Atoi(tagValue(tagRegex, data))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package docx
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTable_AutoFit(t *testing.T) {
+	cell := func(text, tcPr string) string {
+		return `<w:tc>` + tcPr + `<w:p>` + `<w:r><w:rPr><w:rFonts w:ascii="Courier New"/><w:sz w:val="24"/></w:rPr><w:t>` + text + `</w:t></w:r></w:p></w:tc>`
+	}
+	table := `<w:tbl><w:tblPr><w:tblW w:w="2000" w:type="dxa"/><w:tblLayout w:type="fixed"/></w:tblPr>` +
+		`<w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="1000"/></w:tblGrid>` +
+		`<w:tr>` + cell("abcd", `<w:tcPr><w:tcW w:w="1000" w:type="dxa"/></w:tcPr>`) + cell("ab", "") + `</w:tr>` +
+		`<w:tr>` + cell("abcdefghij", `<w:tcPr><w:gridSpan w:val="2"/></w:tcPr>`) + `</w:tr></w:tbl>`
+
+	t.Run("contents", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(table + `<w:p/>`)})
+		tables := doc.Tables()
+		if len(tables) != 1 {
+			t.Fatalf("expected one table, have=%d", len(tables))
+		}
+		if err := tables[0].AutoFit(AutoFitContents); err != nil {
+			t.Fatal(err)
+		}
+
+		// 144 twips per character plus the cell margins, the merged cell widens both columns proportionally
+		expected := []string{
+			`<w:tblPr><w:tblW w:w="0" w:type="auto"/><w:tblLayout w:type="autofit"/></w:tblPr>`,
+			`<w:tblGrid><w:gridCol w:w="1012"/><w:gridCol w:w="644"/></w:tblGrid>`,
+			`<w:tc><w:tcPr><w:tcW w:w="1012" w:type="dxa"/></w:tcPr>`,
+			`<w:tc><w:tcPr><w:tcW w:w="644" w:type="dxa"/></w:tcPr>`,
+			`<w:tc><w:tcPr><w:tcW w:w="1656" w:type="dxa"/><w:gridSpan w:val="2"/></w:tcPr>`,
+		}
+		document := string(doc.GetFile(DocumentXml))
+		for _, e := range expected {
+			if !strings.Contains(document, e) {
+				t.Errorf("document does not contain %s\n%s", e, document)
+			}
+		}
+		if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("page width", func(t *testing.T) {
+		long := strings.Replace(table, "abcdefghij", strings.Repeat("x", 100), 1)
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(long)})
+		if err := doc.Tables()[0].AutoFit(AutoFitContents); err != nil {
+			t.Fatal(err)
+		}
+		total := 0
+		for _, match := range regexp.MustCompile(`<w:gridCol w:w="([0-9]+)"/>`).FindAllStringSubmatch(string(doc.GetFile(DocumentXml)), -1) {
+			width, _ := strconv.Atoi(match[1])
+			total += width
+		}
+		if total == 0 || total > DefaultPageSetup.ContentWidth() {
+			t.Errorf("table is wider than the page, width=%d", total)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(table)})
+		if err := doc.Tables()[0].AutoFit(AutoFitWindow); err != nil {
+			t.Fatal(err)
+		}
+		expected := `<w:tblPr><w:tblW w:w="5000" w:type="pct"/><w:tblLayout w:type="autofit"/></w:tblPr><w:tblGrid><w:gridCol w:w="1000"/>`
+		if !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+			t.Errorf("document does not contain %s\n%s", expected, doc.GetFile(DocumentXml))
+		}
+	})
+}
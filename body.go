@@ -0,0 +1,144 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// BodyElementName is the local name of the body element (<w:body>)
+	BodyElementName = "body"
+	// ParagraphElementName is the local name of paragraphs (<w:p>)
+	ParagraphElementName = "p"
+	// SectionPropertiesElementName is the local name of section properties (<w:sectPr>)
+	SectionPropertiesElementName = "sectPr"
+)
+
+var (
+	// TextContentRegex matches text elements and captures their content
+	TextContentRegex = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>`)
+	// VisibleContentRegex matches elements which render something other than text, making a paragraph non-empty
+	VisibleContentRegex = regexp.MustCompile(`<w:(drawing|pict|object|sym|sectPr)[\s>/]`)
+	// PageBreakRegex matches explicit page breaks (<w:br w:type="page"/>)
+	PageBreakRegex = regexp.MustCompile(`<w:br\s[^>]*w:type="page"`)
+)
+
+// Element is an XML element inside a file, identified by its local name and the byte offsets
+// from the '<' of the open tag to the '>' of the close tag.
+type Element struct {
+	Name     string
+	Position Position
+}
+
+// Bytes returns the full markup of the element.
+func (e Element) Bytes(data []byte) []byte {
+	return data[e.Position.Start:e.Position.End]
+}
+
+// BodyElements returns all direct children of the <w:body> element in document order.
+func BodyElements(data []byte) ([]Element, error) {
+	docReader := NewReader(string(data))
+	decoder := xml.NewDecoder(docReader)
+
+	var elements []Element
+	var current Element
+	depth := 0 // depth relative to the body, 1 means direct child of the body
+	inBody := false
+
+	for {
+		tok, err := decoder.Token()
+		if tok == nil || err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error getting token: %s", err)
+		}
+
+		switch elem := tok.(type) {
+		case xml.StartElement:
+			if !inBody {
+				inBody = elem.Name.Local == BodyElementName
+				continue
+			}
+			depth++
+			if depth == 1 {
+				// Pos() points after the '>' of the tag
+				current = Element{
+					Name:     elem.Name.Local,
+					Position: Position{Start: int64(bytes.LastIndexByte(data[:docReader.Pos()], '<'))},
+				}
+			}
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			if depth == 0 {
+				return elements, nil // </w:body>
+			}
+			if depth == 1 {
+				current.Position.End = docReader.Pos()
+				elements = append(elements, current)
+			}
+			depth--
+		}
+	}
+	return elements, nil
+}
+
+// isEmptyParagraph returns true if the given paragraph markup neither contains text (other than whitespace),
+// nor a drawing, a page break or section properties.
+func isEmptyParagraph(paragraph []byte) bool {
+	for _, match := range TextContentRegex.FindAllSubmatch(paragraph, -1) {
+		if strings.TrimSpace(string(match[1])) != "" {
+			return false
+		}
+	}
+	return !VisibleContentRegex.Match(paragraph) && !PageBreakRegex.Match(paragraph)
+}
+
+// TrimTrailingEmptyParagraphs removes all empty paragraphs at the very end of the document body and returns
+// how many were removed. Trimming stops at the first paragraph with content, a drawing or a page break as well
+// as at any other element which is not a paragraph (e.g. a table).
+//
+// The body-level section properties are kept intact, as are paragraphs carrying the section properties of the
+// previous section. If the body would otherwise be empty, exactly one paragraph is kept.
+func (d *Document) TrimTrailingEmptyParagraphs() (int, error) {
+	data := d.GetFile(DocumentXml)
+	elements, err := BodyElements(data)
+	if err != nil {
+		return 0, err
+	}
+
+	// the body-level sectPr is always the last element of the body
+	end := len(elements)
+	if end > 0 && elements[end-1].Name == SectionPropertiesElementName {
+		end--
+	}
+
+	first := end
+	for first > 0 {
+		elem := elements[first-1]
+		if elem.Name != ParagraphElementName || !isEmptyParagraph(elem.Bytes(data)) {
+			break
+		}
+		first--
+	}
+	if first == 0 && first < end {
+		first = 1 // keep one paragraph, the body must not be empty
+	}
+	if first >= end {
+		return 0, nil
+	}
+
+	cut := Position{Start: elements[first].Position.Start, End: elements[end-1].Position.End}
+	result := applyEdits(data, []edit{{Position: cut}})
+
+	if err := d.SetFile(DocumentXml, result); err != nil {
+		return 0, err
+	}
+	return end - first, d.parseFile(DocumentXml)
+}
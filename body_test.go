@@ -0,0 +1,72 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_TrimTrailingEmptyParagraphs(t *testing.T) {
+	sectPr := `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+		removed  int
+	}{
+		{
+			name:     "trailing empty paragraphs",
+			body:     `<w:p><w:r><w:t>signature</w:t></w:r></w:p><w:p/><w:p><w:r><w:t xml:space="preserve">  </w:t></w:r></w:p><w:p><w:pPr><w:jc w:val="left"/></w:pPr></w:p>` + sectPr,
+			expected: `<w:p><w:r><w:t>signature</w:t></w:r></w:p>` + sectPr,
+			removed:  3,
+		},
+		{
+			name:     "stop at page break",
+			body:     `<w:p><w:r><w:t>a</w:t></w:r></w:p><w:p><w:r><w:br w:type="page"/></w:r></w:p><w:p/>` + sectPr,
+			expected: `<w:p><w:r><w:t>a</w:t></w:r></w:p><w:p><w:r><w:br w:type="page"/></w:r></w:p>` + sectPr,
+			removed:  1,
+		},
+		{
+			name:     "stop at drawing",
+			body:     `<w:p><w:r><w:drawing/></w:r></w:p><w:p></w:p>` + sectPr,
+			expected: `<w:p><w:r><w:drawing/></w:r></w:p>` + sectPr,
+			removed:  1,
+		},
+		{
+			name:     "keep section carrier",
+			body:     `<w:p><w:pPr>` + sectPr + `</w:pPr></w:p><w:p/>` + sectPr,
+			expected: `<w:p><w:pPr>` + sectPr + `</w:pPr></w:p>` + sectPr,
+			removed:  1,
+		},
+		{
+			name:     "keep one paragraph",
+			body:     `<w:p/><w:p/><w:p/>` + sectPr,
+			expected: `<w:p/>` + sectPr,
+			removed:  2,
+		},
+		{
+			name:     "nothing to trim",
+			body:     `<w:p><w:r><w:t>a</w:t></w:r></w:p>`,
+			expected: `<w:p><w:r><w:t>a</w:t></w:r></w:p>`,
+			removed:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)})
+
+			removed, err := doc.TrimTrailingEmptyParagraphs()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if removed != tt.removed {
+				t.Errorf("unexpected amount of removed paragraphs, want=%d, have=%d", tt.removed, removed)
+			}
+			documentXml := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(documentXml, "<w:body>"+tt.expected+"</w:body>") {
+				t.Errorf("unexpected body: %s", documentXml)
+			}
+		})
+	}
+}
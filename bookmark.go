@@ -0,0 +1,97 @@
+package docx
+
+import (
+	"regexp"
+	"sync"
+)
+
+var (
+	// BookmarkStartTagRegex matches the (singleton) start tag of a bookmark, including all attributes
+	BookmarkStartTagRegex = regexp.MustCompile(`<w:bookmarkStart\s[^>]*?/>`)
+	// BookmarkEndTagRegex matches the (singleton) end tag of a bookmark, including all attributes
+	BookmarkEndTagRegex = regexp.MustCompile(`<w:bookmarkEnd\s[^>]*?/>`)
+
+	// attributeRegexes caches the compiled regexes of attributeValue by attribute name
+	attributeRegexesMu sync.RWMutex
+	attributeRegexes   = make(map[string]*regexp.Regexp)
+)
+
+// Bookmark is a named region inside a file which is spanned by a <w:bookmarkStart> and <w:bookmarkEnd> tag
+// sharing the same w:id.
+type Bookmark struct {
+	ID       string
+	Name     string
+	StartTag Position
+	EndTag   Position
+}
+
+// Content returns the position of everything between the start and end tag of the bookmark.
+func (b Bookmark) Content() Position {
+	return Position{
+		Start: b.StartTag.End,
+		End:   b.EndTag.Start,
+	}
+}
+
+// FindBookmarks returns all bookmarks inside the given bytes in order of their start tags.
+// Bookmarks without a matching end tag are ignored.
+func FindBookmarks(data []byte) []Bookmark {
+	endTags := make(map[string]Position)
+	for _, loc := range BookmarkEndTagRegex.FindAllIndex(data, -1) {
+		id, ok := attributeValue(data[loc[0]:loc[1]], "w:id")
+		if !ok {
+			continue
+		}
+		endTags[id] = Position{Start: int64(loc[0]), End: int64(loc[1])}
+	}
+
+	var bookmarks []Bookmark
+	for _, loc := range BookmarkStartTagRegex.FindAllIndex(data, -1) {
+		tag := data[loc[0]:loc[1]]
+		id, ok := attributeValue(tag, "w:id")
+		if !ok {
+			continue
+		}
+		name, _ := attributeValue(tag, "w:name")
+		endTag, ok := endTags[id]
+		if !ok || endTag.Start < int64(loc[1]) {
+			continue
+		}
+		bookmarks = append(bookmarks, Bookmark{
+			ID:       id,
+			Name:     name,
+			StartTag: Position{Start: int64(loc[0]), End: int64(loc[1])},
+			EndTag:   endTag,
+		})
+	}
+	return bookmarks
+}
+
+// attributeValue returns the raw (still escaped) value of the attribute with the given qualified name inside the tag.
+func attributeValue(tag []byte, name string) (string, bool) {
+	match := attributeRegex(name).FindSubmatch(tag)
+	if match == nil {
+		return "", false
+	}
+	if match[1] != nil {
+		return string(match[1]), true
+	}
+	return string(match[2]), true
+}
+
+// attributeRegex returns the regex which matches the attribute with the given qualified name, the groups contain the
+// value in double or single quotes.
+func attributeRegex(name string) *regexp.Regexp {
+	attributeRegexesMu.RLock()
+	re, cached := attributeRegexes[name]
+	attributeRegexesMu.RUnlock()
+	if cached {
+		return re
+	}
+
+	re = regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	attributeRegexesMu.Lock()
+	defer attributeRegexesMu.Unlock()
+	attributeRegexes[name] = re
+	return re
+}
@@ -0,0 +1,347 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BudgetPolicy defines what happens if the written document exceeds the size budget, see WithSizeBudget.
+type BudgetPolicy int
+
+const (
+	// RecompressImages downsamples the images whose resolution exceeds the maximum image density and writes the
+	// document again. ErrSizeBudgetExceeded is returned if it still exceeds the budget.
+	RecompressImages BudgetPolicy = iota
+	// FailOverBudget returns ErrSizeBudgetExceeded without changing the document.
+	FailOverBudget
+)
+
+const (
+	// DefaultMaxImageDensity is the resolution in DPI above which RecompressImages downsamples images.
+	DefaultMaxImageDensity = 150
+	// recompressJpegQuality is the quality of recompressed JPEG images
+	recompressJpegQuality = 85
+)
+
+var (
+	// ErrSizeBudgetExceeded is returned by Write if the document exceeds the size budget, see WithSizeBudget.
+	ErrSizeBudgetExceeded = errors.New("document exceeds the size budget")
+
+	// DrawingOpenTagRegex matches the open tag of an inline or anchored drawing (<wp:inline>, <wp:anchor>)
+	DrawingOpenTagRegex = regexp.MustCompile(`<wp:(?:inline|anchor)(?:\s[^>]*)?>`)
+	// DrawingExtentTagRegex matches the display size of a drawing (<wp:extent cx="..." cy="..."/>)
+	DrawingExtentTagRegex = regexp.MustCompile(`<wp:extent\s[^>]*>`)
+
+	// sourceRectangleRegex matches the cropping of a picture, an empty <a:srcRect/> does not crop
+	sourceRectangleRegex = regexp.MustCompile(`<a:srcRect\s+[a-z]`)
+)
+
+// sizeBudget is the configuration of WithSizeBudget.
+type sizeBudget struct {
+	bytes      int64
+	policy     BudgetPolicy
+	maxDensity float64
+}
+
+// WithSizeBudget limits the size of the document written by Write to the given amount of bytes, e.g. to stay below
+// the attachment limit of email gateways. The document is assembled in memory to measure its size.
+//
+// With RecompressImages, the PNG and JPEG images are downsampled to the maximum image density (see
+// WithMaxImageDensity) of their display size and re-encoded in their format, if this makes them smaller. The
+// display size is taken from the extents of the drawings which show the image (the largest one if it is shown
+// several times). Images whose display size is unknown are never touched, e.g. images of VML shapes, cropped
+// pictures or images which are referenced in another way. SizeReport returns the savings of every image.
+func WithSizeBudget(bytes int64, policy BudgetPolicy) Option {
+	return func(d *Document) {
+		maxDensity := float64(DefaultMaxImageDensity)
+		if d.sizeBudget != nil {
+			maxDensity = d.sizeBudget.maxDensity
+		}
+		d.sizeBudget = &sizeBudget{bytes: bytes, policy: policy, maxDensity: maxDensity}
+	}
+}
+
+// WithMaxImageDensity sets the resolution in DPI above which WithSizeBudget downsamples images,
+// DefaultMaxImageDensity by default. Options which set the budget must precede it.
+func WithMaxImageDensity(dpi float64) Option {
+	return func(d *Document) {
+		if d.sizeBudget == nil {
+			d.sizeBudget = &sizeBudget{bytes: math.MaxInt64, policy: RecompressImages}
+		}
+		d.sizeBudget.maxDensity = dpi
+	}
+}
+
+// SizeReport is the result of the size budget check of the last Write, see WithSizeBudget.
+type SizeReport struct {
+	Budget int64
+	// Size is the size of the written (or rejected) document in bytes.
+	Size int64
+	// Images contains every recompressed image, sorted by part name.
+	Images []ImageSaving
+}
+
+// ImageSaving is an image which was recompressed to stay within the size budget.
+type ImageSaving struct {
+	Part                          string
+	OriginalWidth, OriginalHeight int // in pixels
+	Width, Height                 int
+	OriginalSize, Size            int64 // in bytes
+}
+
+// Saved returns the amount of bytes saved by recompressing the image.
+func (s ImageSaving) Saved() int64 {
+	return s.OriginalSize - s.Size
+}
+
+// SizeReport returns the result of the size budget check of the last Write.
+func (d *Document) SizeReport() SizeReport {
+	return d.sizeReport
+}
+
+// writeWithinBudget writes the document into the writer if it does not exceed the size budget.
+func (d *Document) writeWithinBudget(writer *bytes.Buffer) error {
+	budget := d.sizeBudget
+	d.sizeReport = SizeReport{Budget: budget.bytes}
+	if err := d.writeArchive(writer); err != nil {
+		return err
+	}
+	if int64(writer.Len()) > budget.bytes && budget.policy == RecompressImages {
+		savings, err := d.recompressImages(budget.maxDensity)
+		if err != nil {
+			return err
+		}
+		d.sizeReport.Images = savings
+		if len(savings) > 0 {
+			writer.Reset()
+			if err := d.writeArchive(writer); err != nil {
+				return err
+			}
+		}
+	}
+	d.sizeReport.Size = int64(writer.Len())
+	if d.sizeReport.Size > budget.bytes {
+		return fmt.Errorf("%w: %d bytes, the budget is %d bytes", ErrSizeBudgetExceeded, d.sizeReport.Size, budget.bytes)
+	}
+	return nil
+}
+
+// recompressImages downsamples all images whose display size is known and whose density exceeds maxDensity.
+func (d *Document) recompressImages(maxDensity float64) ([]ImageSaving, error) {
+	sizes, err := d.imageDisplaySizes()
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for part := range sizes {
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+
+	var savings []ImageSaving
+	for _, part := range parts {
+		size := sizes[part]
+		if size.Width <= 0 || size.Height <= 0 {
+			continue
+		}
+		data, err := d.getPart(part)
+		if err != nil {
+			return nil, err
+		}
+		saving, recompressed, ok := recompressImage(data, size, maxDensity)
+		if !ok {
+			continue
+		}
+		if err := d.setPart(part, recompressed); err != nil {
+			return nil, err
+		}
+		saving.Part = part
+		savings = append(savings, saving)
+	}
+	return savings, nil
+}
+
+// imageDisplaySizes returns the largest display size of every image part which is only shown by drawings with
+// known extents. Images which are referenced in any other way have an empty size.
+func (d *Document) imageDisplaySizes() (map[string]ImageSize, error) {
+	sizes := make(map[string]ImageSize)
+	for _, part := range d.packagePartNames() {
+		if !strings.HasSuffix(part, ".xml") || !d.partExists(RelsPath(part)) {
+			continue
+		}
+		rels, err := d.Relationships(part)
+		if err != nil {
+			return nil, err
+		}
+		images := make(map[string]string)
+		for _, rel := range rels {
+			if rel.Type == ImageRelationshipType && !rel.IsExternal() {
+				images[rel.ID] = resolveTarget(part, rel.Target)
+			}
+		}
+		if len(images) == 0 {
+			continue
+		}
+		data, err := d.getPart(part)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ref := range RelationshipReferenceRegex.FindAllSubmatchIndex(data, -1) {
+			target, isImage := images[string(data[ref[4]:ref[5]])]
+			if !isImage {
+				continue
+			}
+			size, known := drawingExtent(data, int64(ref[0]))
+			current, seen := sizes[target]
+			switch {
+			case !known || (seen && current.Width <= 0):
+				sizes[target] = ImageSize{}
+			case !seen || size.Width > current.Width || size.Height > current.Height:
+				if seen {
+					size.Width = maxInt64(size.Width, current.Width)
+					size.Height = maxInt64(size.Height, current.Height)
+				}
+				sizes[target] = size
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// drawingExtent returns the display size of the picture (<a:blip>) which references an image at pos.
+// The size is unknown if the reference is not the picture of a drawing or if the picture is cropped.
+func drawingExtent(data []byte, pos int64) (ImageSize, bool) {
+	tagStart := bytes.LastIndexByte(data[:pos], '<')
+	if tagStart < 0 {
+		return ImageSize{}, false
+	}
+	if blip := blipOpenTagRegex.FindIndex(data[tagStart:]); blip == nil || blip[0] != 0 || int64(tagStart+blip[1]) <= pos {
+		return ImageSize{}, false
+	}
+	start, end, err := enclosingElement(data, DrawingOpenTagRegex, pos)
+	if err != nil {
+		return ImageSize{}, false
+	}
+	drawing := data[start:end]
+	if sourceRectangleRegex.Match(drawing) || bytes.Count(drawing, []byte("<a:blip")) != 1 {
+		return ImageSize{}, false
+	}
+	extent := DrawingExtentTagRegex.Find(drawing)
+	cx, _ := attributeValue(extent, "cx")
+	cy, _ := attributeValue(extent, "cy")
+	width, errWidth := strconv.ParseInt(cx, 10, 64)
+	height, errHeight := strconv.ParseInt(cy, 10, 64)
+	if errWidth != nil || errHeight != nil || width <= 0 || height <= 0 {
+		return ImageSize{}, false
+	}
+	return ImageSize{Width: width, Height: height}, true
+}
+
+// recompressImage downsamples the PNG or JPEG image to maxDensity at the given display size. It returns false if
+// the image is not dense enough to be downsampled or if the result is not smaller.
+func recompressImage(data []byte, size ImageSize, maxDensity float64) (ImageSaving, []byte, bool) {
+	width, height, format, err := ImageDimensions(data)
+	if err != nil || width == 0 || height == 0 {
+		return ImageSaving{}, nil, false
+	}
+	maxWidth := math.Ceil(float64(size.Width) / EMUPerInch * maxDensity)
+	maxHeight := math.Ceil(float64(size.Height) / EMUPerInch * maxDensity)
+	scale := math.Max(maxWidth/float64(width), maxHeight/float64(height))
+	if scale >= 1 {
+		return ImageSaving{}, nil, false
+	}
+	targetWidth := int(math.Max(1, math.Round(float64(width)*scale)))
+	targetHeight := int(math.Max(1, math.Round(float64(height)*scale)))
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ImageSaving{}, nil, false
+	}
+	scaled := downsample(img, targetWidth, targetHeight)
+
+	buf := new(bytes.Buffer)
+	if format == "png" {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(buf, scaled)
+	} else {
+		err = jpeg.Encode(buf, scaled, &jpeg.Options{Quality: recompressJpegQuality})
+	}
+	if err != nil || buf.Len() >= len(data) {
+		return ImageSaving{}, nil, false
+	}
+	return ImageSaving{
+		OriginalWidth:  width,
+		OriginalHeight: height,
+		Width:          targetWidth,
+		Height:         targetHeight,
+		OriginalSize:   int64(len(data)),
+		Size:           int64(buf.Len()),
+	}, buf.Bytes(), true
+}
+
+// downsample scales the image down to the given size, every pixel is the average of the pixels it covers.
+func downsample(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, (y+1)*srcHeight/height
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, (x+1)*srcWidth/width
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			count := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8((sum[i] + count/2) / count)
+			}
+		}
+	}
+	return dst
+}
+
+// packagePartNames returns the sorted names of all parts of the package, including added parts.
+func (d *Document) packagePartNames() []string {
+	var names []string
+	for _, file := range d.zipFile.File {
+		names = append(names, file.Name)
+	}
+	names = append(names, d.addedParts()...)
+	sort.Strings(names)
+	return names
+}
+
+// maxInt64 returns the larger of both values.
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
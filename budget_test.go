@@ -0,0 +1,153 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testNoiseImage returns a PNG image of random pixels, which does not compress well.
+func testNoiseImage(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	random := rand.New(rand.NewSource(1))
+	random.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// openBudgetTestDocx returns a document which shows a dense image at one inch and another one in a VML shape.
+func openBudgetTestDocx(t *testing.T, opts ...Option) *Document {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{logo}</w:t></w:r></w:p><w:p><w:r><w:t>{shape}</w:t></w:r></w:p>`),
+	}), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.InsertImageAtPlaceholder("logo", testNoiseImage(t, 600, 300), ImageSize{Width: Inches(1)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.setPart("word/media/shape.png", testNoiseImage(t, 600, 300)); err != nil {
+		t.Fatal(err)
+	}
+	id, err := doc.addRelationship(DocumentXml, Relationship{Type: ImageRelationshipType, Target: "media/shape.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shape := `<w:pict><v:shape style="width:72pt;height:36pt"><v:imagedata r:id="` + id + `"/></v:shape></w:pict>`
+	document := strings.Replace(string(doc.GetFile(DocumentXml)), "<w:t>{shape}</w:t>", shape, 1)
+	if err := doc.SetFile(DocumentXml, []byte(document)); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestDocument_Write_SizeBudget(t *testing.T) {
+	unlimited := new(bytes.Buffer)
+	if err := openBudgetTestDocx(t).Write(unlimited); err != nil {
+		t.Fatal(err)
+	}
+	original := int64(unlimited.Len())
+
+	tests := []struct {
+		name   string
+		budget int64
+		policy BudgetPolicy
+		err    error
+		images int
+	}{
+		{name: "within budget", budget: original, policy: FailOverBudget},
+		{name: "fail over budget", budget: original - 1, policy: FailOverBudget, err: ErrSizeBudgetExceeded},
+		{name: "recompress images", budget: original * 3 / 4, policy: RecompressImages, images: 1},
+		{name: "still over budget", budget: original / 4, policy: RecompressImages, err: ErrSizeBudgetExceeded, images: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openBudgetTestDocx(t, WithSizeBudget(tt.budget, tt.policy))
+			buf := new(bytes.Buffer)
+			err := doc.Write(buf)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			report := doc.SizeReport()
+			if len(report.Images) != tt.images {
+				t.Fatalf("expected %d recompressed images, got %+v", tt.images, report.Images)
+			}
+			if tt.err != nil {
+				if buf.Len() != 0 || report.Size <= tt.budget {
+					t.Errorf("expected nothing to be written over budget, size %d", report.Size)
+				}
+				return
+			}
+			if int64(buf.Len()) != report.Size || report.Size > tt.budget {
+				t.Errorf("unexpected size %d, the budget is %d", buf.Len(), tt.budget)
+			}
+		})
+	}
+}
+
+func TestDocument_Write_RecompressImages(t *testing.T) {
+	doc := openBudgetTestDocx(t, WithSizeBudget(1, RecompressImages), WithMaxImageDensity(100))
+	if err := doc.Write(new(bytes.Buffer)); !errors.Is(err, ErrSizeBudgetExceeded) {
+		t.Fatalf("expected the tiny budget to be exceeded, got %v", err)
+	}
+
+	images := doc.SizeReport().Images
+	if len(images) != 1 {
+		t.Fatalf("expected one recompressed image, got %+v", images)
+	}
+	saving := images[0]
+	expected := ImageSaving{Part: "word/media/image1.png", OriginalWidth: 600, OriginalHeight: 300, Width: 100, Height: 50}
+	if saving.Part != expected.Part || saving.Width != expected.Width || saving.Height != expected.Height ||
+		saving.OriginalWidth != expected.OriginalWidth || saving.OriginalHeight != expected.OriginalHeight {
+		t.Errorf("want=%+v, have=%+v", expected, saving)
+	}
+	if saving.Saved() <= 0 {
+		t.Errorf("expected the image to be smaller, saved %d bytes", saving.Saved())
+	}
+
+	data, _ := doc.getPart(expected.Part)
+	if width, height, format, err := ImageDimensions(data); err != nil || width != 100 || height != 50 || format != "png" {
+		t.Errorf("unexpected image %dx%d %s: %v", width, height, format, err)
+	}
+	// the display size of the VML image is unknown
+	if data, _ := doc.getPart("word/media/shape.png"); !bytes.Equal(data, testNoiseImage(t, 600, 300)) {
+		t.Error("the image of the VML shape was changed")
+	}
+}
+
+func TestDrawingExtent(t *testing.T) {
+	picture := func(extra string) []byte {
+		return []byte(`<wp:inline><wp:extent cx="914400" cy="457200"/><a:graphic><pic:pic><pic:blipFill>` +
+			`<a:blip r:embed="rId5"/>` + extra + `</pic:blipFill></pic:pic></a:graphic></wp:inline>`)
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		expected ImageSize
+		known    bool
+	}{
+		{"inline picture", picture(""), ImageSize{Width: 914400, Height: 457200}, true},
+		{"cropped picture", picture(`<a:srcRect l="1000"/>`), ImageSize{}, false},
+		{"no drawing", []byte(`<v:shape><v:imagedata r:id="rId5"/></v:shape>`), ImageSize{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := bytes.Index(tt.data, []byte(`r:`))
+			size, known := drawingExtent(tt.data, int64(pos))
+			if size != tt.expected || known != tt.known {
+				t.Errorf("want=%v (%v), have=%v (%v)", tt.expected, tt.known, size, known)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package docx
+
+import (
+	"bytes"
+)
+
+// ChangedParts returns the bytes of all parts which changed since the document was opened, or since the previous
+// call of ChangedParts, by part name. The bytes are the ones Write would write, so applying them to the previous
+// state (replacing the parts, or adding those which did not exist) results in the current document. This allows to
+// synchronize a copy of the document without transferring the whole archive.
+//
+// Parts are recorded as changed whenever they are modified, parts which were modified but have the same bytes as
+// in the previous generation are not returned. Every call starts a new generation, see Generation.
+func (d *Document) ChangedParts() map[string][]byte {
+	changes := make(map[string][]byte)
+	for name := range d.changedParts {
+		current, exists := d.currentPart(name)
+		if !exists {
+			continue
+		}
+		if previous, known := d.previousPart(name); known && bytes.Equal(previous, current) {
+			continue
+		}
+		// the parts are modified in place, the caller and the next generation receive copies
+		changes[name] = append([]byte(nil), current...)
+	}
+
+	if d.reportedParts == nil {
+		d.reportedParts = make(map[string][]byte)
+	}
+	for name, data := range changes {
+		d.reportedParts[name] = append([]byte(nil), data...)
+	}
+	d.changedParts = nil
+	d.generation++
+	return changes
+}
+
+// Generation returns the number of calls of ChangedParts so far. Changes made afterwards are returned by the next
+// call, which is the generation after the returned one.
+func (d *Document) Generation() uint64 {
+	return d.generation
+}
+
+// markChanged records that the part was modified and invalidates its cached scan results. All modifications of parts
+// must be recorded, see ChangedParts.
+func (d *Document) markChanged(part string) {
+	d.touch(part)
+	delete(d.untrimmedParts, part)
+	if d.modifiedFiles == nil {
+		d.modifiedFiles = make(map[string]bool)
+	}
+	d.modifiedFiles[part] = true
+	if d.changedParts == nil {
+		d.changedParts = make(map[string]bool)
+	}
+	d.changedParts[part] = true
+}
+
+// currentPart returns the bytes which Write writes for the part, false is returned if the part does not exist, e.g.
+// because adding it was reverted.
+func (d *Document) currentPart(name string) ([]byte, bool) {
+	if _, parsed := d.files[name]; parsed {
+		return d.unescapedFile(name), true
+	}
+	if data, modified := d.modifiedParts[name]; modified {
+		return data, true
+	}
+	if d.zipFileByName(name) == nil {
+		return nil, false
+	}
+	data, err := d.readArchiveFile(name)
+	return data, err == nil
+}
+
+// previousPart returns the bytes of the part in the previous generation: the bytes which were last returned by
+// ChangedParts or the bytes of the original archive. False is returned if the part did not exist.
+func (d *Document) previousPart(name string) ([]byte, bool) {
+	if data, reported := d.reportedParts[name]; reported {
+		return data, true
+	}
+	if d.zipFileByName(name) == nil {
+		return nil, false
+	}
+	data, err := d.readArchiveFile(name)
+	if err != nil {
+		// the part is returned as changed if its original cannot be read
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,56 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ChangedParts(t *testing.T) {
+	header := `<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{company}</w:t></w:r></w:p></w:hdr>`
+	settings := `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"/>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:         testDocumentXml(`<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p>`),
+		"word/header1.xml":  header,
+		"word/settings.xml": settings,
+	})
+	if changes := doc.ChangedParts(); len(changes) != 0 || doc.Generation() != 1 {
+		t.Errorf("expected no changes after opening, got %v (generation %d)", changes, doc.Generation())
+	}
+
+	// the header is set as well, but its bytes are unchanged
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	changes := doc.ChangedParts()
+	if len(changes) != 1 || string(changes[DocumentXml]) != string(doc.GetFile(DocumentXml)) {
+		t.Errorf("expected only %s to be changed, got %v", DocumentXml, changes)
+	}
+	if changes := doc.ChangedParts(); len(changes) != 0 || doc.Generation() != 3 {
+		t.Errorf("expected no changes since the last generation, got %v (generation %d)", changes, doc.Generation())
+	}
+
+	if err := doc.updatePart("word/header1.xml", []byte(strings.Replace(header, "{company}", "Acme", 1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.setPart("word/added.xml", []byte("<added/>")); err != nil {
+		t.Fatal(err)
+	}
+	// setting a part to its previous bytes is no change
+	if err := doc.setPart("word/settings.xml", []byte(settings)); err != nil {
+		t.Fatal(err)
+	}
+	changes = doc.ChangedParts()
+	if len(changes) != 2 || !strings.Contains(string(changes["word/header1.xml"]), "<w:t>Acme</w:t>") ||
+		string(changes["word/added.xml"]) != "<added/>" {
+		t.Errorf("expected the header and the added part to be changed, got %v", changes)
+	}
+
+	// the changes of all generations result in the written document
+	written := reopen(t, doc)
+	for name, data := range map[string][]byte{"word/header1.xml": changes["word/header1.xml"], DocumentXml: doc.GetFile(DocumentXml)} {
+		if current, _ := written.getPart(name); !reflect.DeepEqual(current, data) {
+			t.Errorf("expected the changes of %s to match the written document, got %s", name, data)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package docx
+
+const (
+	// CommentsXml is the relative path of the comments inside the docx-archive.
+	CommentsXml = "word/comments.xml"
+	// CommentsExtendedXml is the relative path of the threading and state of the comments (Word 2013 and newer).
+	CommentsExtendedXml = "word/commentsExtended.xml"
+	// PeopleXml is the relative path of the authors of the comments (Word 2013 and newer).
+	PeopleXml = "word/people.xml"
+)
+
+// WithCommentReplacement replaces placeholders inside the comments (word/comments.xml) as well. By default comments
+// are skipped, they are usually notes of the authors of a template.
+//
+// Newer Word versions keep the threads of the comments and their state in commentsExtended.xml, which refers to the
+// paragraphs of the comments by their w14:paraId, and the authors in people.xml. Only the text of the runs of the
+// comments is replaced, so that their paragraphs and the related parts stay consistent. Block values would split
+// the paragraphs of a comment, they are not inserted into comments and the placeholder is kept with a warning.
+func WithCommentReplacement() Option {
+	return func(d *Document) {
+		d.replaceComments = true
+	}
+}
+
+// isReplacedNoteFile returns true if the file contains notes which are subject to replacing: the footnotes, endnotes
+// and, if enabled, the comments.
+func (d *Document) isReplacedNoteFile(file string) bool {
+	return isNoteFile(file) || (d.replaceComments && file == CommentsXml)
+}
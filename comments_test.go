@@ -0,0 +1,66 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// testThreadedComments returns the parts of a comment with a reply, as written by newer Word versions.
+func testThreadedComments() map[string]string {
+	return map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:commentRangeStart w:id="0"/><w:r><w:t>{name}</w:t></w:r><w:commentRangeEnd w:id="0"/>` +
+			`<w:r><w:commentReference w:id="0"/></w:r><w:r><w:commentReference w:id="1"/></w:r></w:p>`),
+		CommentsXml: `<w:comments xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml">` +
+			`<w:comment w:id="0" w:author="Jane" w:initials="J"><w:p w14:paraId="1A2B3C4D"><w:r><w:t>Check {name} and {table}</w:t></w:r></w:p></w:comment>` +
+			`<w:comment w:id="1" w:author="John" w:initials="J"><w:p w14:paraId="5E6F7A8B"><w:r><w:t>Done for {name}</w:t></w:r></w:p></w:comment>` +
+			`</w:comments>`,
+		CommentsExtendedXml: `<w15:commentsEx xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml">` +
+			`<w15:commentEx w15:paraId="1A2B3C4D" w15:done="0"/><w15:commentEx w15:paraId="5E6F7A8B" w15:paraIdParent="1A2B3C4D" w15:done="0"/>` +
+			`</w15:commentsEx>`,
+		PeopleXml: `<w15:people xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml">` +
+			`<w15:person w15:author="Jane"><w15:presenceInfo w15:providerId="None" w15:userId="Jane"/></w15:person>` +
+			`<w15:person w15:author="John"><w15:presenceInfo w15:providerId="None" w15:userId="John"/></w15:person>` +
+			`</w15:people>`,
+	}
+}
+
+func TestWithCommentReplacement(t *testing.T) {
+	parts := testThreadedComments()
+	values := PlaceholderMap{"name": "Acme", "table": TableValue(TableSpec{Rows: [][]string{{"a"}}})}
+
+	doc := openTestDocx(t, testThreadedComments())
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	if comments, _ := reopen(t, doc).getPart(CommentsXml); string(comments) != parts[CommentsXml] {
+		t.Errorf("expected the comments to be kept by default, got %s", comments)
+	}
+
+	archive := newTestDocx(t, testThreadedComments())
+	doc, err := OpenBytes(archive, WithCommentReplacement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	written := reopen(t, doc)
+	comments, _ := written.getPart(CommentsXml)
+	for _, expected := range []string{
+		`<w:p w14:paraId="1A2B3C4D"><w:r><w:t>Check Acme and {table}</w:t></w:r></w:p>`,
+		`<w:p w14:paraId="5E6F7A8B"><w:r><w:t>Done for Acme</w:t></w:r></w:p>`,
+	} {
+		if !strings.Contains(string(comments), expected) {
+			t.Errorf("expected %s in %s", expected, comments)
+		}
+	}
+	// the threads and authors refer to the unchanged paragraphs of the comments
+	for _, name := range []string{CommentsExtendedXml, PeopleXml} {
+		if data, _ := written.getPart(name); string(data) != parts[name] {
+			t.Errorf("expected %s to be kept, got %s", name, data)
+		}
+	}
+	if warnings := doc.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "{table}") {
+		t.Errorf("expected a warning about the block value, got %v", warnings)
+	}
+}
@@ -0,0 +1,141 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SettingsXml is the relative path of the document settings inside the docx-archive.
+	SettingsXml = "word/settings.xml"
+	// SettingsRelationshipType is the relationship type of the document settings.
+	SettingsRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings"
+	// SettingsContentType is the content type of the document settings.
+	SettingsContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+
+	// CompatibilityModeWord2007 is the compatibility mode of Word 2007, it applies if no mode is defined.
+	CompatibilityModeWord2007 = 12
+	// CompatibilityModeWord2010 is the compatibility mode of Word 2010.
+	CompatibilityModeWord2010 = 14
+	// CompatibilityModeWord2013 is the compatibility mode of Word 2013 and all later versions.
+	CompatibilityModeWord2013 = 15
+
+	// compatibilityModeName is the name of the compatibility setting which carries the mode
+	compatibilityModeName = "compatibilityMode"
+	// compatSettingUri is the uri of all compatibility settings defined by Word
+	compatSettingUri = "http://schemas.microsoft.com/office/word"
+)
+
+var (
+	// CompatTagRegex matches the compatibility settings (<w:compat>) of the document settings
+	CompatTagRegex = regexp.MustCompile(`(?s)<w:compat(?:\s[^>]*)?>.*?</w:compat>|<w:compat\s*/>`)
+	// CompatSettingTagRegex matches a single compatibility setting (<w:compatSetting>)
+	CompatSettingTagRegex = regexp.MustCompile(`<w:compatSetting(?:\s[^>]*)?/>`)
+	// SettingsCloseTagRegex matches the close tag of the document settings
+	SettingsCloseTagRegex = regexp.MustCompile(`</w:settings>`)
+
+	// compatFollowers are the document settings which must occur after <w:compat> according to the schema
+	compatFollowers = regexp.MustCompile(`<(?:w:docVars|w:rsids|m:mathPr|w:attachedSchema|w:themeFontLang|w:clrSchemeMapping|` +
+		`w:doNotIncludeSubdocsInStats|w:doNotAutoCompressPictures|w:forceUpgrade|w:captions|w:readModeInkLockDown|` +
+		`w:smartTagType|sl:schemaLibrary|w:shapeDefaults|w:doNotEmbedSmartTags|w:decimalSymbol|w:listSeparator)[\s/>]`)
+)
+
+// CompatibilityMode returns the compatibility mode of the document, e.g. 15 for Word 2013 and later.
+// Documents without a mode are in the compatibility mode of Word 2007.
+func (d *Document) CompatibilityMode() int {
+	if !d.partExists(SettingsXml) {
+		return CompatibilityModeWord2007
+	}
+	settings, err := d.getPart(SettingsXml)
+	if err != nil {
+		return CompatibilityModeWord2007
+	}
+	for _, tag := range CompatSettingTagRegex.FindAll(CompatTagRegex.Find(settings), -1) {
+		if name, _ := attributeValue(tag, "w:name"); name != compatibilityModeName {
+			continue
+		}
+		value, _ := attributeValue(tag, "w:val")
+		if mode, err := strconv.Atoi(value); err == nil {
+			return mode
+		}
+	}
+	return CompatibilityModeWord2007
+}
+
+// SetCompatibilityMode changes the compatibility mode of the document. Switching to Word 2013 (15) or later also
+// removes all legacy compatibility options, as Word does when converting a document.
+// If the document has no settings yet, they are added.
+func (d *Document) SetCompatibilityMode(mode int) error {
+	if mode < CompatibilityModeWord2007 {
+		return fmt.Errorf("invalid compatibility mode %d", mode)
+	}
+	settings, err := d.settings()
+	if err != nil {
+		return err
+	}
+
+	var compat strings.Builder
+	compat.WriteString("<w:compat>")
+	modeSetting := fmt.Sprintf(`<w:compatSetting w:name="%s" w:uri="%s" w:val="%d"/>`, compatibilityModeName, compatSettingUri, mode)
+	for _, p := range splitProperties(innerMarkup(CompatTagRegex.Find(settings))) {
+		if p.Name != "w:compatSetting" {
+			if mode < CompatibilityModeWord2013 {
+				compat.WriteString(p.Markup)
+			}
+			continue
+		}
+		if name, _ := attributeValue([]byte(p.Markup), "w:name"); name == compatibilityModeName {
+			compat.WriteString(modeSetting)
+			modeSetting = ""
+			continue
+		}
+		compat.WriteString(p.Markup)
+	}
+	compat.WriteString(modeSetting)
+	compat.WriteString("</w:compat>")
+
+	var pos Position
+	if loc := CompatTagRegex.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[1])}
+	} else if loc := compatFollowers.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[0])}
+	} else if loc := SettingsCloseTagRegex.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[0])}
+	} else {
+		return fmt.Errorf("invalid settings part %s", SettingsXml)
+	}
+	return d.setPart(SettingsXml, applyEdits(settings, []edit{{Position: pos, Replacement: []byte(compat.String())}}))
+}
+
+// settings returns the document settings. If there are none, an empty settings part is added to the document.
+func (d *Document) settings() ([]byte, error) {
+	if d.partExists(SettingsXml) {
+		return d.getPart(SettingsXml)
+	}
+
+	settings := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:settings>`)
+	if err := d.setPart(SettingsXml, settings); err != nil {
+		return nil, err
+	}
+	if err := d.ensureContentType(SettingsXml, SettingsContentType); err != nil {
+		return nil, err
+	}
+	if _, err := d.addRelationship(DocumentXml, Relationship{
+		Type:   SettingsRelationshipType,
+		Target: relativeTarget(DocumentXml, SettingsXml),
+	}); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// warnCompatibility records a warning if the given feature is used on a document in compatibility mode,
+// as Word then renders the generated markup differently.
+func (d *Document) warnCompatibility(feature string) {
+	if mode := d.CompatibilityMode(); mode < CompatibilityModeWord2013 {
+		d.warnings = append(d.warnings, fmt.Sprintf("%s may be rendered differently, the document is in compatibility mode %d", feature, mode))
+	}
+}
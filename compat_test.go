@@ -0,0 +1,106 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func testSettingsXml(content string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + content + `</w:settings>`
+}
+
+func TestDocument_CompatibilityMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		expected int
+	}{
+		{"no settings", "", CompatibilityModeWord2007},
+		{"no compat", testSettingsXml(`<w:zoom w:percent="100"/>`), CompatibilityModeWord2007},
+		{"word 2010", testSettingsXml(`<w:compat><w:useFELayout/>` +
+			`<w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="14"/></w:compat>`), 14},
+		{"word 2013", testSettingsXml(`<w:compat>` +
+			`<w:compatSetting w:uri="http://schemas.microsoft.com/office/word" w:val="15" w:name="compatibilityMode"/></w:compat>`), 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := map[string]string{DocumentXml: testDocumentXml(`<w:p/>`)}
+			if tt.settings != "" {
+				parts[SettingsXml] = tt.settings
+			}
+			if mode := openTestDocx(t, parts).CompatibilityMode(); mode != tt.expected {
+				t.Errorf("unexpected compatibility mode %d, expected %d", mode, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDocument_SetCompatibilityMode(t *testing.T) {
+	setting := `<w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="15"/>`
+	other := `<w:compatSetting w:name="overrideTableStyleFontSizeAndJustification" w:uri="http://schemas.microsoft.com/office/word" w:val="1"/>`
+
+	tests := []struct {
+		name     string
+		settings string
+		expected string
+	}{
+		{
+			name: "legacy options are removed",
+			settings: testSettingsXml(`<w:compat><w:useFELayout/><w:doNotExpandShiftReturn/>` + other +
+				`<w:compatSetting w:name="compatibilityMode" w:uri="http://schemas.microsoft.com/office/word" w:val="14"/></w:compat><w:rsids/>`),
+			expected: `<w:compat>` + other + setting + `</w:compat><w:rsids/>`,
+		},
+		{
+			name:     "compat is inserted before the following settings",
+			settings: testSettingsXml(`<w:defaultTabStop w:val="708"/><w:rsids><w:rsidRoot w:val="00AB"/></w:rsids>`),
+			expected: `<w:defaultTabStop w:val="708"/><w:compat>` + setting + `</w:compat><w:rsids>`,
+		},
+		{
+			name:     "settings are added",
+			expected: `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:compat>` + setting + `</w:compat></w:settings>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := map[string]string{DocumentXml: testDocumentXml(`<w:p/>`)}
+			if tt.settings != "" {
+				parts[SettingsXml] = tt.settings
+			}
+			doc := openTestDocx(t, parts)
+
+			if err := doc.SetCompatibilityMode(CompatibilityModeWord2013); err != nil {
+				t.Fatal(err)
+			}
+			doc = reopen(t, doc)
+			settings, err := doc.getPart(SettingsXml)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(settings), tt.expected) {
+				t.Errorf("unexpected settings\nwant=%s\nhave=%s", tt.expected, settings)
+			}
+			if mode := doc.CompatibilityMode(); mode != CompatibilityModeWord2013 {
+				t.Errorf("unexpected compatibility mode %d", mode)
+			}
+			if contentType, _ := doc.ContentType(SettingsXml); tt.settings == "" && contentType != SettingsContentType {
+				t.Errorf("unexpected content type %s", contentType)
+			}
+		})
+	}
+}
+
+func TestTable_AutoFit_CompatibilityWarning(t *testing.T) {
+	for _, mode := range []int{CompatibilityModeWord2010, CompatibilityModeWord2013} {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr></w:tbl><w:p/>`)})
+		if err := doc.SetCompatibilityMode(mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Tables()[0].AutoFit(AutoFitWindow); err != nil {
+			t.Fatal(err)
+		}
+		if warned := len(doc.Warnings()) > 0; warned != (mode < CompatibilityModeWord2013) {
+			t.Errorf("mode %d: unexpected warnings %v", mode, doc.Warnings())
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WithConcurrentReplace makes ReplaceAll replace the text of the parts (document, headers, footers and notes)
+// concurrently using at most the given amount of workers. Values smaller than two keep the sequential replacement.
+//
+// The structural changes (block values, run merging) are still applied sequentially in the order of the part names.
+// The methods of the values (e.g. String or Markup) may be called concurrently and must therefore not modify
+// shared state without synchronization.
+//
+// If hooks which may modify the state of the caller are registered (a Resolver, a Validator, a ValueFilter,
+// a FormatRule, a TypographyRule or a LanguageDetector), the replacement stays sequential unless WithConcurrentHooks
+// declares them safe for concurrent use.
+func WithConcurrentReplace(workers int) Option {
+	return func(d *Document) {
+		d.replaceWorkers = workers
+	}
+}
+
+// WithConcurrentHooks declares that the registered hooks are safe for concurrent use, so that WithConcurrentReplace
+// replaces concurrently even if hooks are registered.
+func WithConcurrentHooks() Option {
+	return func(d *Document) {
+		d.concurrentHooks = true
+	}
+}
+
+// replaceConcurrently reports whether ReplaceAll replaces the parts using a pool of workers.
+func (d *Document) replaceConcurrently() bool {
+	if d.replaceWorkers < 2 {
+		return false
+	}
+	// the bookmarks of the replacements are numbered in document order, see WithAnchorsForReplacements
+	if d.anchorPrefix != "" {
+		return false
+	}
+	hooks := d.resolver != nil || len(d.validators) > 0 || d.valueFilter != nil || len(d.formatRules) > 0 ||
+		len(d.typographyRules) > 0 || d.languageDetector != nil
+	return !hooks || d.concurrentHooks
+}
+
+// PartError is an error which occurred while replacing inside a single part of the document.
+type PartError struct {
+	Part string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *PartError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Part, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PartError) Unwrap() error {
+	return e.Err
+}
+
+// PartErrors aggregates the errors of all parts, sorted by the name of the part.
+type PartErrors []*PartError
+
+// Error implements the error interface by joining the errors of all parts.
+func (e PartErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the part errors matches the target, so that errors.Is can be used on the aggregate.
+func (e PartErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first part error which matches the target, so that errors.As can be used on the aggregate.
+func (e PartErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceAllConcurrent replaces the text values of all parts using a bounded pool of workers.
+// The workers only use the Replacer of their part and read the document, everything which modifies the document
+// happens afterwards in the order of the part names. If the text of any part could not be replaced, the errors of
+// all parts are returned and the structural changes are skipped.
+func (d *Document) replaceAllConcurrent(placeholderMap PlaceholderMap) error {
+	textValues, blockValues := splitValues(placeholderMap)
+
+	names := d.fileNames()
+
+	type result struct {
+		data         []byte
+		replacedRuns []*Run
+		err          error
+	}
+	results := make([]result, len(names))
+
+	workers := d.replaceWorkers
+	if workers > len(names) {
+		workers = len(names)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, replacedRuns, err := d.replaceText(textValues, names[i])
+				results[i] = result{data: data, replacedRuns: replacedRuns, err: err}
+			}
+		}()
+	}
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs PartErrors
+	for i, r := range results {
+		if r.err != nil {
+			errs = append(errs, &PartError{Part: names[i], Err: r.err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for i, name := range names {
+		changedBytes, err := d.restructure(name, results[i].data, results[i].replacedRuns, blockValues)
+		if err == nil {
+			err = d.SetFile(name, changedBytes)
+		}
+		if err != nil {
+			errs = append(errs, &PartError{Part: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package docx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func concurrentTestParts(headerBody string) map[string]string {
+	part := func(root, body string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:` + root + ` xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + body + `</w:` + root + `>`
+	}
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{name} ordered {count}</w:t></w:r></w:p><w:p><w:r><w:t>{table}</w:t></w:r></w:p>`),
+	}
+	for i := 1; i <= 3; i++ {
+		parts[fmt.Sprintf("word/header%d.xml", i)] = part("hdr", headerBody)
+		parts[fmt.Sprintf("word/footer%d.xml", i)] = part("ftr", `<w:p><w:r><w:t>{na</w:t></w:r><w:r><w:t>me}</w:t></w:r></w:p>`)
+	}
+	return parts
+}
+
+func TestDocument_ReplaceAll_Concurrent(t *testing.T) {
+	values := PlaceholderMap{
+		"name":  "Jane",
+		"count": Number(1234, 0),
+		"table": TableValue(TableSpec{Rows: [][]string{{"a", "b"}}}),
+	}
+
+	sequential := openTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`))
+	if err := sequential.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	concurrent, err := OpenBytes(newTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`)), WithConcurrentReplace(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := concurrent.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+
+	for name := range sequential.files {
+		if want, have := string(sequential.GetFile(name)), string(concurrent.GetFile(name)); want != have {
+			t.Errorf("%s differs from the sequential replacement\nwant=%s\nhave=%s", name, want, have)
+		}
+	}
+	if !strings.Contains(string(concurrent.GetFile(DocumentXml)), "Jane ordered 1,234") {
+		t.Errorf("placeholders were not replaced: %s", concurrent.GetFile(DocumentXml))
+	}
+}
+
+func TestDocument_ReplaceAll_ConcurrentErrors(t *testing.T) {
+	// the placeholder inside the deleted text is counted, but it cannot be replaced
+	doc, err := OpenBytes(newTestDocx(t, concurrentTestParts(`<w:p><w:r><w:delText>{name}</w:delText></w:r></w:p>`)), WithConcurrentReplace(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doc.ReplaceAll(PlaceholderMap{"name": "Jane"})
+	var partErrors PartErrors
+	if !errors.As(err, &partErrors) {
+		t.Fatalf("expected PartErrors, got %v", err)
+	}
+	expectedParts := []string{"word/header1.xml", "word/header2.xml", "word/header3.xml"}
+	if len(partErrors) != len(expectedParts) {
+		t.Fatalf("expected %d errors, got %d: %s", len(expectedParts), len(partErrors), err)
+	}
+	for i, part := range expectedParts {
+		if partErrors[i].Part != part {
+			t.Errorf("error %d belongs to %s, expected %s", i, partErrors[i].Part, part)
+		}
+	}
+
+	var partError *PartError
+	if !errors.As(err, &partError) || partError.Part != expectedParts[0] {
+		t.Errorf("errors.As did not find the first part error: %v", partError)
+	}
+}
+
+func TestDocument_ReplaceAll_ConcurrentHooks(t *testing.T) {
+	filter := func(key string, value interface{}) interface{} { return value }
+	resolver := func(ctx context.Context, key string) (interface{}, error) { return key, nil }
+	tests := []struct {
+		name       string
+		options    []Option
+		validator  bool
+		concurrent bool
+	}{
+		{name: "without hooks", concurrent: true},
+		{name: "value filter", options: []Option{WithValueFilter(filter)}},
+		{name: "resolver", options: []Option{WithResolver(resolver)}},
+		{name: "validator", validator: true},
+		{name: "opt-in", options: []Option{WithValueFilter(filter), WithConcurrentHooks()}, validator: true, concurrent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithConcurrentReplace(4)}, tt.options...)
+			doc, err := OpenBytes(newTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`)), options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.validator {
+				doc.AddValidator(func(doc *Document) error { return nil })
+			}
+			if concurrent := doc.replaceConcurrently(); concurrent != tt.concurrent {
+				t.Errorf("expected concurrent=%v, got %v", tt.concurrent, concurrent)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "count": 3, "table": "none"}); err != nil {
+				t.Fatal(err)
+			}
+			if data := string(doc.GetFile("word/header2.xml")); !strings.Contains(data, "<w:t>Jane</w:t>") {
+				t.Errorf("the header was not replaced: %s", data)
+			}
+		})
+	}
+}
+
+func TestDocument_ProcessParts(t *testing.T) {
+	values := PlaceholderMap{"name": "Jane", "count": 3, "table": "none"}
+
+	doc := openTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`))
+	var names []string
+	err := doc.ProcessParts(values, func(name string, data []byte) error {
+		names = append(names, name)
+		if !strings.Contains(string(data), "Jane") {
+			t.Errorf("%s was not replaced before it was passed on: %s", name, data)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{DocumentXml, "word/footer1.xml", "word/footer2.xml", "word/footer3.xml",
+		"word/header1.xml", "word/header2.xml", "word/header3.xml"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("unexpected parts %v, expected %v", names, expected)
+	}
+
+	// an error of the callback aborts the processing
+	doc = openTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`))
+	errStop := errors.New("stop")
+	calls := 0
+	err = doc.ProcessParts(values, func(name string, data []byte) error {
+		calls++
+		return errStop
+	})
+	var partErr *PartError
+	if !errors.As(err, &partErr) || partErr.Part != DocumentXml || !errors.Is(err, errStop) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the processing to stop after the first part, got %d calls", calls)
+	}
+
+	// the complexity limits are checked before a part is passed on
+	doc, err = OpenBytes(newTestDocx(t, concurrentTestParts(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`)), WithComplexityLimits(ComplexityLimits{MaxRuns: 1, Fail: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls = 0
+	err = doc.ProcessParts(values, func(name string, data []byte) error {
+		calls++
+		return nil
+	})
+	if !errors.As(err, &partErr) || partErr.Part != DocumentXml || !errors.Is(err, ErrComplexityLimitExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no part to be passed on, got %d calls", calls)
+	}
+}
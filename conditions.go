@@ -0,0 +1,359 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// rowPropertyOrder is the sequence in which the children of <w:trPr> must occur according to the schema.
+	rowPropertyOrder = []string{
+		"cnfStyle", "divId", "gridBefore", "gridAfter", "wBefore", "wAfter", "cantSplit", "trHeight", "tblHeader",
+		"tblCellSpacing", "jc", "hidden", "ins", "del", "trPrChange",
+	}
+
+	// TableCellOpenTagRegex matches the open tag of a table cell (but not of its properties, <w:tcPr>)
+	TableCellOpenTagRegex = regexp.MustCompile(`<w:tc(?:\s[^>]*)?>`)
+	// GridAfterTagRegex matches the amount of grid columns which are skipped after the last cell of a row
+	GridAfterTagRegex = regexp.MustCompile(`<w:gridAfter(?:\s[^>]*)?/?>`)
+	// TableCellWidthTagRegex matches the preferred width of a table cell
+	TableCellWidthTagRegex = regexp.MustCompile(`<w:tcW(?:\s[^>]*)?/?>`)
+	// ConditionMarkerRegex matches the key of a condition marker (without delimiters), e.g. '#col:has_discount'.
+	// Column markers remove the grid column of their cell, row markers remove their row.
+	ConditionMarkerRegex = regexp.MustCompile(`^#(col|row):(.+)$`)
+)
+
+// RemovedColumn is a grid column which was removed from a table.
+type RemovedColumn struct {
+	Table  int // index of the table as returned by Document.Tables
+	Column int
+}
+
+// RemovedRow is a row which was removed from a table.
+type RemovedRow struct {
+	Table int // index of the table as returned by Document.Tables
+	Row   int
+}
+
+// ConditionReport lists the table columns and rows removed by Document.SetCondition.
+// The indices refer to the tables before the removal, sorted by table and index.
+type ConditionReport struct {
+	Columns []RemovedColumn
+	Rows    []RemovedRow
+}
+
+// SetCondition evaluates all condition markers of the given key inside the tables of the main document.
+// A marker like '{#col:has_discount}' inside a cell marks the grid columns of that cell, '{#row:has_discount}'
+// marks its row. If the condition is false, the marked columns and rows are removed from their table.
+// The markers themselves are removed in either case.
+func (d *Document) SetCondition(key string, value bool) (ConditionReport, error) {
+	var report ConditionReport
+	data := d.GetFile(DocumentXml)
+
+	if !value {
+		rows := make(map[RemovedRow]bool)
+		columns := make(map[RemovedColumn]bool)
+		for _, placeholder := range d.filePlaceholders[DocumentXml] {
+			kind, markerKey, ok := conditionMarker(placeholder.Text(data))
+			if !ok || markerKey != key {
+				continue
+			}
+			table, row, cell, err := markedCell(data, placeholder.StartPos())
+			if err != nil {
+				return report, fmt.Errorf("invalid condition marker %s: %w", placeholder.Text(data), err)
+			}
+			if kind == "row" {
+				rows[RemovedRow{Table: table, Row: row}] = true
+				continue
+			}
+			for c := cell.column; c < cell.column+cell.span; c++ {
+				columns[RemovedColumn{Table: table, Column: c}] = true
+			}
+		}
+		for row := range rows {
+			report.Rows = append(report.Rows, row)
+		}
+		for column := range columns {
+			report.Columns = append(report.Columns, column)
+		}
+		sort.Slice(report.Rows, func(i, j int) bool {
+			a, b := report.Rows[i], report.Rows[j]
+			return a.Table < b.Table || a.Table == b.Table && a.Row < b.Row
+		})
+		sort.Slice(report.Columns, func(i, j int) bool {
+			a, b := report.Columns[i], report.Columns[j]
+			return a.Table < b.Table || a.Table == b.Table && a.Column < b.Column
+		})
+
+		// removing rows and columns together from the back keeps the indices of everything which is still to be
+		// removed valid: a removed row may contain nested tables, which shifts the indices of all later tables.
+		// Nested tables come after their parent table and are therefore handled first.
+		for i, j := len(report.Rows)-1, len(report.Columns)-1; i >= 0 || j >= 0; {
+			if i >= 0 && (j < 0 || report.Rows[i].Table >= report.Columns[j].Table) {
+				row := report.Rows[i]
+				if err := (&Table{doc: d, index: row.Table}).HideRow(row.Row); err != nil {
+					return report, err
+				}
+				i--
+				continue
+			}
+			column := report.Columns[j]
+			if err := (&Table{doc: d, index: column.Table}).HideColumn(column.Column); err != nil {
+				return report, err
+			}
+			j--
+		}
+	}
+
+	// remove the remaining markers
+	data = d.GetFile(DocumentXml)
+	var edits []edit
+	for _, placeholder := range d.filePlaceholders[DocumentXml] {
+		if _, markerKey, ok := conditionMarker(placeholder.Text(data)); !ok || markerKey != key {
+			continue
+		}
+		for _, fragment := range placeholder.Fragments {
+			edits = append(edits, edit{Position: Position{Start: fragment.StartPos(), End: fragment.EndPos()}})
+		}
+	}
+	if len(edits) == 0 {
+		return report, nil
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Position.Start < edits[j].Position.Start
+	})
+	if err := d.SetFile(DocumentXml, applyEdits(data, edits)); err != nil {
+		return report, err
+	}
+	return report, d.parseFile(DocumentXml)
+}
+
+// conditionMarker returns the kind ('col' or 'row') and the condition key of the given placeholder text.
+func conditionMarker(placeholder string) (kind, key string, ok bool) {
+	if !IsDelimitedPlaceholder(placeholder) {
+		return "", "", false
+	}
+	match := ConditionMarkerRegex.FindStringSubmatch(RemovePlaceholderDelimiter(placeholder))
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], strings.TrimSpace(match[2]), true
+}
+
+// markedCell returns the index of the table, the index of the row and the cell which contain the given position.
+func markedCell(data []byte, pos int64) (table, row int, cell *tableCell, err error) {
+	tableStart, tableEnd, err := enclosingElement(data, TableOpenTagRegex, pos)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("not inside a table: %w", err)
+	}
+	rowStart, _, err := enclosingElement(data, TableRowOpenTagRegex, pos)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("not inside a table row: %w", err)
+	}
+	cellStart, _, err := enclosingElement(data, TableCellOpenTagRegex, pos)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("not inside a table cell: %w", err)
+	}
+	table = len(TableOpenTagRegex.FindAllIndex(data[:tableStart], -1))
+
+	markup := data[tableStart:tableEnd]
+	children, err := childElements(markup)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	row = 0
+	for _, child := range children {
+		if child.Name != "tr" {
+			continue
+		}
+		if tableStart+child.Position.Start != rowStart {
+			row++
+			continue
+		}
+		cells, _, err := tableRowCells(child.Bytes(markup))
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		for _, c := range cells {
+			if rowStart+c.element.Position.Start == cellStart {
+				return table, row, c, nil
+			}
+		}
+	}
+	return 0, 0, nil, fmt.Errorf("cell at offset %d not found", cellStart)
+}
+
+// HideRow removes the row with the given index from the table.
+// The last row of a table cannot be removed, as a table must contain at least one row.
+func (t *Table) HideRow(row int) error {
+	markup, err := t.Bytes()
+	if err != nil {
+		return err
+	}
+	children, err := childElements(markup)
+	if err != nil {
+		return fmt.Errorf("unable to parse table: %s", err)
+	}
+
+	var rows []Element
+	for _, child := range children {
+		if child.Name == "tr" {
+			rows = append(rows, child)
+		}
+	}
+	if row < 0 || row >= len(rows) {
+		return fmt.Errorf("row %d does not exist in table %d", row, t.index)
+	}
+	if len(rows) == 1 {
+		return fmt.Errorf("unable to remove the last row of table %d", t.index)
+	}
+	return t.update(applyEdits(append([]byte(nil), markup...), []edit{{Position: rows[row].Position}}))
+}
+
+// HideColumn removes the grid column with the given index from the table. Cells which only occupy this column
+// are removed, cells which span it (w:gridSpan) become narrower. Rows without any remaining cell are removed.
+func (t *Table) HideColumn(column int) error {
+	markup, err := t.Bytes()
+	if err != nil {
+		return err
+	}
+	markup = append([]byte(nil), markup...)
+	children, err := childElements(markup)
+	if err != nil {
+		return fmt.Errorf("unable to parse table: %s", err)
+	}
+
+	var edits []edit
+	exists := false
+	removedWidth := 0
+	rows, removedRows := 0, 0
+	for _, child := range children {
+		switch child.Name {
+		case "tblGrid":
+			cols, err := childElements(child.Bytes(markup))
+			if err != nil {
+				return err
+			}
+			index := 0
+			for _, col := range cols {
+				if col.Name != "gridCol" {
+					continue
+				}
+				if index == column {
+					exists = true
+					width, _ := attributeValue(col.Bytes(child.Bytes(markup)), "w:w")
+					removedWidth, _ = strconv.Atoi(width)
+					edits = append(edits, edit{Position: Position{
+						Start: child.Position.Start + col.Position.Start,
+						End:   child.Position.Start + col.Position.End,
+					}})
+				}
+				index++
+			}
+		case "tr":
+			rows++
+			rowEdits, removed, covered, err := hideRowColumn(child.Bytes(markup), column, removedWidth)
+			if err != nil {
+				return err
+			}
+			if removed {
+				removedRows++
+			}
+			exists = exists || covered
+			for _, e := range rowEdits {
+				e.Position.Start += child.Position.Start
+				e.Position.End += child.Position.Start
+				edits = append(edits, e)
+			}
+		}
+	}
+	if column < 0 || !exists {
+		return fmt.Errorf("column %d does not exist in table %d", column, t.index)
+	}
+	if removedRows == rows {
+		return fmt.Errorf("unable to remove the last column of table %d", t.index)
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Position.Start < edits[j].Position.Start
+	})
+	return t.update(applyEdits(markup, edits))
+}
+
+// hideRowColumn returns the edits which remove the given grid column from the row. The positions are relative
+// to the row. If the row does not contain any other cell, the whole row is removed.
+// covered reports whether the row occupies the column at all, including w:gridBefore and w:gridAfter.
+func hideRowColumn(row []byte, column, columnWidth int) (edits []edit, removed, covered bool, err error) {
+	cells, used, err := tableRowCells(row)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	for _, cell := range cells {
+		if column < cell.column || column >= cell.column+cell.span {
+			continue
+		}
+		if cell.span == 1 {
+			if len(cells) == 1 {
+				return []edit{{Position: Position{Start: 0, End: int64(len(row))}}}, true, true, nil
+			}
+			return []edit{{Position: cell.element.Position}}, false, true, nil
+		}
+
+		return []edit{cellPropertiesEdit(row, cell.element, func(props string) string {
+			span := ""
+			if cell.span > 2 {
+				span = fmt.Sprintf(`<w:gridSpan w:val="%d"/>`, cell.span-1)
+			}
+			props = setProperty(props, cellPropertyOrder, "w:gridSpan", span)
+
+			tcW := TableCellWidthTagRegex.FindString(props)
+			width, _ := attributeValue([]byte(tcW), "w:w")
+			widthType, _ := attributeValue([]byte(tcW), "w:type")
+			if w, err := strconv.Atoi(width); err == nil && widthType == "dxa" && columnWidth > 0 {
+				if w -= columnWidth; w < 0 {
+					w = 0
+				}
+				props = setProperty(props, cellPropertyOrder, "w:tcW", fmt.Sprintf(`<w:tcW w:w="%d" w:type="dxa"/>`, w))
+			}
+			return props
+		})}, false, true, nil
+	}
+
+	// the column is not occupied by a cell, it may be skipped using w:gridBefore or w:gridAfter
+	rowChildren, err := childElements(row)
+	if err != nil {
+		return nil, false, false, err
+	}
+	for _, child := range rowChildren {
+		if child.Name != "trPr" {
+			continue
+		}
+		trPr := child.Bytes(row)
+		before := intTagValue(GridBeforeTagRegex, trPr, 0)
+		after := intTagValue(GridAfterTagRegex, trPr, 0)
+
+		inner := innerMarkup(trPr)
+		switch {
+		case column < before:
+			inner = setProperty(inner, rowPropertyOrder, "w:gridBefore", gridSkipMarkup("w:gridBefore", before-1))
+		case column >= used && column < used+after:
+			inner = setProperty(inner, rowPropertyOrder, "w:gridAfter", gridSkipMarkup("w:gridAfter", after-1))
+		default:
+			return nil, false, false, nil
+		}
+		return []edit{{Position: child.Position, Replacement: []byte("<w:trPr>" + inner + "</w:trPr>")}}, false, true, nil
+	}
+	return nil, false, false, nil
+}
+
+// gridSkipMarkup returns the markup of w:gridBefore or w:gridAfter, which is empty if no columns are skipped.
+func gridSkipMarkup(name string, columns int) string {
+	if columns <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`<%s w:val="%d"/>`, name, columns)
+}
@@ -0,0 +1,169 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func conditionTestCell(text string, props string) string {
+	return `<w:tc><w:tcPr>` + props + `</w:tcPr><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:tc>`
+}
+
+func TestDocument_SetCondition(t *testing.T) {
+	table := `<w:tbl><w:tblPr/><w:tblGrid><w:gridCol w:w="3000"/><w:gridCol w:w="1000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+		`<w:tr>` + conditionTestCell("Item", "") + conditionTestCell("Discount{#col:has_discount}", "") + conditionTestCell("Total", "") + `</w:tr>` +
+		`<w:tr>` + conditionTestCell("Book", "") + conditionTestCell("10%", "") + conditionTestCell("9.00", "") + `</w:tr>` +
+		`<w:tr>` + conditionTestCell("Sum{#row:has_discount}", `<w:tcW w:w="4000" w:type="dxa"/><w:gridSpan w:val="2"/>`) + conditionTestCell("9.00", "") + `</w:tr>` +
+		`<w:tr><w:trPr><w:gridBefore w:val="2"/></w:trPr>` + conditionTestCell("VAT", "") + `</w:tr>` +
+		`</w:tbl>`
+
+	t.Run("false removes the column and row", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(table + `<w:p/>`)})
+
+		report, err := doc.SetCondition("has_discount", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedReport := ConditionReport{
+			Columns: []RemovedColumn{{Table: 0, Column: 1}},
+			Rows:    []RemovedRow{{Table: 0, Row: 2}},
+		}
+		if !reflect.DeepEqual(report, expectedReport) {
+			t.Errorf("unexpected report\nwant=%+v\nhave=%+v", expectedReport, report)
+		}
+
+		result := string(doc.GetFile(DocumentXml))
+		expected := `<w:tblGrid><w:gridCol w:w="3000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+			`<w:tr>` + conditionTestCell("Item", "") + conditionTestCell("Total", "") + `</w:tr>` +
+			`<w:tr>` + conditionTestCell("Book", "") + conditionTestCell("9.00", "") + `</w:tr>` +
+			`<w:tr><w:trPr><w:gridBefore w:val="1"/></w:trPr>` + conditionTestCell("VAT", "") + `</w:tr></w:tbl>`
+		if !strings.Contains(result, expected) {
+			t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+		}
+		if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("true removes the markers", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(table + `<w:p/>`)})
+
+		report, err := doc.SetCondition("has_discount", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Columns) != 0 || len(report.Rows) != 0 {
+			t.Errorf("expected nothing to be removed, got %+v", report)
+		}
+		expected := strings.Replace(strings.Replace(table, "{#col:has_discount}", "", 1), "{#row:has_discount}", "", 1)
+		if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+			t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+		}
+	})
+
+	t.Run("rows with nested tables before marked columns", func(t *testing.T) {
+		nested := `<w:tbl><w:tblGrid><w:gridCol w:w="1000"/></w:tblGrid><w:tr>` + conditionTestCell("Inner", "") + `</w:tr></w:tbl>`
+		parent := `<w:tbl><w:tblGrid><w:gridCol w:w="3000"/></w:tblGrid>` +
+			`<w:tr>` + conditionTestCell("Item", "") + `</w:tr>` +
+			`<w:tr><w:tc><w:tcPr/><w:p><w:r><w:t>{#row:has_discount}</w:t></w:r></w:p>` + nested + `<w:p/></w:tc></w:tr>` +
+			`</w:tbl>`
+		columns := `<w:tbl><w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+			`<w:tr>` + conditionTestCell("Discount{#col:has_discount}", "") + conditionTestCell("Total", "") + `</w:tr>` +
+			`<w:tr>` + conditionTestCell("10%", "") + conditionTestCell("9.00", "") + `</w:tr>` +
+			`</w:tbl>`
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(parent + `<w:p/>` + columns + `<w:p/>`)})
+
+		report, err := doc.SetCondition("has_discount", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedReport := ConditionReport{
+			Columns: []RemovedColumn{{Table: 2, Column: 0}},
+			Rows:    []RemovedRow{{Table: 0, Row: 1}},
+		}
+		if !reflect.DeepEqual(report, expectedReport) {
+			t.Errorf("unexpected report\nwant=%+v\nhave=%+v", expectedReport, report)
+		}
+
+		expected := `<w:tbl><w:tblGrid><w:gridCol w:w="3000"/></w:tblGrid><w:tr>` + conditionTestCell("Item", "") + `</w:tr></w:tbl><w:p/>` +
+			`<w:tbl><w:tblGrid><w:gridCol w:w="2000"/></w:tblGrid>` +
+			`<w:tr>` + conditionTestCell("Total", "") + `</w:tr><w:tr>` + conditionTestCell("9.00", "") + `</w:tr></w:tbl>`
+		if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+			t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+		}
+		if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestTable_HideColumn(t *testing.T) {
+	table := `<w:tbl><w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="2000"/><w:gridCol w:w="3000"/></w:tblGrid>` +
+		`<w:tr>` + conditionTestCell("a", `<w:tcW w:w="3000" w:type="dxa"/><w:gridSpan w:val="2"/>`) + conditionTestCell("b", "") + `</w:tr>` +
+		`<w:tr>` + conditionTestCell("c", `<w:gridSpan w:val="3"/>`) + `</w:tr>` +
+		`<w:tr><w:trPr><w:gridAfter w:val="2"/></w:trPr>` + conditionTestCell("d", "") + `</w:tr>` +
+		`</w:tbl>`
+
+	tests := []struct {
+		name     string
+		column   int
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:   "straddling spans",
+			column: 1,
+			expected: `<w:tblGrid><w:gridCol w:w="1000"/><w:gridCol w:w="3000"/></w:tblGrid>` +
+				`<w:tr>` + conditionTestCell("a", `<w:tcW w:w="1000" w:type="dxa"/>`) + conditionTestCell("b", "") + `</w:tr>` +
+				`<w:tr>` + conditionTestCell("c", `<w:gridSpan w:val="2"/>`) + `</w:tr>` +
+				`<w:tr><w:trPr><w:gridAfter w:val="1"/></w:trPr>` + conditionTestCell("d", "") + `</w:tr></w:tbl>`,
+		},
+		{
+			name:   "row without remaining cells",
+			column: 0,
+			expected: `<w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="3000"/></w:tblGrid>` +
+				`<w:tr>` + conditionTestCell("a", `<w:tcW w:w="2000" w:type="dxa"/>`) + conditionTestCell("b", "") + `</w:tr>` +
+				`<w:tr>` + conditionTestCell("c", `<w:gridSpan w:val="2"/>`) + `</w:tr></w:tbl>`,
+		},
+		{
+			name:    "unknown column",
+			column:  5,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(table + `<w:p/>`)})
+
+			err := doc.Tables()[0].HideColumn(tt.column)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, tt.expected) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestTable_HideRow(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:tbl><w:tr>` + conditionTestCell("a", "") + `</w:tr><w:tr>` + conditionTestCell("b", "") + `</w:tr></w:tbl><w:p/>`),
+	})
+	table := doc.Tables()[0]
+
+	if err := table.HideRow(0); err != nil {
+		t.Fatal(err)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `<w:tbl><w:tr>`+conditionTestCell("b", "")+`</w:tr></w:tbl>`) {
+		t.Errorf("unexpected document %s", result)
+	}
+	if err := table.HideRow(0); err == nil {
+		t.Error("expected an error when removing the last row")
+	}
+}
@@ -0,0 +1,100 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// ContentTypesXml is the path of the part which defines the content types of all other parts.
+	ContentTypesXml = "[Content_Types].xml"
+)
+
+var (
+	// TypesCloseTagRegex matches the close tag of the content types part
+	TypesCloseTagRegex = regexp.MustCompile(`</Types>`)
+)
+
+// contentTypes is used to unmarshal the [Content_Types].xml part
+type contentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// parseContentTypes reads the content types part of the document
+func (d *Document) parseContentTypes() (contentTypes, error) {
+	var types contentTypes
+	data, err := d.getPart(ContentTypesXml)
+	if err != nil {
+		return types, err
+	}
+	if err := xml.Unmarshal(data, &types); err != nil {
+		return types, fmt.Errorf("unable to parse %s: %s", ContentTypesXml, err)
+	}
+	return types, nil
+}
+
+// ContentType returns the content type of the given part.
+// Overrides for the part name take precedence over the defaults for the file extension.
+// If there is no content type defined for the part, an empty string is returned.
+func (d *Document) ContentType(partName string) (string, error) {
+	types, err := d.parseContentTypes()
+	if err != nil {
+		return "", err
+	}
+	return types.lookup(partName), nil
+}
+
+// lookup returns the content type of the given part or an empty string if it is not defined.
+func (types contentTypes) lookup(partName string) string {
+	for _, override := range types.Overrides {
+		if strings.TrimPrefix(override.PartName, "/") == partName {
+			return override.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(partName), ".")
+	for _, def := range types.Defaults {
+		if strings.EqualFold(def.Extension, ext) {
+			return def.ContentType
+		}
+	}
+	return ""
+}
+
+// ensureContentType makes sure that the given part resolves to the given content type.
+// If it does not, an Override for the part is added right before the closing tag of the content types part.
+func (d *Document) ensureContentType(partName, contentType string) error {
+	current, err := d.ContentType(partName)
+	if err != nil {
+		return err
+	}
+	if current == contentType {
+		return nil
+	}
+
+	data, err := d.getPart(ContentTypesXml)
+	if err != nil {
+		return err
+	}
+	loc := TypesCloseTagRegex.FindIndex(data)
+	if loc == nil {
+		return fmt.Errorf("invalid content types part %s", ContentTypesXml)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(data[:loc[0]])
+	buf.WriteString(fmt.Sprintf(`<Override PartName="/%s" ContentType="%s"/>`, xmlEscape(partName), xmlEscape(contentType)))
+	buf.Write(data[loc[0]:])
+
+	return d.setPart(ContentTypesXml, buf.Bytes())
+}
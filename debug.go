@@ -0,0 +1,98 @@
+package docx
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// DebugModelVersion is the version of the serialized DebugModel. It is increased on every incompatible change.
+const DebugModelVersion = 1
+
+// DebugSpan is a byte range [Start, End) inside a part.
+type DebugSpan struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// DebugCell locates a run inside a table, the table index counts all tables of the part in the order of their start
+// (as Document.Tables does for the main document).
+type DebugCell struct {
+	Table  int `json:"table"`
+	Row    int `json:"row"`
+	Column int `json:"column"` // first grid column of the cell
+}
+
+// DebugRun is the serialized view of a single run.
+type DebugRun struct {
+	ID        int        `json:"id"`
+	OpenTag   DebugSpan  `json:"openTag"`
+	CloseTag  DebugSpan  `json:"closeTag"`
+	HasText   bool       `json:"hasText"`
+	TextOpen  *DebugSpan `json:"textOpenTag,omitempty"`
+	TextClose *DebugSpan `json:"textCloseTag,omitempty"`
+	Text      string     `json:"text,omitempty"`
+	// Paragraph is the index of the paragraph containing the run, -1 if the run is not inside a paragraph
+	Paragraph int        `json:"paragraph"`
+	Cell      *DebugCell `json:"cell,omitempty"`
+}
+
+// DebugPart contains all runs of a single part in the order of their start.
+type DebugPart struct {
+	Name string     `json:"name"`
+	Runs []DebugRun `json:"runs"`
+}
+
+// DebugModel is the serialized view of the library on the parsed parts of a document.
+type DebugModel struct {
+	Version int         `json:"version"`
+	Parts   []DebugPart `json:"parts"`
+}
+
+// DebugModel returns the runs of all parsed parts as JSON, including their byte positions, text, paragraph
+// and table cell. External tools can use it to see which bytes the library assigns to each run.
+// The format is described by the DebugModel type and versioned by DebugModelVersion.
+func (d *Document) DebugModel() ([]byte, error) {
+	model := DebugModel{Version: DebugModelVersion, Parts: []DebugPart{}}
+
+	var names []string
+	for name := range d.runParsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data := d.GetFile(name)
+		runs := append([]*Run(nil), d.runParsers[name].Runs()...)
+		sort.Slice(runs, func(i, j int) bool {
+			return runs[i].OpenTag.Start < runs[j].OpenTag.Start
+		})
+
+		paragraphs := ParagraphOpenTagRegex.FindAllIndex(data, -1)
+		part := DebugPart{Name: name, Runs: []DebugRun{}}
+		for _, run := range runs {
+			debugRun := DebugRun{
+				ID:        run.ID,
+				OpenTag:   DebugSpan{Start: run.OpenTag.Start, End: run.OpenTag.End},
+				CloseTag:  DebugSpan{Start: run.CloseTag.Start, End: run.CloseTag.End},
+				HasText:   run.HasText,
+				Paragraph: -1,
+			}
+			if run.HasText {
+				debugRun.TextOpen = &DebugSpan{Start: run.Text.OpenTag.Start, End: run.Text.OpenTag.End}
+				debugRun.TextClose = &DebugSpan{Start: run.Text.CloseTag.Start, End: run.Text.CloseTag.End}
+				debugRun.Text = run.GetText(data)
+			}
+			if start, _, err := enclosingElement(data, ParagraphOpenTagRegex, run.OpenTag.Start); err == nil {
+				debugRun.Paragraph = sort.Search(len(paragraphs), func(i int) bool {
+					return int64(paragraphs[i][0]) >= start
+				})
+			}
+			if table, row, cell, err := markedCell(data, run.OpenTag.Start); err == nil {
+				debugRun.Cell = &DebugCell{Table: table, Row: row, Column: cell.column}
+			}
+			part.Runs = append(part.Runs, debugRun)
+		}
+		model.Parts = append(model.Parts, part)
+	}
+	return json.MarshalIndent(model, "", "  ")
+}
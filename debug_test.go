@@ -0,0 +1,53 @@
+package docx
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_DebugModel(t *testing.T) {
+	body := `<w:p><w:r><w:t>{name}</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:tab/></w:r></w:p></w:tc></w:tr></w:tbl>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	serialized, err := doc.DebugModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var model DebugModel
+	if err := json.Unmarshal(serialized, &model); err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Version != DebugModelVersion {
+		t.Errorf("unexpected version %d", model.Version)
+	}
+	if len(model.Parts) != 1 || model.Parts[0].Name != DocumentXml {
+		t.Fatalf("unexpected parts %+v", model.Parts)
+	}
+	runs := model.Parts[0].Runs
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+
+	data := doc.GetFile(DocumentXml)
+	for i, run := range runs {
+		if tag := string(data[run.OpenTag.Start:run.OpenTag.End]); tag != "<w:r>" {
+			t.Errorf("run %d: open tag span points to %s", i, tag)
+		}
+		if tag := string(data[run.CloseTag.Start:run.CloseTag.End]); tag != "</w:r>" {
+			t.Errorf("run %d: close tag span points to %s", i, tag)
+		}
+	}
+
+	if runs[0].Text != "{name}" || !runs[0].HasText || runs[0].Paragraph != 0 || runs[0].Cell != nil {
+		t.Errorf("unexpected first run %+v", runs[0])
+	}
+	if !reflect.DeepEqual(runs[1].Cell, &DebugCell{Table: 0, Row: 0, Column: 0}) || runs[1].Paragraph != 1 {
+		t.Errorf("unexpected second run %+v", runs[1])
+	}
+	if runs[2].HasText || runs[2].TextOpen != nil || !reflect.DeepEqual(runs[2].Cell, &DebugCell{Table: 0, Row: 0, Column: 1}) {
+		t.Errorf("unexpected third run %+v", runs[2])
+	}
+}
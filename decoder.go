@@ -0,0 +1,54 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// DecoderSettings configure the xml.Decoder which locates the runs of the parsed files, see xml.Decoder for the
+// details of each setting. The zero value decodes strictly, just like xml.NewDecoder.
+type DecoderSettings struct {
+	// NonStrict disables the strict mode of the decoder (xml.Decoder.Strict). Unknown entities like '&nbsp;' are
+	// kept as text, a '&' which does not start an entity is accepted and attribute values may be unquoted.
+	// Some producers write such documents and Word still opens them.
+	NonStrict bool
+	// AutoClose are the names of elements which are closed implicitly in non-strict mode (xml.Decoder.AutoClose).
+	AutoClose []string
+	// Entity maps additional entity names to their replacement text (xml.Decoder.Entity).
+	Entity map[string]string
+}
+
+// WithDecoderSettings configures the decoder which parses the document, headers, footers and notes.
+// By default the files are decoded strictly and documents which are not well-formed are rejected with ErrInvalidXml.
+//
+// The settings only affect parsing: the replaced values are always inserted as well-formed markup and the
+// rest of the file is kept as it is. Validate still requires well-formed XML.
+func WithDecoderSettings(settings DecoderSettings) Option {
+	return func(d *Document) {
+		d.decoderSettings = settings
+	}
+}
+
+// newDecoder returns a decoder on the reader which uses the settings.
+func (s DecoderSettings) newDecoder(reader io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(reader)
+	decoder.Strict = !s.NonStrict
+	decoder.AutoClose = s.AutoClose
+	decoder.Entity = s.Entity
+	return decoder
+}
+
+// checkWellFormed decodes the whole data using the settings and returns an error if it cannot be decoded.
+func (s DecoderSettings) checkWellFormed(data []byte) error {
+	decoder := s.newDecoder(bytes.NewReader(data))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
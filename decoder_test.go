@@ -0,0 +1,37 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithDecoderSettings(t *testing.T) {
+	archive := newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Dear&nbsp;{name},</w:t></w:r></w:p>`),
+	})
+	if _, err := OpenBytes(archive); !errors.Is(err, ErrInvalidXml) {
+		t.Fatalf("expected ErrInvalidXml by default, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		settings DecoderSettings
+	}{
+		{"non-strict", DecoderSettings{NonStrict: true}},
+		{"entity", DecoderSettings{Entity: map[string]string{"nbsp": " "}}},
+	}
+	for _, tt := range tests {
+		doc, err := OpenBytes(archive, WithDecoderSettings(tt.settings))
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		// the entity is kept as it is
+		if expected := `<w:t>Dear&nbsp;Jane,</w:t>`; !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+			t.Errorf("%s: expected %s in %s", tt.name, expected, doc.GetFile(DocumentXml))
+		}
+	}
+}
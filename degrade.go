@@ -0,0 +1,280 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// WordVersion is a version of Microsoft Word, identified by its internal version number.
+type WordVersion int
+
+const (
+	// Word2007 is Word 2007, the first version which reads docx documents.
+	Word2007 WordVersion = 12
+	// Word2010 is Word 2010.
+	Word2010 WordVersion = 14
+	// Word2013 is Word 2013.
+	Word2013 WordVersion = 15
+	// Word2016 is Word 2016 and all later versions, including Microsoft 365.
+	Word2016 WordVersion = 16
+)
+
+// CompatibilityFeature distinguishes the features reported by CompatibilityReport.
+type CompatibilityFeature int
+
+const (
+	// FeatureSvgImage is an SVG image (<asvg:svgBlip>), which is rendered by Word 2016 and later.
+	FeatureSvgImage CompatibilityFeature = iota
+	// FeatureCheckbox is a checkbox content control (<w14:checkbox>), which is rendered by Word 2010 and later.
+	FeatureCheckbox
+	// FeatureNumberFormat is a number format (<w:numFmt>) which was added after Word 2007, e.g. 'custom'.
+	FeatureNumberFormat
+	// FeatureAnchorOption is a relative size or position of an anchored drawing (wp14), which are rendered by
+	// Word 2010 and later.
+	FeatureAnchorOption
+)
+
+var (
+	// SvgBlipTagRegex matches the SVG image of a picture (<asvg:svgBlip>), the PNG fallback being the enclosing <a:blip>
+	SvgBlipTagRegex = regexp.MustCompile(`<[A-Za-z0-9_]+:svgBlip\s[^>]*>`)
+	// CheckboxTagRegex matches the checkbox properties of a content control (<w14:checkbox>)
+	CheckboxTagRegex = regexp.MustCompile(`<w14:checkbox[\s/>]`)
+	// NumberFormatTagRegex matches a number format (<w:numFmt>) of a numbering level or of notes
+	NumberFormatTagRegex = regexp.MustCompile(`<w:numFmt\s[^>]*>`)
+	// AnchorOptionTagRegex matches the relative sizes and positions of anchored drawings, the group contains the name
+	AnchorOptionTagRegex = regexp.MustCompile(`<wp14:(sizeRelH|sizeRelV|pctPosHOffset|pctPosVOffset)(?:\s[^>]*)?/?>`)
+
+	// drawingExtensionOpenTagRegex matches the open tag of a DrawingML extension (<a:ext>)
+	drawingExtensionOpenTagRegex = regexp.MustCompile(`<a:ext(?:\s[^>]*)?>`)
+	// emptyDrawingExtensionListRegex matches an extension list without extensions
+	emptyDrawingExtensionListRegex = regexp.MustCompile(`<a:extLst>\s*</a:extLst>`)
+	// blipOpenTagRegex matches the open tag of a picture (<a:blip>)
+	blipOpenTagRegex = regexp.MustCompile(`<a:blip(?:\s[^>]*)?>`)
+	// contentControlOpenTagRegex matches the open tag of a content control (<w:sdt>)
+	contentControlOpenTagRegex = regexp.MustCompile(`<w:sdt(?:\s[^>]*)?>`)
+	// checkedTagRegex matches the checked state of a checkbox content control
+	checkedTagRegex = regexp.MustCompile(`<w14:checked(?:\s[^>]*)?/?>`)
+	// contentControlContentRegex matches the content of a content control, the group contains it
+	contentControlContentRegex = regexp.MustCompile(`(?s)<w:sdtContent(?:\s[^>]*)?>(.*)</w:sdtContent>`)
+
+	// numberFormatVersions are the number formats which Word 2007 does not know, by the version which added them
+	numberFormatVersions = map[string]WordVersion{
+		"custom": Word2010,
+	}
+)
+
+// CompatibilityIssue is a feature of the document which an older version of Word cannot render.
+type CompatibilityIssue struct {
+	Feature CompatibilityFeature
+	// Part is the part which contains the feature, e.g. 'word/document.xml'.
+	Part string
+	// Position is the position of the tag of the feature inside the part.
+	Position Position
+	// Detail names the occurrence, e.g. the number format or the anchor option.
+	Detail string
+	// Version is the first version of Word which renders the feature.
+	Version WordVersion
+	// Degradable is true if DegradeFor replaces the feature with markup the target renders.
+	Degradable bool
+}
+
+// CompatibilityReport returns all features of the document, headers, footers, notes and numbering definitions
+// which Word in the target version cannot render, in the order of the parts and their position.
+// Features inside mc:AlternateContent are not reported, older versions use the fallback.
+func (d *Document) CompatibilityReport(target WordVersion) ([]CompatibilityIssue, error) {
+	var issues []CompatibilityIssue
+	for _, part := range d.compatibilityParts() {
+		data, err := d.getPart(part)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range compatibilityIssues(part, data) {
+			if issue.Version > target {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// DegradeFor replaces the features which Word in the target version cannot render with safe equivalents:
+//
+//   - SVG images are removed from pictures which have a PNG fallback, the fallback is displayed instead.
+//     The SVG image itself remains in the package.
+//   - Checkbox content controls inside a paragraph are converted to legacy FORMCHECKBOX fields with the same
+//     checked state. The formatting of the first run of the control is kept.
+//   - The 'custom' number format is replaced by its fallback: 'decimalZero' for zero padded formats
+//     (e.g. '01, 02, 03, ...'), 'decimal' otherwise.
+//
+// Use CompatibilityReport to list the features which remain, e.g. anchor options.
+func (d *Document) DegradeFor(target WordVersion) error {
+	for _, part := range d.compatibilityParts() {
+		data, err := d.getPart(part)
+		if err != nil {
+			return err
+		}
+
+		var (
+			edits   []edit
+			svgRefs = make(map[string]bool)
+		)
+		for _, issue := range compatibilityIssues(part, data) {
+			if issue.Version <= target || !issue.Degradable {
+				continue
+			}
+			e, err := degrade(data, issue)
+			if err != nil {
+				return fmt.Errorf("unable to degrade %s in %s: %s", issue.Detail, part, err)
+			}
+			if issue.Feature == FeatureSvgImage {
+				if id, ok := attributeValue(data[issue.Position.Start:issue.Position.End], "r:embed"); ok {
+					svgRefs[id] = true
+				}
+			}
+			edits = append(edits, e)
+		}
+		if len(edits) == 0 {
+			continue
+		}
+
+		sort.SliceStable(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+		data = emptyDrawingExtensionListRegex.ReplaceAll(applyEdits(data, edits), nil)
+		if err := d.updatePart(part, data); err != nil {
+			return err
+		}
+		for _, ref := range RelationshipReferenceRegex.FindAllSubmatch(data, -1) {
+			delete(svgRefs, string(ref[2]))
+		}
+		if err := d.removeRelationships(part, svgRefs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compatibilityParts returns the parts which are scanned by CompatibilityReport, sorted.
+func (d *Document) compatibilityParts() []string {
+	parts := d.fileNames()
+	if d.partExists(NumberingXml) {
+		parts = append(parts, NumberingXml)
+		sort.Strings(parts)
+	}
+	return parts
+}
+
+// compatibilityIssues returns all features of the part which were added after Word 2007, in ascending order.
+func compatibilityIssues(part string, data []byte) []CompatibilityIssue {
+	alternates := alternateContentElements(data, "AlternateContent")
+
+	var issues []CompatibilityIssue
+	add := func(issue CompatibilityIssue) {
+		if positionInside(alternates, issue.Position.Start) {
+			return
+		}
+		issue.Part = part
+		issues = append(issues, issue)
+	}
+
+	for _, loc := range SvgBlipTagRegex.FindAllIndex(data, -1) {
+		_, fallback := svgFallback(data, int64(loc[0]))
+		add(CompatibilityIssue{
+			Feature:    FeatureSvgImage,
+			Position:   Position{Start: int64(loc[0]), End: int64(loc[1])},
+			Detail:     "svg image",
+			Version:    Word2016,
+			Degradable: fallback,
+		})
+	}
+	for _, loc := range CheckboxTagRegex.FindAllIndex(data, -1) {
+		start, end, err := enclosingElement(data, contentControlOpenTagRegex, int64(loc[0]))
+		degradable := err == nil && !ParagraphOpenTagRegex.Match(data[start:end])
+		add(CompatibilityIssue{
+			Feature:    FeatureCheckbox,
+			Position:   Position{Start: start, End: end},
+			Detail:     "checkbox",
+			Version:    Word2010,
+			Degradable: degradable,
+		})
+	}
+	for _, loc := range NumberFormatTagRegex.FindAllIndex(data, -1) {
+		format, _ := attributeValue(data[loc[0]:loc[1]], "w:val")
+		version, isNew := numberFormatVersions[format]
+		if !isNew {
+			continue
+		}
+		add(CompatibilityIssue{
+			Feature:    FeatureNumberFormat,
+			Position:   Position{Start: int64(loc[0]), End: int64(loc[1])},
+			Detail:     format,
+			Version:    version,
+			Degradable: true,
+		})
+	}
+	for _, loc := range AnchorOptionTagRegex.FindAllSubmatchIndex(data, -1) {
+		add(CompatibilityIssue{
+			Feature:  FeatureAnchorOption,
+			Position: Position{Start: int64(loc[0]), End: int64(loc[1])},
+			Detail:   string(data[loc[2]:loc[3]]),
+			Version:  Word2010,
+		})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Position.Start < issues[j].Position.Start })
+	return issues
+}
+
+// svgFallback returns the position of the extension (<a:ext>) which contains the SVG image at pos, and whether the
+// enclosing picture has an embedded PNG fallback.
+func svgFallback(data []byte, pos int64) (Position, bool) {
+	start, end, err := enclosingElement(data, drawingExtensionOpenTagRegex, pos)
+	if err != nil {
+		return Position{}, false
+	}
+	blipStart, _, err := enclosingElement(data, blipOpenTagRegex, pos)
+	if err != nil {
+		return Position{}, false
+	}
+	embed, _ := attributeValue(blipOpenTagRegex.Find(data[blipStart:]), "r:embed")
+	return Position{Start: start, End: end}, embed != ""
+}
+
+// degrade returns the edit which replaces the degradable feature with markup that Word 2007 renders.
+func degrade(data []byte, issue CompatibilityIssue) (edit, error) {
+	switch issue.Feature {
+	case FeatureSvgImage:
+		pos, _ := svgFallback(data, issue.Position.Start)
+		return edit{Position: pos}, nil
+	case FeatureCheckbox:
+		return edit{Position: issue.Position, Replacement: legacyCheckbox(data[issue.Position.Start:issue.Position.End])}, nil
+	case FeatureNumberFormat:
+		format := "decimal"
+		if pattern, _ := attributeValue(data[issue.Position.Start:issue.Position.End], "w:format"); strings.HasPrefix(pattern, "0") {
+			format = "decimalZero"
+		}
+		return edit{Position: issue.Position, Replacement: []byte(fmt.Sprintf(`<w:numFmt w:val="%s"/>`, format))}, nil
+	}
+	return edit{}, fmt.Errorf("feature %d cannot be degraded", issue.Feature)
+}
+
+// legacyCheckbox returns the FORMCHECKBOX field which replaces the given checkbox content control.
+func legacyCheckbox(sdt []byte) []byte {
+	checked := "0"
+	if tag := checkedTagRegex.Find(sdt); tag != nil {
+		if value, ok := attributeValue(tag, "w14:val"); !ok || value == "1" || value == "true" {
+			checked = "1"
+		}
+	}
+	var properties string
+	if content := contentControlContentRegex.FindSubmatch(sdt); content != nil {
+		properties = string(RunPropertiesRegex.Find(content[1]))
+	}
+
+	var field strings.Builder
+	field.WriteString(`<w:r>` + properties + `<w:fldChar w:fldCharType="begin"><w:ffData><w:name w:val=""/><w:enabled/>` +
+		`<w:calcOnExit w:val="0"/><w:checkBox><w:sizeAuto/><w:default w:val="` + checked + `"/></w:checkBox></w:ffData></w:fldChar></w:r>`)
+	field.WriteString(`<w:r>` + properties + `<w:instrText xml:space="preserve"> FORMCHECKBOX </w:instrText></w:r>`)
+	field.WriteString(`<w:r>` + properties + `<w:fldChar w:fldCharType="end"/></w:r>`)
+	return []byte(field.String())
+}
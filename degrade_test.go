@@ -0,0 +1,117 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func testDegradeParts() map[string]string {
+	picture := `<w:p><w:r><w:drawing><wp:anchor><wp:extent cx="100" cy="100"/>` +
+		`<a:graphic><a:graphicData><pic:pic><pic:blipFill><a:blip r:embed="rId1"><a:extLst>` +
+		`<a:ext uri="{96DAC541-7B7A-43D3-8B79-37D633B846F1}"><asvg:svgBlip r:embed="rId2"/></a:ext>` +
+		`</a:extLst></a:blip></pic:blipFill></pic:pic></a:graphicData></a:graphic>` +
+		`<wp14:sizeRelH relativeFrom="page"><wp14:pctWidth>50000</wp14:pctWidth></wp14:sizeRelH></wp:anchor></w:drawing></w:r></w:p>`
+	checkbox := `<w:p><w:sdt><w:sdtPr><w14:checkbox><w14:checked w14:val="1"/></w14:checkbox></w:sdtPr>` +
+		`<w:sdtContent><w:r><w:rPr><w:b/></w:rPr><w:t>☒</w:t></w:r></w:sdtContent></w:sdt><w:r><w:t>Agreed</w:t></w:r></w:p>`
+	alternate := `<w:p><mc:AlternateContent><mc:Choice Requires="w14"><w:sdt><w:sdtPr><w14:checkbox/></w:sdtPr>` +
+		`<w:sdtContent><w:r><w:t>☐</w:t></w:r></w:sdtContent></w:sdt></mc:Choice><mc:Fallback><w:r><w:t>☐</w:t></w:r></mc:Fallback></mc:AlternateContent></w:p>`
+	numbering := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:abstractNum w:abstractNumId="0">` +
+		`<w:lvl w:ilvl="0"><w:numFmt w:val="custom" w:format="001, 002, 003, ..."/><w:lvlText w:val="%1."/></w:lvl>` +
+		`<w:lvl w:ilvl="1"><w:numFmt w:val="lowerLetter"/></w:lvl></w:abstractNum></w:numbering>`
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + ImageRelationshipType + `" Target="media/image1.png"/>` +
+		`<Relationship Id="rId2" Type="` + ImageRelationshipType + `" Target="media/image2.svg"/></Relationships>`
+
+	return map[string]string{
+		DocumentXml:                    testDocumentXml(picture + checkbox + alternate),
+		NumberingXml:                   numbering,
+		"word/_rels/document.xml.rels": rels,
+		"word/media/image1.png":        "png",
+		"word/media/image2.svg":        "<svg/>",
+	}
+}
+
+func TestDocument_CompatibilityReport(t *testing.T) {
+	doc := openTestDocx(t, testDegradeParts())
+
+	tests := []struct {
+		target   WordVersion
+		expected []string
+	}{
+		{Word2007, []string{"word/document.xml:svg image:16:true", "word/document.xml:sizeRelH:14:false",
+			"word/document.xml:checkbox:14:true", "word/numbering.xml:custom:14:true"}},
+		{Word2010, []string{"word/document.xml:svg image:16:true"}},
+		{Word2016, nil},
+	}
+	for _, tt := range tests {
+		issues, err := doc.CompatibilityReport(tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		for _, issue := range issues {
+			found = append(found, fmt.Sprintf("%s:%s:%d:%v", issue.Part, issue.Detail, issue.Version, issue.Degradable))
+		}
+		if strings.Join(found, "\n") != strings.Join(tt.expected, "\n") {
+			t.Errorf("target %d: unexpected issues\nwant=%v\nhave=%v", tt.target, tt.expected, found)
+		}
+	}
+}
+
+func TestDocument_DegradeFor(t *testing.T) {
+	doc := openTestDocx(t, testDegradeParts())
+	if err := doc.DegradeFor(Word2010); err != nil {
+		t.Fatal(err)
+	}
+	doc = reopen(t, doc)
+
+	document := string(doc.GetFile(DocumentXml))
+	if err := checkWellFormed([]byte(document)); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`<a:blip r:embed="rId1"></a:blip>`, `<w14:checkbox>`, `<wp14:sizeRelH`} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in document %s", expected, document)
+		}
+	}
+	rels, err := doc.Relationships(DocumentXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rels) != 1 || rels[0].ID != "rId1" {
+		t.Errorf("expected the svg relationship to be removed, got %v", rels)
+	}
+
+	if err := doc.DegradeFor(Word2007); err != nil {
+		t.Fatal(err)
+	}
+	document = string(doc.GetFile(DocumentXml))
+	checkbox := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:fldChar w:fldCharType="begin"><w:ffData><w:name w:val=""/><w:enabled/>` +
+		`<w:calcOnExit w:val="0"/><w:checkBox><w:sizeAuto/><w:default w:val="1"/></w:checkBox></w:ffData></w:fldChar></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:instrText xml:space="preserve"> FORMCHECKBOX </w:instrText></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:fldChar w:fldCharType="end"/></w:r><w:r><w:t>Agreed</w:t></w:r></w:p>`
+	if !strings.Contains(document, checkbox) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", checkbox, document)
+	}
+	if !strings.Contains(document, `<mc:Choice Requires="w14"><w:sdt>`) {
+		t.Errorf("expected the alternate content to remain, got %s", document)
+	}
+	numbering, err := doc.getPart(NumberingXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(numbering), `<w:numFmt w:val="decimalZero"/>`) {
+		t.Errorf("unexpected numbering %s", numbering)
+	}
+
+	issues, err := doc.CompatibilityReport(Word2007)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Feature != FeatureAnchorOption {
+		t.Errorf("expected only the anchor option to remain, got %v", issues)
+	}
+}
@@ -0,0 +1,129 @@
+package docx
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithDelimiters registers additional pairs of delimiters which are used besides OpenDelimiter and CloseDelimiter,
+// e.g. to handle templates which use both '{x}' and '${y}':
+//
+//	docx.Open("template.docx", docx.WithDelimiters(docx.Delimiters{Open: "${", Close: "}"}))
+//
+// Keys without delimiters (e.g. in ReplaceAll) match the placeholders of all pairs. If placeholders of different
+// pairs overlap, e.g. '{y}' inside '${y}', the placeholder which starts first wins, for equal starts the longer one.
+// Delimiters consisting of multiple characters are only detected if they are not split across runs.
+// The additional delimiters apply to text replacement, all other features (e.g. images and rows) use the default.
+func WithDelimiters(delimiters ...Delimiters) Option {
+	return func(d *Document) {
+		d.delimiters = append(d.delimiters, delimiters...)
+	}
+}
+
+// allDelimiters returns the default delimiters followed by all additional delimiters.
+func (d *Document) allDelimiters() []Delimiters {
+	return append([]Delimiters{DefaultDelimiters()}, d.delimiters...)
+}
+
+// parsePlaceholders parses the placeholders of all delimiter pairs, dropping overlapping placeholders.
+func (d *Document) parsePlaceholders(runs DocumentRuns, data []byte) ([]*Placeholder, error) {
+	if len(d.delimiters) == 0 {
+		return parsePlaceholders(runs, data, d.grammar(DefaultDelimiters()), d.emptyRunsBreakPlaceholders)
+	}
+
+	var all []*Placeholder
+	for _, delimiters := range d.allDelimiters() {
+		placeholders, err := parsePlaceholders(runs, data, d.grammar(delimiters), d.emptyRunsBreakPlaceholders)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, placeholders...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].StartPos() != all[j].StartPos() {
+			return all[i].StartPos() < all[j].StartPos()
+		}
+		return all[i].EndPos() > all[j].EndPos()
+	})
+
+	var placeholders []*Placeholder
+	end := int64(-1)
+	for _, placeholder := range all {
+		if placeholder.StartPos() < end {
+			continue
+		}
+		placeholders = append(placeholders, placeholder)
+		end = placeholder.EndPos()
+	}
+	return placeholders, nil
+}
+
+// placeholderLiterals returns the texts of all placeholders which match the given key. A key which is already
+// delimited only matches itself, otherwise it is delimited with every pair of delimiters.
+func (d *Document) placeholderLiterals(key string) []string {
+	pairs := d.allDelimiters()
+	for _, delimiters := range pairs {
+		if delimiters.Delimits(key) {
+			return []string{key}
+		}
+	}
+
+	var literals []string
+	seen := make(map[string]bool)
+	for _, delimiters := range pairs {
+		literal := delimiters.Wrap(key)
+		if !seen[literal] {
+			seen[literal] = true
+			literals = append(literals, literal)
+		}
+	}
+	return literals
+}
+
+// countOccurrences returns how often the literals occur inside the text. Overlapping occurrences
+// are counted once, following the same rules as overlapping placeholders. Occurrences which start inside one of the
+// escaped delimiters are no placeholders and not counted.
+func countOccurrences(text string, literals []string, escapes []Position) int {
+	var occurrences []Position
+	for _, literal := range literals {
+		if literal == "" {
+			continue
+		}
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], literal)
+			if i < 0 {
+				break
+			}
+			start := int64(offset + i)
+			occurrences = append(occurrences, Position{Start: start, End: start + int64(len(literal))})
+			offset += i + len(literal)
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool {
+		if occurrences[i].Start != occurrences[j].Start {
+			return occurrences[i].Start < occurrences[j].Start
+		}
+		return occurrences[i].End > occurrences[j].End
+	})
+
+	count := 0
+	end := int64(-1)
+	for _, occurrence := range occurrences {
+		if occurrence.Start < end || escapedAt(escapes, occurrence.Start) {
+			continue
+		}
+		count++
+		end = occurrence.End
+	}
+	return count
+}
+
+// escapedAt returns true if the position is inside one of the escaped delimiters.
+func escapedAt(escapes []Position, pos int64) bool {
+	for _, escape := range escapes {
+		if pos >= escape.Start && pos < escape.End {
+			return true
+		}
+	}
+	return false
+}
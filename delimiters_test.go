@@ -0,0 +1,42 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_MixedDelimiters(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">Dear {name}, your order ${order} </w:t></w:r>` +
+		`<w:r><w:t>ships on ${</w:t></w:r><w:r><w:t>date}.</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}),
+		WithDelimiters(Delimiters{Open: "${", Close: "}"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "order": "#42", "date": "Monday"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := string(doc.GetFile(DocumentXml))
+	expected := `<w:r><w:t xml:space="preserve">Dear Jane, your order #42 </w:t></w:r>` +
+		`<w:r><w:t>ships on Monday</w:t></w:r><w:r><w:t>.</w:t></w:r>`
+	if !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestDocument_Replace_DelimitedKey(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{x} and [x]</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}),
+		WithDelimiters(Delimiters{Open: "[", Close: "]"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Replace("[x]", "square"); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `{x} and square`) {
+		t.Errorf("only the placeholder with the given delimiters must be replaced: %s", result)
+	}
+}
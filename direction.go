@@ -0,0 +1,168 @@
+package docx
+
+import (
+	"bytes"
+	"sort"
+	"unicode"
+)
+
+// TextDirection defines the direction of the runs which contain replaced values, see WithTextDirection.
+type TextDirection int
+
+const (
+	// TextDirectionAuto detects the direction from the paragraph: in a right-to-left paragraph (<w:bidi/>, either
+	// directly, by its style or by the properties of its paragraph mark), runs with right-to-left characters
+	// (e.g. Arabic or Hebrew) are marked as right-to-left (<w:rtl/>). Runs which define <w:rtl> are kept as they are.
+	TextDirectionAuto TextDirection = iota
+	// TextDirectionLeftToRight removes <w:rtl> from all runs which contain replaced values.
+	TextDirectionLeftToRight
+	// TextDirectionRightToLeft marks all runs which contain replaced values as right-to-left (<w:rtl/>).
+	TextDirectionRightToLeft
+)
+
+// WithTextDirection forces the direction of the runs which contain replaced values, including the runs
+// inserted by values like RichText. By default (TextDirectionAuto) the direction is detected.
+func WithTextDirection(direction TextDirection) Option {
+	return func(d *Document) {
+		d.textDirection = direction
+	}
+}
+
+// applyTextDirection sets the direction of all runs of the already replaced data which lie inside the given
+// (replaced) runs, whose positions must be up-to-date. The file is parsed again, the returned runs are the
+// runs of the new parse which lie inside the replaced runs.
+func (d *Document) applyTextDirection(file string, data []byte, replacedRuns []*Run) ([]byte, []*Run, error) {
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		d.warnOnce(err.Error())
+	}
+	if d.textDirection == TextDirectionAuto && !hasBidiContext(data, styles) {
+		return data, replacedRuns, nil
+	}
+
+	if err := d.SetFile(file, data); err != nil {
+		return nil, nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, nil, err
+	}
+	var spans []Position
+	for _, run := range replacedRuns {
+		spans = append(spans, Position{Start: run.OpenTag.Start, End: run.CloseTag.End})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+
+	var (
+		edits  []edit
+		starts []int64
+	)
+	for _, run := range d.runParsers[file].Runs() {
+		if !positionInside(spans, run.OpenTag.Start) {
+			continue
+		}
+		starts = append(starts, run.OpenTag.Start)
+		inner := run.GetProperties(data)
+		var props string
+		switch d.textDirection {
+		case TextDirectionRightToLeft:
+			props = SetRunProperty(inner, "w:rtl", "<w:rtl/>")
+		case TextDirectionLeftToRight:
+			props = SetRunProperty(inner, "w:rtl", "")
+		default:
+			if _, defined := ToggleValue(inner, "w:rtl"); defined || !containsRightToLeft(run.GetText(data)) || !d.paragraphBidi(styles, data, run) {
+				continue
+			}
+			props = SetRunProperty(inner, "w:rtl", "<w:rtl/>")
+		}
+		if props == inner {
+			continue
+		}
+		pos, _ := runPropertiesPosition(data, run)
+		var markup string
+		if props != "" {
+			markup = "<w:rPr>" + props + "</w:rPr>"
+		}
+		edits = append(edits, edit{Position: pos, Replacement: []byte(markup)})
+	}
+	if len(edits) == 0 {
+		return data, d.runsAt(file, starts), nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+	for i, start := range starts {
+		for _, e := range edits {
+			if e.Position.Start < start {
+				starts[i] += int64(len(e.Replacement)) - (e.Position.End - e.Position.Start)
+			}
+		}
+	}
+	data = applyEdits(data, edits)
+	if err := d.SetFile(file, data); err != nil {
+		return nil, nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, nil, err
+	}
+	return data, d.runsAt(file, starts), nil
+}
+
+// runsAt returns the runs of the parsed file which start at the given positions.
+func (d *Document) runsAt(file string, starts []int64) []*Run {
+	wanted := make(map[int64]bool)
+	for _, start := range starts {
+		wanted[start] = true
+	}
+	var runs []*Run
+	for _, run := range d.runParsers[file].Runs() {
+		if wanted[run.OpenTag.Start] {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+// hasBidiContext returns true if the data or the styles may contain right-to-left paragraphs.
+func hasBidiContext(data []byte, styles styleDefinitions) bool {
+	if bytes.Contains(data, []byte("<w:bidi")) || bytes.Contains(data, []byte("<w:rtl")) {
+		return true
+	}
+	for _, style := range styles.Styles {
+		if bytes.Contains([]byte(style.ParagraphProperties.Inner), []byte("<w:bidi")) {
+			return true
+		}
+	}
+	return bytes.Contains([]byte(styles.ParagraphDefault.Inner), []byte("<w:bidi"))
+}
+
+// paragraphBidi returns true if the paragraph which contains the run is a right-to-left paragraph, either by its
+// direct properties, its style or by the run properties of its paragraph mark.
+func (d *Document) paragraphBidi(styles styleDefinitions, data []byte, run *Run) bool {
+	direct, style := paragraphProperties(styles, data, run)
+	for _, p := range splitProperties(direct) {
+		if p.Name == "w:rPr" {
+			if rtl, _ := ToggleValue(innerMarkup([]byte(p.Markup)), "w:rtl"); rtl {
+				return true
+			}
+		}
+	}
+	if bidi, defined := ToggleValue(direct, "w:bidi"); defined {
+		return bidi
+	}
+	for _, s := range d.styleChain(styles, style, "paragraph") {
+		if bidi, defined := ToggleValue(s.ParagraphProperties.Inner, "w:bidi"); defined {
+			return bidi
+		}
+	}
+	bidi, _ := ToggleValue(styles.ParagraphDefault.Inner, "w:bidi")
+	return bidi
+}
+
+// containsRightToLeft returns true if the text contains a character of a right-to-left script.
+func containsRightToLeft(text string) bool {
+	for _, r := range text {
+		if unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko) {
+			return true
+		}
+	}
+	return false
+}
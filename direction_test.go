@@ -0,0 +1,89 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_TextDirection(t *testing.T) {
+	styles := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:style w:type="paragraph" w:styleId="Arabic"><w:pPr><w:bidi/></w:pPr></w:style></w:styles>`
+
+	tests := []struct {
+		name      string
+		direction TextDirection
+		body      string
+		value     interface{}
+		expected  string
+	}{
+		{
+			name:     "rtl paragraph",
+			body:     `<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "مرحبا",
+			expected: `<w:r><w:rPr><w:b/><w:rtl/></w:rPr><w:t>مرحبا</w:t></w:r>`,
+		},
+		{
+			name:     "rtl paragraph style",
+			body:     `<w:p><w:pPr><w:pStyle w:val="Arabic"/></w:pPr><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "שלום",
+			expected: `<w:r><w:rPr><w:rtl/></w:rPr><w:t>שלום</w:t></w:r>`,
+		},
+		{
+			name:     "rtl paragraph mark",
+			body:     `<w:p><w:pPr><w:rPr><w:rtl/></w:rPr></w:pPr><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "مرحبا",
+			expected: `<w:r><w:rPr><w:rtl/></w:rPr><w:t>مرحبا</w:t></w:r>`,
+		},
+		{
+			name:     "latin text in rtl paragraph",
+			body:     `<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "Jane",
+			expected: `<w:r><w:t>Jane</w:t></w:r>`,
+		},
+		{
+			name:     "ltr paragraph",
+			body:     `<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "مرحبا",
+			expected: `<w:r><w:t>مرحبا</w:t></w:r>`,
+		},
+		{
+			name:     "run defines direction",
+			body:     `<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:rtl w:val="0"/></w:rPr><w:t>{name}</w:t></w:r></w:p>`,
+			value:    "مرحبا",
+			expected: `<w:r><w:rPr><w:rtl w:val="0"/></w:rPr><w:t>مرحبا</w:t></w:r>`,
+		},
+		{
+			name:      "forced rtl with inserted runs",
+			direction: TextDirectionRightToLeft,
+			body:      `<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			value:     RichText{Plain("a"), Bold("b")},
+			expected: `<w:r><w:rPr><w:rtl/></w:rPr><w:t>a</w:t></w:r><w:r><w:rPr><w:b/><w:rtl/></w:rPr><w:t xml:space="preserve">b</w:t></w:r>` +
+				`<w:r><w:rPr><w:rtl/></w:rPr><w:t xml:space="preserve"></w:t></w:r>`,
+		},
+		{
+			name:      "forced ltr",
+			direction: TextDirectionLeftToRight,
+			body:      `<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:b/><w:rtl/></w:rPr><w:t>{name}</w:t></w:r></w:p>`,
+			value:     "مرحبا",
+			expected:  `<w:r><w:rPr><w:b/></w:rPr><w:t>مرحبا</w:t></w:r>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(tt.body),
+				StylesXml:   styles,
+			}), WithTextDirection(tt.direction))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": tt.value}); err != nil {
+				t.Fatal(err)
+			}
+			if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, tt.expected) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", tt.expected, result)
+			}
+		})
+	}
+}
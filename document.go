@@ -11,7 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -42,17 +44,163 @@ type Document struct {
 	headerFiles []string
 	// paths to all footer files inside the zip archive
 	footerFiles []string
+	// paths to the footnotes, endnotes and comments (see WithCommentReplacement) inside the zip archive
+	noteFiles []string
 	// The document contains multiple files which eventually need a parser each.
 	// The map key is the file path inside the document to which the parser belongs.
 	runParsers map[string]*RunParser
 
 	filePlaceholders map[string][]*Placeholder
 	fileReplacers    map[string]*Replacer
+	// cached placeholder scans of the parsed files by part version, see InvalidateCaches
+	scanCache *scanCache
+	// texts of the placeholders which are ignored as they cross alternate content boundaries, by file
+	ignoredPlaceholders map[string][]string
+
+	// all other parts of the archive which were modified or added and are not subject to replacing
+	modifiedParts FileMap
+
+	// resolver is consulted by ReplaceResolved to lazily fetch placeholder values
+	resolver        Resolver
+	resolverTimeout time.Duration
+	// maximum amount of keys resolved at the same time, DefaultResolverWorkers if <= 0
+	resolverWorkers int
+
+	// caps on the amount of replacements, 0 means unlimited
+	maxReplacements    int
+	keyMaxReplacements map[string]int
+
+	// languageDetector is consulted by DetectLanguages for runs without a declared language
+	languageDetector LanguageDetector
+
+	// validators are invoked by Finalize
+	validators []Validator
+
+	// mergeRuns enables merging the runs of replaced placeholders with their neighbours
+	mergeRuns bool
+
+	// replaceWorkers is the amount of workers used by ReplaceAll, sequential if smaller than two
+	replaceWorkers int
+	// the hooks are safe for concurrent use, see WithConcurrentHooks
+	concurrentHooks bool
+
+	// thresholds for the size of the generated document
+	complexityLimits *ComplexityLimits
+
+	// maximum total uncompressed size of the archive, 0 means unlimited
+	maxUncompressedSize int64
+
+	// join text values with adjacent characters using word joiners
+	nonBreakingValues bool
+
+	// direction of the runs which contain replaced values
+	textDirection TextDirection
+
+	// whether empty runs break placeholders which are split into several runs
+	emptyRunsBreakPlaceholders bool
+
+	// keep the whitespace around placeholder keys ('{ name }'), see WithKeyTrimming
+	keepKeyWhitespace bool
+	// parsed parts before their keys were trimmed, until the parts are modified
+	untrimmedParts map[string]untrimmedPart
+	// parts which were modified since the document was opened, their keys are trimmed as a modification
+	modifiedFiles map[string]bool
+
+	// additional delimiters, besides OpenDelimiter and CloseDelimiter
+	delimiters []Delimiters
+
+	// escape which makes the following delimiter literal, see WithDelimiterEscape
+	delimiterEscape string
+
+	// resolve language suffixes of placeholder keys ('{name@kk}') from nested values, see WithLanguageVariants
+	languageVariants bool
+	defaultLanguage  string
+
+	// language of the LocalDateValue values, instead of the language of the document, see WithLocale
+	locale string
+
+	// progress receives the progress of long operations, see WithProgress
+	progress *progressReporter
+
+	// expand placeholders inside string values up to the given depth, see WithNestedPlaceholders
+	nestedPlaceholders bool
+	nestingDepth       int
+
+	// replace placeholders inside field instructions (<w:instrText>), see WithFieldInstructionReplacement
+	replaceFieldInstructions bool
+
+	// replace placeholders inside equations (<m:t>) as well, see WithMathPlaceholders
+	mathPlaceholders bool
+
+	// replace placeholders inside the comments, see WithCommentReplacement
+	replaceComments bool
+
+	// settings of the decoder which parses the files, see WithDecoderSettings
+	decoderSettings DecoderSettings
+
+	// maximum nesting depth of runs, 0 means DefaultMaxRunNesting, see WithMaxRunNesting
+	maxRunNesting int
+
+	// replace placeholders inside the names and alternative texts of drawings, see WithAttributePlaceholders
+	attributePlaceholders bool
+
+	// replace placeholders inside the core and extended properties, see WithPropertyPlaceholders
+	propertyPlaceholders bool
+
+	// replace placeholders inside relationship targets and the replacements so far, see WithRelationshipTargets
+	replaceTargets     bool
+	targetReplacements []TargetReplacement
+
+	// which fragment of a placeholder with mixed formatting receives the value, see WithFragmentFormatting
+	fragmentFormatting FragmentFormattingPolicy
+
+	// filter applied to all values before they are inserted, see WithValueFilter
+	valueFilter ValueFilter
+
+	// rules which format the replaced values, see WithFormatRule
+	formatRules []FormatRule
+
+	// typographic rules applied to the values and their substitutions so far, see WithTypography
+	typographyRules         []TypographyRule
+	typographySubstitutions map[string]int
+
+	// separator of []string values, see WithJoinSeparator
+	joinSeparator string
+
+	// replace CRLF and CR line endings of the parsed parts by LF before parsing them
+	normalizeLineEndings bool
+
+	// maximum size of the written document and the result of its last check, see WithSizeBudget
+	sizeBudget *sizeBudget
+	sizeReport SizeReport
+
+	// surround every replacement with a bookmark, the next bookmark id and the names so far by key,
+	// see WithAnchorsForReplacements
+	anchorPrefix   string
+	nextBookmarkID int
+	anchors        map[string][]string
+
+	// parts which changed since the last call of ChangedParts, the bytes it returned and the number of its calls
+	changedParts  map[string]bool
+	reportedParts map[string][]byte
+	generation    uint64
+
+	// check that the parts round-trip through re-parsing in Validate, see WithRoundTripCheck
+	roundTripCheck bool
+
+	// write all entries with a fixed timestamp and compression level, see WithReproducibleOutput
+	reproducibleOutput bool
+
+	// warnings about repairs of the document, e.g. missing section properties
+	warnings []string
 }
 
+// Option is used to configure a Document when opening it.
+type Option func(*Document)
+
 // Open will open and parse the file pointed to by path.
 // The file must be a valid docx file or an error is returned.
-func Open(path string) (*Document, error) {
+func Open(path string, opts ...Option) (*Document, error) {
 	fh, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open .docx docxFile: %s", err)
@@ -63,20 +211,20 @@ func Open(path string) (*Document, error) {
 		return nil, fmt.Errorf("unable to open zip reader: %s", err)
 	}
 
-	return newDocument(&rc.Reader, path, fh)
+	return newDocument(&rc.Reader, path, fh, opts...)
 }
 
 // OpenBytes allows to create a Document from a byte slice.
 // It behaves just like Open().
 //
 // Note: In this case, the docxFile property will be nil!
-func OpenBytes(b []byte) (*Document, error) {
+func OpenBytes(b []byte, opts ...Option) (*Document, error) {
 	rc, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
 	if err != nil {
 		return nil, fmt.Errorf("unable to open zip reader: %s", err)
 	}
 
-	return newDocument(rc, "", nil)
+	return newDocument(rc, "", nil, opts...)
 }
 
 // newDocument will create a new document struct given the zipFile.
@@ -85,7 +233,8 @@ func OpenBytes(b []byte) (*Document, error) {
 // newDocument will parse the docx archive and ValidatePositions that at least a 'document.xml' exists.
 // If 'word/document.xml' is missing, an error is returned since the docx cannot be correct.
 // Then all files are parsed for their runs before returning the new document.
-func newDocument(zipFile *zip.Reader, path string, docxFile *os.File) (*Document, error) {
+// The given options are applied before anything is parsed.
+func newDocument(zipFile *zip.Reader, path string, docxFile *os.File, opts ...Option) (*Document, error) {
 	doc := &Document{
 		docxFile:         docxFile,
 		zipFile:          zipFile,
@@ -94,6 +243,19 @@ func newDocument(zipFile *zip.Reader, path string, docxFile *os.File) (*Document
 		runParsers:       make(map[string]*RunParser),
 		filePlaceholders: make(map[string][]*Placeholder),
 		fileReplacers:    make(map[string]*Replacer),
+		scanCache:        newScanCache(),
+		modifiedParts:    make(FileMap),
+
+		ignoredPlaceholders: make(map[string][]string),
+		joinSeparator:       DefaultJoinSeparator,
+	}
+
+	for _, opt := range opts {
+		opt(doc)
+	}
+
+	if err := doc.checkUncompressedSize(); err != nil {
+		return nil, err
 	}
 
 	ResetRunIdCounter()
@@ -108,31 +270,122 @@ func newDocument(zipFile *zip.Reader, path string, docxFile *os.File) (*Document
 		return nil, fmt.Errorf("invalid docx archive, %s is missing", DocumentXml)
 	}
 
-	// parse all files
-	for name, data := range doc.files {
+	doc.checkLineEndings()
 
-		// find all runs
-		doc.runParsers[name] = NewRunParser(data)
-		err := doc.runParsers[name].Execute()
-		if err != nil {
-			return nil, err
-		}
-
-		// parse placeholders and initialize replacers
-		placeholder, err := ParsePlaceholders(doc.runParsers[name].Runs(), data)
-		if err != nil {
+	// parse all files
+	for name := range doc.files {
+		if err := doc.parseFile(name); err != nil {
 			return nil, err
 		}
-		doc.filePlaceholders[name] = placeholder
-		doc.fileReplacers[name] = NewReplacer(data, placeholder)
 	}
 
 	return doc, nil
 }
 
+// parseFile will (re-)parse the given file for its runs and placeholders and initialize a new replacer for it.
+// It must be called whenever the bytes of a file were modified outside of the Replacer, otherwise
+// all offsets of that file are stale.
+func (d *Document) parseFile(name string) error {
+	data, exists := d.files[name]
+	if !exists {
+		return fmt.Errorf("unregistered file %s", name)
+	}
+
+	// find all runs
+	d.runParsers[name] = NewRunParser(data)
+	d.runParsers[name].progress = d.progress
+	d.runParsers[name].fieldInstructions = d.replaceFieldInstructions
+	d.runParsers[name].decoderSettings = d.decoderSettings
+	d.runParsers[name].maxNesting = d.maxRunNesting
+	d.runParsers[name].mathText = d.mathPlaceholders
+	err := d.runParsers[name].Execute()
+	if err != nil {
+		return err
+	}
+
+	// parse placeholders and initialize replacers
+	placeholder, err := d.parsePlaceholders(withoutSeparatorRuns(name, data, d.runParsers[name].Runs()), data)
+	if err != nil {
+		return err
+	}
+	placeholder = d.dropCrossingPlaceholders(name, data, placeholder)
+	// the trimmed keys are no modification, the part is written as it is until it is modified
+	if edits := d.trimKeyEdits(data, placeholder); len(edits) > 0 {
+		d.keepUntrimmed(name, data, d.runParsers[name].Runs())
+		d.files[name] = applyEdits(data, edits)
+		return d.parseFile(name)
+	}
+	d.filePlaceholders[name] = placeholder
+	d.touch(name)
+	d.fileReplacers[name] = NewReplacer(data, placeholder)
+	d.fileReplacers[name].joinAdjacent = d.nonBreakingValues
+	d.fileReplacers[name].formatting = d.fragmentFormatting
+	return nil
+}
+
 // ReplaceAll will iterate over all files and perform the replacement according to the PlaceholderMap.
+// The document is never re-serialized: only the contents of the text elements which contain placeholders and the
+// markup inserted for values (e.g. tables) are rewritten, all other bytes are copied verbatim.
+//
+// The order of processing is deterministic and independent of the iteration order of the map: the files are
+// processed in the order of their names, the placeholders of a file in document order (see Placeholders).
+// Every distinct placeholder is replaced, with all its occurrences, at its first occurrence. If several keys
+// match the same placeholder (e.g. "name" and "{name}"), the first key in lexical order is used.
 func (d *Document) ReplaceAll(placeholderMap PlaceholderMap) error {
-	for name := range d.files {
+	var report ReplaceReport
+	placeholderMap, err := d.prepareValues(placeholderMap, &report)
+	if err != nil {
+		return err
+	}
+	if _, err := d.replaceRelationshipTargets(placeholderMap); err != nil {
+		return err
+	}
+	if _, err := d.replaceAttributes(placeholderMap, true); err != nil {
+		return err
+	}
+	if _, err := d.replaceProperties(placeholderMap, true); err != nil {
+		return err
+	}
+	return d.replaceAll(applyFormats(placeholderMap, report.Formats))
+}
+
+// prepareValues resolves the language variants and the languages of local dates, filters the values, joins list
+// values, expands the nested placeholders of the values and applies the typographic rules, if enabled. The languages
+// of the variants, the substitutions of the typographic rules and the formats of the format rules are recorded in the
+// report, the formats only apply to the text of the document (see applyFormats).
+func (d *Document) prepareValues(placeholderMap PlaceholderMap, report *ReplaceReport) (PlaceholderMap, error) {
+	if d.languageVariants {
+		placeholderMap, report.Languages = d.resolveLanguageVariants(placeholderMap)
+	}
+	placeholderMap = d.localizeValues(placeholderMap)
+	placeholderMap = d.filterValues(placeholderMap)
+	placeholderMap = d.joinValues(placeholderMap)
+	// nested placeholders are expanded before escaping, the expanded values are escaped as a whole
+	if d.nestedPlaceholders {
+		var err error
+		if placeholderMap, err = d.expandNestedValues(placeholderMap); err != nil {
+			return nil, err
+		}
+	}
+	report.Formats = d.valueFormats(placeholderMap)
+	placeholderMap, report.Typography = d.applyTypography(placeholderMap)
+	return placeholderMap, nil
+}
+
+// replaceAll replaces the prepared values, see ReplaceAll.
+func (d *Document) replaceAll(placeholderMap PlaceholderMap) error {
+	if err := d.checkReplacementLimits(placeholderMap); err != nil {
+		return err
+	}
+	d.planReplacements(placeholderMap)
+	d.warnMixedFormatting(placeholderMap)
+	if d.replaceConcurrently() {
+		if err := d.replaceAllConcurrent(placeholderMap); err != nil {
+			return err
+		}
+		return d.checkComplexity()
+	}
+	for _, name := range d.fileNames() {
 		changedBytes, err := d.replace(placeholderMap, name)
 		if err != nil {
 			return err
@@ -143,13 +396,27 @@ func (d *Document) ReplaceAll(placeholderMap PlaceholderMap) error {
 			return err
 		}
 	}
-	return nil
+	return d.checkComplexity()
 }
 
 // Replace will attempt to replace the given key with the value in every file.
 func (d *Document) Replace(key, value string) error {
-	for name := range d.files {
-		changedBytes, err := d.replace(PlaceholderMap{key: value}, name)
+	if d.valueFilter != nil {
+		value = fmt.Sprint(d.filterValue(key, value))
+	}
+	formats := d.valueFormats(PlaceholderMap{key: value})
+	if len(d.typographyRules) > 0 {
+		typographic, _ := d.applyTypography(PlaceholderMap{key: value})
+		value = fmt.Sprint(typographic[key])
+	}
+	values := applyFormats(PlaceholderMap{key: value}, formats)
+	if err := d.checkReplacementLimits(values); err != nil {
+		return err
+	}
+	d.planReplacements(values)
+	d.warnMixedFormatting(values)
+	for _, name := range d.fileNames() {
+		changedBytes, err := d.replace(values, name)
 		if err != nil {
 			return err
 		}
@@ -158,39 +425,183 @@ func (d *Document) Replace(key, value string) error {
 			return err
 		}
 	}
+	return d.checkComplexity()
+}
+
+// ProcessParts performs the replacement according to the PlaceholderMap part by part, in the order of the part names,
+// and passes the result of each part to fn as soon as it is replaced, e.g. to start writing the output early.
+// An error of the replacement or of fn aborts the processing and is returned as *PartError.
+func (d *Document) ProcessParts(placeholderMap PlaceholderMap, fn func(name string, data []byte) error) error {
+	var report ReplaceReport
+	placeholderMap, err := d.prepareValues(placeholderMap, &report)
+	if err != nil {
+		return err
+	}
+	if err := d.checkReplacementLimits(placeholderMap); err != nil {
+		return err
+	}
+	if _, err := d.replaceRelationshipTargets(placeholderMap); err != nil {
+		return err
+	}
+	if _, err := d.replaceAttributes(placeholderMap, true); err != nil {
+		return err
+	}
+	if _, err := d.replaceProperties(placeholderMap, true); err != nil {
+		return err
+	}
+	placeholderMap = applyFormats(placeholderMap, report.Formats)
+	d.planReplacements(placeholderMap)
+	d.warnMixedFormatting(placeholderMap)
+
+	names := d.fileNames()
+
+	for _, name := range names {
+		changedBytes, err := d.replace(placeholderMap, name)
+		if err == nil {
+			err = d.SetFile(name, changedBytes)
+		}
+		// a part which exceeds the limits is not passed on
+		if err == nil {
+			err = d.checkComplexity()
+		}
+		if err == nil {
+			err = fn(name, d.GetFile(name))
+		}
+		if err != nil {
+			return &PartError{Part: name, Err: err}
+		}
+	}
 	return nil
 }
 
 // replace will create a parser on the given bytes, execute it and replace every placeholders found with the data
 // from the placeholderMap.
 func (d *Document) replace(placeholderMap PlaceholderMap, file string) ([]byte, error) {
+	textValues, blockValues := splitValues(placeholderMap)
+	data, replacedRuns, err := d.replaceText(textValues, file)
+	if err != nil {
+		return nil, err
+	}
+	return d.restructure(file, data, replacedRuns, blockValues)
+}
+
+// splitValues separates the block values from all other values. Block values cannot be replaced by the Replacer
+// as they do not fit into a run, they are replaced afterwards.
+func splitValues(placeholderMap PlaceholderMap) (textValues PlaceholderMap, blockValues map[string]BlockValue) {
+	blockValues = make(map[string]BlockValue)
+	textValues = make(PlaceholderMap)
+	for key, value := range placeholderMap {
+		if block, ok := value.(BlockValue); ok {
+			blockValues[RemovePlaceholderDelimiter(key)] = block
+		} else {
+			textValues[key] = value
+		}
+	}
+	return textValues, blockValues
+}
+
+// replaceText replaces all text values inside the given file using its Replacer and returns the changed bytes and
+// the replaced runs. Only the Replacer of the file is modified, the document itself is not.
+func (d *Document) replaceText(textValues PlaceholderMap, file string) ([]byte, []*Run, error) {
 	if _, ok := d.runParsers[file]; !ok {
-		return nil, fmt.Errorf("no parser for file %s", file)
+		return nil, nil, fmt.Errorf("no parser for file %s", file)
 	}
-	placeholderCount := d.countPlaceholders(file, placeholderMap)
-	placeholders := d.filePlaceholders[file]
+	placeholderCount := d.countPlaceholders(file, textValues)
 	replacer := d.fileReplacers[file]
 
-	for key, value := range placeholderMap {
-		err := replacer.Replace(key, fmt.Sprint(value))
-		if err != nil {
-			if errors.Is(err, ErrPlaceholderNotFound) {
-				continue
-			} else {
-				return nil, err
+	// the literals of the keys, the first key in lexical order wins if several keys have the same literal
+	var keys []string
+	for key := range textValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	literalKeys := make(map[string]string)
+	for _, key := range keys {
+		for _, literal := range d.placeholderLiterals(key) {
+			if _, exists := literalKeys[literal]; !exists {
+				literalKeys[literal] = key
 			}
 		}
 	}
 
+	// the placeholders are replaced in document order, every literal at its first occurrence
+	var literals []string
+	seen := make(map[string]bool)
+	for _, placeholder := range d.orderedPlaceholders(replacer.placeholders) {
+		literal := placeholder.Text(replacer.document)
+		if _, exists := literalKeys[literal]; exists && !seen[literal] {
+			seen[literal] = true
+			literals = append(literals, literal)
+		}
+	}
+
+	for _, literal := range literals {
+		var err error
+		replaced := replacer.ReplaceCount
+		value := textValues[literalKeys[literal]]
+		if d.anchorPrefix != "" {
+			// every replacement is surrounded by a bookmark, see WithAnchorsForReplacements
+			err = d.replaceAnchored(replacer, literal, RemovePlaceholderDelimiter(literalKeys[literal]), value)
+		} else if markup, ok := value.(MarkupValue); ok {
+			err = replacer.ReplaceMarkup(literal, markup)
+		} else {
+			err = replacer.Replace(literal, fmt.Sprint(value))
+		}
+		d.progress.replaced(int64(replacer.ReplaceCount - replaced))
+		if err != nil && !errors.Is(err, ErrPlaceholderNotFound) {
+			return nil, nil, err
+		}
+	}
+
 	// ensure that all placeholders have been replaced
 	if placeholderCount != replacer.ReplaceCount {
-		return nil, fmt.Errorf("not all placeholders were replaced, want=%d, have=%d", placeholderCount, replacer.ReplaceCount)
+		return nil, nil, fmt.Errorf("not all placeholders were replaced, want=%d, have=%d", placeholderCount, replacer.ReplaceCount)
+	}
+	return replacer.Bytes(), replacer.replacedRuns, nil
+}
+
+// restructure applies the changes to the replaced data of the given file which alter its structure,
+// merging the replaced runs (if enabled) and replacing the block values.
+func (d *Document) restructure(file string, data []byte, replacedRuns []*Run, blockValues map[string]BlockValue) ([]byte, error) {
+	if len(replacedRuns) > 0 {
+		var err error
+		if data, replacedRuns, err = d.applyTextDirection(file, data, replacedRuns); err != nil {
+			return nil, err
+		}
+	}
+	if d.mergeRuns && len(replacedRuns) > 0 {
+		var err error
+		if data, err = d.mergeReplacedRuns(file, data, replacedRuns); err != nil {
+			return nil, err
+		}
 	}
 
-	d.fileReplacers[file] = replacer
-	d.filePlaceholders[file] = placeholders
+	if len(blockValues) == 0 {
+		return data, nil
+	}
+	return d.replaceBlockValues(file, data, blockValues)
+}
 
-	return replacer.Bytes(), nil
+// replaceBlockValues replaces the block values inside the already replaced data of the given file.
+// The file is parsed again before and after, as the block values change the structure of the file.
+func (d *Document) replaceBlockValues(file string, data []byte, blockValues map[string]BlockValue) ([]byte, error) {
+	if err := d.SetFile(file, data); err != nil {
+		return nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, err
+	}
+	changedBytes, err := d.replaceBlocks(file, blockValues)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.SetFile(file, changedBytes); err != nil {
+		return nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, err
+	}
+	return changedBytes, nil
 }
 
 // Runs returns all runs from all parsed files.
@@ -201,29 +612,50 @@ func (d *Document) Runs() (runs []*Run) {
 	return runs
 }
 
-// Placeholders returns all placeholders from the docx document.
+// Placeholders returns all placeholders from the docx document, in a deterministic order: the files in the order
+// of their names and the placeholders of every file in document order.
 func (d *Document) Placeholders() (placeholders []*Placeholder) {
-	for _, p := range d.filePlaceholders {
-		placeholders = append(placeholders, p...)
+	for _, name := range d.fileNames() {
+		placeholders = append(placeholders, d.scanPart(name, false).placeholders...)
 	}
 	return placeholders
 }
 
+// orderedPlaceholders returns a copy of the placeholders in document order.
+func (d *Document) orderedPlaceholders(placeholders []*Placeholder) []*Placeholder {
+	ordered := append([]*Placeholder(nil), placeholders...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StartPos() < ordered[j].StartPos() })
+	return ordered
+}
+
+// fileNames returns the names of all files which are subject to replacing (document, headers, footers and notes),
+// sorted.
+func (d *Document) fileNames() []string {
+	var names []string
+	for name := range d.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // countPlaceholders will return the total count of placeholders from the placeholderMap in the given data.
 // Reoccurring placeholders are also counted multiple times.
 func (d *Document) countPlaceholders(file string, placeholderMap PlaceholderMap) int {
-	data := d.GetFile(file)
-	plaintext := d.stripXmlTags(string(data))
-	var placeholderCount int
+	plaintext := d.scanPart(file, true).text
+	var literals []string
 	for key := range placeholderMap {
-		placeholder := AddPlaceholderDelimiter(key)
-
-		count := strings.Count(plaintext, placeholder)
-		if count > 0 {
-			placeholderCount += count
+		literals = append(literals, d.placeholderLiterals(key)...)
+	}
+	count := countOccurrences(plaintext, literals, d.grammar(DefaultDelimiters()).escapes(plaintext))
+	for _, ignored := range d.ignoredPlaceholders[file] {
+		for _, literal := range literals {
+			if ignored == literal {
+				count--
+			}
 		}
 	}
-	return placeholderCount
+	return count
 }
 
 // stripXmlTags is a stdlib way of stripping out all xml tags using the html.Tokenizer.
@@ -253,6 +685,12 @@ loop:
 	return output
 }
 
+// Warnings returns all warnings recorded while working on the document, e.g. about parts of the document
+// which had to be added because they were missing.
+func (d *Document) Warnings() []string {
+	return d.warnings
+}
+
 // GetFile returns the content of the given fileName if it exists.
 func (d *Document) GetFile(fileName string) []byte {
 	if f, exists := d.files[fileName]; exists {
@@ -267,7 +705,14 @@ func (d *Document) SetFile(fileName string, fileBytes []byte) error {
 	if _, exists := d.files[fileName]; !exists {
 		return fmt.Errorf("unregistered file %s", fileName)
 	}
+	untrimmed, isUntrimmed := d.untrimmedParts[fileName]
+	unchanged := bytes.Equal(d.files[fileName], fileBytes)
 	d.files[fileName] = fileBytes
+	d.markChanged(fileName)
+	// setting the same bytes keeps the part as it is, see WithKeyTrimming
+	if isUntrimmed && unchanged {
+		d.untrimmedParts[fileName] = untrimmed
+	}
 	return nil
 }
 
@@ -277,14 +722,34 @@ func (d *Document) SetFile(fileName string, fileBytes []byte) error {
 //   - word/document.xml
 //   - word/header*.xml
 //   - word/footer*.xml
+//   - word/footnotes.xml and word/endnotes.xml
+//   - word/comments.xml, see WithCommentReplacement
 func (d *Document) parseArchive() error {
+	var (
+		read   int64
+		report func(done int64)
+	)
+	if d.progress != nil {
+		var total int64
+		for _, file := range d.zipFile.File {
+			if file.Name == DocumentXml || HeaderPathRegex.MatchString(file.Name) || FooterPathRegex.MatchString(file.Name) || d.isReplacedNoteFile(file.Name) {
+				total += int64(file.UncompressedSize64)
+			}
+		}
+		report = d.progress.bytes(ProgressRead, total)
+	}
+
 	readZipFile := func(file *zip.File) []byte {
 		readCloser, err := file.Open()
 		if err != nil {
 			return nil
 		}
 		defer readCloser.Close()
-		fileBytes, err := ioutil.ReadAll(readCloser)
+		var reader io.Reader = readCloser
+		if report != nil {
+			reader = progressReader{reader: readCloser, read: &read, report: report}
+		}
+		fileBytes, err := ioutil.ReadAll(reader)
 		if err != nil {
 			return nil
 		}
@@ -303,6 +768,10 @@ func (d *Document) parseArchive() error {
 			d.files[file.Name] = readZipFile(file)
 			d.footerFiles = append(d.footerFiles, file.Name)
 		}
+		if d.isReplacedNoteFile(file.Name) {
+			d.files[file.Name] = readZipFile(file)
+			d.noteFiles = append(d.noteFiles, file.Name)
+		}
 	}
 	return nil
 }
@@ -333,17 +802,39 @@ func (d *Document) WriteToFile(file string) error {
 // Docx files are basically zip archives with many XMLs included.
 // Files which cannot be modified through this lib will just be read from the original docx and copied into the writer.
 func (d *Document) Write(writer io.Writer) error {
-	zipWriter := zip.NewWriter(writer)
+	if err := d.checkComplexity(); err != nil {
+		return err
+	}
+	if d.sizeBudget == nil {
+		return d.writeArchive(writer)
+	}
+	buf := new(bytes.Buffer)
+	if err := d.writeWithinBudget(buf); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(writer)
+	return err
+}
+
+// writeArchive writes the zip archive of the document into the writer.
+func (d *Document) writeArchive(writer io.Writer) error {
+	zipWriter := d.newZipWriter(writer)
 	defer zipWriter.Close()
 
 	// writeModifiedFile will check if the given zipFile is a file which was modified and writes it.
 	// If the file is not one of the modified files, false is returned.
 	writeModifiedFile := func(writer io.Writer, zipFile *zip.File) (bool, error) {
+		if _, isPart := d.modifiedParts[zipFile.Name]; isPart {
+			if err := d.modifiedParts.Write(writer, zipFile.Name); err != nil {
+				return false, fmt.Errorf("unable to writeFile %s: %s", zipFile.Name, err)
+			}
+			return true, nil
+		}
 		isModified := d.isModifiedFile(zipFile.Name)
 		if !isModified {
 			return false, nil
 		}
-		if err := d.files.Write(writer, zipFile.Name); err != nil {
+		if _, err := writer.Write(d.unescapedFile(zipFile.Name)); err != nil {
 			return false, fmt.Errorf("unable to writeFile %s: %s", zipFile.Name, err)
 		}
 		return true, nil
@@ -351,7 +842,7 @@ func (d *Document) Write(writer io.Writer) error {
 
 	// write all files into the zip archive (docx-file)
 	for _, zipFile := range d.zipFile.File {
-		fw, err := zipWriter.Create(zipFile.Name)
+		fw, err := d.createEntry(zipWriter, zipFile.Name)
 		if err != nil {
 			return fmt.Errorf("unable to create writer: %s", err)
 		}
@@ -379,12 +870,67 @@ func (d *Document) Write(writer io.Writer) error {
 			return fmt.Errorf("unable to close reader for %s: %s", zipFile.Name, err)
 		}
 	}
+
+	// parts which were added to the document do not exist in the original archive and are written last
+	for _, name := range d.addedParts() {
+		fw, err := d.createEntry(zipWriter, name)
+		if err != nil {
+			return fmt.Errorf("unable to create writer: %s", err)
+		}
+		if err := d.modifiedParts.Write(fw, name); err != nil {
+			return fmt.Errorf("unable to writeFile %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// addedParts returns the sorted names of all parts which do not exist in the original archive.
+func (d *Document) addedParts() (names []string) {
+	for name := range d.modifiedParts {
+		if d.zipFileByName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// zipFileByName returns the file of the original archive with the given name or nil if it does not exist.
+func (d *Document) zipFileByName(name string) *zip.File {
+	for _, file := range d.zipFile.File {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
+
+// getPart returns the current content of any part of the archive, including modified and added parts.
+func (d *Document) getPart(name string) ([]byte, error) {
+	if data, exists := d.files[name]; exists {
+		return data, nil
+	}
+	if data, exists := d.modifiedParts[name]; exists {
+		return data, nil
+	}
+	return d.readArchiveFile(name)
+}
+
+// setPart sets the content of a part of the archive. The part is added to the document if it does not exist.
+// Files which are subject to replacing must be set using SetFile instead.
+func (d *Document) setPart(name string, data []byte) error {
+	if _, exists := d.files[name]; exists {
+		return fmt.Errorf("file %s is handled by a parser, use SetFile instead", name)
+	}
+	d.modifiedParts[name] = data
+	d.markChanged(name)
 	return nil
 }
 
 // isModifiedFile will look through all modified files and check if the searchFileName exists
 func (d *Document) isModifiedFile(searchFileName string) bool {
-	allFiles := append(d.headerFiles, d.footerFiles...)
+	allFiles := append(append([]string(nil), d.headerFiles...), d.footerFiles...)
+	allFiles = append(allFiles, d.noteFiles...)
 	allFiles = append(allFiles, DocumentXml)
 
 	for _, file := range allFiles {
@@ -1,6 +1,12 @@
 package docx
 
-import "testing"
+import (
+	"archive/zip"
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
 
 func BenchmarkDocument_ReplaceAll(b *testing.B) {
 	for n := 0; n < b.N; n++ {
@@ -33,3 +39,207 @@ func BenchmarkDocument_ReplaceAll(b *testing.B) {
 		}
 	}
 }
+
+// testDocumentXml wraps the given body content into a minimal document.xml
+func testDocumentXml(body string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" ` +
+		`xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape" ` +
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" ` +
+		`xmlns:v="urn:schemas-microsoft-com:vml" mc:Ignorable="wps"><w:body>` +
+		body + `</w:body></w:document>`
+}
+
+// newTestDocx assembles a docx archive from the given parts.
+// If no content types part is given, a minimal one is added.
+func newTestDocx(t testing.TB, parts map[string]string) []byte {
+	if _, exists := parts[ContentTypesXml]; !exists {
+		parts[ContentTypesXml] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`</Types>`
+	}
+
+	var names []string
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	for _, name := range names {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(parts[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// openTestDocx opens a docx assembled by newTestDocx
+func openTestDocx(t testing.TB, parts map[string]string) *Document {
+	doc, err := OpenBytes(newTestDocx(t, parts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// reopen writes the document and opens the result again
+func reopen(t testing.TB, doc *Document) *Document {
+	buf := new(bytes.Buffer)
+	if err := doc.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reopened
+}
+
+func TestDocument_PreservesRootElement(t *testing.T) {
+	root := `<w:document xmlns:wpc="http://schemas.microsoft.com/office/word/2010/wordprocessingCanvas" ` +
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" ` +
+		`xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml" ` +
+		`xmlns:wp14="http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing" ` +
+		`mc:Ignorable="w14 w15 wp14">`
+	body := `<w:p w14:paraId="1A2B3C4D"><w:r><w:t>{na</w:t></w:r><w:r><w:t>me}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>{table}</w:t></w:r></w:p><w:p><w:r><w:t>{logo}</w:t></w:r></w:p>` +
+		`<w:tbl><w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>{item}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{#col:discount}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + root + `<w:body>` + body + `</w:body></w:document>`,
+	}), WithRunMerging())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"replace", func() error {
+			return doc.ReplaceAll(PlaceholderMap{"name": "Jane", "table": TableValue(TableSpec{Rows: [][]string{{"a"}}})})
+		}},
+		{"insert image", func() error {
+			return doc.InsertImageAtPlaceholder("logo", testImage(t, "png", 20, 10), ImageSize{})
+		}},
+		{"expand rows", func() error {
+			return doc.ExpandTableRow("item", []PlaceholderMap{{"item": "a"}, {"item": "b"}}, ExpandOptions{})
+		}},
+		{"set condition", func() error {
+			_, err := doc.SetCondition("discount", false)
+			return err
+		}},
+		{"set page setup", func() error {
+			return doc.SetPageSetup(DefaultPageSetup)
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s failed: %s", step.name, err)
+		}
+		data := string(doc.GetFile(DocumentXml))
+		if !strings.Contains(data, root+`<w:body>`) {
+			t.Fatalf("root element changed after %s: %s", step.name, data[:strings.Index(data, "<w:body")])
+		}
+	}
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocument_Replace_PreservesUntouchedBytes(t *testing.T) {
+	header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+		`<w:document xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" ` +
+		`xmlns:w='http://schemas.openxmlformats.org/wordprocessingml/2006/main' ` +
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" mc:Ignorable="w14">`
+	prefix := header + "\n  <w:body>\n    <!-- generated -->\n" +
+		`    <w:p w14:paraId="1A2B3C4D" w14:textId="77777777" w:rsidR="00AB"><w:pPr><w:jc w:val="left"/></w:pPr>` +
+		`<w:r w:rsidRPr="00CD"><w:rPr><w:b  /></w:rPr><w:t xml:space='preserve'>`
+	between := `</w:t></w:r  ><w:proofErr w:type="spellStart"/><w:r><w:t  >`
+	suffix := `</w:t></w:r></w:p>` + "\n" +
+		`    <w:p w14:paraId="5E6F7A8B"><w:r><w:t>untouched {other}</w:t></w:r></w:p>` + "\n" +
+		`    <w:sectPr w:rsidR="00AB"><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>` + "\n  </w:body>\n</w:document>"
+
+	input := prefix + `Dear {na` + between + `me}, welcome` + suffix
+	doc := openTestDocx(t, map[string]string{DocumentXml: input})
+	if err := doc.Replace("name", "Jane"); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the contents of the text elements which contain the placeholder are changed
+	expected := prefix + `Dear Jane` + between + `, welcome` + suffix
+	if result := string(doc.GetFile(DocumentXml)); result != expected {
+		t.Errorf("bytes outside of the replaced text changed\nwant=%s\nhave=%s", expected, result)
+	}
+	if result := string(reopen(t, doc).GetFile(DocumentXml)); result != expected {
+		t.Errorf("bytes changed when writing the document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestDocument_ReplaceAll_Deterministic(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{c} {a} {b}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>{b}</w:t></w:r><w:r><w:t xml:space="preserve"> {a} {d}</w:t></w:r></w:p>`
+	header := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:hdr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{d}{c}</w:t></w:r></w:p></w:hdr>`
+	parts := map[string]string{DocumentXml: testDocumentXml(body), "word/header1.xml": header}
+	placeholderMap := PlaceholderMap{
+		"a":   RichText{Bold("A")},
+		"b":   "B",
+		"{b}": "other B",
+		"c":   "C\nC",
+		"d":   Symbol{Font: SymbolFont, Char: 0xF0FC, Text: "✓"},
+	}
+
+	var expected []byte
+	for i := 0; i < 20; i++ {
+		doc := openTestDocx(t, parts)
+		if i == 0 {
+			placeholders := doc.Placeholders()
+			if len(placeholders) != 8 {
+				t.Fatalf("expected 8 placeholders, got %d", len(placeholders))
+			}
+			// the document sorts before the header
+			var texts []string
+			for _, placeholder := range placeholders[:6] {
+				texts = append(texts, placeholder.Text(doc.GetFile(DocumentXml)))
+			}
+			if order := strings.Join(texts, ""); order != "{c}{a}{b}{b}{a}{d}" {
+				t.Fatalf("unexpected order of placeholders %s", order)
+			}
+		}
+		if err := doc.ReplaceAll(placeholderMap); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := doc.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			expected = buf.Bytes()
+			if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `<w:t>B</w:t>`) {
+				t.Errorf("expected the key 'b' to win over '{b}', got %s", result)
+			}
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Fatalf("output of run %d differs", i)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package docxtest provides a test harness for templates. RenderCheck renders a template with sample data and
+// asserts that the result passes a set of checks, e.g. in the CI of a repository of templates:
+//
+//	func TestInvoiceTemplate(t *testing.T) {
+//		docxtest.RenderCheck(t, "invoice.docx", map[string]interface{}{"name": "Jane", "total": "10.00"},
+//			docxtest.NoUnreplacedPlaceholders, docxtest.OpensWithoutRepair, docxtest.MaxPages(2))
+//	}
+//
+// Checks are functions which receive the rendered document and return an error describing what is wrong with it,
+// custom checks are written the same way.
+package docxtest
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lukasjarosch/go-docx"
+)
+
+var (
+	// drawingRegex matches the non-visual properties of a drawing (<wp:docPr>) including their extensions
+	drawingRegex = regexp.MustCompile(`(?s)<wp:docPr(?:\s[^>]*)?(?:/>|>.*?</wp:docPr>)`)
+	// descriptionRegex matches the alternative text of a drawing, the group contains the text
+	descriptionRegex = regexp.MustCompile(`^<wp:docPr\s[^>]*?\bdescr\s*=\s*"([^"]*)"`)
+	// nameRegex matches the name of a drawing, the group contains the name
+	nameRegex = regexp.MustCompile(`^<wp:docPr\s[^>]*?\bname\s*=\s*"([^"]*)"`)
+	// decorativeRegex matches the extension which marks a drawing as decorative, which needs no alternative text
+	decorativeRegex = regexp.MustCompile(`<\w+:decorative\s[^>]*?\bval\s*=\s*"(?:1|true)"`)
+)
+
+// Check inspects the rendered document. It returns an error describing the problems it found, or nil.
+type Check func(doc *docx.Document) error
+
+// RenderCheck renders the template at templatePath with the sample data, using the same defaults as docx.Render,
+// and runs all checks on the rendered document. The test fails immediately if the template cannot be rendered
+// (e.g. because of docx.ErrMissingValues), otherwise every failing check fails the test with its error.
+// The rendered document is returned to allow further assertions.
+func RenderCheck(t testing.TB, templatePath string, sampleData map[string]interface{}, checks ...Check) *docx.Document {
+	t.Helper()
+
+	var rendered bytes.Buffer
+	if err := docx.Render(templatePath, sampleData, &rendered); err != nil {
+		t.Fatalf("%s: unable to render: %s", templatePath, err)
+		return nil
+	}
+	doc, err := docx.OpenBytes(rendered.Bytes())
+	if err != nil {
+		t.Fatalf("%s: unable to open the rendered document: %s", templatePath, err)
+		return nil
+	}
+	for i, check := range checks {
+		if err := check(doc); err != nil {
+			t.Errorf("%s: check %d failed: %s", templatePath, i+1, err)
+		}
+	}
+	return doc
+}
+
+// NoUnreplacedPlaceholders fails if the rendered document still contains placeholders, e.g. because a value
+// introduced new placeholders.
+func NoUnreplacedPlaceholders(doc *docx.Document) error {
+	parts, err := parsedParts(doc)
+	if err != nil {
+		return err
+	}
+	var unreplaced []string
+	for _, name := range parts {
+		data := doc.GetFile(name)
+		parser := docx.NewRunParser(data)
+		if err := parser.Execute(); err != nil {
+			return fmt.Errorf("unable to parse %s: %s", name, err)
+		}
+		placeholders, err := docx.ParsePlaceholders(parser.Runs(), data)
+		if err != nil {
+			return fmt.Errorf("unable to parse the placeholders of %s: %s", name, err)
+		}
+		for _, placeholder := range placeholders {
+			unreplaced = append(unreplaced, fmt.Sprintf("%s in %s", placeholder.Text(data), name))
+		}
+	}
+	if len(unreplaced) > 0 {
+		return fmt.Errorf("unreplaced placeholders: %s", strings.Join(unreplaced, ", "))
+	}
+	return nil
+}
+
+// OpensWithoutRepair fails if Word would have to repair the rendered document: all parsed parts must be well-formed
+// (see docx.Document.Validate), every part must have a content type and all internal relationships must point to
+// existing parts.
+func OpensWithoutRepair(doc *docx.Document) error {
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+	parts, err := doc.Parts()
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool)
+	var problems []string
+	for _, part := range parts {
+		exists[part.Name] = true
+		if part.ContentType == "" && part.Name != docx.ContentTypesXml {
+			problems = append(problems, fmt.Sprintf("%s has no content type", part.Name))
+		}
+	}
+
+	// the relationships of the package itself belong to the empty part name
+	sources := []string{""}
+	for _, part := range parts {
+		if !strings.HasSuffix(part.Name, ".rels") {
+			sources = append(sources, part.Name)
+		}
+	}
+	for _, source := range sources {
+		rels, err := doc.Relationships(source)
+		if err != nil {
+			return err
+		}
+		for _, rel := range rels {
+			if rel.IsExternal() {
+				continue
+			}
+			if target := resolveTarget(source, rel.Target); !exists[target] {
+				problems = append(problems, fmt.Sprintf("relationship %s of %s points to the missing part %s", rel.ID, docx.RelsPath(source), target))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the document needs to be repaired: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// MaxPages fails if the estimated amount of pages of the rendered document exceeds max, see
+// docx.Document.EstimatePageCount.
+func MaxPages(max int) Check {
+	return func(doc *docx.Document) error {
+		if pages, confidence := doc.EstimatePageCount(); pages > max {
+			return fmt.Errorf("estimated %d pages (%s confidence), allowed are %d", pages, confidence, max)
+		}
+		return nil
+	}
+}
+
+// NoMissingAltText fails if a picture, chart or other drawing of the rendered document has no alternative text.
+// Drawings which are marked as decorative need none.
+func NoMissingAltText(doc *docx.Document) error {
+	parts, err := parsedParts(doc)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, name := range parts {
+		for _, drawing := range drawingRegex.FindAll(doc.GetFile(name), -1) {
+			if decorativeRegex.Match(drawing) {
+				continue
+			}
+			if match := descriptionRegex.FindSubmatch(drawing); match != nil && strings.TrimSpace(string(match[1])) != "" {
+				continue
+			}
+			drawingName := "unnamed drawing"
+			if match := nameRegex.FindSubmatch(drawing); match != nil {
+				drawingName = fmt.Sprintf("drawing %q", match[1])
+			}
+			missing = append(missing, fmt.Sprintf("%s in %s", drawingName, name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing alternative text: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parsedParts returns the names of the parts whose placeholders are replaced (document, headers, footers and notes).
+func parsedParts(doc *docx.Document) ([]string, error) {
+	parts, err := doc.Parts()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, part := range parts {
+		if doc.GetFile(part.Name) != nil {
+			names = append(names, part.Name)
+		}
+	}
+	return names, nil
+}
+
+// resolveTarget returns the part name (without leading slash) of the internal relationship target, which is
+// relative to the source part.
+func resolveTarget(source, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(source), target)
+}
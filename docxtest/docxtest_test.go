@@ -0,0 +1,156 @@
+package docxtest
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lukasjarosch/go-docx"
+)
+
+const (
+	testContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`</Types>`
+	testPackageRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+		`</Relationships>`
+)
+
+// testDrawing returns an inline drawing with the given non-visual properties.
+func testDrawing(docPr string) string {
+	return `<w:r><w:drawing><wp:inline><wp:extent cx="914400" cy="914400"/>` + docPr + `</wp:inline></w:drawing></w:r>`
+}
+
+// testTemplate writes a template with the given body and additional parts into the directory and returns its path.
+func testTemplate(t *testing.T, dir, name, body string, parts map[string]string) string {
+	all := map[string]string{
+		docx.ContentTypesXml: testContentTypes,
+		"_rels/.rels":        testPackageRels,
+		docx.DocumentXml: `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+			`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"><w:body>` + body + `</w:body></w:document>`,
+	}
+	for name, data := range parts {
+		all[name] = data
+	}
+
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	for name, data := range all {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// recorder is a testing.TB which records the failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs RenderCheck on a recorder and returns it.
+func record(t *testing.T, templatePath string, data map[string]interface{}, checks ...Check) *recorder {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RenderCheck(r, templatePath, data, checks...)
+	}()
+	<-done
+	return r
+}
+
+func TestRenderCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docxtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clean := testTemplate(t, dir, "clean.docx", `<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p>`+
+		`<w:p>`+testDrawing(`<wp:docPr id="1" name="Logo" descr="Company logo"/>`)+`</w:p>`+
+		`<w:p>`+testDrawing(`<wp:docPr id="2" name="Line"><a:extLst xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`+
+		`<a:ext uri="{C183D7F6-B498-43B3-948B-1728B52AA6E4}"><adec:decorative xmlns:adec="http://schemas.microsoft.com/office/drawing/2017/decorative" val="1"/>`+
+		`</a:ext></a:extLst></wp:docPr>`)+`</w:p>`, nil)
+	doc := RenderCheck(t, clean, map[string]interface{}{"name": "Jane"},
+		NoUnreplacedPlaceholders, OpensWithoutRepair, MaxPages(1), NoMissingAltText)
+	if text, _ := doc.PlainText(); !strings.Contains(text, "Dear Jane,") {
+		t.Errorf("expected the rendered document to be returned, got %s", text)
+	}
+
+	broken := testTemplate(t, dir, "broken.docx", `<w:p><w:r><w:t>Dear {name},</w:t></w:r><w:r><w:br w:type="page"/></w:r></w:p>`+
+		`<w:p><w:r><w:t>Page two</w:t></w:r>`+testDrawing(`<wp:docPr id="1" name="Chart 1" descr=" "/>`)+`</w:p>`,
+		map[string]string{"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/missing.png"/>` +
+			`</Relationships>`})
+	custom := func(doc *docx.Document) error {
+		return fmt.Errorf("custom check")
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		data     map[string]interface{}
+		check    Check
+		expected string
+	}{
+		{"unreplaced placeholders", broken, map[string]interface{}{"name": "{first} {last}"}, NoUnreplacedPlaceholders,
+			"check 1 failed: unreplaced placeholders: {first} in word/document.xml, {last} in word/document.xml"},
+		{"repair", broken, map[string]interface{}{"name": "Jane"}, OpensWithoutRepair,
+			"check 1 failed: the document needs to be repaired: relationship rId1 of word/_rels/document.xml.rels points to the missing part word/media/missing.png"},
+		{"pages", broken, map[string]interface{}{"name": "Jane"}, MaxPages(1),
+			"check 1 failed: estimated 2 pages (medium confidence), allowed are 1"},
+		{"alt text", broken, map[string]interface{}{"name": "Jane"}, NoMissingAltText,
+			`check 1 failed: missing alternative text: drawing "Chart 1" in word/document.xml`},
+		{"custom", clean, map[string]interface{}{"name": "Jane"}, custom, "check 1 failed: custom check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := record(t, tt.template, tt.data, tt.check)
+			if r.fatal != "" || len(r.errors) != 1 || r.errors[0] != tt.template+": "+tt.expected {
+				t.Errorf("expected the failure %s, got %v (fatal: %s)", tt.expected, r.errors, r.fatal)
+			}
+		})
+	}
+
+	r := record(t, clean, map[string]interface{}{}, NoUnreplacedPlaceholders)
+	if expected := clean + ": unable to render: " + docx.ErrMissingValues.Error() + ": name"; r.fatal != expected || len(r.errors) != 0 {
+		t.Errorf("expected the fatal failure %s, got %s", expected, r.fatal)
+	}
+}
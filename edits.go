@@ -0,0 +1,71 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrInvalidEdit is returned by ApplyEdits if one of the edits cannot be applied.
+	ErrInvalidEdit = errors.New("invalid edit")
+)
+
+// Edit describes a single modification of a file: the bytes inside Position are replaced by Replacement.
+// An empty Position (Start == End) inserts the replacement, an empty Replacement deletes the bytes.
+type Edit struct {
+	Position    Position
+	Replacement []byte
+}
+
+// ApplyEdits applies a batch of edits to the given file as one transaction, e.g. for editor integrations
+// which compute their changes based on the positions reported by the parser.
+//
+// All positions refer to the current content of the file, the offsets of later edits do not have to be
+// shifted by the caller. The edits may be given in any order, insertions at the same offset are applied
+// in the given order. The file is parsed again afterwards, so that all runs and placeholders are up to date.
+//
+// Either all edits are applied or none: if a position is invalid or out of bounds, if two edits overlap,
+// if the result is not well-formed XML or if it cannot be parsed, ErrInvalidEdit is returned and the file
+// remains untouched.
+func (d *Document) ApplyEdits(fileName string, edits []Edit) error {
+	data, exists := d.files[fileName]
+	if !exists {
+		return fmt.Errorf("unregistered file %s", fileName)
+	}
+
+	sorted := make([]edit, len(edits))
+	for i, e := range edits {
+		if !e.Position.Valid() || e.Position.Start < 0 || e.Position.End > int64(len(data)) {
+			return fmt.Errorf("%w: position %d-%d is invalid or out of bounds", ErrInvalidEdit, e.Position.Start, e.Position.End)
+		}
+		sorted[i] = edit(e)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Position.Start != sorted[j].Position.Start {
+			return sorted[i].Position.Start < sorted[j].Position.Start
+		}
+		return sorted[i].Position.End < sorted[j].Position.End
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Position.Start < sorted[i-1].Position.End {
+			return fmt.Errorf("%w: overlapping edits at offset %d", ErrInvalidEdit, sorted[i].Position.Start)
+		}
+	}
+
+	result := applyEdits(data, sorted)
+	if err := d.decoderSettings.checkWellFormed(result); err != nil {
+		return fmt.Errorf("%w: the result is not well-formed XML: %s", ErrInvalidEdit, err)
+	}
+
+	if err := d.SetFile(fileName, result); err != nil {
+		return err
+	}
+	if err := d.parseFile(fileName); err != nil {
+		// the original data was parsed before, restoring it cannot fail
+		d.SetFile(fileName, data)
+		d.parseFile(fileName)
+		return fmt.Errorf("%w: %s", ErrInvalidEdit, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDocument_ApplyEdits(t *testing.T) {
+	body := `<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p><w:p><w:r><w:t>from {city}</w:t></w:r></w:p>`
+	data := []byte(testDocumentXml(body))
+	at := func(s string) Position {
+		start := int64(bytes.Index(data, []byte(s)))
+		return Position{Start: start, End: start + int64(len(s))}
+	}
+	insert := func(s string) Position {
+		start := int64(bytes.Index(data, []byte(s)))
+		return Position{Start: start, End: start}
+	}
+
+	tests := []struct {
+		name     string
+		edits    []Edit
+		expected string
+		err      bool
+	}{
+		{
+			name: "right to left offsets",
+			edits: []Edit{
+				{Position: at("from"), Replacement: []byte("greetings from")},
+				{Position: at("Dear"), Replacement: []byte("Hello")},
+				{Position: insert("{city}"), Replacement: []byte("sunny ")},
+			},
+			expected: `<w:p><w:r><w:t>Hello {name},</w:t></w:r></w:p><w:p><w:r><w:t>greetings from sunny {city}</w:t></w:r></w:p>`,
+		},
+		{
+			name: "insertions in the given order",
+			edits: []Edit{
+				{Position: at("{city}"), Replacement: []byte("{town}")},
+				{Position: insert("{city}"), Replacement: []byte("the ")},
+				{Position: insert("{city}"), Replacement: []byte("old ")},
+			},
+			expected: `<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p><w:p><w:r><w:t>from the old {town}</w:t></w:r></w:p>`,
+		},
+		{
+			name: "overlapping",
+			edits: []Edit{
+				{Position: at("Dear {name}"), Replacement: []byte("Hi")},
+				{Position: at("{name},"), Replacement: []byte("there")},
+			},
+			err: true,
+		},
+		{
+			name:  "out of bounds",
+			edits: []Edit{{Position: Position{Start: 10, End: int64(len(data)) + 1}}},
+			err:   true,
+		},
+		{
+			name:  "invalid position",
+			edits: []Edit{{Position: Position{Start: 10, End: 5}}},
+			err:   true,
+		},
+		{
+			name: "not well-formed",
+			edits: []Edit{
+				{Position: at("Dear"), Replacement: []byte("Hello")},
+				{Position: at("</w:t></w:r></w:p><w:p>")},
+			},
+			err: true,
+		},
+		{
+			name: "unparsable placeholders",
+			edits: []Edit{
+				{Position: at("Dear"), Replacement: []byte("Hello")},
+				{Position: at("{name}"), Replacement: []byte("}name{")},
+			},
+			err: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: string(data)}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = doc.ApplyEdits(DocumentXml, tt.edits)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidEdit) {
+					t.Fatalf("expected ErrInvalidEdit, got %v", err)
+				}
+				if !bytes.Equal(doc.GetFile(DocumentXml), data) {
+					t.Errorf("the file was modified: %s", doc.GetFile(DocumentXml))
+				}
+				if placeholders := doc.Placeholders(); len(placeholders) != 2 {
+					t.Errorf("expected the original 2 placeholders, got %d", len(placeholders))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := testDocumentXml(tt.expected); string(doc.GetFile(DocumentXml)) != expected {
+				t.Errorf("expected %s, got %s", expected, doc.GetFile(DocumentXml))
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "city": "Almaty", "town": "Almaty"}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Contains(doc.GetFile(DocumentXml), []byte("Almaty</w:t>")) {
+				t.Errorf("the edited file was not parsed again: %s", doc.GetFile(DocumentXml))
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package docx
+
+import (
+	"sort"
+	"strings"
+)
+
+// WithDelimiterEscape enables escaping of delimiters, so that templates may contain literal delimiters, e.g. JSON
+// samples. A delimiter which directly follows the escape is no delimiter, with the escape '\':
+//
+//	docx.Open("template.docx", docx.WithDelimiterEscape(`\`))
+//
+// the text '\{"id": 1}' never contains a placeholder and is written as '{"id": 1}'. A closing delimiter which closes
+// no placeholder is literal anyway, it may be escaped as well ('\}'). The escape applies to the opening and closing
+// delimiters of all pairs (see WithDelimiters), an escape which is not followed by a delimiter is kept as it is.
+// The escape and the delimiter must not be split across runs.
+//
+// Escaped delimiters are kept in the parts while working on the document (e.g. GetFile), they are only unescaped
+// when the document is written, which includes the text of the replaced values.
+// An empty escape disables escaping, which is the default.
+func WithDelimiterEscape(escape string) Option {
+	return func(d *Document) {
+		d.delimiterEscape = escape
+	}
+}
+
+// placeholderGrammar defines which delimiters of a text delimit placeholders. It is shared by everything which
+// detects placeholders, so that the parser, the counting of placeholders and the output agree on what is a
+// placeholder and what is a literal delimiter.
+type placeholderGrammar struct {
+	delimiters Delimiters
+	// escape makes the following delimiter literal, no delimiter can be escaped if it is empty
+	escape string
+	// escapable are the delimiters of all pairs, all of them can be escaped
+	escapable []string
+}
+
+// grammar returns the grammar of the placeholders which use the delimiters.
+func (d *Document) grammar(delimiters Delimiters) placeholderGrammar {
+	grammar := placeholderGrammar{delimiters: delimiters, escape: d.delimiterEscape}
+	if grammar.escape == "" {
+		return grammar
+	}
+	for _, pair := range d.allDelimiters() {
+		grammar.escapable = append(grammar.escapable, pair.Open, pair.Close)
+	}
+	// the longest delimiter is escaped if several delimiters follow the escape, e.g. '\${' instead of '\$'
+	sort.SliceStable(grammar.escapable, func(i, j int) bool { return len(grammar.escapable[i]) > len(grammar.escapable[j]) })
+	return grammar
+}
+
+// escapeAt returns the length of the escaped delimiter at the start of the text including the escape, or 0 if the
+// text does not start with an escaped delimiter.
+func (g placeholderGrammar) escapeAt(text string) int {
+	if g.escape == "" || !strings.HasPrefix(text, g.escape) {
+		return 0
+	}
+	for _, delimiter := range g.escapable {
+		if delimiter != "" && strings.HasPrefix(text[len(g.escape):], delimiter) {
+			return len(g.escape) + len(delimiter)
+		}
+	}
+	return 0
+}
+
+// delimiterPositions returns the positions of all opening and closing delimiters of the text which are not escaped.
+// The text is scanned from left to right, delimiters do not overlap.
+func (g placeholderGrammar) delimiterPositions(text string) (openPos, closePos []int) {
+	for i := 0; i < len(text); {
+		if length := g.escapeAt(text[i:]); length > 0 {
+			i += length
+			continue
+		}
+		if g.delimiters.Open != "" && strings.HasPrefix(text[i:], g.delimiters.Open) {
+			openPos = append(openPos, i)
+			i += len(g.delimiters.Open)
+			continue
+		}
+		if g.delimiters.Close != "" && strings.HasPrefix(text[i:], g.delimiters.Close) {
+			closePos = append(closePos, i)
+			i += len(g.delimiters.Close)
+			continue
+		}
+		i++
+	}
+	return openPos, closePos
+}
+
+// escapes returns the positions of all escaped delimiters of the text, including their escapes.
+func (g placeholderGrammar) escapes(text string) []Position {
+	if g.escape == "" {
+		return nil
+	}
+	var positions []Position
+	for i := 0; i < len(text); {
+		if length := g.escapeAt(text[i:]); length > 0 {
+			positions = append(positions, Position{Start: int64(i), End: int64(i + length)})
+			i += length
+			continue
+		}
+		i++
+	}
+	return positions
+}
+
+// unescape removes the escapes of all escaped delimiters of the text.
+func (g placeholderGrammar) unescape(text string) string {
+	positions := g.escapes(text)
+	if len(positions) == 0 {
+		return text
+	}
+	var builder strings.Builder
+	offset := int64(0)
+	for _, pos := range positions {
+		builder.WriteString(text[offset:pos.Start])
+		offset = pos.Start + int64(len(g.escape))
+	}
+	builder.WriteString(text[offset:])
+	return builder.String()
+}
+
+// unescapedFile returns the data of the parsed file with the escapes of all escaped delimiters inside its text
+// removed, see WithDelimiterEscape. The data is returned unchanged if escaping is disabled.
+// Files whose placeholder keys were trimmed are returned without the trimming until they are modified, see
+// WithKeyTrimming.
+func (d *Document) unescapedFile(name string) []byte {
+	if untrimmed, unmodified := d.untrimmedParts[name]; unmodified {
+		return d.unescape(untrimmed.data, untrimmed.runs)
+	}
+	parser, parsed := d.runParsers[name]
+	if !parsed {
+		return d.files[name]
+	}
+	return d.unescape(d.files[name], parser.Runs())
+}
+
+// unescape returns the data with the escapes of all escaped delimiters inside the text of the runs removed.
+func (d *Document) unescape(data []byte, runs DocumentRuns) []byte {
+	if d.delimiterEscape == "" {
+		return data
+	}
+	grammar := d.grammar(DefaultDelimiters())
+	var edits []edit
+	for _, run := range runs.WithText() {
+		text := run.GetText(data)
+		if unescaped := grammar.unescape(text); unescaped != text {
+			edits = append(edits, edit{
+				Position:    Position{Start: run.Text.OpenTag.End, End: run.Text.CloseTag.Start},
+				Replacement: []byte(unescaped),
+			})
+		}
+	}
+	if len(edits) == 0 {
+		return data
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+	return applyEdits(data, edits)
+}
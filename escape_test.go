@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholderGrammar_DelimiterPositions(t *testing.T) {
+	defaults := placeholderGrammar{delimiters: DefaultDelimiters()}
+	escaped := placeholderGrammar{delimiters: DefaultDelimiters(), escape: `\`, escapable: []string{"${", "{", "}"}}
+	dollar := placeholderGrammar{delimiters: Delimiters{Open: "${", Close: "}"}, escape: `\`, escapable: []string{"${", "{", "}"}}
+
+	tests := []struct {
+		name      string
+		grammar   placeholderGrammar
+		text      string
+		openPos   []int
+		closePos  []int
+		unescaped string
+	}{
+		{"without escape", defaults, `\{a}`, []int{1}, []int{3}, `\{a}`},
+		{"escaped open", escaped, `\{a}`, nil, []int{3}, `{a}`},
+		{"escaped braces", escaped, `{"id": \{a\}} {b}`, []int{0, 14}, []int{12, 16}, `{"id": {a}} {b}`},
+		{"escape without delimiter", escaped, `C:\dir {a}`, []int{7}, []int{9}, `C:\dir {a}`},
+		{"escaped longest delimiter", escaped, `\${a} {b}`, []int{6}, []int{4, 8}, `${a} {b}`},
+		{"escaped longest delimiter of other pair", dollar, `\${a} ${b}`, []int{6}, []int{4, 9}, `${a} ${b}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openPos, closePos := tt.grammar.delimiterPositions(tt.text)
+			if !reflect.DeepEqual(openPos, tt.openPos) || !reflect.DeepEqual(closePos, tt.closePos) {
+				t.Errorf("expected %v and %v, got %v and %v", tt.openPos, tt.closePos, openPos, closePos)
+			}
+			if unescaped := tt.grammar.unescape(tt.text); unescaped != tt.unescaped {
+				t.Errorf("expected %s, got %s", tt.unescaped, unescaped)
+			}
+		})
+	}
+}
+
+func TestWithDelimiterEscape(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">Sample: \{"name": "{name}"}, \${name} and ${name}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">Literal \{name\}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}),
+		WithDelimiters(Delimiters{Open: "${", Close: "}"}), WithDelimiterEscape(`\`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// discovery agrees with the scanner: the escaped placeholders are no placeholders
+	var texts []string
+	for _, placeholder := range doc.Placeholders() {
+		texts = append(texts, placeholder.Text(doc.GetFile(DocumentXml)))
+	}
+	if expected := []string{"{name}", "${name}"}; !reflect.DeepEqual(texts, expected) {
+		t.Errorf("expected the placeholders %v, got %v", expected, texts)
+	}
+	if count := doc.countPlaceholders(DocumentXml, PlaceholderMap{"name": ""}); count != 2 {
+		t.Errorf("expected 2 placeholders to be counted, got %d", count)
+	}
+
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	// the escapes are kept until the document is written
+	if document := string(doc.GetFile(DocumentXml)); !strings.Contains(document, `Literal \{name\}`) {
+		t.Errorf("expected the escapes to be kept in %s", document)
+	}
+	document := string(reopen(t, doc).GetFile(DocumentXml))
+	for _, expected := range []string{
+		`Sample: {"name": "Jane"}, ${name} and Jane</w:t>`,
+		`Literal {name}</w:t>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+}
+
+func TestWithDelimiterEscape_Snapshot(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">Literal \{name\} for {name}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithDelimiterEscape(`\`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the snapshot contains the bytes which are written, without the escapes
+	snapshot := doc.Snapshot()
+	var written bytes.Buffer
+	if err := doc.Write(&written); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(written.Bytes()), int64(written.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != len(snapshot.Names()) {
+		t.Errorf("expected %d parts, got %v", len(archive.File), snapshot.Names())
+	}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		part, exists := snapshot.Part(file.Name)
+		if !exists {
+			t.Errorf("%s is missing in the snapshot", file.Name)
+			continue
+		}
+		if data, _ := part.Bytes(); !bytes.Equal(data, expected) {
+			t.Errorf("%s differs from the written part\nwant=%s\nhave=%s", file.Name, expected, data)
+		}
+	}
+	part, _ := snapshot.Part(DocumentXml)
+	if data, _ := part.Bytes(); !strings.Contains(string(data), `Literal {name} for Jane`) {
+		t.Errorf("expected the unescaped text in %s", data)
+	}
+}
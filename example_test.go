@@ -0,0 +1,164 @@
+package docx_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"strings"
+
+	"github.com/lukasjarosch/go-docx"
+)
+
+func ExampleOpen() {
+	doc, err := docx.Open("test/letter.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+
+	text, err := doc.PlainText()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(text)
+	// Output:
+	// Dear {name},
+	// thank you for your order from {city}.
+	// {logo}
+	// Regards, {sender}
+}
+
+func ExampleDocument_ReplaceAll() {
+	doc, err := docx.Open("test/letter.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+
+	// values are inserted as they are, special characters have to be escaped already
+	err = doc.ReplaceAll(docx.PlaceholderMap{
+		"name":   "Jane Doe",
+		"city":   "Almaty",
+		"logo":   "",
+		"sender": "Smith &amp; Sons",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := doc.Write(out); err != nil {
+		log.Fatal(err)
+	}
+	result, err := docx.OpenBytes(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	text, _ := result.PlainText()
+	fmt.Print(text)
+	// Output:
+	// Dear Jane Doe,
+	// thank you for your order from Almaty.
+	//
+	// Regards, Smith & Sons
+}
+
+func ExampleDocument_InsertImageAtPlaceholder() {
+	doc, err := docx.Open("test/letter.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+
+	logo := new(bytes.Buffer)
+	if err := png.Encode(logo, image.NewRGBA(image.Rect(0, 0, 200, 100))); err != nil {
+		log.Fatal(err)
+	}
+	// the height is computed from the aspect ratio of the image
+	if err := doc.InsertImageAtPlaceholder("logo", logo.Bytes(), docx.ImageSize{Width: docx.Inches(2)}); err != nil {
+		log.Fatal(err)
+	}
+
+	document := string(doc.GetFile(docx.DocumentXml))
+	fmt.Println(strings.Contains(document, "{logo}"))
+	fmt.Println(strings.Count(document, `<wp:extent cx="1828800" cy="914400"/>`))
+	for _, part := range doc.Snapshot().Parts() {
+		if strings.HasPrefix(part.Name(), "word/media/") {
+			fmt.Println(part.Name(), part.ContentType())
+		}
+	}
+	// Output:
+	// false
+	// 1
+	// word/media/image1.png image/png
+}
+
+func ExampleRender() {
+	type letter struct {
+		Name   string `docx:"name"`
+		City   string `docx:"city"`
+		Logo   string `docx:"logo"`
+		Sender string `docx:"sender"`
+	}
+
+	// unlike ReplaceAll, Render escapes the values and fails if a placeholder has no value
+	out := new(bytes.Buffer)
+	err := docx.Render("test/letter.docx", letter{Name: "Jane Doe", City: "Almaty", Sender: "Smith & Sons"}, out)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := docx.OpenBytes(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	text, _ := result.PlainText()
+	fmt.Print(text)
+	// Output:
+	// Dear Jane Doe,
+	// thank you for your order from Almaty.
+	//
+	// Regards, Smith & Sons
+}
+
+func ExampleDocument_ExpandTableRow() {
+	doc, err := docx.Open("test/invoice.docx")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer doc.Close()
+
+	err = doc.ExpandTableRow("item", []docx.PlaceholderMap{
+		{"item": "Book", "quantity": 2, "price": docx.Currency(18, "$")},
+		{"item": "Pencil", "quantity": 10, "price": docx.Currency(4.5, "$")},
+	}, docx.ExpandOptions{
+		Totals:    []docx.ColumnAggregate{docx.SumColumn("price")},
+		TotalsRow: docx.PlaceholderMap{"item": "Total", "quantity": ""},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := doc.ReplaceAll(docx.PlaceholderMap{"number": "2021-001"}); err != nil {
+		log.Fatal(err)
+	}
+
+	text, _ := doc.PlainText()
+	fmt.Print(text)
+	// Output:
+	// Invoice 2021-001
+	// Item
+	// Quantity
+	// Price
+	// Book
+	// 2
+	// $18.00
+	// Pencil
+	// 10
+	// $4.50
+	// Total
+	//
+	// $22.50
+	// Payable within 14 days.
+}
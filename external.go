@@ -0,0 +1,223 @@
+package docx
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// WebSettingsXml is the relative path of the web settings inside the docx-archive.
+	WebSettingsXml = "word/webSettings.xml"
+	// AttachedTemplateRelationshipType is the relationship type of the template attached to the document settings.
+	AttachedTemplateRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/attachedTemplate"
+	// FrameRelationshipType is the relationship type of the source documents of frames in the web settings.
+	FrameRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/frame"
+)
+
+// ExternalReferenceKind distinguishes the external references removed by StripExternalReferences.
+type ExternalReferenceKind int
+
+const (
+	// ExternalImage is a linked image which is loaded from outside of the package.
+	ExternalImage ExternalReferenceKind = iota
+	// ExternalTemplate is the attached template of the document settings.
+	ExternalTemplate
+	// ExternalFrame is the source document of a frame.
+	ExternalFrame
+	// ExternalField is an INCLUDEPICTURE or INCLUDETEXT field.
+	ExternalField
+)
+
+var (
+	// LinkedImageTagRegex matches the tags which reference images, in DrawingML (<a:blip>) and VML (<v:imagedata>)
+	LinkedImageTagRegex = regexp.MustCompile(`<a:blip\s[^>]*>|<v:imagedata\s[^>]*>`)
+	// AttachedTemplateTagRegex matches the attached template of the document settings
+	AttachedTemplateTagRegex = regexp.MustCompile(`<w:attachedTemplate\s[^>]*/>`)
+	// FrameSourceTagRegex matches the source document of a frame in the web settings
+	FrameSourceTagRegex = regexp.MustCompile(`<w:sourceFileName\s[^>]*/>`)
+
+	// includeInstructionRegex matches the start of field instructions which include external content
+	includeInstructionRegex = regexp.MustCompile(`^\s*(?i:INCLUDEPICTURE|INCLUDETEXT|INCLUDE|IMPORT)\s`)
+)
+
+// ExternalReference is a reference to content outside of the package which was removed from the document.
+type ExternalReference struct {
+	Kind ExternalReferenceKind
+	// Part is the part which contained the reference, e.g. 'word/document.xml'.
+	Part string
+	// Target is the url or path of the external content. For fields it is the field instruction.
+	Target string
+	// Cached is true if a linked image also had an embedded copy, which is displayed from now on.
+	Cached bool
+}
+
+// StripExternalReferences removes all references to content outside of the package, so that opening the document
+// does not load anything from remote locations. This is required before displaying untrusted documents.
+//
+//   - Linked images of the body, headers, footers, footnotes and endnotes are unlinked. If the image also has an
+//     embedded copy, that copy is displayed. Otherwise the empty picture remains as placeholder of the same size.
+//   - The attached template of the document settings is removed.
+//   - The source documents of frames are removed from the web settings.
+//   - INCLUDEPICTURE and INCLUDETEXT fields are converted to their last result, which is plain content of the document.
+//
+// The removed references are returned in document order per part.
+func (d *Document) StripExternalReferences() ([]ExternalReference, error) {
+	var removed []ExternalReference
+	for _, part := range d.fileNames() {
+		references, err := d.stripPart(part)
+		if err != nil {
+			return nil, err
+		}
+		removed = append(removed, references...)
+	}
+
+	references, err := d.stripReferencingTags(SettingsXml, AttachedTemplateRelationshipType, ExternalTemplate, AttachedTemplateTagRegex)
+	if err != nil {
+		return nil, err
+	}
+	removed = append(removed, references...)
+
+	references, err = d.stripReferencingTags(WebSettingsXml, FrameRelationshipType, ExternalFrame, FrameSourceTagRegex)
+	if err != nil {
+		return nil, err
+	}
+	return append(removed, references...), nil
+}
+
+// stripPart unlinks the linked images and removes the markup of include fields of the given part.
+func (d *Document) stripPart(part string) ([]ExternalReference, error) {
+	rels, err := d.Relationships(part)
+	if err != nil {
+		return nil, err
+	}
+	linked := make(map[string]string)
+	for _, rel := range rels {
+		if rel.Type == ImageRelationshipType && rel.IsExternal() {
+			linked[rel.ID] = rel.Target
+		}
+	}
+	data, err := d.getPart(part)
+	if err != nil {
+		return nil, err
+	}
+
+	type positioned struct {
+		pos       int
+		reference ExternalReference
+	}
+	var (
+		found []positioned
+		edits []edit
+	)
+	if len(linked) > 0 {
+		for _, loc := range LinkedImageTagRegex.FindAllIndex(data, -1) {
+			tag := data[loc[0]:loc[1]]
+			var (
+				stripped []byte
+				targets  []string
+				cached   bool
+			)
+			last := 0
+			for _, ref := range RelationshipReferenceRegex.FindAllSubmatchIndex(tag, -1) {
+				target, isLinked := linked[string(tag[ref[4]:ref[5]])]
+				if !isLinked {
+					cached = true
+					continue
+				}
+				stripped = append(stripped, tag[last:ref[0]]...)
+				last = ref[1]
+				targets = append(targets, target)
+			}
+			if targets == nil {
+				continue
+			}
+			stripped = append(stripped, tag[last:]...)
+			edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[1])}, Replacement: stripped})
+			for _, target := range targets {
+				found = append(found, positioned{pos: loc[0], reference: ExternalReference{
+					Kind:   ExternalImage,
+					Part:   part,
+					Target: target,
+					Cached: cached,
+				}})
+			}
+		}
+	}
+
+	for _, f := range fields(data) {
+		if !includeInstructionRegex.MatchString(f.instruction) {
+			continue
+		}
+		for _, pos := range f.elements {
+			edits = append(edits, edit{Position: pos})
+		}
+		found = append(found, positioned{pos: f.start, reference: ExternalReference{
+			Kind:   ExternalField,
+			Part:   part,
+			Target: strings.TrimSpace(f.instruction),
+		}})
+	}
+
+	if len(edits) > 0 {
+		sort.SliceStable(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+		if err := d.updatePart(part, applyEdits(data, edits)); err != nil {
+			return nil, err
+		}
+	}
+	ids := make(map[string]bool)
+	for id := range linked {
+		ids[id] = true
+	}
+	if err := d.removeRelationships(part, ids); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].pos < found[j].pos })
+	references := make([]ExternalReference, 0, len(found))
+	for _, f := range found {
+		references = append(references, f.reference)
+	}
+	return references, nil
+}
+
+// stripReferencingTags removes all relationships of the given type from the part, together with the tags
+// referencing them.
+func (d *Document) stripReferencingTags(part, relType string, kind ExternalReferenceKind, tagRegex *regexp.Regexp) ([]ExternalReference, error) {
+	if !d.partExists(part) {
+		return nil, nil
+	}
+	rels, err := d.Relationships(part)
+	if err != nil {
+		return nil, err
+	}
+	var references []ExternalReference
+	ids := make(map[string]bool)
+	for _, rel := range rels {
+		if rel.Type != relType {
+			continue
+		}
+		ids[rel.ID] = true
+		references = append(references, ExternalReference{Kind: kind, Part: part, Target: rel.Target})
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	data, err := d.getPart(part)
+	if err != nil {
+		return nil, err
+	}
+	var edits []edit
+	for _, loc := range tagRegex.FindAllIndex(data, -1) {
+		if id, _ := attributeValue(data[loc[0]:loc[1]], "r:id"); ids[id] {
+			edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[1])}})
+		}
+	}
+	if len(edits) > 0 {
+		if err := d.setPart(part, applyEdits(data, edits)); err != nil {
+			return nil, err
+		}
+	}
+	return references, d.removeRelationships(part, ids)
+}
@@ -0,0 +1,83 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_StripExternalReferences(t *testing.T) {
+	rels := func(content string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + content + `</Relationships>`
+	}
+	body := `<w:p><w:r><w:drawing><a:blip r:embed="rId1" r:link="rId2"/></w:drawing></w:r>` +
+		`<w:r><w:drawing><a:blip r:link="rId3"/></w:drawing></w:r></w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> INCLUDETEXT "https://example.com/a.docx" </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>included</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:fldSimple w:instr=" INCLUDEPICTURE &quot;https://example.com/b.png&quot; \d "><w:r><w:t>picture</w:t></w:r></w:fldSimple></w:p>` +
+		`<w:p><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(body),
+		RelsPath(DocumentXml): rels(`<Relationship Id="rId1" Type="` + ImageRelationshipType + `" Target="media/image1.png"/>` +
+			`<Relationship Id="rId2" Type="` + ImageRelationshipType + `" Target="https://example.com/tracker.png" TargetMode="External"/>` +
+			`<Relationship Id="rId3" Type="` + ImageRelationshipType + `" Target="https://example.com/remote.png" TargetMode="External"/>`),
+		SettingsXml: testSettingsXml(`<w:zoom w:percent="100"/><w:attachedTemplate r:id="rId1"/><w:defaultTabStop w:val="708"/>`),
+		RelsPath(SettingsXml): rels(`<Relationship Id="rId1" Type="` + AttachedTemplateRelationshipType + `" ` +
+			`Target="file://server/templates/Normal.dotm" TargetMode="External"/>`),
+	})
+
+	removed, err := doc.StripExternalReferences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ExternalReference{
+		{Kind: ExternalImage, Part: DocumentXml, Target: "https://example.com/tracker.png", Cached: true},
+		{Kind: ExternalImage, Part: DocumentXml, Target: "https://example.com/remote.png"},
+		{Kind: ExternalField, Part: DocumentXml, Target: `INCLUDETEXT "https://example.com/a.docx"`},
+		{Kind: ExternalField, Part: DocumentXml, Target: `INCLUDEPICTURE "https://example.com/b.png" \d`},
+		{Kind: ExternalTemplate, Part: SettingsXml, Target: "file://server/templates/Normal.dotm"},
+	}
+	if len(removed) != len(expected) {
+		t.Fatalf("unexpected references %+v", removed)
+	}
+	for i := range expected {
+		if removed[i] != expected[i] {
+			t.Errorf("reference %d: got %+v, expected %+v", i, removed[i], expected[i])
+		}
+	}
+
+	doc = reopen(t, doc)
+	result := string(doc.GetFile(DocumentXml))
+	expectedBody := `<w:p><w:r><w:drawing><a:blip r:embed="rId1"/></w:drawing></w:r><w:r><w:drawing><a:blip/></w:drawing></w:r></w:p>` +
+		`<w:p><w:r></w:r><w:r></w:r><w:r></w:r><w:r><w:t>included</w:t></w:r><w:r></w:r></w:p>` +
+		`<w:p><w:r><w:t>picture</w:t></w:r></w:p>` +
+		`<w:p><w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p>`
+	if !strings.Contains(result, expectedBody) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expectedBody, result)
+	}
+	documentRels, err := doc.Relationships(DocumentXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documentRels) != 1 || documentRels[0].ID != "rId1" {
+		t.Errorf("unexpected document relationships %+v", documentRels)
+	}
+	settingsRels, err := doc.Relationships(SettingsXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settingsRels) != 0 {
+		t.Errorf("unexpected settings relationships %+v", settingsRels)
+	}
+	settings, err := doc.getPart(SettingsXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<w:zoom w:percent="100"/><w:defaultTabStop w:val="708"/>`; !strings.Contains(string(settings), expected) {
+		t.Errorf("unexpected settings\nwant=%s\nhave=%s", expected, settings)
+	}
+
+	if removed, err := doc.StripExternalReferences(); err != nil || len(removed) != 0 {
+		t.Errorf("expected nothing to remove, got %+v (%v)", removed, err)
+	}
+}
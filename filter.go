@@ -0,0 +1,44 @@
+package docx
+
+// ValueFilter maps the value of a placeholder to the value which is inserted instead, e.g. to redact personal data.
+// The key is given without delimiters. Values which should not change are returned as they are.
+type ValueFilter func(key string, value interface{}) interface{}
+
+// WithValueFilter applies the filter to every value before it is inserted, so that one data source can render
+// several variants of a document:
+//
+//	redacted := docx.WithValueFilter(func(key string, value interface{}) interface{} {
+//		if key == "ssn" {
+//			return "███-██-████"
+//		}
+//		return value
+//	})
+//
+// The filter receives the resolved values (after WithLanguageVariants and WithResolver) before they are formatted
+// or escaped: lists are joined, nested placeholders are expanded and Render escapes after filtering. It applies
+// uniformly to ReplaceAll, Replace, Render and ProcessParts as well as to the rows and the totals of ExpandTableRow.
+func WithValueFilter(filter ValueFilter) Option {
+	return func(d *Document) {
+		d.valueFilter = filter
+	}
+}
+
+// filterValue returns the filtered value of the key, the key may contain delimiters.
+func (d *Document) filterValue(key string, value interface{}) interface{} {
+	if d.valueFilter == nil {
+		return value
+	}
+	return d.valueFilter(RemovePlaceholderDelimiter(key), value)
+}
+
+// filterValues returns a copy of the map with all values filtered, the map itself if there is no filter.
+func (d *Document) filterValues(placeholderMap PlaceholderMap) PlaceholderMap {
+	if d.valueFilter == nil || placeholderMap == nil {
+		return placeholderMap
+	}
+	filtered := make(PlaceholderMap, len(placeholderMap))
+	for key, value := range placeholderMap {
+		filtered[key] = d.filterValue(key, value)
+	}
+	return filtered
+}
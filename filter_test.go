@@ -0,0 +1,84 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// redact masks the social security number and the salaries.
+func redact(key string, value interface{}) interface{} {
+	switch key {
+	case "ssn":
+		return "███-██-████"
+	case "salary":
+		return "[redacted]"
+	}
+	return value
+}
+
+func TestDocument_ValueFilter(t *testing.T) {
+	body := `<w:p><w:r><w:t>{name} ({ssn})</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{employee}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{salary}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+		`<w:p><w:r><w:t>{summary}</w:t></w:r></w:p>`
+	values := PlaceholderMap{"name": "Jane", "ssn": "123-45-6789", "summary": "SSN {ssn}"}
+
+	tests := []struct {
+		name     string
+		options  []Option
+		expected []string
+	}{
+		{
+			name:     "full",
+			options:  []Option{WithNestedPlaceholders(0)},
+			expected: []string{"Jane (123-45-6789)", "Bob", "1000", "Alice", "2000", "3000", "SSN 123-45-6789"},
+		},
+		{
+			name:     "redacted",
+			options:  []Option{WithNestedPlaceholders(0), WithValueFilter(redact)},
+			expected: []string{"Jane (███-██-████)", "Bob", "[redacted]", "Alice", "SSN ███-██-████"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = doc.ExpandTableRow("employee", []PlaceholderMap{
+				{"employee": "Bob", "salary": 1000},
+				{"employee": "Alice", "salary": 2000},
+			}, ExpandOptions{Totals: []ColumnAggregate{SumColumn("salary")}, TotalsRow: PlaceholderMap{"employee": ""}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := doc.render("payroll", values); err != nil {
+				t.Fatal(err)
+			}
+
+			document := string(doc.GetFile(DocumentXml))
+			for _, expected := range tt.expected {
+				if !strings.Contains(document, "<w:t>"+expected+"</w:t>") {
+					t.Errorf("expected %s in %s", expected, document)
+				}
+			}
+			if tt.name == "redacted" && (strings.Contains(document, "6789") || strings.Contains(document, "000")) {
+				t.Errorf("values were not redacted: %s", document)
+			}
+		})
+	}
+}
+
+func TestDocument_Replace_ValueFilter(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{ssn}</w:t></w:r></w:p>`),
+	}), WithValueFilter(redact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Replace("ssn", "123-45-6789"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<w:t>███-██-████</w:t>"; !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+		t.Errorf("expected %s in %s", expected, doc.GetFile(DocumentXml))
+	}
+}
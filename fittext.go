@@ -0,0 +1,59 @@
+package docx
+
+import "github.com/lukasjarosch/go-docx/measure"
+
+// FittedText is a replacement value whose font size is reduced until the text fits into a maximum width.
+// The width of the text is estimated using the FontMetrics of the font of the replaced run (see MetricsForFont).
+// The estimation is not pixel-perfect, but deterministic.
+type FittedText struct {
+	Text string
+	// MaxWidth is the width in twips (1/20 point) the text must fit into.
+	MaxWidth int
+	// MinSize and MaxSize define the range of font sizes in points which may be used.
+	MinSize float64
+	MaxSize float64
+}
+
+// FitText returns a value which renders the text with the largest font size between minSize and maxSize (points)
+// whose estimated width does not exceed maxWidthTwips. If the text does not even fit using minSize, minSize is used.
+//
+// Deprecated: use FitTextWidth, which accepts a measure.Length.
+func FitText(text string, maxWidthTwips int, minSize, maxSize float64) FittedText {
+	return FitTextWidth(text, measure.Twips(maxWidthTwips), measure.Points(minSize), measure.Points(maxSize))
+}
+
+// FitTextWidth returns a value which renders the text with the largest font size between minSize and maxSize
+// whose estimated width does not exceed maxWidth, e.g. FitTextWidth(name, measure.Cm(5), 8, 12).
+// If the text does not even fit using minSize, minSize is used.
+func FitTextWidth(text string, maxWidth measure.Length, minSize, maxSize measure.Points) FittedText {
+	return FittedText{
+		Text:     text,
+		MaxWidth: int(measure.ToEMU(maxWidth).Twips()),
+		MinSize:  float64(minSize),
+		MaxSize:  float64(maxSize),
+	}
+}
+
+// Size returns the font size in points which is used to render the text with the given metrics.
+// Font sizes are reduced in steps of half a point, the smallest unit of font sizes in WordprocessingML.
+func (f FittedText) Size(metrics FontMetrics) float64 {
+	size := f.MaxSize
+	for size > f.MinSize && metrics.TextWidth(f.Text, size) > f.MaxWidth {
+		size -= 0.5
+	}
+	if size < f.MinSize {
+		size = f.MinSize
+	}
+	return size
+}
+
+// Markup implements the MarkupValue interface. The text is placed into a run of its own with the fitted size.
+func (f FittedText) Markup(runProperties string) string {
+	size := f.Size(MetricsForFont(runFont(runProperties)))
+	return RichText{{Text: f.Text, Size: size}}.Markup(runProperties)
+}
+
+// String returns the text of the value.
+func (f FittedText) String() string {
+	return f.Text
+}
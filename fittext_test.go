@@ -0,0 +1,72 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lukasjarosch/go-docx/measure"
+)
+
+func TestFontMetrics_TextWidth(t *testing.T) {
+	// 'Hello' in Helvetica: 722 + 556 + 222 + 222 + 556 = 2278 units
+	// at 10pt: 2278 / 1000 * 10pt * 20 = 455.6 twips
+	if width := HelveticaMetrics.TextWidth("Hello", 10); width != 456 {
+		t.Errorf("unexpected width, want=%d, have=%d", 456, width)
+	}
+	if width := CourierMetrics.TextWidth("Hello", 10); width != 600 {
+		t.Errorf("unexpected width, want=%d, have=%d", 600, width)
+	}
+}
+
+func TestFittedText_Size(t *testing.T) {
+	// every character is exactly one em wide, so the width in twips is len * size * 20
+	RegisterFontMetrics("Square", FontMetrics{DefaultWidth: 1000})
+	metrics := MetricsForFont("square")
+
+	tests := []struct {
+		name     string
+		value    FittedText
+		expected float64
+	}{
+		{"fits with max size", FitText("abcd", 960, 8, 12), 12},
+		{"reduced size", FitText("abcdef", 960, 6, 12), 8},
+		{"reduced in half points", FitText("abcdefghij", 1700, 6, 12), 8.5},
+		{"min size", FitText("abcdefghijklmnopqrstuvwxyz", 960, 7, 12), 7},
+		{"typed width", FitTextWidth("abcdef", measure.Points(48), 6, 12), 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if size := tt.value.Size(metrics); size != tt.expected {
+				t.Errorf("unexpected size, want=%.1f, have=%.1f", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestDocument_ReplaceAll_FitText(t *testing.T) {
+	RegisterFontMetrics("Square", FontMetrics{DefaultWidth: 1000})
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:rFonts w:ascii="Square"/><w:sz w:val="24"/></w:rPr><w:t>{name}</w:t></w:r></w:p>`),
+	})
+
+	err := doc.ReplaceAll(PlaceholderMap{
+		"name": FitText("abcdef", 960, 6, 12),
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := `<w:r><w:rPr><w:rFonts w:ascii="Square"/><w:sz w:val="16"/><w:szCs w:val="16"/></w:rPr><w:t xml:space="preserve">abcdef</w:t></w:r>`
+	if !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+		t.Errorf("fitted run not found in %s", doc.GetFile(DocumentXml))
+	}
+}
+
+func TestTextSpan_WordFitText(t *testing.T) {
+	markup := RichText{WordFitText("name", 1440)}.Markup(`<w:fitText w:val="10"/>`)
+	expected := `</w:t></w:r><w:r><w:rPr><w:fitText w:val="1440"/></w:rPr><w:t xml:space="preserve">name</w:t></w:r><w:r><w:rPr><w:fitText w:val="10"/></w:rPr><w:t xml:space="preserve">`
+	if markup != expected {
+		t.Errorf("unexpected markup\nwant=%s\nhave=%s", expected, markup)
+	}
+}
@@ -0,0 +1,112 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// RunFontsRegex matches the font definition of run properties and captures the ascii font name
+	RunFontsRegex = regexp.MustCompile(`<w:rFonts\s[^>]*w:ascii="([^"]*)"`)
+)
+
+// FontMetrics describe the advance widths of the characters of a font in 1/1000 em.
+// They are used to estimate the rendered width of text without a layout engine.
+type FontMetrics struct {
+	// Widths maps characters to their width, characters which are not in the map have the DefaultWidth.
+	Widths       map[rune]int
+	DefaultWidth int
+}
+
+// TextWidth estimates the width of the given text in twips (1/20 point) when rendered with the given size in points.
+// The estimation ignores kerning and ligatures, but it is deterministic.
+func (m FontMetrics) TextWidth(text string, size float64) int {
+	units := 0
+	for _, char := range text {
+		width, ok := m.Widths[char]
+		if !ok {
+			width = m.DefaultWidth
+		}
+		units += width
+	}
+	// units are 1/1000 em, one em is the font size and one point has 20 twips
+	return int(float64(units)*size*20/1000 + 0.5)
+}
+
+// asciiMetrics creates FontMetrics given the widths of the printable ascii characters (32 to 126).
+func asciiMetrics(widths []int, defaultWidth int) FontMetrics {
+	metrics := FontMetrics{Widths: make(map[rune]int, len(widths)), DefaultWidth: defaultWidth}
+	for i, width := range widths {
+		metrics.Widths[rune(32+i)] = width
+	}
+	return metrics
+}
+
+var (
+	// HelveticaMetrics are the metrics of Helvetica which are also used for metric-compatible fonts like Arial.
+	HelveticaMetrics = asciiMetrics([]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}, 556)
+	// TimesMetrics are the metrics of Times which are also used for metric-compatible fonts like Times New Roman.
+	TimesMetrics = asciiMetrics([]int{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+		921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+		556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+		333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+		500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+	}, 500)
+	// CourierMetrics are the metrics of the monospaced Courier (and Courier New).
+	CourierMetrics = FontMetrics{DefaultWidth: 600}
+
+	fontMetricsMu sync.RWMutex
+	fontMetrics   = map[string]FontMetrics{
+		"helvetica":        HelveticaMetrics,
+		"arial":            HelveticaMetrics,
+		"liberation sans":  HelveticaMetrics,
+		"arimo":            HelveticaMetrics,
+		"times":            TimesMetrics,
+		"times new roman":  TimesMetrics,
+		"liberation serif": TimesMetrics,
+		"tinos":            TimesMetrics,
+		"courier":          CourierMetrics,
+		"courier new":      CourierMetrics,
+		"liberation mono":  CourierMetrics,
+		"cousine":          CourierMetrics,
+	}
+)
+
+// RegisterFontMetrics registers the metrics of a font, the font name is case-insensitive.
+// Existing metrics for the same font are overwritten.
+func RegisterFontMetrics(font string, metrics FontMetrics) {
+	fontMetricsMu.Lock()
+	defer fontMetricsMu.Unlock()
+	fontMetrics[strings.ToLower(font)] = metrics
+}
+
+// MetricsForFont returns the registered metrics of the given font.
+// If the font is unknown, HelveticaMetrics are returned. Since they are rather wide compared to the default
+// fonts of Word, estimations for unknown fonts tend to be too wide rather than too narrow.
+func MetricsForFont(font string) FontMetrics {
+	fontMetricsMu.RLock()
+	defer fontMetricsMu.RUnlock()
+	if metrics, ok := fontMetrics[strings.ToLower(font)]; ok {
+		return metrics
+	}
+	return HelveticaMetrics
+}
+
+// runFont returns the ascii font of the given (inner) run properties or an empty string if none is set.
+func runFont(runProperties string) string {
+	match := RunFontsRegex.FindStringSubmatch(runProperties)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunFormat is the formatting which a FormatRule applies to a replaced value, on top of the run properties of the
+// placeholder.
+type RunFormat struct {
+	Bold   bool
+	Italic bool
+	// Color is the text color as hex RGB value (e.g. 'FF0000'), an empty color keeps the inherited color.
+	Color string
+}
+
+// FormatRule returns the formatting of the value of a placeholder, or nil if the value keeps the formatting of
+// the placeholder. The key is given without delimiters.
+type FormatRule func(key string, value interface{}) *RunFormat
+
+// WithFormatRule adds a rule which formats replaced values depending on their value, e.g. to render negative
+// amounts red:
+//
+//	negativeRed := docx.WithFormatRule(func(key string, value interface{}) *docx.RunFormat {
+//		if n, ok := value.(docx.NumberValue); ok && n.Value < 0 {
+//			return &docx.RunFormat{Color: "FF0000"}
+//		}
+//		return nil
+//	})
+//
+// The rules receive the resolved values (after WithValueFilter and the expansion of nested placeholders), before
+// they are escaped or changed by WithTypography. If several rules format a value, their formats are combined and the
+// color of the last rule wins. RichText values keep their explicit formatting: the color of a span wins over the
+// color of the rules, bold and italic are added. Other MarkupValue and BlockValue values are not formatted.
+// The rules apply to ReplaceAll, Replace and Render, Render reports the formats in ReplaceReport.Formats.
+func WithFormatRule(rule FormatRule) Option {
+	return func(d *Document) {
+		d.formatRules = append(d.formatRules, rule)
+	}
+}
+
+// valueFormats returns the combined formats of all rules for the values of the map, by key.
+func (d *Document) valueFormats(placeholderMap PlaceholderMap) map[string]RunFormat {
+	if len(d.formatRules) == 0 {
+		return nil
+	}
+	formats := make(map[string]RunFormat)
+	for key, value := range placeholderMap {
+		var format *RunFormat
+		for _, rule := range d.formatRules {
+			ruleFormat := rule(RemovePlaceholderDelimiter(key), value)
+			if ruleFormat == nil {
+				continue
+			}
+			if format == nil {
+				format = &RunFormat{}
+			}
+			format.Bold = format.Bold || ruleFormat.Bold
+			format.Italic = format.Italic || ruleFormat.Italic
+			if ruleFormat.Color != "" {
+				format.Color = ruleFormat.Color
+			}
+		}
+		if format != nil {
+			formats[key] = *format
+		}
+	}
+	return formats
+}
+
+// applyFormats returns a copy of the map in which all values with a format are formatted, see formatValue.
+func applyFormats(placeholderMap PlaceholderMap, formats map[string]RunFormat) PlaceholderMap {
+	if len(formats) == 0 {
+		return placeholderMap
+	}
+	result := make(PlaceholderMap, len(placeholderMap))
+	for key, value := range placeholderMap {
+		if format, exists := formats[key]; exists {
+			value = formatValue(value, format)
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// formatValue returns the value with the format applied. Text values become a formattedText, which inserts the
+// text just like an unformatted value is inserted.
+func formatValue(value interface{}, format RunFormat) interface{} {
+	switch v := value.(type) {
+	case RichText:
+		spans := make(RichText, len(v))
+		for i, span := range v {
+			span.Bold = span.Bold || format.Bold
+			span.Italic = span.Italic || format.Italic
+			if span.Color == "" {
+				span.Color = format.Color
+			}
+			spans[i] = span
+		}
+		return spans
+	case MarkupValue, BlockValue:
+		return value
+	}
+	return formattedText{text: fmt.Sprint(value), format: format}
+}
+
+// formattedText is a text value with the format of a FormatRule. The text is inserted as it is, like unformatted
+// values are inserted.
+type formattedText struct {
+	text   string
+	format RunFormat
+}
+
+// Markup implements the MarkupValue interface.
+func (t formattedText) Markup(runProperties string) string {
+	span := TextSpan{Bold: t.format.Bold, Italic: t.format.Italic, Color: t.format.Color}
+	return span.markup(strings.Replace(t.text, "\n", "</w:t><w:br/><w:t>", -1), runProperties)
+}
+
+// String returns the text of the value.
+func (t formattedText) String() string {
+	return t.text
+}
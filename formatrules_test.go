@@ -0,0 +1,93 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testNegativeRed = WithFormatRule(func(key string, value interface{}) *RunFormat {
+		switch v := value.(type) {
+		case NumberValue:
+			if v.Value < 0 {
+				return &RunFormat{Color: "FF0000"}
+			}
+		case CurrencyValue:
+			if v.Amount < 0 {
+				return &RunFormat{Color: "FF0000"}
+			}
+		}
+		return nil
+	})
+	testPastBold = WithFormatRule(func(key string, value interface{}) *RunFormat {
+		if date, ok := value.(DateValue); ok && date.Time.Before(time.Now()) {
+			return &RunFormat{Bold: true}
+		}
+		return nil
+	})
+)
+
+func TestDocument_ReplaceAll_FormatRules(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">Balance {balance}, due {due}, paid {paid} by {name}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), testNegativeRed, testPastBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	future := time.Now().AddDate(1, 0, 0)
+	if err := doc.ReplaceAll(PlaceholderMap{
+		"balance": Number(-5, 0),
+		"due":     Date(past, "02.01.2006"),
+		"paid":    Date(future, "2006"),
+		"name":    "Jane &amp; John",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	document := string(doc.GetFile(DocumentXml))
+	expected := `<w:t xml:space="preserve">Balance </w:t></w:r><w:r><w:rPr><w:i/><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve">-5</w:t></w:r>` +
+		`<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">, due </w:t></w:r><w:r><w:rPr><w:b/><w:i/></w:rPr><w:t xml:space="preserve">02.01.2020</w:t></w:r>` +
+		`<w:r><w:rPr><w:i/></w:rPr><w:t xml:space="preserve">, paid ` + future.Format("2006") + ` by Jane &amp; John</w:t>`
+	if !strings.Contains(document, expected) {
+		t.Errorf("expected %s in %s", expected, document)
+	}
+}
+
+func TestRender_FormatRules(t *testing.T) {
+	body := `<w:p><w:r><w:t>{total}</w:t></w:r></w:p><w:p><w:r><w:t>{note}</w:t></w:r></w:p>`
+	explicitBlue := WithFormatRule(func(key string, value interface{}) *RunFormat {
+		if key == "note" {
+			return &RunFormat{Color: "FF0000", Italic: true}
+		}
+		return nil
+	})
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), testNegativeRed, explicitBlue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.render("invoice", map[string]interface{}{
+		"total": Currency(-10, "€"),
+		"note":  RichText{{Text: "overdue", Color: "0000FF"}, Plain(" & unpaid")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{
+		`<w:rPr><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve">€-10.00</w:t>`,
+		// the explicit color of the span wins over the color of the rule
+		`<w:rPr><w:i/><w:color w:val="0000FF"/></w:rPr><w:t xml:space="preserve">overdue</w:t>`,
+		`<w:rPr><w:i/><w:color w:val="FF0000"/></w:rPr><w:t xml:space="preserve"> &amp; unpaid</w:t>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+	expectedFormats := map[string]RunFormat{"total": {Color: "FF0000"}, "note": {Italic: true, Color: "FF0000"}}
+	if !reflect.DeepEqual(report.Formats, expectedFormats) {
+		t.Errorf("expected the formats %v, got %v", expectedFormats, report.Formats)
+	}
+}
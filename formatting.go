@@ -0,0 +1,376 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// toggleProperties are the run properties with toggle semantics. Inside the style hierarchy their values are
+// combined (a bold character style inside a bold paragraph style is not bold), while direct formatting sets the
+// value explicitly, e.g. <w:b w:val="0"/> turns bold off.
+var toggleProperties = map[string]bool{
+	"w:b": true, "w:bCs": true, "w:i": true, "w:iCs": true, "w:caps": true, "w:smallCaps": true, "w:strike": true,
+	"w:dstrike": true, "w:outline": true, "w:shadow": true, "w:emboss": true, "w:imprint": true, "w:vanish": true,
+}
+
+// RunFormatting is the effective formatting of a run, after resolving its styles.
+type RunFormatting struct {
+	Bold   bool
+	Italic bool
+}
+
+// RunProperties are the inner XML of effective run properties (the children of <w:rPr>) in schema order.
+type RunProperties string
+
+// Toggle returns the value of the toggle property with the given qualified name, e.g. 'w:b'.
+func (p RunProperties) Toggle(name string) bool {
+	value, _ := ToggleValue(string(p), name)
+	return value
+}
+
+// Value returns the w:val attribute of the property with the given qualified name, e.g. 'w:sz'.
+func (p RunProperties) Value(name string) (string, bool) {
+	return propertyValue(string(p), name)
+}
+
+// ParagraphProperties are the inner XML of effective paragraph properties (the children of <w:pPr>) in schema order.
+type ParagraphProperties string
+
+// Value returns the w:val attribute of the property with the given qualified name, e.g. 'w:jc'.
+func (p ParagraphProperties) Value(name string) (string, bool) {
+	return propertyValue(string(p), name)
+}
+
+// styleDefinitions is used to unmarshal the properties of the styles part
+type styleDefinitions struct {
+	Default struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"docDefaults>rPrDefault>rPr"`
+	ParagraphDefault struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"docDefaults>pPrDefault>pPr"`
+	Styles []styleDefinition `xml:"style"`
+}
+
+// styleDefinition is a single style of the styles part
+type styleDefinition struct {
+	ID      string `xml:"styleId,attr"`
+	Type    string `xml:"type,attr"`
+	Default string `xml:"default,attr"`
+	Name    struct {
+		Val string `xml:"val,attr"`
+	} `xml:"name"`
+	BasedOn struct {
+		Val string `xml:"val,attr"`
+	} `xml:"basedOn"`
+	Link struct {
+		Val string `xml:"val,attr"`
+	} `xml:"link"`
+	RunProperties struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"rPr"`
+	ParagraphProperties struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"pPr"`
+}
+
+// ToggleValue returns the value of the toggle property with the given qualified name (e.g. 'w:b') inside the
+// given inner run properties. A property without w:val is on, 'false', '0' and 'off' turn it off.
+// If the properties do not contain the property, defined is false.
+func ToggleValue(runProperties, name string) (value, defined bool) {
+	for _, p := range splitProperties(runProperties) {
+		if p.Name != name {
+			continue
+		}
+		val, exists := attributeValue([]byte(p.Markup), "w:val")
+		if !exists {
+			return true, true
+		}
+		switch strings.ToLower(val) {
+		case "false", "0", "off":
+			return false, true
+		}
+		return true, true
+	}
+	return false, false
+}
+
+// normalizeToggles returns the inner run properties with all toggle properties in their canonical form,
+// i.e. <w:b/> if the property is on and <w:b w:val="0"/> if it is explicitly off.
+// Properties which are equal after normalizing format text identically.
+func normalizeToggles(runProperties string) string {
+	var result strings.Builder
+	for _, p := range splitProperties(runProperties) {
+		if !toggleProperties[p.Name] {
+			result.WriteString(p.Markup)
+			continue
+		}
+		value, _ := ToggleValue(p.Markup, p.Name)
+		result.WriteString(toggleMarkup(p.Name, value))
+	}
+	return result.String()
+}
+
+// toggleMarkup returns the canonical markup of a toggle property.
+func toggleMarkup(name string, value bool) string {
+	if value {
+		return "<" + name + "/>"
+	}
+	return "<" + name + ` w:val="0"/>`
+}
+
+// propertyValue returns the w:val attribute of the property with the given qualified name.
+func propertyValue(props, name string) (string, bool) {
+	for _, p := range splitProperties(props) {
+		if p.Name == name {
+			return attributeValue([]byte(p.Markup), "w:val")
+		}
+	}
+	return "", false
+}
+
+// RunFormatting returns the effective bold and italic formatting of a run of the given file.
+// Direct formatting of the run takes precedence. Otherwise the values of the run style and the paragraph style
+// (following the styles they are based on) are combined as toggles, falling back to the document defaults.
+func (d *Document) RunFormatting(file string, run *Run) (RunFormatting, error) {
+	data := d.GetFile(file)
+	if data == nil {
+		return RunFormatting{}, fmt.Errorf("unknown file %s", file)
+	}
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return RunFormatting{}, err
+	}
+
+	props := d.effectiveRunProperties(styles, data, run)
+	return RunFormatting{Bold: props.Toggle("w:b"), Italic: props.Toggle("w:i")}, nil
+}
+
+// EffectiveRunProperties returns the properties which apply to the run after resolving the style hierarchy:
+// direct formatting over the character style over the paragraph style over the document defaults.
+// Styles inherit the properties of the styles they are based on, toggle properties (e.g. bold) of the character
+// and paragraph style are combined as toggles. A style referenced with the wrong type is replaced by its linked style.
+// The run must be one of the current runs of the document (see Runs), otherwise the result is empty.
+// Problems like a missing styles part or cyclic styles are recorded as warnings.
+func (d *Document) EffectiveRunProperties(run *Run) RunProperties {
+	data := d.runData(run)
+	if data == nil {
+		return ""
+	}
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		d.warnOnce(err.Error())
+	}
+	return d.effectiveRunProperties(styles, data, run)
+}
+
+// EffectiveParagraphProperties returns the properties which apply to the paragraph containing the run after
+// resolving the style hierarchy: direct formatting over the paragraph style over the document defaults.
+// The properties of the paragraph mark (<w:rPr>), the section and revisions are not part of the result.
+// The run must be one of the current runs of the document (see Runs), otherwise the result is empty.
+func (d *Document) EffectiveParagraphProperties(run *Run) ParagraphProperties {
+	data := d.runData(run)
+	if data == nil {
+		return ""
+	}
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		d.warnOnce(err.Error())
+	}
+
+	direct, paragraphStyle := paragraphProperties(styles, data, run)
+	var result string
+	merge := func(props string) {
+		for _, p := range splitProperties(props) {
+			switch p.Name {
+			case "w:rPr", "w:sectPr", "w:pPrChange":
+				continue
+			}
+			result = SetParagraphProperty(result, p.Name, p.Markup)
+		}
+	}
+	merge(styles.ParagraphDefault.Inner)
+	chain := d.styleChain(styles, paragraphStyle, "paragraph")
+	for i := len(chain) - 1; i >= 0; i-- {
+		merge(chain[i].ParagraphProperties.Inner)
+	}
+	merge(direct)
+	return ParagraphProperties(result)
+}
+
+// effectiveRunProperties resolves the properties of a run of the given data.
+func (d *Document) effectiveRunProperties(styles styleDefinitions, data []byte, run *Run) RunProperties {
+	direct := run.GetProperties(data)
+	_, paragraphStyle := paragraphProperties(styles, data, run)
+	runChain := d.styleChain(styles, tagValue(RunStyleTagRegex, []byte(direct)), "character")
+	paragraphChain := d.styleChain(styles, paragraphStyle, "paragraph")
+
+	var result string
+	merge := func(props string) {
+		for _, p := range splitProperties(props) {
+			if !toggleProperties[p.Name] {
+				result = SetRunProperty(result, p.Name, p.Markup)
+			}
+		}
+	}
+	merge(styles.Default.Inner)
+	for i := len(paragraphChain) - 1; i >= 0; i-- {
+		merge(paragraphChain[i].RunProperties.Inner)
+	}
+	for i := len(runChain) - 1; i >= 0; i-- {
+		merge(runChain[i].RunProperties.Inner)
+	}
+	merge(direct)
+
+	// toggles of the run and paragraph style are combined, direct formatting sets them explicitly
+	for _, p := range splitProperties(direct + styles.Default.Inner + chainRunProperties(runChain) + chainRunProperties(paragraphChain)) {
+		if !toggleProperties[p.Name] {
+			continue
+		}
+		if value, defined := ToggleValue(direct, p.Name); defined {
+			result = SetRunProperty(result, p.Name, toggleMarkup(p.Name, value))
+			continue
+		}
+		runValue, runDefined := chainToggle(runChain, p.Name)
+		paragraphValue, paragraphDefined := chainToggle(paragraphChain, p.Name)
+		if runDefined || paragraphDefined {
+			result = SetRunProperty(result, p.Name, toggleMarkup(p.Name, runValue != paragraphValue))
+			continue
+		}
+		value, _ := ToggleValue(styles.Default.Inner, p.Name)
+		result = SetRunProperty(result, p.Name, toggleMarkup(p.Name, value))
+	}
+	return RunProperties(result)
+}
+
+// paragraphProperties returns the inner direct properties of the paragraph containing the run and its style,
+// which is the default paragraph style if the paragraph has none.
+func paragraphProperties(styles styleDefinitions, data []byte, run *Run) (props string, style string) {
+	style = styles.defaultStyle("paragraph")
+	start, _, err := enclosingElement(data, ParagraphOpenTagRegex, run.OpenTag.Start)
+	if err != nil {
+		return "", style
+	}
+	openTagEnd := start + int64(bytes.IndexByte(data[start:], '>')) + 1
+	pPr := ParagraphPropertiesRegex.Find(data[openTagEnd:])
+	if pPr == nil {
+		return "", style
+	}
+	if s := tagValue(ParagraphStyleTagRegex, pPr); s != "" {
+		style = s
+	}
+	return innerMarkup(pPr), style
+}
+
+// runData returns the data of the file which contains the given run.
+func (d *Document) runData(run *Run) []byte {
+	if file := d.runFile(run); file != "" {
+		return d.GetFile(file)
+	}
+	return nil
+}
+
+// runFile returns the name of the file which contains the given run, or an empty string if no file contains it.
+func (d *Document) runFile(run *Run) string {
+	for name, parser := range d.runParsers {
+		for _, r := range parser.Runs() {
+			if r == run {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// styleDefinitions parses the styles part of the document. Documents without styles have no definitions.
+func (d *Document) styleDefinitions() (styleDefinitions, error) {
+	var styles styleDefinitions
+	if !d.partExists(StylesXml) {
+		return styles, nil
+	}
+	data, err := d.getPart(StylesXml)
+	if err != nil {
+		return styles, err
+	}
+	if err := xml.Unmarshal(data, &styles); err != nil {
+		return styleDefinitions{}, fmt.Errorf("unable to parse %s: %s", StylesXml, err)
+	}
+	return styles, nil
+}
+
+// defaultStyle returns the ID of the default style of the given type.
+func (s styleDefinitions) defaultStyle(styleType string) string {
+	for _, style := range s.Styles {
+		if style.Type == styleType && (style.Default == "1" || style.Default == "true") {
+			return style.ID
+		}
+	}
+	return ""
+}
+
+// style returns the style with the given ID.
+func (s styleDefinitions) style(styleID string) *styleDefinition {
+	for i := range s.Styles {
+		if s.Styles[i].ID == styleID {
+			return &s.Styles[i]
+		}
+	}
+	return nil
+}
+
+// styleChain returns the style with the given ID followed by the styles it is based on. If the style is not of
+// the expected type, its linked style is used instead. A cyclic chain ends before the repeated style and is
+// recorded as a warning.
+func (d *Document) styleChain(styles styleDefinitions, styleID, styleType string) []*styleDefinition {
+	style := styles.style(styleID)
+	if style != nil && style.Type != "" && style.Type != styleType && style.Link.Val != "" {
+		style = styles.style(style.Link.Val)
+	}
+
+	var chain []*styleDefinition
+	visited := make(map[string]bool)
+	for style != nil {
+		if visited[style.ID] {
+			d.warnOnce(fmt.Sprintf("style %s is based on itself, the rest of its chain is ignored", style.ID))
+			break
+		}
+		visited[style.ID] = true
+		chain = append(chain, style)
+		if style.BasedOn.Val == "" {
+			break
+		}
+		style = styles.style(style.BasedOn.Val)
+	}
+	return chain
+}
+
+// chainToggle returns the value of the toggle property as defined by the nearest style of the chain.
+func chainToggle(chain []*styleDefinition, name string) (value, defined bool) {
+	for _, style := range chain {
+		if value, defined := ToggleValue(style.RunProperties.Inner, name); defined {
+			return value, true
+		}
+	}
+	return false, false
+}
+
+// chainRunProperties returns the concatenated run properties of all styles of the chain.
+func chainRunProperties(chain []*styleDefinition) string {
+	var result strings.Builder
+	for _, style := range chain {
+		result.WriteString(style.RunProperties.Inner)
+	}
+	return result.String()
+}
+
+// warnOnce records the warning unless it was already recorded.
+func (d *Document) warnOnce(warning string) {
+	for _, w := range d.warnings {
+		if w == warning {
+			return
+		}
+	}
+	d.warnings = append(d.warnings, warning)
+}
@@ -0,0 +1,197 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToggleValue(t *testing.T) {
+	tests := []struct {
+		props   string
+		value   bool
+		defined bool
+	}{
+		{``, false, false},
+		{`<w:bCs/>`, false, false},
+		{`<w:b/>`, true, true},
+		{`<w:b w:val="1"/>`, true, true},
+		{`<w:b w:val="true"/>`, true, true},
+		{`<w:b w:val="0"/>`, false, true},
+		{`<w:b w:val="false"/>`, false, true},
+		{`<w:b w:val="off"/>`, false, true},
+	}
+	for _, tt := range tests {
+		value, defined := ToggleValue(tt.props, "w:b")
+		if value != tt.value || defined != tt.defined {
+			t.Errorf("%s: got value=%v defined=%v, expected value=%v defined=%v", tt.props, value, defined, tt.value, tt.defined)
+		}
+	}
+}
+
+func TestDocument_RunFormatting(t *testing.T) {
+	styles := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:i/></w:rPr></w:rPrDefault></w:docDefaults>` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:basedOn w:val="Normal"/><w:rPr><w:b/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading2"><w:basedOn w:val="Heading1"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Quiet"><w:basedOn w:val="Heading1"/><w:rPr><w:b w:val="0"/><w:i w:val="0"/></w:rPr></w:style>` +
+		`<w:style w:type="character" w:styleId="Strong"><w:rPr><w:b/></w:rPr></w:style>` +
+		`</w:styles>`
+
+	tests := []struct {
+		name      string
+		paragraph string
+		expected  RunFormatting
+	}{
+		{"document defaults", `<w:p><w:r><w:t>x</w:t></w:r></w:p>`, RunFormatting{Italic: true}},
+		{"style defines bold", `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>x</w:t></w:r></w:p>`, RunFormatting{Bold: true, Italic: true}},
+		{"inherited from based on style", `<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>x</w:t></w:r></w:p>`, RunFormatting{Bold: true, Italic: true}},
+		{"style bold, run turns it off", `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:rPr><w:b w:val="0"/></w:rPr><w:t>x</w:t></w:r></w:p>`, RunFormatting{Italic: true}},
+		{"style not bold, run turns it on", `<w:p><w:pPr><w:pStyle w:val="Quiet"/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>x</w:t></w:r></w:p>`, RunFormatting{Bold: true}},
+		{"style turns off the based on style", `<w:p><w:pPr><w:pStyle w:val="Quiet"/></w:pPr><w:r><w:t>x</w:t></w:r></w:p>`, RunFormatting{}},
+		{"character style in bold paragraph style", `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:rPr><w:rStyle w:val="Strong"/></w:rPr><w:t>x</w:t></w:r></w:p>`, RunFormatting{Bold: false, Italic: true}},
+		{"character style", `<w:p><w:r><w:rPr><w:rStyle w:val="Strong"/></w:rPr><w:t>x</w:t></w:r></w:p>`, RunFormatting{Bold: true, Italic: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(tt.paragraph),
+				StylesXml:   styles,
+			})
+			runs := doc.runParsers[DocumentXml].Runs()
+			if len(runs) != 1 {
+				t.Fatalf("expected one run, got %d", len(runs))
+			}
+			formatting, err := doc.RunFormatting(DocumentXml, runs[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatting != tt.expected {
+				t.Errorf("unexpected formatting %+v, expected %+v", formatting, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDocument_EffectiveRunProperties(t *testing.T) {
+	styles := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="160"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:pPr><w:jc w:val="left"/></w:pPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:basedOn w:val="Normal"/><w:link w:val="Heading1Char"/>` +
+		`<w:pPr><w:keepNext/></w:pPr><w:rPr><w:b/><w:color w:val="2F5496"/><w:sz w:val="32"/></w:rPr></w:style>` +
+		`<w:style w:type="character" w:styleId="Heading1Char"><w:rPr><w:color w:val="FF0000"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Loop1"><w:basedOn w:val="Loop2"/><w:rPr><w:i/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Loop2"><w:basedOn w:val="Loop1"/><w:rPr><w:sz w:val="40"/></w:rPr></w:style>` +
+		`</w:styles>`
+
+	tests := []struct {
+		name      string
+		paragraph string
+		run       RunProperties
+		para      ParagraphProperties
+		warnings  int
+	}{
+		{
+			name:      "document defaults",
+			paragraph: `<w:p><w:r><w:t>x</w:t></w:r></w:p>`,
+			run:       `<w:rFonts w:ascii="Calibri"/><w:sz w:val="22"/>`,
+			para:      `<w:spacing w:after="160"/><w:jc w:val="left"/>`,
+		},
+		{
+			name:      "paragraph style and direct formatting",
+			paragraph: `<w:p><w:pPr><w:pStyle w:val="Heading1"/><w:jc w:val="center"/></w:pPr><w:r><w:rPr><w:sz w:val="36"/></w:rPr><w:t>x</w:t></w:r></w:p>`,
+			run:       `<w:rFonts w:ascii="Calibri"/><w:b/><w:color w:val="2F5496"/><w:sz w:val="36"/>`,
+			para:      `<w:pStyle w:val="Heading1"/><w:keepNext/><w:spacing w:after="160"/><w:jc w:val="center"/>`,
+		},
+		{
+			name:      "paragraph style used as character style is replaced by its linked style",
+			paragraph: `<w:p><w:r><w:rPr><w:rStyle w:val="Heading1"/></w:rPr><w:t>x</w:t></w:r></w:p>`,
+			run:       `<w:rStyle w:val="Heading1"/><w:rFonts w:ascii="Calibri"/><w:color w:val="FF0000"/><w:sz w:val="22"/>`,
+			para:      `<w:spacing w:after="160"/><w:jc w:val="left"/>`,
+		},
+		{
+			name:      "cyclic based on",
+			paragraph: `<w:p><w:pPr><w:pStyle w:val="Loop1"/></w:pPr><w:r><w:t>x</w:t></w:r></w:p>`,
+			run:       `<w:rFonts w:ascii="Calibri"/><w:i/><w:sz w:val="40"/>`,
+			para:      `<w:pStyle w:val="Loop1"/><w:spacing w:after="160"/>`,
+			warnings:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(tt.paragraph),
+				StylesXml:   styles,
+			})
+			runs := doc.Runs()
+			if len(runs) != 1 {
+				t.Fatalf("expected one run, got %d", len(runs))
+			}
+			if props := doc.EffectiveRunProperties(runs[0]); props != tt.run {
+				t.Errorf("unexpected run properties\nwant=%s\nhave=%s", tt.run, props)
+			}
+			if props := doc.EffectiveParagraphProperties(runs[0]); props != tt.para {
+				t.Errorf("unexpected paragraph properties\nwant=%s\nhave=%s", tt.para, props)
+			}
+			if len(doc.Warnings()) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, doc.Warnings())
+			}
+		})
+	}
+}
+
+func TestDocument_EffectiveRunProperties_InvalidStyles(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>x</w:t></w:r></w:p>`),
+		StylesXml:   `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style>`,
+	})
+	run := doc.Runs()[0]
+	for i := 0; i < 2; i++ {
+		if props := doc.EffectiveRunProperties(run); props != "<w:b/>" {
+			t.Errorf("unexpected run properties %s", props)
+		}
+		doc.EffectiveParagraphProperties(run)
+	}
+	// the problem of the styles is reported only once
+	if len(doc.Warnings()) != 1 {
+		t.Errorf("expected 1 warning, got %v", doc.Warnings())
+	}
+}
+
+func TestRunProperties_Value(t *testing.T) {
+	props := RunProperties(`<w:b w:val="0"/><w:sz w:val="24"/>`)
+	if props.Toggle("w:b") {
+		t.Error("expected bold to be off")
+	}
+	if size, ok := props.Value("w:sz"); !ok || size != "24" {
+		t.Errorf("unexpected size %q", size)
+	}
+	if _, ok := props.Value("w:color"); ok {
+		t.Error("unexpected color")
+	}
+}
+
+func TestDocument_ReplaceAll_MergeToggles(t *testing.T) {
+	body := `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr>` +
+		`<w:r><w:rPr><w:b w:val="0"/></w:rPr><w:t xml:space="preserve">Dear </w:t></w:r>` +
+		`<w:r><w:t>{name}</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t>, </w:t></w:r>` +
+		`<w:r><w:rPr><w:b w:val="true"/></w:rPr><w:t>{greeting}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithRunMerging())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "greeting": "welcome"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := string(doc.GetFile(DocumentXml))
+	expected := `<w:r><w:rPr><w:b w:val="0"/></w:rPr><w:t xml:space="preserve">Dear </w:t></w:r><w:r><w:t>Jane</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">, welcome</w:t></w:r>`
+	if !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
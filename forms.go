@@ -0,0 +1,276 @@
+package docx
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FormValueKind distinguishes the kinds of form values returned by ExtractFormData.
+type FormValueKind int
+
+const (
+	// FormText is the text of a text content control (plain, rich or date) or of a FORMTEXT field.
+	FormText FormValueKind = iota
+	// FormCheckbox is the state of a checkbox content control or of a FORMCHECKBOX field.
+	FormCheckbox
+	// FormChoice is the selection of a drop-down list or combo box content control or of a FORMDROPDOWN field.
+	FormChoice
+)
+
+var (
+	// ContentControlTokenRegex matches the open and close tags of content controls (<w:sdt>), but not of their children
+	ContentControlTokenRegex = regexp.MustCompile(`<w:sdt(?:\s[^>]*)?>|</w:sdt>`)
+	// ContentControlPropertiesRegex matches the properties of a content control (<w:sdtPr>)
+	ContentControlPropertiesRegex = regexp.MustCompile(`(?s)<w:sdtPr(?:\s[^>]*)?>.*?</w:sdtPr>`)
+	// ListItemTagRegex matches an item of a drop-down list or combo box content control (<w:listItem>)
+	ListItemTagRegex = regexp.MustCompile(`<w:listItem\s[^>]*>`)
+
+	// formTextRegex matches the elements which make up the text of a form value, the group contains the text
+	formTextRegex = regexp.MustCompile(`<w:t(?:\s[^>]*)?>([^<]*)</w:t>|<w:tab/>|<w:(?:br|cr)(?:\s[^>]*)?/>|</w:p>`)
+	// formFieldDataRegex matches the form field data inside the begin field character of a legacy form field
+	formFieldDataRegex = regexp.MustCompile(`(?s)\A<w:fldChar\s[^>]*[^/]>\s*<w:ffData>.*?</w:ffData>`)
+	// formFieldValueTagRegex matches a tag of the form field data which carries a value, the group contains the name
+	formFieldValueTagRegex = regexp.MustCompile(`<w:(name|checked|default|result|listEntry)(?:\s[^>]*)?/>`)
+	// listControlRegex matches the list properties of a drop-down list or combo box content control
+	listControlRegex = regexp.MustCompile(`<w:(?:dropDownList|comboBox)[\s/>]`)
+)
+
+// emptyFormText is the text Word shows in an empty FORMTEXT field, five en spaces.
+const emptyFormText = "\u2002\u2002\u2002\u2002\u2002"
+
+// FormValue is the current value of a form control, as returned by ExtractFormData.
+type FormValue struct {
+	Kind FormValueKind
+	// Text is the displayed text of the control. It is empty if the control shows its placeholder text.
+	Text string
+	// Checked is the state of a checkbox.
+	Checked bool
+	// Choice is the value of the selected item of a choice. Combo boxes may contain text which is not an item,
+	// then Choice is the text.
+	Choice string
+	// Items are the values of all items of a choice.
+	Items []string
+	// Values are all values in document order if several controls share the name, the other fields are those
+	// of the first control then.
+	Values []FormValue
+}
+
+// ExtractFormData returns the current values of all form controls of the document, e.g. of a completed form
+// returned by a customer:
+//   - content controls (<w:sdt>) by their tag: text, checkbox, date, drop-down list and combo box controls
+//   - legacy form fields by the name of their bookmark: FORMTEXT, FORMCHECKBOX and FORMDROPDOWN
+//
+// Controls in all parsed parts are extracted, including controls inside tables. Content controls without a tag and
+// form fields without a name are skipped. Content controls which contain other controls, e.g. repeating sections,
+// are returned with their whole text as well. If several controls share a name, all of their values are returned
+// in FormValue.Values.
+func (d *Document) ExtractFormData() map[string]FormValue {
+	values := make(map[string]FormValue)
+	add := func(name string, value FormValue) {
+		existing, exists := values[name]
+		if !exists {
+			values[name] = value
+			return
+		}
+		if len(existing.Values) == 0 {
+			existing.Values = []FormValue{existing}
+		}
+		existing.Values = append(existing.Values, value)
+		values[name] = existing
+	}
+
+	for _, file := range d.fileNames() {
+		data := d.GetFile(file)
+		for _, control := range contentControls(data) {
+			if tag, value, ok := contentControlValue(control); ok {
+				add(tag, value)
+			}
+		}
+		bookmarks := FindBookmarks(data)
+		for _, f := range fields(data) {
+			if name, value, ok := formFieldValue(data, f, bookmarks); ok {
+				add(name, value)
+			}
+		}
+	}
+	return values
+}
+
+// contentControls returns the markup of all content controls of the data in the order of their start, nested
+// content controls are returned after their parent.
+func contentControls(data []byte) [][]byte {
+	var (
+		starts   []int
+		controls [][]byte
+		open     []int // indices of the open controls
+	)
+	for _, loc := range ContentControlTokenRegex.FindAllIndex(data, -1) {
+		if data[loc[0]+1] != '/' {
+			open = append(open, len(starts))
+			starts = append(starts, loc[0])
+			controls = append(controls, nil)
+			continue
+		}
+		if len(open) == 0 {
+			continue
+		}
+		i := open[len(open)-1]
+		open = open[:len(open)-1]
+		controls[i] = data[starts[i]:loc[1]]
+	}
+
+	var complete [][]byte
+	for _, control := range controls {
+		if control != nil {
+			complete = append(complete, control)
+		}
+	}
+	return complete
+}
+
+// contentControlValue returns the tag and the value of the content control markup. False is returned if the
+// content control has no tag.
+func contentControlValue(control []byte) (string, FormValue, bool) {
+	properties := ContentControlPropertiesRegex.Find(control)
+	tag, ok := childAttribute(properties, "w:tag", "w:val")
+	if !ok || tag == "" {
+		return "", FormValue{}, false
+	}
+
+	// the content of nested controls belongs to this control as well
+	var content []byte
+	if start := bytes.Index(control, []byte("<w:sdtContent")); start >= 0 {
+		if end := bytes.LastIndex(control, []byte("</w:sdtContent>")); end > start {
+			content = control[start:end]
+		}
+	}
+
+	value := FormValue{Kind: FormText, Text: formText(content)}
+	if bytes.Contains(properties, []byte("<w:showingPlcHdr")) {
+		value.Text = ""
+	}
+	switch {
+	case CheckboxTagRegex.Match(properties):
+		value.Kind = FormCheckbox
+		if checked := checkedTagRegex.Find(properties); checked != nil {
+			state, ok := attributeValue(checked, "w14:val")
+			value.Checked = !ok || state == "1" || state == "true"
+		}
+	case listControlRegex.Match(properties):
+		value.Kind = FormChoice
+		value.Choice = value.Text
+		for _, item := range ListItemTagRegex.FindAll(properties, -1) {
+			itemValue, _ := attributeValue(item, "w:value")
+			itemValue = html.UnescapeString(itemValue)
+			value.Items = append(value.Items, itemValue)
+			if text, ok := attributeValue(item, "w:displayText"); ok && value.Text != "" && html.UnescapeString(text) == value.Text {
+				value.Choice = itemValue
+			}
+		}
+	}
+	return html.UnescapeString(tag), value, true
+}
+
+// formFieldValue returns the name and the value of a legacy form field. The name is the name of the innermost
+// bookmark around the field, or the name of the form field data if there is none. False is returned if the field
+// is no form field or has no name.
+func formFieldValue(data []byte, f field, bookmarks []Bookmark) (string, FormValue, bool) {
+	arguments := strings.Fields(f.instruction)
+	if f.simple || len(arguments) == 0 {
+		return "", FormValue{}, false
+	}
+	var value FormValue
+	switch strings.ToUpper(arguments[0]) {
+	case "FORMTEXT":
+		value = FormValue{Kind: FormText, Text: f.text}
+		if value.Text == emptyFormText {
+			value.Text = ""
+		}
+	case "FORMCHECKBOX":
+		value = FormValue{Kind: FormCheckbox}
+	case "FORMDROPDOWN":
+		value = FormValue{Kind: FormChoice}
+	default:
+		return "", FormValue{}, false
+	}
+
+	var name string
+	var checked, defaultChecked *bool
+	result := 0
+	for _, tag := range formFieldValueTagRegex.FindAllSubmatch(formFieldDataRegex.Find(data[f.start:]), -1) {
+		val, hasVal := attributeValue(tag[0], "w:val")
+		val = html.UnescapeString(val)
+		state := !hasVal || val == "1" || val == "true"
+		switch string(tag[1]) {
+		case "name":
+			name = val
+		case "checked":
+			checked = &state
+		case "default":
+			defaultChecked = &state
+			if value.Kind == FormChoice {
+				result, _ = strconv.Atoi(val)
+			}
+		case "result":
+			result, _ = strconv.Atoi(val)
+		case "listEntry":
+			value.Items = append(value.Items, val)
+		}
+	}
+	switch value.Kind {
+	case FormCheckbox:
+		if checked != nil {
+			value.Checked = *checked
+		} else if defaultChecked != nil {
+			value.Checked = *defaultChecked
+		}
+	case FormChoice:
+		if result >= 0 && result < len(value.Items) {
+			value.Choice = value.Items[result]
+		}
+		value.Text = value.Choice
+	}
+
+	// the bookmarks are sorted by their start, the last one around the field is the innermost
+	for _, bookmark := range bookmarks {
+		if bookmark.StartTag.Start > int64(f.start) {
+			break
+		}
+		if bookmark.EndTag.Start >= int64(f.start) && bookmark.Name != "" {
+			name = html.UnescapeString(bookmark.Name)
+		}
+	}
+	if name == "" {
+		return "", FormValue{}, false
+	}
+	return name, value, true
+}
+
+// formText returns the text of the markup: paragraphs are separated by newlines, tabs and breaks are kept.
+func formText(markup []byte) string {
+	var text strings.Builder
+	for _, loc := range formTextRegex.FindAllSubmatchIndex(markup, -1) {
+		switch {
+		case loc[2] >= 0:
+			text.WriteString(html.UnescapeString(string(markup[loc[2]:loc[3]])))
+		case bytes.HasPrefix(markup[loc[0]:], []byte("<w:tab")):
+			text.WriteString("\t")
+		default:
+			text.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(text.String(), "\n")
+}
+
+// childAttribute returns the raw value of the attribute of the first child element with the given name.
+func childAttribute(markup []byte, element, attribute string) (string, bool) {
+	re := regexp.MustCompile(`<` + regexp.QuoteMeta(element) + `(?:\s[^>]*)?/?>`)
+	tag := re.Find(markup)
+	if tag == nil {
+		return "", false
+	}
+	return attributeValue(tag, attribute)
+}
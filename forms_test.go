@@ -0,0 +1,82 @@
+package docx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testContentControl(properties, content string) string {
+	return `<w:sdt><w:sdtPr>` + properties + `</w:sdtPr><w:sdtContent>` + content + `</w:sdtContent></w:sdt>`
+}
+
+func testFormField(bookmark, data, instruction, result string) string {
+	field := `<w:r><w:fldChar w:fldCharType="begin"><w:ffData>` + data + `</w:ffData></w:fldChar></w:r>` +
+		`<w:r><w:instrText xml:space="preserve"> ` + instruction + ` </w:instrText></w:r>`
+	if result != "" {
+		field += `<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t xml:space="preserve">` + result + `</w:t></w:r>`
+	}
+	field += `<w:r><w:fldChar w:fldCharType="end"/></w:r>`
+	if bookmark == "" {
+		return field
+	}
+	return `<w:bookmarkStart w:id="` + bookmark + `" w:name="` + bookmark + `"/>` + field + `<w:bookmarkEnd w:id="` + bookmark + `"/>`
+}
+
+func TestDocument_ExtractFormData(t *testing.T) {
+	run := func(text string) string { return `<w:r><w:t>` + text + `</w:t></w:r>` }
+	row := func(item string) string {
+		return `<w:tr><w:tc><w:p>` + testContentControl(`<w:tag w:val="item"/>`, run(item)) + `</w:p></w:tc></w:tr>`
+	}
+	body := `<w:tbl>` + row("Book") + row("Pencil &amp; eraser") + `</w:tbl>` +
+		`<w:p>` + testContentControl(`<w:tag w:val="agree"/><w14:checkbox><w14:checked w14:val="1"/></w14:checkbox>`, run("☒")) + `</w:p>` +
+		`<w:p>` + testContentControl(`<w:tag w:val="newsletter"/><w14:checkbox><w14:checked w14:val="0"/></w14:checkbox>`, run("☐")) + `</w:p>` +
+		`<w:p>` + testContentControl(`<w:tag w:val="country"/><w:dropDownList><w:listItem w:displayText="Kazakhstan" w:value="KZ"/>`+
+		`<w:listItem w:displayText="Russia" w:value="RU"/></w:dropDownList>`, run("Kazakhstan")) + `</w:p>` +
+		`<w:p>` + testContentControl(`<w:tag w:val="city"/><w:comboBox><w:listItem w:displayText="Almaty" w:value="ALA"/></w:comboBox>`, run("Astana")) + `</w:p>` +
+		`<w:p>` + testContentControl(`<w:tag w:val="comment"/><w:showingPlcHdr/>`, run("Click here to enter text.")) + `</w:p>` +
+		testContentControl(`<w:alias w:val="untagged"/>`, `<w:p>`+run("ignored")+`</w:p>`) +
+		testContentControl(`<w:tag w:val="address"/>`, `<w:p>`+run("Abay 1")+`</w:p><w:p>`+run("Almaty")+`</w:p>`) +
+		`<w:p>` + testFormField("Name", `<w:name w:val="Text1"/><w:enabled/><w:textInput/>`, "FORMTEXT", "Jane") + `</w:p>` +
+		`<w:p>` + testFormField("Empty", `<w:name w:val="Text2"/><w:textInput/>`, "FORMTEXT", emptyFormText) + `</w:p>` +
+		`<w:p>` + testFormField("Check1", `<w:name w:val="Check1"/><w:checkBox><w:sizeAuto/><w:default w:val="0"/><w:checked/></w:checkBox>`, "FORMCHECKBOX", "") + `</w:p>` +
+		`<w:p>` + testFormField("", `<w:name w:val="Check2"/><w:checkBox><w:default w:val="1"/></w:checkBox>`, "FORMCHECKBOX", "") + `</w:p>` +
+		`<w:p>` + testFormField("Size", `<w:name w:val="Dropdown1"/><w:ddList><w:result w:val="1"/><w:listEntry w:val="S"/><w:listEntry w:val="M"/></w:ddList>`, "FORMDROPDOWN", "") + `</w:p>` +
+		`<w:p>` + testFormField("", `<w:name w:val=""/><w:textInput/>`, "FORMTEXT", "nameless") + `</w:p>`
+
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(body),
+		"word/footer1.xml": `<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p>` +
+			testContentControl(`<w:tag w:val="item"/>`, run("Eraser")) + `</w:p></w:ftr>`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]FormValue{
+		"item": {Kind: FormText, Text: "Book", Values: []FormValue{
+			{Kind: FormText, Text: "Book"},
+			{Kind: FormText, Text: "Pencil & eraser"},
+			{Kind: FormText, Text: "Eraser"},
+		}},
+		"agree":      {Kind: FormCheckbox, Text: "☒", Checked: true},
+		"newsletter": {Kind: FormCheckbox, Text: "☐"},
+		"country":    {Kind: FormChoice, Text: "Kazakhstan", Choice: "KZ", Items: []string{"KZ", "RU"}},
+		"city":       {Kind: FormChoice, Text: "Astana", Choice: "Astana", Items: []string{"ALA"}},
+		"comment":    {Kind: FormText},
+		"address":    {Kind: FormText, Text: "Abay 1\nAlmaty"},
+		"Name":       {Kind: FormText, Text: "Jane"},
+		"Empty":      {Kind: FormText},
+		"Check1":     {Kind: FormCheckbox, Checked: true},
+		"Check2":     {Kind: FormCheckbox, Checked: true},
+		"Size":       {Kind: FormChoice, Text: "M", Choice: "M", Items: []string{"S", "M"}},
+	}
+	values := doc.ExtractFormData()
+	for name, value := range expected {
+		if !reflect.DeepEqual(values[name], value) {
+			t.Errorf("%s: expected %+v, got %+v", name, value, values[name])
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("expected %d values, got %d: %+v", len(expected), len(values), values)
+	}
+}
@@ -0,0 +1,455 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// HyperlinkRelationshipType is the relationship type of hyperlinks.
+	HyperlinkRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	// HyperlinkStyle is the ID of the character style Word uses for hyperlinks.
+	HyperlinkStyle = "Hyperlink"
+)
+
+var (
+	// HyperlinkOpenTagRegex matches the open tag of hyperlinks (<w:hyperlink>)
+	HyperlinkOpenTagRegex = regexp.MustCompile(`<w:hyperlink(?:\s[^>]*)?>`)
+)
+
+// hyperlink is a MarkupValue which renders a hyperlink referencing an external relationship.
+type hyperlink struct {
+	RelationshipID string
+	Text           string
+}
+
+// Markup closes the run of the placeholder, adds the hyperlink with the text formatted like the placeholder and
+// the hyperlink style, and continues the original run afterwards.
+func (h hyperlink) Markup(runProperties string) string {
+	var lines []string
+	for _, line := range strings.Split(h.Text, "\n") {
+		lines = append(lines, xmlEscape(line))
+	}
+
+	var markup strings.Builder
+	markup.WriteString("</w:t></w:r>")
+	markup.WriteString(fmt.Sprintf(`<w:hyperlink xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:id="%s" w:history="1">`, h.RelationshipID))
+	markup.WriteString(fmt.Sprintf(`<w:r><w:rPr>%s</w:rPr>`, SetRunProperty(runProperties, "w:rStyle", fmt.Sprintf(`<w:rStyle w:val="%s"/>`, HyperlinkStyle))))
+	markup.WriteString(`<w:t xml:space="preserve">` + strings.Join(lines, "</w:t><w:br/><w:t>") + `</w:t></w:r></w:hyperlink>`)
+	markup.WriteString("<w:r>")
+	if runProperties != "" {
+		markup.WriteString(fmt.Sprintf("<w:rPr>%s</w:rPr>", runProperties))
+	}
+	markup.WriteString(`<w:t xml:space="preserve">`)
+	return markup.String()
+}
+
+// ReplaceHyperlink replaces all occurrences of the placeholder with a hyperlink which displays the given text and
+// points to the given (absolute) url. Every part containing the placeholder gets an external relationship to the url.
+// Placeholders which are already inside a hyperlink cannot be replaced, as hyperlinks must not be nested.
+func (d *Document) ReplaceHyperlink(key, displayText, target string) error {
+	if u, err := url.Parse(target); err != nil || u.Scheme == "" {
+		return fmt.Errorf("invalid hyperlink url %q", target)
+	}
+	key = RemovePlaceholderDelimiter(key)
+	if err := d.checkReplacementLimits(PlaceholderMap{key: nil}); err != nil {
+		return err
+	}
+
+	for name := range d.files {
+		if !d.containsPlaceholder(name, key) {
+			continue
+		}
+		data := d.GetFile(name)
+		for _, placeholder := range d.filePlaceholders[name] {
+			if placeholder.Text(data) != AddPlaceholderDelimiter(key) {
+				continue
+			}
+			if _, _, err := enclosingElement(data, HyperlinkOpenTagRegex, placeholder.StartPos()); err == nil {
+				return fmt.Errorf("placeholder %s in %s is already inside a hyperlink", AddPlaceholderDelimiter(key), name)
+			}
+		}
+
+		relID, err := d.addRelationship(name, Relationship{
+			Type:       HyperlinkRelationshipType,
+			Target:     target,
+			TargetMode: TargetModeExternal,
+		})
+		if err != nil {
+			return err
+		}
+		changedBytes, err := d.replace(PlaceholderMap{key: hyperlink{RelationshipID: relID, Text: displayText}}, name)
+		if err != nil {
+			return err
+		}
+		if err := d.SetFile(name, changedBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	// FootnotesXml is the relative path of the footnotes inside the docx-archive.
+	FootnotesXml = "word/footnotes.xml"
+	// EndnotesXml is the relative path of the endnotes inside the docx-archive.
+	EndnotesXml = "word/endnotes.xml"
+)
+
+// HyperlinkKind distinguishes the targets of hyperlinks.
+type HyperlinkKind int
+
+const (
+	// HyperlinkExternal links to an external resource like a web page.
+	HyperlinkExternal HyperlinkKind = iota
+	// HyperlinkMailto links to an e-mail address ('mailto:').
+	HyperlinkMailto
+	// HyperlinkAnchor links to a bookmark inside the document.
+	HyperlinkAnchor
+)
+
+var (
+	// HyperlinkElementRegex matches a complete hyperlink element, the first group contains its content
+	HyperlinkElementRegex = regexp.MustCompile(`(?s)<w:hyperlink(?:\s[^>]*)?>(.*?)</w:hyperlink>`)
+	// RelationshipTagRegex matches a single relationship of a relationships part
+	RelationshipTagRegex = regexp.MustCompile(`<Relationship\s[^>]*>`)
+
+	// fieldTokenRegex matches the parts of fields (begin, separate and end characters, instructions and simple
+	// fields), texts and paragraph starts in document order
+	fieldTokenRegex = regexp.MustCompile(`<w:fldChar\s[^>]*>|<w:instrText(?:\s[^>]*)?>([^<]*)</w:instrText>|` +
+		`<w:fldSimple\s[^>]*>|</w:fldSimple>|<w:t(?:\s[^>]*)?>([^<]*)</w:t>|<w:p(?:\s[^>]*)?/?>`)
+	// hyperlinkInstructionRegex matches the start of a HYPERLINK field instruction
+	hyperlinkInstructionRegex = regexp.MustCompile(`^\s*(?i:HYPERLINK)\s`)
+	// fieldInstructionAttributeRegex matches the instruction attribute of a simple field
+	fieldInstructionAttributeRegex = regexp.MustCompile(`\sw:instr\s*=\s*"([^"]*)"`)
+	// instructionArgumentRegex matches an argument of a field instruction, quoted or not
+	instructionArgumentRegex = regexp.MustCompile(`"[^"]*"|\S+`)
+)
+
+// Hyperlink is a hyperlink found inside the document.
+type Hyperlink struct {
+	// URL is the target of the link, empty for links to bookmarks.
+	URL string
+	// Anchor is the bookmark the link points to, if any.
+	Anchor string
+	// Text is the displayed text.
+	Text string
+	Kind HyperlinkKind
+	// Part is the part containing the link, e.g. 'word/document.xml'.
+	Part string
+	// Paragraph is the index of the paragraph containing the link within the part.
+	Paragraph int
+	// Field is true if the link is a HYPERLINK field instead of a <w:hyperlink> element.
+	Field bool
+}
+
+// Hyperlinks returns all hyperlinks of the document body, headers, footers, footnotes and endnotes, ordered by part
+// and position. Both <w:hyperlink> elements and HYPERLINK fields are returned.
+func (d *Document) Hyperlinks() ([]Hyperlink, error) {
+	var links []Hyperlink
+	for _, part := range d.fileNames() {
+		data, err := d.getPart(part)
+		if err != nil {
+			return nil, err
+		}
+		rels, err := d.Relationships(part)
+		if err != nil {
+			return nil, err
+		}
+		targets := make(map[string]string)
+		for _, rel := range rels {
+			if rel.Type == HyperlinkRelationshipType {
+				targets[rel.ID] = rel.Target
+			}
+		}
+
+		type positioned struct {
+			pos  int
+			link Hyperlink
+		}
+		var found []positioned
+		for _, loc := range HyperlinkElementRegex.FindAllSubmatchIndex(data, -1) {
+			openTag := data[loc[0] : loc[0]+bytes.IndexByte(data[loc[0]:], '>')+1]
+			id, _ := attributeValue(openTag, "r:id")
+			anchor, _ := attributeValue(openTag, "w:anchor")
+			link := Hyperlink{
+				URL:       targets[id],
+				Anchor:    html.UnescapeString(anchor),
+				Text:      innerText(data[loc[2]:loc[3]]),
+				Part:      part,
+				Paragraph: paragraphIndex(data, loc[0]),
+			}
+			link.Kind = hyperlinkKind(link.URL)
+			found = append(found, positioned{pos: loc[0], link: link})
+		}
+		for _, field := range hyperlinkFields(data) {
+			link := Hyperlink{
+				URL:       field.url,
+				Anchor:    field.anchor,
+				Text:      field.text,
+				Kind:      hyperlinkKind(field.url),
+				Part:      part,
+				Paragraph: paragraphIndex(data, field.start),
+				Field:     true,
+			}
+			found = append(found, positioned{pos: field.start, link: link})
+		}
+
+		sort.SliceStable(found, func(i, j int) bool { return found[i].pos < found[j].pos })
+		for _, f := range found {
+			links = append(links, f.link)
+		}
+	}
+	return links, nil
+}
+
+// RewriteHyperlinks replaces the URL of every hyperlink with the result of rewrite, e.g. to add tracking parameters.
+// The targets of hyperlink relationships and the instructions of HYPERLINK fields are updated consistently.
+// Links to bookmarks have no URL and are not passed to rewrite, e-mail links are passed with their 'mailto:' scheme.
+func (d *Document) RewriteHyperlinks(rewrite func(url string) string) error {
+	for _, part := range d.fileNames() {
+		// relationship targets
+		if d.partExists(RelsPath(part)) {
+			rels, err := d.getPart(RelsPath(part))
+			if err != nil {
+				return err
+			}
+			var edits []edit
+			for _, loc := range RelationshipTagRegex.FindAllIndex(rels, -1) {
+				tag := rels[loc[0]:loc[1]]
+				relType, _ := attributeValue(tag, "Type")
+				target, _ := attributeValue(tag, "Target")
+				if relType != HyperlinkRelationshipType {
+					continue
+				}
+				target = html.UnescapeString(target)
+				if rewritten := rewrite(target); rewritten != target {
+					edits = append(edits, edit{
+						Position:    Position{Start: int64(loc[0]), End: int64(loc[1])},
+						Replacement: []byte(setAttribute(string(tag), "Target", rewritten)),
+					})
+				}
+			}
+			if len(edits) > 0 {
+				if err := d.setPart(RelsPath(part), applyEdits(rels, edits)); err != nil {
+					return err
+				}
+			}
+		}
+
+		// field instructions
+		data, err := d.getPart(part)
+		if err != nil {
+			return err
+		}
+		var edits []edit
+		for _, field := range hyperlinkFields(data) {
+			if field.url == "" {
+				continue
+			}
+			rewritten := rewrite(field.url)
+			if rewritten == field.url {
+				continue
+			}
+			instruction := field.instruction[:field.urlPos.Start] + `"` + rewritten + `"` + field.instruction[field.urlPos.End:]
+			edits = append(edits, field.setInstruction(instruction)...)
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		sort.SliceStable(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+		if err := d.updatePart(part, applyEdits(data, edits)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updatePart replaces the data of the part. Parsed files (document, headers, footers and notes) are parsed again.
+func (d *Document) updatePart(part string, data []byte) error {
+	if _, parsed := d.files[part]; !parsed {
+		return d.setPart(part, data)
+	}
+	if err := d.SetFile(part, data); err != nil {
+		return err
+	}
+	return d.parseFile(part)
+}
+
+// field is a field of a part, either a complex field (<w:fldChar>) or a simple field (<w:fldSimple>).
+type field struct {
+	start       int
+	instruction string
+	// instructionPositions are the positions of the instruction contents, i.e. the inner text of every
+	// <w:instrText> or the w:instr attribute value of a simple field
+	instructionPositions []Position
+	// elements are the positions of the field markup around the result: the field characters and
+	// instruction elements of a complex field or the tags of a simple field
+	elements []Position
+	simple   bool
+	text     string
+}
+
+// hyperlinkField is a HYPERLINK field of a part.
+type hyperlinkField struct {
+	field
+	url    string
+	urlPos Position // position of the (quoted) url inside the instruction
+	anchor string
+}
+
+// setInstruction returns the edits which replace the instruction of the field. The complete instruction is
+// written into the first instruction element, the others are emptied.
+func (f field) setInstruction(instruction string) []edit {
+	var edits []edit
+	for i, pos := range f.instructionPositions {
+		replacement := ""
+		if i == 0 {
+			replacement = instruction
+		}
+		if f.simple {
+			replacement = html.EscapeString(replacement)
+		} else {
+			replacement = xmlEscape(replacement)
+		}
+		edits = append(edits, edit{Position: pos, Replacement: []byte(replacement)})
+	}
+	return edits
+}
+
+// hyperlinkFields returns all HYPERLINK fields of the data, both complex fields (<w:fldChar>) and simple fields.
+func hyperlinkFields(data []byte) []hyperlinkField {
+	var links []hyperlinkField
+	for _, f := range fields(data) {
+		if !hyperlinkInstructionRegex.MatchString(f.instruction) {
+			continue
+		}
+		link := hyperlinkField{field: f}
+		link.parseInstruction()
+		links = append(links, link)
+	}
+	return links
+}
+
+// fields returns all fields of the data in the order in which they end, nested fields come before their parent.
+func fields(data []byte) []field {
+	type openField struct {
+		field
+		inResult bool
+	}
+	var (
+		stack  []*openField
+		result []field
+	)
+
+	for _, loc := range fieldTokenRegex.FindAllSubmatchIndex(data, -1) {
+		token := data[loc[0]:loc[1]]
+		tokenPos := Position{Start: int64(loc[0]), End: int64(loc[1])}
+		var top *openField
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch {
+		case bytes.HasPrefix(token, []byte("<w:fldChar")):
+			switch fieldType, _ := attributeValue(token, "w:fldCharType"); fieldType {
+			case "begin":
+				stack = append(stack, &openField{field: field{start: loc[0], elements: []Position{tokenPos}}})
+			case "separate":
+				if top != nil {
+					top.inResult = true
+					top.elements = append(top.elements, tokenPos)
+				}
+			case "end":
+				if top != nil {
+					stack = stack[:len(stack)-1]
+					top.elements = append(top.elements, tokenPos)
+					result = append(result, top.field)
+				}
+			}
+		case loc[2] >= 0:
+			if top != nil && !top.inResult {
+				top.instruction += html.UnescapeString(string(data[loc[2]:loc[3]]))
+				top.instructionPositions = append(top.instructionPositions, Position{Start: int64(loc[2]), End: int64(loc[3])})
+				top.elements = append(top.elements, tokenPos)
+			}
+		case bytes.HasPrefix(token, []byte("<w:fldSimple")):
+			f := &openField{field: field{start: loc[0], simple: true, elements: []Position{tokenPos}}, inResult: true}
+			if m := fieldInstructionAttributeRegex.FindSubmatchIndex(token); m != nil {
+				f.instruction = html.UnescapeString(string(token[m[2]:m[3]]))
+				f.instructionPositions = []Position{{Start: int64(loc[0] + m[2]), End: int64(loc[0] + m[3])}}
+			}
+			if bytes.HasSuffix(token, []byte("/>")) {
+				result = append(result, f.field)
+			} else {
+				stack = append(stack, f)
+			}
+		case bytes.Equal(token, []byte("</w:fldSimple>")):
+			if top != nil && top.simple {
+				stack = stack[:len(stack)-1]
+				top.elements = append(top.elements, tokenPos)
+				result = append(result, top.field)
+			}
+		case loc[4] >= 0:
+			if top != nil && top.inResult {
+				top.text += html.UnescapeString(string(data[loc[4]:loc[5]]))
+			}
+		}
+	}
+	return result
+}
+
+// parseInstruction extracts the url and anchor of the HYPERLINK instruction.
+func (f *hyperlinkField) parseInstruction() {
+	args := instructionArgumentRegex.FindAllStringIndex(f.instruction, -1)
+	for i := 1; i < len(args); i++ {
+		arg := f.instruction[args[i][0]:args[i][1]]
+		if strings.HasPrefix(arg, `\`) {
+			// switches with an argument
+			switch strings.ToLower(arg) {
+			case `\l`:
+				if i+1 < len(args) {
+					f.anchor = strings.Trim(f.instruction[args[i+1][0]:args[i+1][1]], `"`)
+				}
+				i++
+			case `\o`, `\t`:
+				i++
+			}
+			continue
+		}
+		if f.url == "" {
+			f.url = strings.Trim(arg, `"`)
+			f.urlPos = Position{Start: int64(args[i][0]), End: int64(args[i][1])}
+		}
+	}
+}
+
+// hyperlinkKind returns the kind of a hyperlink with the given url.
+func hyperlinkKind(url string) HyperlinkKind {
+	switch {
+	case url == "":
+		return HyperlinkAnchor
+	case strings.HasPrefix(strings.ToLower(url), "mailto:"):
+		return HyperlinkMailto
+	}
+	return HyperlinkExternal
+}
+
+// innerText returns the unescaped text of all <w:t> elements inside the markup.
+func innerText(markup []byte) string {
+	var text strings.Builder
+	for _, match := range TextContentRegex.FindAllSubmatch(markup, -1) {
+		text.WriteString(html.UnescapeString(string(match[1])))
+	}
+	return text.String()
+}
+
+// paragraphIndex returns the index of the paragraph containing the given position.
+func paragraphIndex(data []byte, pos int) int {
+	return len(ParagraphOpenTagRegex.FindAllIndex(data[:pos], -1)) - 1
+}
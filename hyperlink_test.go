@@ -0,0 +1,132 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceHyperlink(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Visit {homepage} today</w:t></w:r></w:p>`),
+	})
+	if err := doc.ReplaceHyperlink("homepage", "our website", "https://example.com/?a=1&b=2"); err != nil {
+		t.Fatal(err)
+	}
+	doc = reopen(t, doc)
+
+	rels, err := doc.Relationships(DocumentXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rels) != 1 || rels[0].Type != HyperlinkRelationshipType || rels[0].Target != "https://example.com/?a=1&b=2" || !rels[0].IsExternal() {
+		t.Fatalf("unexpected relationships %+v", rels)
+	}
+
+	result := string(doc.GetFile(DocumentXml))
+	expected := `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Visit </w:t></w:r>` +
+		`<w:hyperlink xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:id="` + rels[0].ID + `" w:history="1">` +
+		`<w:r><w:rPr><w:rStyle w:val="Hyperlink"/><w:b/></w:rPr><w:t xml:space="preserve">our website</w:t></w:r></w:hyperlink>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> today</w:t></w:r>`
+	if !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+	if err := checkWellFormed([]byte(result)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocument_ReplaceHyperlink_Errors(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:hyperlink r:id="rId1"><w:r><w:t>{homepage}</w:t></w:r></w:hyperlink></w:p>`),
+	})
+	if err := doc.ReplaceHyperlink("homepage", "link", "https://example.com"); err == nil {
+		t.Error("expected an error for a placeholder inside a hyperlink")
+	}
+	if err := doc.ReplaceHyperlink("homepage", "link", "example"); err == nil {
+		t.Error("expected an error for a relative url")
+	}
+}
+
+func TestDocument_Hyperlinks(t *testing.T) {
+	rels := func(content string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + content + `</Relationships>`
+	}
+	body := `<w:p><w:r><w:t>intro</w:t></w:r></w:p>` +
+		`<w:p><w:hyperlink r:id="rId1"><w:r><w:t xml:space="preserve">our </w:t></w:r><w:r><w:t>site</w:t></w:r></w:hyperlink>` +
+		`<w:hyperlink w:anchor="terms"><w:r><w:t>terms</w:t></w:r></w:hyperlink></w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> HYPERLINK "https://exa</w:instrText></w:r>` +
+		`<w:r><w:instrText>mple.org/docs" \o "Docs"</w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r>` +
+		`<w:r><w:t>docs</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:fldSimple w:instr=" HYPERLINK &quot;mailto:info@example.com&quot; "><w:r><w:t>mail us</w:t></w:r></w:fldSimple></w:p>`
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<w:p><w:hyperlink r:id="rId7"><w:r><w:t>imprint</w:t></w:r></w:hyperlink></w:p></w:ftr>`
+	footnotes := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:footnote w:id="1"><w:p>` +
+		`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>HYPERLINK https://example.net/source</w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>source</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r>` +
+		`</w:p></w:footnote></w:footnotes>`
+
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:                  testDocumentXml(body),
+		RelsPath(DocumentXml):        rels(`<Relationship Id="rId1" Type="` + HyperlinkRelationshipType + `" Target="https://example.com/?a=1&amp;b=2" TargetMode="External"/>`),
+		"word/footer1.xml":           footer,
+		RelsPath("word/footer1.xml"): rels(`<Relationship Id="rId7" Type="` + HyperlinkRelationshipType + `" Target="https://example.com/imprint" TargetMode="External"/>`),
+		FootnotesXml:                 footnotes,
+	})
+
+	links, err := doc.Hyperlinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Hyperlink{
+		{URL: "https://example.com/?a=1&b=2", Text: "our site", Kind: HyperlinkExternal, Part: DocumentXml, Paragraph: 1},
+		{Anchor: "terms", Text: "terms", Kind: HyperlinkAnchor, Part: DocumentXml, Paragraph: 1},
+		{URL: "https://example.org/docs", Text: "docs", Kind: HyperlinkExternal, Part: DocumentXml, Paragraph: 2, Field: true},
+		{URL: "mailto:info@example.com", Text: "mail us", Kind: HyperlinkMailto, Part: DocumentXml, Paragraph: 3, Field: true},
+		{URL: "https://example.com/imprint", Text: "imprint", Kind: HyperlinkExternal, Part: "word/footer1.xml", Paragraph: 0},
+		{URL: "https://example.net/source", Text: "source", Kind: HyperlinkExternal, Part: FootnotesXml, Paragraph: 0, Field: true},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links, got %d: %+v", len(expected), len(links), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Errorf("link %d: got %+v, expected %+v", i, links[i], expected[i])
+		}
+	}
+
+	err = doc.RewriteHyperlinks(func(url string) string {
+		if strings.HasPrefix(url, "mailto:") {
+			return url
+		}
+		if strings.Contains(url, "?") {
+			return url + "&utm_source=docx"
+		}
+		return url + "?utm_source=docx"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = reopen(t, doc)
+	links, err = doc.Hyperlinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten := []string{"https://example.com/?a=1&b=2&utm_source=docx", "", "https://example.org/docs?utm_source=docx",
+		"mailto:info@example.com", "https://example.com/imprint?utm_source=docx", "https://example.net/source?utm_source=docx"}
+	for i, url := range rewritten {
+		if i < len(links) && links[i].URL != url {
+			t.Errorf("link %d: got %s, expected %s", i, links[i].URL, url)
+		}
+	}
+	instruction := `<w:instrText xml:space="preserve"> HYPERLINK &#34;https://example.org/docs?utm_source=docx&#34; \o &#34;Docs&#34;</w:instrText></w:r>` +
+		`<w:r><w:instrText></w:instrText></w:r>`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, instruction) {
+		t.Errorf("unexpected field instruction %s", result)
+	}
+	if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
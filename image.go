@@ -0,0 +1,245 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register the JPEG decoder for image.DecodeConfig
+	_ "image/png"  // register the PNG decoder for image.DecodeConfig
+	"regexp"
+	"strconv"
+
+	"github.com/lukasjarosch/go-docx/measure"
+)
+
+const (
+	// ImageRelationshipType is the relationship type of embedded images.
+	ImageRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+
+	// EMUPerInch is the amount of English Metric Units (EMU) per inch. All DrawingML sizes are in EMU.
+	EMUPerInch = 914400
+	// EMUPerCentimeter is the amount of English Metric Units (EMU) per centimeter.
+	EMUPerCentimeter = 360000
+	// EMUPerPixel is the amount of English Metric Units (EMU) per pixel at 96 DPI.
+	EMUPerPixel = 9525
+)
+
+var (
+	// DrawingPropertiesIdRegex matches the id of the non-visual drawing properties (<wp:docPr id="1">),
+	// which must be unique throughout a part.
+	DrawingPropertiesIdRegex = regexp.MustCompile(`<wp:docPr\s[^>]*?\bid\s*=\s*["']([0-9]+)["']`)
+
+	// imageFormats maps the format names of image.DecodeConfig to the file extension and content type of the part
+	imageFormats = map[string]struct {
+		Extension   string
+		ContentType string
+	}{
+		"png":  {"png", "image/png"},
+		"jpeg": {"jpeg", "image/jpeg"},
+	}
+)
+
+// ImageSize is the size in EMU at which an image is displayed.
+// If only one of the dimensions is set, the other one is computed by preserving the aspect ratio of the image.
+// If neither is set, the intrinsic size of the image at 96 DPI is used.
+type ImageSize struct {
+	Width  int64
+	Height int64
+}
+
+// NewImageSize returns the size of an image in any unit, e.g. NewImageSize(measure.Cm(4), nil).
+// A nil dimension is computed from the other one, see ImageSize.
+func NewImageSize(width, height measure.Length) ImageSize {
+	return ImageSize{Width: int64(measure.ToEMU(width)), Height: int64(measure.ToEMU(height))}
+}
+
+// Inches converts the given amount of inches to EMU.
+//
+// Deprecated: use NewImageSize with measure.Inches instead.
+func Inches(inches float64) int64 {
+	return int64(inches * EMUPerInch)
+}
+
+// Centimeters converts the given amount of centimeters to EMU.
+//
+// Deprecated: use NewImageSize with measure.Cm instead.
+func Centimeters(cm float64) int64 {
+	return int64(cm * EMUPerCentimeter)
+}
+
+// ImageDimensions decodes the header of the given PNG or JPEG image and returns its size in pixels
+// together with the detected format ('png' or 'jpeg').
+func ImageDimensions(data []byte) (width, height int, format string, err error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("unable to decode image: %w", err)
+	}
+	if _, supported := imageFormats[format]; !supported {
+		return 0, 0, "", fmt.Errorf("unsupported image format %s", format)
+	}
+	return config.Width, config.Height, format, nil
+}
+
+// resolve returns the final size of an image with the given intrinsic pixel dimensions.
+func (s ImageSize) resolve(width, height int) ImageSize {
+	switch {
+	case s.Width > 0 && s.Height > 0:
+		return s
+	case s.Width > 0:
+		return ImageSize{Width: s.Width, Height: s.Width * int64(height) / int64(width)}
+	case s.Height > 0:
+		return ImageSize{Width: s.Height * int64(width) / int64(height), Height: s.Height}
+	default:
+		return ImageSize{Width: int64(width) * EMUPerPixel, Height: int64(height) * EMUPerPixel}
+	}
+}
+
+// InsertImageAtPlaceholder replaces every occurrence of the placeholder key with the given PNG or JPEG image.
+// The image is displayed inline, at the position of the placeholder, using the given size.
+// The image is added to the package only once, every file which contains the placeholder references it.
+func (d *Document) InsertImageAtPlaceholder(key string, data []byte, size ImageSize) error {
+	return d.InsertImage(key, ImageReplacement{Data: data, Size: size})
+}
+
+// InsertImage replaces every occurrence of the placeholder key with the image of the replacement, placed according
+// to its layout (see ImageLayout). The image is added to the package only once, every file which contains the
+// placeholder references it.
+func (d *Document) InsertImage(key string, replacement ImageReplacement) error {
+	data := replacement.Data
+	width, height, format, err := ImageDimensions(data)
+	if err != nil {
+		return err
+	}
+	if width == 0 || height == 0 {
+		return fmt.Errorf("image has no dimensions")
+	}
+	key = RemovePlaceholderDelimiter(key)
+	if err := d.checkReplacementLimits(PlaceholderMap{key: nil}); err != nil {
+		return err
+	}
+	size := replacement.Size.resolve(width, height)
+
+	var mediaPart string
+	for name := range d.files {
+		if !d.containsPlaceholder(name, key) {
+			continue
+		}
+
+		if mediaPart == "" {
+			imageFormat := imageFormats[format]
+			mediaPart = d.uniquePartName("word/media/image1." + imageFormat.Extension)
+			if err := d.setPart(mediaPart, data); err != nil {
+				return err
+			}
+			if err := d.ensureContentType(mediaPart, imageFormat.ContentType); err != nil {
+				return err
+			}
+		}
+
+		relID, err := d.addRelationship(name, Relationship{
+			Type:   ImageRelationshipType,
+			Target: relativeTarget(name, mediaPart),
+		})
+		if err != nil {
+			return err
+		}
+
+		value := &inlineImage{
+			RelationshipID: relID,
+			Size:           size,
+			Layout:         replacement.Layout,
+			nextID:         maxDrawingID(d.GetFile(name)) + 1,
+		}
+		if replacement.Layout == FullWidth || replacement.Layout == FloatRightHalf {
+			value.sizes = d.layoutSizes(name, key, replacement.Layout, width, height)
+		}
+		var placeholderValue interface{} = value
+		if replacement.Layout == InlineCentered {
+			placeholderValue = centeredImage{value}
+		}
+		changedBytes, err := d.replace(PlaceholderMap{key: placeholderValue}, name)
+		if err != nil {
+			return err
+		}
+		if err := d.SetFile(name, changedBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsPlaceholder returns true if the given file contains the placeholder with the given key.
+func (d *Document) containsPlaceholder(file, key string) bool {
+	for _, placeholder := range d.filePlaceholders[file] {
+		if placeholder.Text(d.GetFile(file)) == AddPlaceholderDelimiter(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDrawingID returns the highest id of all drawings inside data.
+func maxDrawingID(data []byte) int {
+	maxID := 0
+	for _, match := range DrawingPropertiesIdRegex.FindAllSubmatch(data, -1) {
+		if id, _ := strconv.Atoi(string(match[1])); id > maxID {
+			maxID = id
+		}
+	}
+	return maxID
+}
+
+// inlineImage is the MarkupValue of an image which is displayed inline, or floating (see FloatRightHalf).
+// Every call to Markup allocates a new drawing id, starting with nextID, and uses the next of the sizes.
+type inlineImage struct {
+	RelationshipID string
+	Size           ImageSize
+	Layout         ImageLayout
+	nextID         int
+	// sizes of the occurrences of the placeholder, if they depend on their section
+	sizes []ImageSize
+}
+
+// Markup closes the text of the run, inserts the drawing and reopens the text.
+// All namespaces are declared locally as the document may not declare them.
+func (img *inlineImage) Markup(string) string {
+	id, size := img.next()
+	if img.Layout == FloatRightHalf {
+		return "</w:t>" + anchoredDrawing(id, img.RelationshipID, size) + "<w:t>"
+	}
+	return "</w:t>" + inlineDrawing(id, img.RelationshipID, size) + "<w:t>"
+}
+
+// next allocates the drawing id and returns the size of the next occurrence.
+func (img *inlineImage) next() (int, ImageSize) {
+	id := img.nextID
+	img.nextID++
+	size := img.Size
+	if len(img.sizes) > 0 {
+		size, img.sizes = img.sizes[0], img.sizes[1:]
+	}
+	return id, size
+}
+
+// inlineDrawing returns the drawing (<w:drawing>) of an inline picture.
+func inlineDrawing(id int, relationshipID string, size ImageSize) string {
+	return fmt.Sprintf(`<w:drawing>`+
+		`<wp:inline distT="0" distB="0" distL="0" distR="0" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing">`+
+		`<wp:extent cx="%[1]d" cy="%[2]d"/>`+
+		`<wp:docPr id="%[3]d" name="Picture %[3]d"/>`+
+		`%[4]s</wp:inline></w:drawing>`, size.Width, size.Height, id, pictureGraphic(id, relationshipID, size))
+}
+
+// pictureGraphic returns the frame properties and graphic of a picture, which follow the non-visual properties of
+// inline and anchored drawings.
+func pictureGraphic(id int, relationshipID string, size ImageSize) string {
+	return fmt.Sprintf(`<wp:cNvGraphicFramePr><a:graphicFrameLocks xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">`+
+		`<a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:nvPicPr><pic:cNvPr id="%[3]d" name="Picture %[3]d"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:embed="%[4]s"/>`+
+		`<a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[1]d" cy="%[2]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic>`, size.Width, size.Height, id, relationshipID)
+}
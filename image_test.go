@@ -0,0 +1,122 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/lukasjarosch/go-docx/measure"
+)
+
+func testImage(t *testing.T, format string, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	buf := new(bytes.Buffer)
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(buf, img)
+	case "jpeg":
+		err = jpeg.Encode(buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageSize_resolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     ImageSize
+		expected ImageSize
+	}{
+		{"intrinsic size", ImageSize{}, ImageSize{200 * EMUPerPixel, 100 * EMUPerPixel}},
+		{"width only", ImageSize{Width: Inches(4)}, ImageSize{Inches(4), Inches(2)}},
+		{"height only", ImageSize{Height: Centimeters(3)}, ImageSize{Centimeters(6), Centimeters(3)}},
+		{"explicit override", ImageSize{Inches(1), Inches(1)}, ImageSize{Inches(1), Inches(1)}},
+		{"typed units", NewImageSize(measure.Mm(30), nil), ImageSize{Centimeters(3), Centimeters(1.5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if size := tt.size.resolve(200, 100); size != tt.expected {
+				t.Errorf("unexpected size, want=%v, have=%v", tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestDocument_InsertImageAtPlaceholder(t *testing.T) {
+	tests := []struct {
+		format      string
+		contentType string
+	}{
+		{"png", "image/png"},
+		{"jpeg", "image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Logo: {logo}</w:t></w:r></w:p><w:p><w:r><w:t>{logo}</w:t></w:r></w:p>`),
+			})
+			data := testImage(t, tt.format, 300, 150)
+
+			err := doc.InsertImageAtPlaceholder("logo", data, ImageSize{Width: Inches(2)})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			doc = reopen(t, doc)
+
+			document := string(doc.GetFile(DocumentXml))
+			if strings.Contains(document, "{logo}") {
+				t.Error("placeholder was not replaced")
+			}
+			extent := fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, Inches(2), Inches(1))
+			if strings.Count(document, extent) != 2 {
+				t.Errorf("expected two images with extent %s in %s", extent, document)
+			}
+			if !strings.Contains(document, `<wp:docPr id="1"`) || !strings.Contains(document, `<wp:docPr id="2"`) {
+				t.Error("drawing ids are not unique")
+			}
+			if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+				t.Error(err)
+			}
+
+			rels, err := doc.Relationships(DocumentXml)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(rels) != 1 || rels[0].Type != ImageRelationshipType || rels[0].Target != "media/image1."+tt.format {
+				t.Errorf("unexpected relationships %+v", rels)
+				return
+			}
+			if !strings.Contains(document, `r:embed="`+rels[0].ID+`"`) {
+				t.Error("image does not reference the relationship")
+			}
+
+			media, err := doc.getPart("word/media/image1." + tt.format)
+			if err != nil || !bytes.Equal(media, data) {
+				t.Errorf("media part was not written: %v", err)
+			}
+			contentType, err := doc.ContentType("word/media/image1." + tt.format)
+			if err != nil || contentType != tt.contentType {
+				t.Errorf("unexpected content type, want=%s, have=%s", tt.contentType, contentType)
+			}
+		})
+	}
+}
+
+func TestDocument_InsertImageAtPlaceholder_InvalidImage(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{logo}</w:t></w:r></w:p>`),
+	})
+	if err := doc.InsertImageAtPlaceholder("logo", []byte("GIF89a"), ImageSize{}); err == nil {
+		t.Error("expected an error for an unsupported image")
+	}
+}
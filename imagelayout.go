@@ -0,0 +1,89 @@
+package docx
+
+import (
+	"fmt"
+
+	"github.com/lukasjarosch/go-docx/measure"
+)
+
+// ImageLayout defines how an image replaces its placeholder, see ImageReplacement.
+type ImageLayout int
+
+const (
+	// Inline displays the image inline at the position of the placeholder, using the size of the replacement.
+	Inline ImageLayout = iota
+	// InlineCentered displays the image inline in a centered paragraph of its own, using the size of the replacement.
+	// The paragraph of the placeholder is split like it is for a BlockValue.
+	InlineCentered
+	// FullWidth displays the image inline at the position of the placeholder, as wide as the text of its section
+	// (the page width minus the margins).
+	FullWidth
+	// FloatRightHalf displays the image at the right of the column, half as wide as the text of its section.
+	// The image is anchored to the paragraph of the placeholder and the text wraps around it (square wrapping).
+	FloatRightHalf
+)
+
+// ImageReplacement is an image which replaces a placeholder, see InsertImage.
+type ImageReplacement struct {
+	// Data is the PNG or JPEG image.
+	Data []byte
+	// Size is the size of the image for the Inline and InlineCentered layouts, see ImageSize. The other layouts
+	// compute the width from the section and preserve the aspect ratio of the image.
+	Size ImageSize
+	// Layout is the placement of the image, Inline by default.
+	Layout ImageLayout
+}
+
+// centeredImage is the BlockValue of an image which is displayed in a centered paragraph of its own.
+type centeredImage struct {
+	*inlineImage
+}
+
+// BlockMarkup returns the centered paragraph containing the drawing.
+func (img centeredImage) BlockMarkup(int) string {
+	id, size := img.next()
+	return `<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r>` + inlineDrawing(id, img.RelationshipID, size) + `</w:r></w:p>`
+}
+
+// anchoredDrawing returns the drawing (<w:drawing>) of a picture which floats at the right of the column and is
+// wrapped by the text on both sides.
+func anchoredDrawing(id int, relationshipID string, size ImageSize) string {
+	return fmt.Sprintf(`<w:drawing>`+
+		`<wp:anchor distT="0" distB="0" distL="114300" distR="114300" simplePos="0" relativeHeight="%[4]d" behindDoc="0" `+
+		`locked="0" layoutInCell="1" allowOverlap="1" xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing">`+
+		`<wp:simplePos x="0" y="0"/>`+
+		`<wp:positionH relativeFrom="column"><wp:align>right</wp:align></wp:positionH>`+
+		`<wp:positionV relativeFrom="paragraph"><wp:posOffset>0</wp:posOffset></wp:positionV>`+
+		`<wp:extent cx="%[1]d" cy="%[2]d"/>`+
+		`<wp:effectExtent l="0" t="0" r="0" b="0"/>`+
+		`<wp:wrapSquare wrapText="bothSides"/>`+
+		`<wp:docPr id="%[3]d" name="Picture %[3]d"/>`+
+		`%[5]s</wp:anchor></w:drawing>`, size.Width, size.Height, id, 251658240+id, pictureGraphic(id, relationshipID, size))
+}
+
+// layoutSizes returns the sizes of the occurrences of the placeholder key inside the file for the layout, in the
+// order of the placeholders of the file. The width is taken from the section of the placeholder, which ends with
+// the next section properties. Parts without sections (e.g. headers) use the page setup of the document.
+func (d *Document) layoutSizes(file, key string, layout ImageLayout, width, height int) []ImageSize {
+	data := d.GetFile(file)
+	sections := SectionPropertiesRegex.FindAllIndex(data, -1)
+	var sizes []ImageSize
+	for _, placeholder := range d.filePlaceholders[file] {
+		if placeholder.Text(data) != AddPlaceholderDelimiter(key) {
+			continue
+		}
+		setup := d.PageSetup()
+		for _, loc := range sections {
+			if int64(loc[0]) > placeholder.StartPos() {
+				setup = ParsePageSetup(data[loc[0]:loc[1]])
+				break
+			}
+		}
+		available := int64(measure.ToEMU(measure.Twips(setup.ContentWidth())))
+		if layout == FloatRightHalf {
+			available /= 2
+		}
+		sizes = append(sizes, ImageSize{Width: available}.resolve(width, height))
+	}
+	return sizes
+}
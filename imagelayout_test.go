@@ -0,0 +1,86 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDocument_InsertImage(t *testing.T) {
+	pages := []struct {
+		name   string
+		sectPr string
+		// content width in EMU
+		width int64
+	}{
+		{"A4", `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr>`, 9026 * 635},
+		{"Letter", `<w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1800" w:bottom="1440" w:left="1800"/></w:sectPr>`, 8640 * 635},
+	}
+	tests := []struct {
+		name     string
+		layout   ImageLayout
+		expected func(width int64) []string
+	}{
+		{"inline centered", InlineCentered, func(int64) []string {
+			return []string{
+				`<w:p><w:r><w:t xml:space="preserve">Figure 1: </w:t></w:r></w:p><w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:drawing><wp:inline `,
+				fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, Inches(2), Inches(1)),
+				`</wp:inline></w:drawing></w:r></w:p><w:p><w:r><w:t xml:space="preserve"> shows the results.</w:t></w:r></w:p>`,
+			}
+		}},
+		{"full width", FullWidth, func(width int64) []string {
+			return []string{
+				`<w:t xml:space="preserve">Figure 1: </w:t><w:drawing><wp:inline `,
+				fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/>`, width, width/2),
+			}
+		}},
+		{"float right half", FloatRightHalf, func(width int64) []string {
+			return []string{
+				`<w:drawing><wp:anchor distT="0" distB="0" distL="114300" distR="114300" simplePos="0" `,
+				`<wp:positionH relativeFrom="column"><wp:align>right</wp:align></wp:positionH>`,
+				fmt.Sprintf(`<wp:extent cx="%d" cy="%d"/><wp:effectExtent l="0" t="0" r="0" b="0"/><wp:wrapSquare wrapText="bothSides"/>`, width/2, width/4),
+			}
+		}},
+	}
+	for _, page := range pages {
+		for _, tt := range tests {
+			t.Run(page.name+" "+tt.name, func(t *testing.T) {
+				doc := openTestDocx(t, map[string]string{
+					DocumentXml: testDocumentXml(`<w:p><w:r><w:t xml:space="preserve">Figure 1: {chart} shows the results.</w:t></w:r></w:p>` + page.sectPr),
+				})
+				replacement := ImageReplacement{Data: testImage(t, "png", 400, 200), Size: ImageSize{Width: Inches(2)}, Layout: tt.layout}
+				if err := doc.InsertImage("chart", replacement); err != nil {
+					t.Fatal(err)
+				}
+				if err := doc.Validate(); err != nil {
+					t.Fatal(err)
+				}
+				document := string(reopen(t, doc).GetFile(DocumentXml))
+				for _, expected := range tt.expected(page.width) {
+					if !strings.Contains(document, expected) {
+						t.Errorf("expected %s in %s", expected, document)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDocument_InsertImage_SectionWidths(t *testing.T) {
+	// the first section is a landscape A4 page, the last one is a portrait A4 page
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{chart}</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:sectPr><w:pgSz w:w="16838" w:h="11906" w:orient="landscape"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr></w:pPr></w:p>` +
+			`<w:p><w:r><w:t>{chart}</w:t></w:r></w:p>` +
+			`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr>`),
+	})
+	if err := doc.InsertImage("chart", ImageReplacement{Data: testImage(t, "png", 400, 200), Layout: FullWidth}); err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	landscape, portrait := int64(13958*635), int64(9026*635)
+	first, last := fmt.Sprintf(`<wp:extent cx="%d"`, landscape), fmt.Sprintf(`<wp:extent cx="%d"`, portrait)
+	if i, j := strings.Index(document, first), strings.Index(document, last); i < 0 || j < i {
+		t.Errorf("expected the widths %d and %d of the sections in %s", landscape, portrait, document)
+	}
+}
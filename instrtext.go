@@ -0,0 +1,28 @@
+package docx
+
+import (
+	"regexp"
+)
+
+var (
+	// FieldInstructionRegex matches a field instruction (<w:instrText>...</w:instrText>)
+	FieldInstructionRegex = regexp.MustCompile(`<w:instrText(?:\s[^>]*)?>[^<]*</w:instrText\s*>`)
+)
+
+// WithFieldInstructionReplacement replaces placeholders inside field instructions (<w:instrText>) as well, e.g. in
+// the target of an INCLUDEPICTURE field. By default field instructions are skipped: they are not display text and
+// replacing inside them easily breaks the field. Placeholders inside field instructions are then neither replaced
+// nor reported as missing.
+//
+// The values are inserted like in text runs, they should be single-line text which is valid inside the instruction,
+// e.g. without unbalanced quotes.
+func WithFieldInstructionReplacement() Option {
+	return func(d *Document) {
+		d.replaceFieldInstructions = true
+	}
+}
+
+// withoutFieldInstructions returns a copy of the data without field instructions, the data itself is not modified.
+func withoutFieldInstructions(data []byte) []byte {
+	return FieldInstructionRegex.ReplaceAll(data, nil)
+}
@@ -0,0 +1,50 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_FieldInstructions(t *testing.T) {
+	body := `<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+		`<w:r><w:instrText xml:space="preserve"> INCLUDEPICTURE "{url}" \d </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>{url}</w:t></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>`
+
+	tests := []struct {
+		name        string
+		options     []Option
+		instruction string
+	}{
+		{
+			name:        "skipped by default",
+			instruction: `<w:instrText xml:space="preserve"> INCLUDEPICTURE "{url}" \d </w:instrText>`,
+		},
+		{
+			name:        "explicitly requested",
+			options:     []Option{WithFieldInstructionReplacement()},
+			instruction: `<w:instrText xml:space="preserve"> INCLUDEPICTURE "https://example.com/logo.png" \d </w:instrText>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"url": "https://example.com/logo.png"}); err != nil {
+				t.Fatal(err)
+			}
+			document := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(document, tt.instruction) {
+				t.Errorf("expected %s in %s", tt.instruction, document)
+			}
+			if !strings.Contains(document, `<w:t>https://example.com/logo.png</w:t>`) {
+				t.Errorf("the display text was not replaced: %s", document)
+			}
+			if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
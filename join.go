@@ -0,0 +1,67 @@
+package docx
+
+import (
+	"strings"
+)
+
+const (
+	// DefaultJoinSeparator is the separator of []string values, see WithJoinSeparator.
+	DefaultJoinSeparator = ", "
+	// LineBreakSeparator puts every joined value on its own line, separated by line breaks (<w:br/>).
+	LineBreakSeparator = "\n"
+)
+
+// JoinedValue is a list of values which replaces a placeholder joined by the separator, e.g. the tags of an article
+// joined by " · ". Unlike []string values, which are joined by the separator of the document (see WithJoinSeparator),
+// every JoinedValue has its own separator.
+type JoinedValue struct {
+	Values    []string
+	Separator string
+}
+
+// Join returns a JoinedValue of the values with the given separator.
+func Join(separator string, values ...string) JoinedValue {
+	return JoinedValue{Values: values, Separator: separator}
+}
+
+// String returns the joined values.
+func (v JoinedValue) String() string {
+	return strings.Join(v.Values, v.Separator)
+}
+
+// WithJoinSeparator sets the separator which joins the elements of []string values, DefaultJoinSeparator by default.
+// With LineBreakSeparator (or any separator which contains "\n") the values are separated by line breaks, just like
+// multi-line text values.
+func WithJoinSeparator(separator string) Option {
+	return func(d *Document) {
+		d.joinSeparator = separator
+	}
+}
+
+// joinValues returns a copy of the map in which all []string and JoinedValue values are joined to text.
+// The map itself is returned if it contains no such values.
+func (d *Document) joinValues(placeholderMap PlaceholderMap) PlaceholderMap {
+	var joined PlaceholderMap
+	for key, value := range placeholderMap {
+		var text string
+		switch v := value.(type) {
+		case []string:
+			text = strings.Join(v, d.joinSeparator)
+		case JoinedValue:
+			text = v.String()
+		default:
+			continue
+		}
+		if joined == nil {
+			joined = make(PlaceholderMap, len(placeholderMap))
+			for k, v := range placeholderMap {
+				joined[k] = v
+			}
+		}
+		joined[key] = text
+	}
+	if joined == nil {
+		return placeholderMap
+	}
+	return joined
+}
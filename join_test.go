@@ -0,0 +1,84 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_JoinedValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		value    interface{}
+		expected string
+		text     string
+	}{
+		{
+			name:     "inline",
+			value:    []string{"go", "docx", "templates"},
+			expected: `<w:t>Tags: go, docx, templates</w:t>`,
+			text:     "Tags: go, docx, templates\n",
+		},
+		{
+			name:     "custom separator",
+			options:  []Option{WithJoinSeparator(" | ")},
+			value:    []string{"go", "docx"},
+			expected: `<w:t>Tags: go | docx</w:t>`,
+			text:     "Tags: go | docx\n",
+		},
+		{
+			name:     "multi-line",
+			options:  []Option{WithJoinSeparator(LineBreakSeparator)},
+			value:    []string{"go", "docx", "templates"},
+			expected: `<w:t>Tags: go</w:t><w:br/><w:t>docx</w:t><w:br/><w:t>templates</w:t>`,
+			text:     "Tags: go\ndocx\ntemplates\n",
+		},
+		{
+			name:     "joined value overrides the separator",
+			value:    Join(LineBreakSeparator, "go", "docx"),
+			expected: `<w:t>Tags: go</w:t><w:br/><w:t>docx</w:t>`,
+			text:     "Tags: go\ndocx\n",
+		},
+		{
+			name:     "empty",
+			value:    []string{},
+			expected: `<w:t>Tags: </w:t>`,
+			text:     "Tags: \n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Tags: {tags}</w:t></w:r></w:p>`),
+			}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"tags": tt.value}); err != nil {
+				t.Fatal(err)
+			}
+			if document := string(doc.GetFile(DocumentXml)); !strings.Contains(document, tt.expected) {
+				t.Errorf("expected %s in %s", tt.expected, document)
+			}
+			if text, _ := doc.PlainText(); text != tt.text {
+				t.Errorf("want=%q, have=%q", tt.text, text)
+			}
+		})
+	}
+}
+
+func TestDocument_Render_JoinedValues(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{categories}</w:t></w:r></w:p>`),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every element is escaped
+	if _, err := doc.render("article", map[string]interface{}{"categories": []string{"R&D", "<ops>"}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<w:t>R&amp;D, &lt;ops&gt;</w:t>`; !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+		t.Errorf("expected %s in %s", expected, doc.GetFile(DocumentXml))
+	}
+}
@@ -0,0 +1,173 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// UndeterminedLanguage is reported for text whose language is neither declared nor detected (BCP 47 'und').
+	UndeterminedLanguage = "und"
+)
+
+var (
+	// LanguageTagRegex matches the language property of run properties (<w:lang w:val="en-US"/>)
+	LanguageTagRegex = regexp.MustCompile(`<w:lang(?:\s[^>]*)?/?>`)
+	// ParagraphStyleTagRegex matches the style reference of a paragraph (<w:pStyle w:val="Heading1"/>)
+	ParagraphStyleTagRegex = regexp.MustCompile(`<w:pStyle(?:\s[^>]*)?/?>`)
+	// RunStyleTagRegex matches the style reference of a run (<w:rStyle w:val="Emphasis"/>)
+	RunStyleTagRegex = regexp.MustCompile(`<w:rStyle(?:\s[^>]*)?/?>`)
+)
+
+// LanguageDetector detects the language of text which does not declare a language.
+// It returns a BCP 47 language tag such as 'en-US' or an empty string if the language is unknown.
+type LanguageDetector interface {
+	DetectLanguage(text string) string
+}
+
+// WithLanguageDetector configures the detector which DetectLanguages consults for runs without a declared language.
+func WithLanguageDetector(detector LanguageDetector) Option {
+	return func(d *Document) {
+		d.languageDetector = detector
+	}
+}
+
+// LanguageUsage describes how much text of a single language the document contains.
+type LanguageUsage struct {
+	Language string
+	// Characters is the amount of characters (runes) in the language.
+	Characters int
+	// Paragraphs are the zero-based indices of all paragraphs of the main document in which the language appears.
+	Paragraphs []int
+}
+
+// styleLanguages is used to unmarshal the language definitions of the styles part
+type styleLanguages struct {
+	Default struct {
+		Val string `xml:"val,attr"`
+	} `xml:"docDefaults>rPrDefault>rPr>lang"`
+	Styles []struct {
+		ID      string `xml:"styleId,attr"`
+		BasedOn struct {
+			Val string `xml:"val,attr"`
+		} `xml:"basedOn"`
+		Lang struct {
+			Val string `xml:"val,attr"`
+		} `xml:"rPr>lang"`
+	} `xml:"style"`
+}
+
+// DetectLanguages reports which languages the text of the main document is written in.
+// The language of every run is taken from its own properties, falling back to the run style, the paragraph style
+// and finally the document defaults. If neither declares a language, the LanguageDetector is consulted (see
+// WithLanguageDetector). Text whose language remains unknown is reported as UndeterminedLanguage.
+//
+// The result is sorted by the amount of characters, the most used language comes first.
+func (d *Document) DetectLanguages() ([]LanguageUsage, error) {
+	var styles styleLanguages
+	if d.partExists(StylesXml) {
+		data, err := d.getPart(StylesXml)
+		if err != nil {
+			return nil, err
+		}
+		if err := xml.Unmarshal(data, &styles); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %s", StylesXml, err)
+		}
+	}
+	styleLanguage := styles.resolver()
+
+	usages := make(map[string]*LanguageUsage)
+	for index, paragraph := range ParagraphRegex.FindAll(d.GetFile(DocumentXml), -1) {
+		paragraphStyle := tagValue(ParagraphStyleTagRegex, paragraph)
+
+		for _, run := range RunRegex.FindAll(paragraph, -1) {
+			var text string
+			for _, match := range TextContentRegex.FindAllSubmatch(run, -1) {
+				text += html.UnescapeString(string(match[1]))
+			}
+			if text == "" {
+				continue
+			}
+
+			props := ""
+			if match := RunPropertiesRegex.FindSubmatch(run); match != nil {
+				props = string(match[1])
+			}
+			language := tagValue(LanguageTagRegex, []byte(props))
+			if language == "" {
+				language = styleLanguage(tagValue(RunStyleTagRegex, []byte(props)))
+			}
+			if language == "" {
+				language = styleLanguage(paragraphStyle)
+			}
+			if language == "" {
+				language = styles.Default.Val
+			}
+			if language == "" && d.languageDetector != nil {
+				language = d.languageDetector.DetectLanguage(text)
+			}
+			if language == "" {
+				language = UndeterminedLanguage
+			}
+
+			usage, exists := usages[language]
+			if !exists {
+				usage = &LanguageUsage{Language: language}
+				usages[language] = usage
+			}
+			usage.Characters += utf8.RuneCountInString(text)
+			if n := len(usage.Paragraphs); n == 0 || usage.Paragraphs[n-1] != index {
+				usage.Paragraphs = append(usage.Paragraphs, index)
+			}
+		}
+	}
+
+	result := make([]LanguageUsage, 0, len(usages))
+	for _, usage := range usages {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Characters != result[j].Characters {
+			return result[i].Characters > result[j].Characters
+		}
+		return result[i].Language < result[j].Language
+	})
+	return result, nil
+}
+
+// resolver returns a function which resolves the language of a style, following the styles it is based on.
+func (s styleLanguages) resolver() func(styleID string) string {
+	languages := make(map[string]string)
+	basedOn := make(map[string]string)
+	for _, style := range s.Styles {
+		languages[style.ID] = style.Lang.Val
+		basedOn[style.ID] = style.BasedOn.Val
+	}
+
+	return func(styleID string) string {
+		visited := make(map[string]bool)
+		for styleID != "" && !visited[styleID] {
+			if language := languages[styleID]; language != "" {
+				return language
+			}
+			visited[styleID] = true
+			styleID = basedOn[styleID]
+		}
+		return ""
+	}
+}
+
+// tagValue returns the w:val attribute of the first tag inside data which matches the given regex.
+func tagValue(tagRegex *regexp.Regexp, data []byte) string {
+	tag := tagRegex.Find(data)
+	if tag == nil {
+		return ""
+	}
+	value, _ := attributeValue(tag, "w:val")
+	return value
+}
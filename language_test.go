@@ -0,0 +1,67 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testLanguageDetector struct{}
+
+func (testLanguageDetector) DetectLanguage(text string) string {
+	if strings.Contains(text, "bonjour") {
+		return "fr-FR"
+	}
+	return ""
+}
+
+func TestDocument_DetectLanguages(t *testing.T) {
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(
+			`<w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:rPr><w:lang w:val="de-DE"/></w:rPr><w:t>Hallo</w:t></w:r></w:p>` +
+				`<w:p><w:pPr><w:pStyle w:val="Quote"/></w:pPr><w:r><w:t>Hola</w:t></w:r></w:p>` +
+				`<w:p><w:r><w:rPr><w:rStyle w:val="German"/></w:rPr><w:t>Tsch&#252;ss</w:t></w:r></w:p>`),
+		StylesXml: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="en-US" w:eastAsia="ja-JP"/></w:rPr></w:rPrDefault></w:docDefaults>` +
+			`<w:style w:type="paragraph" w:styleId="Spanish"><w:rPr><w:lang w:val="es-ES"/></w:rPr></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Quote"><w:basedOn w:val="Spanish"/></w:style>` +
+			`<w:style w:type="character" w:styleId="German"><w:rPr><w:lang w:val="de-DE"/></w:rPr></w:style>` +
+			`</w:styles>`,
+	}
+
+	usages, err := openTestDocx(t, parts).DetectLanguages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LanguageUsage{
+		{Language: "de-DE", Characters: 12, Paragraphs: []int{0, 2}},
+		{Language: "en-US", Characters: 5, Paragraphs: []int{0}},
+		{Language: "es-ES", Characters: 4, Paragraphs: []int{1}},
+	}
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("unexpected languages\nwant=%+v\nhave=%+v", expected, usages)
+	}
+}
+
+func TestDocument_DetectLanguages_Detector(t *testing.T) {
+	data := newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>bonjour</w:t></w:r></w:p><w:p><w:r><w:t>???</w:t></w:r></w:p>`),
+	})
+	doc, err := OpenBytes(data, WithLanguageDetector(testLanguageDetector{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usages, err := doc.DetectLanguages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LanguageUsage{
+		{Language: "fr-FR", Characters: 7, Paragraphs: []int{0}},
+		{Language: UndeterminedLanguage, Characters: 3, Paragraphs: []int{1}},
+	}
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("unexpected languages\nwant=%+v\nhave=%+v", expected, usages)
+	}
+}
@@ -0,0 +1,164 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrMaxReplacementsExceeded is returned if a placeholder occurs more often than the configured maximum.
+	ErrMaxReplacementsExceeded = errors.New("maximum amount of replacements exceeded")
+	// ErrComplexityLimitExceeded is returned if the document exceeds the configured ComplexityLimits
+	// and the limits are configured to fail.
+	ErrComplexityLimitExceeded = errors.New("document complexity limit exceeded")
+	// ErrArchiveTooLarge is returned by Open and OpenBytes if the uncompressed size of the archive exceeds the
+	// maximum, see WithMaxUncompressedSize.
+	ErrArchiveTooLarge = errors.New("uncompressed archive size exceeds the maximum")
+)
+
+// WithMaxUncompressedSize rejects documents whose parts are larger than max bytes in total when uncompressed, before
+// anything is decompressed. The sizes are taken from the headers of the archive, see UncompressedSize.
+// A limit <= 0 means unlimited, which is the default.
+func WithMaxUncompressedSize(max int64) Option {
+	return func(d *Document) {
+		d.maxUncompressedSize = max
+	}
+}
+
+// WithMaxRunNesting limits how deep runs may be nested in the parsed files, which protects against crafted documents
+// with pathological nesting. Deeper nested runs make Open and OpenBytes fail with ErrRunNestingTooDeep.
+// A limit of 0 uses DefaultMaxRunNesting, which is the default, a negative limit means unlimited.
+func WithMaxRunNesting(max int) Option {
+	return func(d *Document) {
+		d.maxRunNesting = max
+	}
+}
+
+// UncompressedSize returns the total uncompressed size of all parts of the original archive in bytes.
+// The sizes are read from the headers of the archive, nothing is decompressed. Modifications of the document are
+// not included.
+func (d *Document) UncompressedSize() int64 {
+	var total uint64
+	for _, file := range d.zipFile.File {
+		total += file.UncompressedSize64
+		if total > math.MaxInt64 {
+			return math.MaxInt64
+		}
+	}
+	return int64(total)
+}
+
+// checkUncompressedSize returns ErrArchiveTooLarge if the archive exceeds the maximum of WithMaxUncompressedSize.
+func (d *Document) checkUncompressedSize() error {
+	if d.maxUncompressedSize <= 0 {
+		return nil
+	}
+	if size := d.UncompressedSize(); size > d.maxUncompressedSize {
+		return fmt.Errorf("%w: %d bytes, allowed are %d", ErrArchiveTooLarge, size, d.maxUncompressedSize)
+	}
+	return nil
+}
+
+// WithMaxReplacements limits how often every single placeholder key may be replaced throughout the document.
+// If a key occurs more often, replacing fails with ErrMaxReplacementsExceeded before anything is replaced.
+// A limit <= 0 means unlimited, which is the default.
+//
+// The limit protects against runaway templates, it is all or nothing: replacing the first occurrences up to the
+// limit and keeping the remaining ones is out of scope, as ReplaceAll requires all occurrences of a key to be
+// replaced. Templates which need that use distinct keys for the occurrences instead.
+func WithMaxReplacements(max int) Option {
+	return func(d *Document) {
+		d.maxReplacements = max
+	}
+}
+
+// ComplexityLimits are thresholds for the size of the generated document. Word fails to open documents which are
+// too complex, e.g. after generating millions of runs in a loop. A limit <= 0 disables the respective check.
+type ComplexityLimits struct {
+	// MaxRuns is the maximum amount of runs of all parsed parts.
+	MaxRuns int
+	// MaxDocumentSize is the maximum size of the main document part in bytes.
+	MaxDocumentSize int
+	// Fail returns ErrComplexityLimitExceeded instead of recording a warning.
+	Fail bool
+}
+
+// WithComplexityLimits checks the document against the given limits after replacing and before writing it.
+// Exceeded limits are recorded as warnings, unless the limits are configured to fail.
+func WithComplexityLimits(limits ComplexityLimits) Option {
+	return func(d *Document) {
+		d.complexityLimits = &limits
+	}
+}
+
+// WithKeyMaxReplacements limits how often the given placeholder key may be replaced throughout the document.
+// It takes precedence over the global limit set by WithMaxReplacements and fails the same way, the occurrences
+// are never replaced partially. A limit <= 0 means unlimited.
+func WithKeyMaxReplacements(key string, max int) Option {
+	return func(d *Document) {
+		if d.keyMaxReplacements == nil {
+			d.keyMaxReplacements = make(map[string]int)
+		}
+		d.keyMaxReplacements[RemovePlaceholderDelimiter(key)] = max
+	}
+}
+
+// replacementLimit returns the maximum amount of replacements for the given key, 0 means unlimited.
+func (d *Document) replacementLimit(key string) int {
+	if max, ok := d.keyMaxReplacements[key]; ok {
+		return max
+	}
+	return d.maxReplacements
+}
+
+// checkReplacementLimits ensures that no key of the placeholderMap occurs more often than allowed.
+func (d *Document) checkReplacementLimits(placeholderMap PlaceholderMap) error {
+	if d.maxReplacements <= 0 && len(d.keyMaxReplacements) == 0 {
+		return nil
+	}
+
+	occurrences := d.placeholderOccurrences()
+	for key := range placeholderMap {
+		key = RemovePlaceholderDelimiter(key)
+		max := d.replacementLimit(key)
+		if max > 0 && occurrences[key] > max {
+			return fmt.Errorf("placeholder '%s' occurs %d times, allowed are %d: %w", key, occurrences[key], max, ErrMaxReplacementsExceeded)
+		}
+	}
+	return nil
+}
+
+// placeholderOccurrences returns how often every placeholder key (without delimiters) occurs in all files.
+func (d *Document) placeholderOccurrences() map[string]int {
+	occurrences := make(map[string]int)
+	for file := range d.filePlaceholders {
+		for key, count := range d.scanPart(file, false).occurrences {
+			occurrences[key] += count
+		}
+	}
+	return occurrences
+}
+
+// checkComplexity checks the current state of the document against the configured ComplexityLimits.
+func (d *Document) checkComplexity() error {
+	limits := d.complexityLimits
+	if limits == nil {
+		return nil
+	}
+
+	var exceeded []string
+	if runs := len(d.Runs()); limits.MaxRuns > 0 && runs > limits.MaxRuns {
+		exceeded = append(exceeded, fmt.Sprintf("the document has %d runs, allowed are %d", runs, limits.MaxRuns))
+	}
+	if size := len(d.GetFile(DocumentXml)); limits.MaxDocumentSize > 0 && size > limits.MaxDocumentSize {
+		exceeded = append(exceeded, fmt.Sprintf("%s has %d bytes, allowed are %d", DocumentXml, size, limits.MaxDocumentSize))
+	}
+	for _, problem := range exceeded {
+		if limits.Fail {
+			return fmt.Errorf("%s: %w", problem, ErrComplexityLimitExceeded)
+		}
+		d.warnOnce(problem)
+	}
+	return nil
+}
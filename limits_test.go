@@ -0,0 +1,124 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_MaxReplacements(t *testing.T) {
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{a}{a}{a}{b}</w:t></w:r></w:p>`),
+	}
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"unlimited by default", nil, false},
+		{"global limit exceeded", []Option{WithMaxReplacements(2)}, true},
+		{"global limit reached", []Option{WithMaxReplacements(3)}, false},
+		{"key limit exceeded", []Option{WithKeyMaxReplacements("a", 1)}, true},
+		{"key limit overrides global limit", []Option{WithMaxReplacements(1), WithKeyMaxReplacements("{a}", 0)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, parts), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := string(doc.GetFile(DocumentXml))
+
+			err = doc.ReplaceAll(PlaceholderMap{"a": "x", "b": "y"})
+			if tt.wantErr {
+				if !errors.Is(err, ErrMaxReplacementsExceeded) {
+					t.Errorf("expected ErrMaxReplacementsExceeded, got %v", err)
+				}
+				if before != string(doc.GetFile(DocumentXml)) {
+					t.Error("document must not be modified if the limit is exceeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDocument_ComplexityLimits(t *testing.T) {
+	body := `<w:p><w:r><w:t>{a}</w:t></w:r><w:r><w:t>{b}</w:t></w:r><w:r><w:t>{c}</w:t></w:r></w:p>`
+	values := PlaceholderMap{"a": "1", "b": "2", "c": "3"}
+
+	tests := []struct {
+		name     string
+		limits   ComplexityLimits
+		warnings int
+		fail     bool
+	}{
+		{"within limits", ComplexityLimits{MaxRuns: 3, MaxDocumentSize: 10000}, 0, false},
+		{"too many runs", ComplexityLimits{MaxRuns: 2}, 1, false},
+		{"document too large", ComplexityLimits{MaxRuns: 2, MaxDocumentSize: 100}, 2, false},
+		{"fail", ComplexityLimits{MaxRuns: 2, Fail: true}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithComplexityLimits(tt.limits))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = doc.ReplaceAll(values)
+			if tt.fail != errors.Is(err, ErrComplexityLimitExceeded) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err := doc.Write(new(bytes.Buffer)); tt.fail != errors.Is(err, ErrComplexityLimitExceeded) {
+				t.Errorf("unexpected error when writing %v", err)
+			}
+			if len(doc.Warnings()) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, doc.Warnings())
+			}
+		})
+	}
+}
+
+func TestDocument_UncompressedSize(t *testing.T) {
+	parts := map[string]string{
+		DocumentXml:  testDocumentXml(`<w:p><w:r><w:t>` + strings.Repeat("x", 10000) + `</w:t></w:r></w:p>`),
+		"word/a.bin": strings.Repeat("a", 5000),
+	}
+	data := newTestDocx(t, parts)
+	var expected int64
+	for _, part := range parts {
+		expected += int64(len(part))
+	}
+
+	doc, err := OpenBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := doc.UncompressedSize(); size != expected {
+		t.Errorf("want=%d, have=%d", expected, size)
+	}
+	if int64(len(data)) >= expected {
+		t.Errorf("expected the archive to be compressed")
+	}
+
+	if _, err := OpenBytes(data, WithMaxUncompressedSize(expected)); err != nil {
+		t.Errorf("expected the archive to be within the limit: %v", err)
+	}
+	if _, err := OpenBytes(data, WithMaxUncompressedSize(expected-1)); !errors.Is(err, ErrArchiveTooLarge) {
+		t.Errorf("expected %v, got %v", ErrArchiveTooLarge, err)
+	}
+}
+
+func TestWithMaxRunNesting(t *testing.T) {
+	data := newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(testNestedRuns(10))})
+	if _, err := OpenBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenBytes(data, WithMaxRunNesting(5)); !errors.Is(err, ErrRunNestingTooDeep) {
+		t.Errorf("expected %v, got %v", ErrRunNestingTooDeep, err)
+	}
+}
@@ -0,0 +1,108 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LineEndings counts the line endings of a part.
+type LineEndings struct {
+	LF   int // '\n' without a preceding '\r'
+	CRLF int // '\r\n'
+	CR   int // '\r' without a following '\n'
+}
+
+// Mixed returns true if the part uses more than one kind of line ending.
+func (l LineEndings) Mixed() bool {
+	kinds := 0
+	for _, n := range []int{l.LF, l.CRLF, l.CR} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
+
+// CountLineEndings returns the line endings of the data.
+func CountLineEndings(data []byte) LineEndings {
+	var endings LineEndings
+	for i, b := range data {
+		switch {
+		case b == '\n' && (i == 0 || data[i-1] != '\r'):
+			endings.LF++
+		case b == '\r' && i+1 < len(data) && data[i+1] == '\n':
+			endings.CRLF++
+		case b == '\r':
+			endings.CR++
+		}
+	}
+	return endings
+}
+
+// normalizeLineEndings returns the data with all CRLF and CR line endings replaced by LF.
+// XML parsers do the same before processing the markup (XML 1.0, section 2.11), the change therefore keeps the
+// meaning of the markup; line breaks inside attribute values are read as spaces either way.
+func normalizeLineEndings(data []byte) []byte {
+	if bytes.IndexByte(data, '\r') < 0 {
+		return data
+	}
+	data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+	return bytes.Replace(data, []byte("\r"), []byte("\n"), -1)
+}
+
+// WithNormalizedLineEndings replaces the CRLF and CR line endings of all parsed parts (document, headers, footers
+// and notes) by LF before they are parsed. All positions therefore refer to the normalized parts.
+//
+// Positions are byte offsets and stay correct without normalizing as well. The option is meant for documents of
+// tools which write CRLF or mix line endings, whose text (e.g. of Document.Placeholders) would otherwise contain
+// the carriage returns. Line endings inside the text of runs are not rendered by Word in either case.
+func WithNormalizedLineEndings() Option {
+	return func(d *Document) {
+		d.normalizeLineEndings = true
+	}
+}
+
+// LineEndings returns the line endings of all parsed parts which contain carriage returns, by part.
+func (d *Document) LineEndings() map[string]LineEndings {
+	endings := make(map[string]LineEndings)
+	for _, name := range d.fileNames() {
+		if data := d.files[name]; bytes.IndexByte(data, '\r') >= 0 {
+			endings[name] = CountLineEndings(data)
+		}
+	}
+	return endings
+}
+
+// NormalizeLineEndings replaces the CRLF and CR line endings of all parsed parts by LF and parses the changed
+// parts again, see WithNormalizedLineEndings.
+func (d *Document) NormalizeLineEndings() error {
+	for _, name := range d.fileNames() {
+		data := d.files[name]
+		normalized := normalizeLineEndings(data)
+		if len(normalized) == len(data) {
+			continue
+		}
+		if err := d.SetFile(name, normalized); err != nil {
+			return err
+		}
+		if err := d.parseFile(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLineEndings normalizes the line endings of all parsed parts if requested and otherwise records a warning
+// for every part with mixed line endings.
+func (d *Document) checkLineEndings() {
+	for _, name := range d.fileNames() {
+		if d.normalizeLineEndings {
+			d.files[name] = normalizeLineEndings(d.files[name])
+			d.markChanged(name)
+			continue
+		}
+		if endings := CountLineEndings(d.files[name]); endings.Mixed() {
+			d.warnOnce(fmt.Sprintf("%s mixes line endings (%d LF, %d CRLF, %d CR)", name, endings.LF, endings.CRLF, endings.CR))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountLineEndings(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected LineEndings
+		mixed    bool
+	}{
+		{"a\nb\n", LineEndings{LF: 2}, false},
+		{"\r\na\r\n", LineEndings{CRLF: 2}, false},
+		{"a\rb\r\nc\n", LineEndings{LF: 1, CRLF: 1, CR: 1}, true},
+		{"", LineEndings{}, false},
+	}
+	for _, tt := range tests {
+		endings := CountLineEndings([]byte(tt.data))
+		if endings != tt.expected || endings.Mixed() != tt.mixed {
+			t.Errorf("%q: want=%+v (mixed %v), have=%+v", tt.data, tt.expected, tt.mixed, endings)
+		}
+	}
+}
+
+func TestDocument_ReplaceAll_CRLF(t *testing.T) {
+	body := "<w:p>\r\n<w:r\r\n w:rsidR=\"00A1\">\r\n<w:rPr>\r\n<w:b/>\r\n</w:rPr>\r\n<w:t>{na</w:t>\r\n</w:r>\r\n" +
+		"<w:r>\r\n<w:t\r\nxml:space=\"preserve\">me}, line\r\none</w:t>\r\n</w:r>\r\n</w:p>\r\n" +
+		"<w:p>\r\n<w:r>\r\n<w:t>{city}</w:t>\r\n</w:r>\r\n</w:p>\n"
+	document := strings.Replace(testDocumentXml(body), "?><", "?>\r\n<", 1)
+
+	tests := []struct {
+		name      string
+		options   []Option
+		expected  string
+		warnings  int
+		remaining int
+	}{
+		{
+			name: "positions account for carriage returns",
+			expected: "<w:t>Jane</w:t>\r\n</w:r>\r\n<w:r>\r\n<w:t\r\nxml:space=\"preserve\">, line\r\none</w:t>\r\n</w:r>\r\n</w:p>\r\n" +
+				"<w:p>\r\n<w:r>\r\n<w:t>Almaty</w:t>",
+			warnings:  1,
+			remaining: 19,
+		},
+		{
+			name:    "normalized",
+			options: []Option{WithNormalizedLineEndings()},
+			expected: "<w:t>Jane</w:t>\n</w:r>\n<w:r>\n<w:t\nxml:space=\"preserve\">, line\none</w:t>\n</w:r>\n</w:p>\n" +
+				"<w:p>\n<w:r>\n<w:t>Almaty</w:t>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: document}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(doc.Warnings()) != tt.warnings {
+				t.Errorf("expected %d warnings, got %v", tt.warnings, doc.Warnings())
+			}
+			if err := ValidatePositions(doc.GetFile(DocumentXml), doc.runParsers[DocumentXml].Runs()); err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "city": "Almaty"}); err != nil {
+				t.Fatal(err)
+			}
+
+			data := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(data, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, data)
+			}
+			if endings := doc.LineEndings()[DocumentXml]; endings.CRLF != tt.remaining {
+				t.Errorf("expected %d CRLF line endings, got %+v", tt.remaining, endings)
+			}
+			if err := doc.NormalizeLineEndings(); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(doc.GetFile(DocumentXml)), "\r") || len(doc.LineEndings()) != 0 {
+				t.Errorf("expected no carriage returns after normalizing")
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package docx
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateStyle is the style of a LocalDateValue.
+type DateStyle int
+
+const (
+	// DateShort formats dates with numbers only, e.g. '03/14/2021' (en-US) or '14.03.2021' (de).
+	DateShort DateStyle = iota
+	// DateLong formats dates with the name of the month, e.g. 'March 14, 2021' (en-US) or '14. März 2021' (de).
+	DateLong
+)
+
+var (
+	// ThemeFontLanguageTagRegex matches the languages of the theme fonts in the document settings (<w:themeFontLang>)
+	ThemeFontLanguageTagRegex = regexp.MustCompile(`<w:themeFontLang(?:\s[^>]*)?/?>`)
+)
+
+// dateLocale defines the date formats of a language. The layouts are used by time.Format, the english names of the
+// months in the long layout are replaced by the names of the locale.
+type dateLocale struct {
+	short  string
+	long   string
+	months [12]string
+}
+
+// dateLocales are the locales of LocalDateValue by language, languages with a region fall back to their primary
+// language, e.g. 'de-AT' to 'de'.
+var dateLocales = map[string]dateLocale{
+	"en":    {short: "01/02/2006", long: "January 2, 2006"},
+	"en-GB": {short: "02/01/2006", long: "2 January 2006"},
+	"de": {short: "02.01.2006", long: "2. January 2006", months: [12]string{"Januar", "Februar", "März", "April",
+		"Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}},
+	"fr": {short: "02/01/2006", long: "2 January 2006", months: [12]string{"janvier", "février", "mars", "avril",
+		"mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}},
+	"es": {short: "02/01/2006", long: "2 de January de 2006", months: [12]string{"enero", "febrero", "marzo", "abril",
+		"mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}},
+	"ru": {short: "02.01.2006", long: "2 January 2006 г.", months: [12]string{"января", "февраля", "марта", "апреля",
+		"мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"}},
+	"kk": {short: "02.01.2006", long: "2006 жылғы 2 January", months: [12]string{"қаңтар", "ақпан", "наурыз", "сәуір",
+		"мамыр", "маусым", "шілде", "тамыз", "қыркүйек", "қазан", "қараша", "желтоқсан"}},
+}
+
+// LocalDateValue is a replacement value which formats a point in time in the format of a language. The language
+// defaults to the language of the document (see WithLocale and Document.DefaultLanguage), it is resolved when the
+// value is replaced. Languages without a known format use DefaultDateLayout.
+type LocalDateValue struct {
+	Time  time.Time
+	Style DateStyle
+	// Language overrides the language of the document, e.g. 'de-DE'.
+	Language string
+}
+
+// LocalDate returns a LocalDateValue in the given style and the language of the document.
+func LocalDate(t time.Time, style DateStyle) LocalDateValue {
+	return LocalDateValue{Time: t, Style: style}
+}
+
+// String returns the formatted date.
+func (d LocalDateValue) String() string {
+	locale, exists := dateLocales[d.Language]
+	if !exists {
+		locale, exists = dateLocales[primaryLanguage(d.Language)]
+	}
+	if !exists {
+		return d.Time.Format(DefaultDateLayout)
+	}
+	if d.Style == DateShort {
+		return d.Time.Format(locale.short)
+	}
+	formatted := d.Time.Format(locale.long)
+	if month := locale.months[d.Time.Month()-1]; month != "" {
+		formatted = strings.Replace(formatted, d.Time.Month().String(), month, 1)
+	}
+	return formatted
+}
+
+// DefaultAlignment implements the AlignedValue interface, dates are right-aligned.
+func (d LocalDateValue) DefaultAlignment() Alignment {
+	return AlignRight
+}
+
+// WithLocale sets the language which formats the LocalDateValue values without a language, instead of the language
+// of the document.
+func WithLocale(language string) Option {
+	return func(d *Document) {
+		d.locale = language
+	}
+}
+
+// DefaultLanguage returns the default language of the document, which applies to all text without a language:
+// the language of the document defaults of the styles, or else the language of the theme fonts of the settings.
+// An empty string is returned if the document declares neither.
+func (d *Document) DefaultLanguage() string {
+	if d.partExists(StylesXml) {
+		if data, err := d.getPart(StylesXml); err == nil {
+			var styles styleLanguages
+			if err := xml.Unmarshal(data, &styles); err == nil && styles.Default.Val != "" {
+				return styles.Default.Val
+			}
+		}
+	}
+	if d.partExists(SettingsXml) {
+		if data, err := d.getPart(SettingsXml); err == nil {
+			return tagValue(ThemeFontLanguageTagRegex, data)
+		}
+	}
+	return ""
+}
+
+// localizeValues returns a copy of the map in which all LocalDateValue values without a language have the language
+// of WithLocale or of the document.
+func (d *Document) localizeValues(placeholderMap PlaceholderMap) PlaceholderMap {
+	return d.localizer()(placeholderMap)
+}
+
+// localizer returns a function which localizes maps like localizeValues. The language is resolved once, when the
+// first date without a language is found, e.g. for all rows of ExpandTableRow.
+func (d *Document) localizer() func(PlaceholderMap) PlaceholderMap {
+	var language string
+	resolved := false
+	return func(placeholderMap PlaceholderMap) PlaceholderMap {
+		var localized PlaceholderMap
+		for key, value := range placeholderMap {
+			date, ok := value.(LocalDateValue)
+			if !ok || date.Language != "" {
+				continue
+			}
+			if localized == nil {
+				localized = make(PlaceholderMap, len(placeholderMap))
+				for key, value := range placeholderMap {
+					localized[key] = value
+				}
+			}
+			if !resolved {
+				language, resolved = d.locale, true
+				if language == "" {
+					language = d.DefaultLanguage()
+				}
+			}
+			date.Language = language
+			localized[key] = date
+		}
+		if localized == nil {
+			return placeholderMap
+		}
+		return localized
+	}
+}
@@ -0,0 +1,163 @@
+package docx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStylesWithLanguage returns a styles part whose document defaults have the given language.
+func testStylesWithLanguage(language string) string {
+	return `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:lang w:val="` + language + `"/></w:rPr></w:rPrDefault></w:docDefaults>` +
+		`</w:styles>`
+}
+
+func TestLocalDateValue_String(t *testing.T) {
+	date := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		language string
+		style    DateStyle
+		expected string
+	}{
+		{"en-US", DateShort, "03/14/2021"},
+		{"en-US", DateLong, "March 14, 2021"},
+		{"en-GB", DateShort, "14/03/2021"},
+		{"de-DE", DateShort, "14.03.2021"},
+		{"de-AT", DateLong, "14. März 2021"},
+		{"fr", DateLong, "14 mars 2021"},
+		{"ru-RU", DateLong, "14 марта 2021 г."},
+		{"kk-KZ", DateLong, "2021 жылғы 14 наурыз"},
+		{"ja-JP", DateLong, "2021-03-14"},
+		{"", DateShort, "2021-03-14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			value := LocalDateValue{Time: date, Style: tt.style, Language: tt.language}
+			if formatted := value.String(); formatted != tt.expected {
+				t.Errorf("unexpected date, want=%s, have=%s", tt.expected, formatted)
+			}
+		})
+	}
+}
+
+func TestDocument_DefaultLanguage(t *testing.T) {
+	settings := `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:themeFontLang w:val="ru-RU" w:eastAsia="ja-JP"/></w:settings>`
+	tests := []struct {
+		name     string
+		parts    map[string]string
+		expected string
+	}{
+		{"styles", map[string]string{StylesXml: testStylesWithLanguage("de-DE"), SettingsXml: settings}, "de-DE"},
+		{"settings", map[string]string{SettingsXml: settings}, "ru-RU"},
+		{"none", map[string]string{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.parts[DocumentXml] = testDocumentXml(`<w:p/>`)
+			if language := openTestDocx(t, tt.parts).DefaultLanguage(); language != tt.expected {
+				t.Errorf("unexpected language, want=%s, have=%s", tt.expected, language)
+			}
+		})
+	}
+}
+
+func TestDocument_ReplaceAll_LocalDate(t *testing.T) {
+	date := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		language string
+		options  []Option
+		value    LocalDateValue
+		expected string
+	}{
+		{"document language", "en-US", nil, LocalDate(date, DateLong), "March 14, 2021"},
+		{"other document language", "de-DE", nil, LocalDate(date, DateLong), "14. März 2021"},
+		{"locale", "de-DE", []Option{WithLocale("kk-KZ")}, LocalDate(date, DateShort), "14.03.2021"},
+		{"value language", "de-DE", []Option{WithLocale("kk-KZ")}, LocalDateValue{Time: date, Language: "en-GB"}, "14/03/2021"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := map[string]string{
+				DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Date: {date}</w:t></w:r></w:p>`),
+				StylesXml:   testStylesWithLanguage(tt.language),
+			}
+			doc, err := OpenBytes(newTestDocx(t, parts), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"date": tt.value}); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := reopen(t, doc).getPart(DocumentXml); !strings.Contains(string(data), "<w:t>Date: "+tt.expected+"</w:t>") {
+				t.Errorf("expected the date %s in %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestRenderFile_LocalDate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localdate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	template := filepath.Join(dir, "report.docx")
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Stand: {date}</w:t></w:r></w:p>`),
+		StylesXml:   testStylesWithLanguage("de-DE"),
+	}
+	if err := ioutil.WriteFile(template, newTestDocx(t, parts), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "output.docx")
+	data := map[string]interface{}{"date": LocalDate(time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC), DateLong)}
+	if _, err := RenderFile(template, output, data); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	if language := doc.DefaultLanguage(); language != "de-DE" {
+		t.Errorf("unexpected language %s", language)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "<w:t>Stand: 14. März 2021</w:t>") {
+		t.Errorf("expected the german date in %s", result)
+	}
+}
+
+func TestDocument_ExpandTableRow_LocalDate(t *testing.T) {
+	row := `<w:tr><w:tc><w:p><w:r><w:t>{item}</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>{date}</w:t></w:r></w:p></w:tc></w:tr>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:tbl>` + row + `</w:tbl>`),
+		StylesXml:   testStylesWithLanguage("de-DE"),
+	})
+
+	date := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	err := doc.ExpandTableRow("item", []PlaceholderMap{
+		{"item": "Basic", "date": LocalDate(date, DateLong)},
+		{"item": "Premium", "date": LocalDateValue{Time: date, Style: DateShort, Language: "en-GB"}},
+	}, ExpandOptions{
+		Totals: []ColumnAggregate{{Key: "item", Aggregate: func(values []interface{}) interface{} {
+			return len(values)
+		}}},
+		TotalsRow: PlaceholderMap{"date": LocalDate(date.AddDate(0, 1, 0), DateLong)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{"<w:t>14. März 2021</w:t>", "<w:t>14/03/2021</w:t>", "<w:t>14. April 2021</w:t>"} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected the date %s in %s", expected, document)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package docx
+
+import (
+	"regexp"
+)
+
+var (
+	// MathTextRegex matches the text of a math run (<m:t>...</m:t>)
+	MathTextRegex = regexp.MustCompile(`<m:t(?:\s[^>]*)?>[^<]*</m:t\s*>`)
+)
+
+// WithMathPlaceholders replaces placeholders inside equations as well. Equations are written in Office Math (OMML),
+// whose runs (<m:r>) contain their text in <m:t> instead of <w:t>. By default equations are skipped, their
+// placeholders are neither replaced nor reported as missing.
+//
+// The values should be plain text, formatted values (e.g. RichText) insert runs of the document which are not valid
+// inside an equation.
+func WithMathPlaceholders() Option {
+	return func(d *Document) {
+		d.mathPlaceholders = true
+	}
+}
+
+// withoutMathText returns a copy of the data without the text of math runs, the data itself is not modified.
+func withoutMathText(data []byte) []byte {
+	return MathTextRegex.ReplaceAll(data, nil)
+}
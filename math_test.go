@@ -0,0 +1,44 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithMathPlaceholders(t *testing.T) {
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Area of {shape}:</w:t></w:r>` +
+			`<m:oMath xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math"><m:r><m:t>A=</m:t></m:r>` +
+			`<m:sSup><m:e><m:r><m:rPr><m:sty m:val="p"/></m:rPr><m:t xml:space="preserve">{side}</m:t></m:r></m:e>` +
+			`<m:sup><m:r><m:t>2</m:t></m:r></m:sup></m:sSup></m:oMath></w:p>`),
+	}
+	values := PlaceholderMap{"shape": "square", "side": "a"}
+
+	doc := openTestDocx(t, parts)
+	if placeholders := doc.Placeholders(); len(placeholders) != 1 {
+		t.Errorf("expected the equations to be skipped by default, got %v", placeholders)
+	}
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := reopen(t, doc).getPart(DocumentXml); !strings.Contains(string(data), `<m:t xml:space="preserve">{side}</m:t>`) {
+		t.Errorf("expected the equation to be kept, got %s", data)
+	}
+
+	doc, err := OpenBytes(newTestDocx(t, parts), WithMathPlaceholders())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := reopen(t, doc).getPart(DocumentXml)
+	for _, expected := range []string{
+		`<w:t>Area of square:</w:t>`,
+		`<m:r><m:rPr><m:sty m:val="p"/></m:rPr><m:t xml:space="preserve">a</m:t></m:r>`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in %s", expected, data)
+		}
+	}
+}
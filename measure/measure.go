@@ -0,0 +1,132 @@
+// Package measure provides typed units for the measurements of WordprocessingML documents.
+//
+// The markup uses a different unit almost everywhere: twips (1/20 point) for page sizes and indentation,
+// English Metric Units (EMU) for drawings, half-points for font sizes, eighths of a point for borders and
+// fiftieths of a percent for relative widths. Functions which accept a Length can be given any of the units:
+//
+//	docx.NewImageSize(measure.Cm(4), nil)
+//	docx.WordFitTextWidth("Total", measure.Inches(1.5))
+//
+// All conversions go through EMU, which is exact for all units, and are rounded to the nearest integer
+// when converting to one of the integral units.
+package measure
+
+import "math"
+
+const (
+	// EMUPerInch is the amount of English Metric Units per inch.
+	EMUPerInch = 914400
+	// EMUPerCentimeter is the amount of English Metric Units per centimeter.
+	EMUPerCentimeter = 360000
+	// EMUPerMillimeter is the amount of English Metric Units per millimeter.
+	EMUPerMillimeter = 36000
+	// EMUPerPoint is the amount of English Metric Units per point (1/72 inch).
+	EMUPerPoint = 12700
+	// EMUPerTwip is the amount of English Metric Units per twip (1/20 point).
+	EMUPerTwip = 635
+)
+
+// Length is a measurement in any of the units of this package.
+type Length interface {
+	// EMU returns the length in English Metric Units.
+	EMU() EMU
+}
+
+// EMU is a length in English Metric Units, the unit of DrawingML (e.g. the size of images).
+type EMU int64
+
+// EMU implements the Length interface.
+func (e EMU) EMU() EMU {
+	return e
+}
+
+// Twips returns the length in twips (1/20 point), rounded.
+func (e EMU) Twips() Twips {
+	return Twips(math.Round(float64(e) / EMUPerTwip))
+}
+
+// Points returns the length in points.
+func (e EMU) Points() Points {
+	return Points(float64(e) / EMUPerPoint)
+}
+
+// HalfPoints returns the length in half-points, the unit of font sizes (<w:sz>), rounded.
+func (e EMU) HalfPoints() int {
+	return int(math.Round(float64(e) * 2 / EMUPerPoint))
+}
+
+// EighthPoints returns the length in eighths of a point, the unit of border widths (<w:sz> of borders), rounded.
+func (e EMU) EighthPoints() int {
+	return int(math.Round(float64(e) * 8 / EMUPerPoint))
+}
+
+// Inches returns the length in inches.
+func (e EMU) Inches() Inches {
+	return Inches(float64(e) / EMUPerInch)
+}
+
+// Cm returns the length in centimeters.
+func (e EMU) Cm() Cm {
+	return Cm(float64(e) / EMUPerCentimeter)
+}
+
+// Mm returns the length in millimeters.
+func (e EMU) Mm() Mm {
+	return Mm(float64(e) / EMUPerMillimeter)
+}
+
+// Twips is a length in twips (1/20 point), the unit of page sizes, margins and indentation.
+type Twips int64
+
+// EMU implements the Length interface.
+func (t Twips) EMU() EMU {
+	return EMU(t * EMUPerTwip)
+}
+
+// Points is a length in points (1/72 inch).
+type Points float64
+
+// EMU implements the Length interface.
+func (p Points) EMU() EMU {
+	return EMU(math.Round(float64(p) * EMUPerPoint))
+}
+
+// Inches is a length in inches.
+type Inches float64
+
+// EMU implements the Length interface.
+func (i Inches) EMU() EMU {
+	return EMU(math.Round(float64(i) * EMUPerInch))
+}
+
+// Cm is a length in centimeters.
+type Cm float64
+
+// EMU implements the Length interface.
+func (c Cm) EMU() EMU {
+	return EMU(math.Round(float64(c) * EMUPerCentimeter))
+}
+
+// Mm is a length in millimeters.
+type Mm float64
+
+// EMU implements the Length interface.
+func (m Mm) EMU() EMU {
+	return EMU(math.Round(float64(m) * EMUPerMillimeter))
+}
+
+// Percent is a relative measurement, e.g. of the width of a table. 100 is the full width.
+type Percent float64
+
+// Fiftieths returns the percentage in fiftieths of a percent, the unit of relative widths (w:type="pct"), rounded.
+func (p Percent) Fiftieths() int {
+	return int(math.Round(float64(p) * 50))
+}
+
+// ToEMU returns the length in EMU, a nil length is 0.
+func ToEMU(length Length) EMU {
+	if length == nil {
+		return 0
+	}
+	return length.EMU()
+}
@@ -0,0 +1,57 @@
+package measure
+
+import "testing"
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		length Length
+		twips  Twips
+		emu    EMU
+	}{
+		{"inch", Inches(1), 1440, 914400},
+		{"centimeters", Cm(2), 1134, 720000},
+		{"millimeters", Mm(25.4), 1440, 914400},
+		{"points", Points(12), 240, 152400},
+		{"twips", Twips(567), 567, 360045},
+		{"emu", EMU(635), 1, 635},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if emu := tt.length.EMU(); emu != tt.emu {
+				t.Errorf("unexpected EMU %d, expected %d", emu, tt.emu)
+			}
+			if twips := tt.length.EMU().Twips(); twips != tt.twips {
+				t.Errorf("unexpected twips %d, expected %d", twips, tt.twips)
+			}
+		})
+	}
+}
+
+func TestEMU_Units(t *testing.T) {
+	size := Points(10.5).EMU()
+	if halfPoints := size.HalfPoints(); halfPoints != 21 {
+		t.Errorf("unexpected half-points %d", halfPoints)
+	}
+	if eighths := Points(0.5).EMU().EighthPoints(); eighths != 4 {
+		t.Errorf("unexpected eighths of a point %d", eighths)
+	}
+	if points := size.Points(); points != 10.5 {
+		t.Errorf("unexpected points %v", points)
+	}
+	if cm := Inches(1).EMU().Cm(); cm != 2.54 {
+		t.Errorf("unexpected centimeters %v", cm)
+	}
+	if mm := Cm(1.5).EMU().Mm(); mm != 15 {
+		t.Errorf("unexpected millimeters %v", mm)
+	}
+	if inches := Twips(720).EMU().Inches(); inches != 0.5 {
+		t.Errorf("unexpected inches %v", inches)
+	}
+	if fiftieths := Percent(100).Fiftieths(); fiftieths != 5000 {
+		t.Errorf("unexpected fiftieths %d", fiftieths)
+	}
+	if emu := ToEMU(nil); emu != 0 {
+		t.Errorf("unexpected EMU of nil %d", emu)
+	}
+}
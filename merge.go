@@ -0,0 +1,100 @@
+package docx
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+)
+
+var (
+	// SimpleRunRegex matches a run which consists of nothing but optional run properties and a single text.
+	// The first group contains the inner run properties, the second group the text.
+	SimpleRunRegex = regexp.MustCompile(`^(?s:<w:r(?:\s[^>]*)?>(?:<w:rPr(?:\s[^>]*)?>(.*?)</w:rPr>|<w:rPr\s*/>)?<w:t(?:\s[^>]*)?>([^<]*)</w:t></w:r>)$`)
+)
+
+// WithRunMerging enables merging runs after replacing. Every run which contained a replaced placeholder is merged
+// with directly adjacent runs if both runs only consist of text with identical run properties.
+// This reduces the amount of runs as placeholders are often split into several runs by Word.
+func WithRunMerging() Option {
+	return func(d *Document) {
+		d.mergeRuns = true
+	}
+}
+
+// mergeReplacedRuns merges the given (replaced) runs of the already replaced data with their adjacent runs.
+// The positions of the runs must be up-to-date. The file is parsed again as its runs change.
+func (d *Document) mergeReplacedRuns(file string, data []byte, replacedRuns []*Run) ([]byte, error) {
+	replaced := make(map[int64]bool)
+	for _, run := range replacedRuns {
+		replaced[run.OpenTag.Start] = true
+	}
+
+	if err := d.SetFile(file, data); err != nil {
+		return nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, err
+	}
+	runs := append([]*Run(nil), d.runParsers[file].Runs()...)
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].OpenTag.Start < runs[j].OpenTag.Start
+	})
+
+	var edits []edit
+	for i := 0; i < len(runs); {
+		first := runs[i]
+		match := SimpleRunRegex.FindSubmatch(data[first.OpenTag.Start:first.CloseTag.End])
+		if match == nil {
+			i++
+			continue
+		}
+
+		// extend the group as long as the next run is adjacent, equally formatted and one of both was replaced.
+		// Toggles are compared by their value, so that <w:b/> equals <w:b w:val="1"/> but never <w:b w:val="0"/>.
+		last, text := i, append([]byte(nil), match[2]...)
+		for last+1 < len(runs) {
+			current, next := runs[last], runs[last+1]
+			if next.OpenTag.Start != current.CloseTag.End || !(replaced[current.OpenTag.Start] || replaced[next.OpenTag.Start]) {
+				break
+			}
+			nextMatch := SimpleRunRegex.FindSubmatch(data[next.OpenTag.Start:next.CloseTag.End])
+			if nextMatch == nil || normalizeToggles(string(nextMatch[1])) != normalizeToggles(string(match[1])) {
+				break
+			}
+			text = append(text, nextMatch[2]...)
+			last++
+		}
+		if last == i {
+			i++
+			continue
+		}
+
+		var merged bytes.Buffer
+		merged.Write(data[first.OpenTag.Start:first.OpenTag.End])
+		if len(match[1]) > 0 {
+			merged.WriteString("<w:rPr>")
+			merged.Write(match[1])
+			merged.WriteString("</w:rPr>")
+		}
+		merged.WriteString(`<w:t xml:space="preserve">`)
+		merged.Write(text)
+		merged.WriteString("</w:t></w:r>")
+		edits = append(edits, edit{
+			Position:    Position{Start: first.OpenTag.Start, End: runs[last].CloseTag.End},
+			Replacement: merged.Bytes(),
+		})
+		i = last + 1
+	}
+	if len(edits) == 0 {
+		return data, nil
+	}
+
+	data = applyEdits(data, edits)
+	if err := d.SetFile(file, data); err != nil {
+		return nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,42 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_RunMerging(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Dear </w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t>{first</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>name}</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> {last}</w:t></w:r>` +
+		`<w:r><w:rPr><w:i/></w:rPr><w:t>,</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>untouched</w:t></w:r><w:r><w:t xml:space="preserve"> runs</w:t></w:r></w:p>`
+	data := newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	replace := func(opts ...Option) *Document {
+		doc, err := OpenBytes(data, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.ReplaceAll(PlaceholderMap{"firstname": "Jane", "last": "Doe"}); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	plain := replace()
+	merged := replace(WithRunMerging())
+
+	if have, want := len(merged.Runs()), len(plain.Runs())-3; have != want {
+		t.Errorf("unexpected amount of runs, want=%d, have=%d", want, have)
+	}
+	expected := `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Dear Jane Doe</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>,</w:t></w:r>`
+	if !strings.Contains(string(merged.GetFile(DocumentXml)), expected) {
+		t.Errorf("runs were not merged: %s", merged.GetFile(DocumentXml))
+	}
+	if !strings.Contains(string(merged.GetFile(DocumentXml)), `<w:r><w:t>untouched</w:t></w:r>`) {
+		t.Error("runs without replaced placeholders must not be merged")
+	}
+	if err := checkWellFormed(merged.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
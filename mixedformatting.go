@@ -0,0 +1,79 @@
+package docx
+
+import (
+	"fmt"
+)
+
+// FragmentFormattingPolicy defines which formatting a value receives if the fragments of its placeholder are
+// formatted differently, e.g. because only the '{' of '{name}' was made bold by accident.
+type FragmentFormattingPolicy int
+
+const (
+	// FirstFragmentFormatting uses the run properties of the run in which the placeholder starts. This is the default.
+	FirstFragmentFormatting FragmentFormattingPolicy = iota
+	// MajorityFormatting uses the run properties which cover most characters of the placeholder, including the
+	// delimiters. On a tie, the properties of the earlier fragment are used.
+	MajorityFormatting
+)
+
+// WithFragmentFormatting sets the policy which decides the formatting of values whose placeholders are formatted
+// differently mid-token. The value is inserted into the run of the chosen fragment and receives its run
+// properties, the other fragments are removed. Every such placeholder is recorded as a warning.
+func WithFragmentFormatting(policy FragmentFormattingPolicy) Option {
+	return func(d *Document) {
+		d.fragmentFormatting = policy
+	}
+}
+
+// HasMixedFormatting returns true if the fragments of the placeholder are in runs with different run properties.
+func (p *Placeholder) HasMixedFormatting(docBytes []byte) bool {
+	for _, fragment := range p.Fragments[1:] {
+		if fragment.Run.GetProperties(docBytes) != p.Fragments[0].Run.GetProperties(docBytes) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueFragment returns the index of the fragment which receives the value of the placeholder, according to the
+// formatting policy.
+func (p *Placeholder) valueFragment(docBytes []byte, policy FragmentFormattingPolicy) int {
+	if policy != MajorityFormatting || len(p.Fragments) < 2 {
+		return 0
+	}
+	lengths := make(map[string]int64)
+	for _, fragment := range p.Fragments {
+		lengths[fragment.Run.GetProperties(docBytes)] += fragment.Position.End - fragment.Position.Start
+	}
+	chosen := 0
+	for i, fragment := range p.Fragments {
+		if lengths[fragment.Run.GetProperties(docBytes)] > lengths[p.Fragments[chosen].Run.GetProperties(docBytes)] {
+			chosen = i
+		}
+	}
+	return chosen
+}
+
+// warnMixedFormatting records a warning for every placeholder with mixed formatting which is going to be replaced
+// by one of the text values.
+func (d *Document) warnMixedFormatting(placeholderMap PlaceholderMap) {
+	textValues, _ := splitValues(placeholderMap)
+	replaced := make(map[string]bool)
+	for key := range textValues {
+		for _, literal := range d.placeholderLiterals(key) {
+			replaced[literal] = true
+		}
+	}
+	policy := "the first fragment"
+	if d.fragmentFormatting == MajorityFormatting {
+		policy = "the majority of the characters"
+	}
+	for _, file := range d.fileNames() {
+		data := d.GetFile(file)
+		for _, placeholder := range d.scanPart(file, false).placeholders {
+			if text := placeholder.Text(data); replaced[text] && placeholder.HasMixedFormatting(data) {
+				d.warnOnce(fmt.Sprintf("placeholder %s in %s is formatted differently mid-token, using the formatting of %s", text, file, policy))
+			}
+		}
+	}
+}
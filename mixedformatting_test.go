@@ -0,0 +1,81 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_FragmentFormatting(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Dear {</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>name</w:t></w:r>` +
+		`<w:r><w:rPr><w:i/></w:rPr><w:t>},</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>{city}</w:t></w:r></w:p>`
+
+	tests := []struct {
+		name     string
+		options  []Option
+		expected string
+		warning  string
+	}{
+		{
+			name:     "first fragment by default",
+			expected: `<w:r><w:rPr><w:b/></w:rPr><w:t>Dear Jane</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t></w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>,</w:t></w:r>`,
+			warning:  "placeholder {name} in word/document.xml is formatted differently mid-token, using the formatting of the first fragment",
+		},
+		{
+			name:     "majority",
+			options:  []Option{WithFragmentFormatting(MajorityFormatting)},
+			expected: `<w:r><w:rPr><w:b/></w:rPr><w:t>Dear </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>Jane</w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>,</w:t></w:r>`,
+			warning:  "placeholder {name} in word/document.xml is formatted differently mid-token, using the formatting of the majority of the characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "city": "Almaty"}); err != nil {
+				t.Fatal(err)
+			}
+			document := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(document, tt.expected) {
+				t.Errorf("expected %s in %s", tt.expected, document)
+			}
+			if !strings.Contains(document, `<w:r><w:rPr><w:b/></w:rPr><w:t>Almaty</w:t></w:r>`) {
+				t.Errorf("the consistently formatted placeholder was not replaced: %s", document)
+			}
+			if warnings := doc.Warnings(); len(warnings) != 1 || warnings[0] != tt.warning {
+				t.Errorf("expected the warning %q, got %q", tt.warning, warnings)
+			}
+		})
+	}
+}
+
+func TestPlaceholder_valueFragment(t *testing.T) {
+	tests := []struct {
+		body     string
+		first    int
+		majority int
+	}{
+		{`<w:r><w:rPr><w:b/></w:rPr><w:t>{</w:t></w:r><w:r><w:t>name}</w:t></w:r>`, 0, 1},
+		{`<w:r><w:t>{na</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>me}</w:t></w:r>`, 0, 0},
+		{`<w:r><w:rPr><w:b/></w:rPr><w:t>{n</w:t></w:r><w:r><w:t>am</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>e}</w:t></w:r>`, 0, 0},
+	}
+	for _, tt := range tests {
+		data := []byte(testDocumentXml(`<w:p>` + tt.body + `</w:p>`))
+		parser := NewRunParser(data)
+		if err := parser.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		placeholders, err := ParsePlaceholders(parser.Runs(), data)
+		if err != nil || len(placeholders) != 1 {
+			t.Fatalf("expected one placeholder, got %d: %v", len(placeholders), err)
+		}
+		if first := placeholders[0].valueFragment(data, FirstFragmentFormatting); first != tt.first {
+			t.Errorf("%s: first fragment policy chose %d, expected %d", tt.body, first, tt.first)
+		}
+		if majority := placeholders[0].valueFragment(data, MajorityFormatting); majority != tt.majority {
+			t.Errorf("%s: majority policy chose %d, expected %d", tt.body, majority, tt.majority)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultNestingDepth is the maximum depth of nested placeholders used by WithNestedPlaceholders.
+const DefaultNestingDepth = 3
+
+var (
+	// ErrPlaceholderCycle is returned if the values of nested placeholders refer to each other.
+	ErrPlaceholderCycle = errors.New("cyclic nested placeholders")
+	// ErrNestingTooDeep is returned if nested placeholders exceed the maximum depth.
+	ErrNestingTooDeep = errors.New("nested placeholders exceed the maximum depth")
+)
+
+// WithNestedPlaceholders expands placeholders inside string values, using the values of the same map:
+// with PlaceholderMap{"greeting": "Dear {title} {last_name},", "title": "Dr.", "last_name": "Abay"}
+// '{greeting}' becomes 'Dear Dr. Abay,'. Placeholders of all delimiters (see WithDelimiters) are expanded,
+// placeholders without a value in the map are kept as they are.
+//
+// The values are expanded recursively up to maxDepth levels (DefaultNestingDepth if not positive), deeper values
+// which still contain placeholders return ErrNestingTooDeep. Values which refer to each other return
+// ErrPlaceholderCycle. Both errors name the chain of keys involved.
+//
+// The expansion happens on the values before they are inserted: the expanded value is used like any other value.
+// Render therefore escapes after expanding, escaping the expanded value as a whole, while ReplaceAll inserts it
+// as it is. Values which are not strings are formatted like ReplaceAll does; markup and block values cannot be
+// referenced by nested placeholders.
+func WithNestedPlaceholders(maxDepth int) Option {
+	return func(d *Document) {
+		if maxDepth <= 0 {
+			maxDepth = DefaultNestingDepth
+		}
+		d.nestedPlaceholders = true
+		d.nestingDepth = maxDepth
+	}
+}
+
+// expandNestedValues returns a copy of the map in which all placeholders inside string values are expanded.
+func (d *Document) expandNestedValues(placeholderMap PlaceholderMap) (PlaceholderMap, error) {
+	var keys []string
+	for key := range placeholderMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	expanded := make(map[string]nestedValue)
+	result := make(PlaceholderMap, len(placeholderMap))
+	for _, key := range keys {
+		value := placeholderMap[key]
+		if _, isString := value.(string); !isString {
+			result[key] = value
+			continue
+		}
+		nested, err := d.expandNestedValue(placeholderMap, RemovePlaceholderDelimiter(key), nil, expanded)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = nested.text
+	}
+	return result, nil
+}
+
+// nestedValue is an expanded value. The path is the longest chain of keys which were expanded, starting with the
+// key of the value itself; values without placeholders have a path of length one.
+type nestedValue struct {
+	text string
+	path []string
+}
+
+// expandNestedValue returns the expanded value of the key. The chain contains the keys whose values are currently
+// expanded, expanded caches the results by key.
+func (d *Document) expandNestedValue(placeholderMap PlaceholderMap, key string, chain []string, expanded map[string]nestedValue) (nestedValue, error) {
+	if nested, done := expanded[key]; done {
+		return nested, nil
+	}
+	for i, k := range chain {
+		if k == key {
+			return nestedValue{}, fmt.Errorf("%w: %s", ErrPlaceholderCycle, strings.Join(append(chain[i:len(chain):len(chain)], key), " -> "))
+		}
+	}
+	chain = append(chain[:len(chain):len(chain)], key)
+
+	value, _ := lookupValue(placeholderMap, key)
+	switch value.(type) {
+	case MarkupValue, BlockValue:
+		return nestedValue{}, fmt.Errorf("unable to expand %s: %s is not a text value", strings.Join(chain, " -> "), key)
+	}
+	text, isString := value.(string)
+	if !isString {
+		return nestedValue{text: fmt.Sprint(value), path: []string{key}}, nil
+	}
+
+	var result strings.Builder
+	var deepest []string
+	last := 0
+	for _, ref := range d.nestedReferences(text) {
+		if _, exists := lookupValue(placeholderMap, ref.key); !exists {
+			continue
+		}
+		nested, err := d.expandNestedValue(placeholderMap, ref.key, chain, expanded)
+		if err != nil {
+			return nestedValue{}, err
+		}
+		if len(nested.path) > len(deepest) {
+			deepest = nested.path
+		}
+		result.WriteString(text[last:ref.start])
+		result.WriteString(nested.text)
+		last = ref.end
+	}
+	result.WriteString(text[last:])
+
+	nested := nestedValue{text: result.String(), path: append([]string{key}, deepest...)}
+	// the path of a value with nested placeholders contains the referenced value as well
+	if len(nested.path)-1 > d.nestingDepth {
+		return nestedValue{}, fmt.Errorf("%w (%d): %s", ErrNestingTooDeep, d.nestingDepth, strings.Join(nested.path, " -> "))
+	}
+	expanded[key] = nested
+	return nested, nil
+}
+
+// nestedReference is a placeholder inside a value, start and end are byte offsets including the delimiters.
+type nestedReference struct {
+	key        string
+	start, end int
+}
+
+// nestedReferences returns the placeholders of all delimiters inside the text, in ascending order. Overlapping
+// placeholders are resolved like in the document: the one which starts first wins, for equal starts the longer one.
+func (d *Document) nestedReferences(text string) []nestedReference {
+	var all []nestedReference
+	for _, delimiters := range d.allDelimiters() {
+		offset := 0
+		for {
+			start := strings.Index(text[offset:], delimiters.Open)
+			if start < 0 {
+				break
+			}
+			start += offset
+			keyStart := start + len(delimiters.Open)
+			length := strings.Index(text[keyStart:], delimiters.Close)
+			if length < 0 {
+				break
+			}
+			// an open delimiter inside the key starts a new placeholder
+			if inner := strings.LastIndex(text[keyStart:keyStart+length], delimiters.Open); inner >= 0 {
+				offset = keyStart + inner
+				continue
+			}
+			if length > 0 {
+				end := keyStart + length + len(delimiters.Close)
+				all = append(all, nestedReference{key: text[keyStart : keyStart+length], start: start, end: end})
+			}
+			offset = keyStart + length + len(delimiters.Close)
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].start != all[j].start {
+			return all[i].start < all[j].start
+		}
+		return all[i].end > all[j].end
+	})
+	var references []nestedReference
+	end := 0
+	for _, ref := range all {
+		if ref.start >= end {
+			references = append(references, ref)
+			end = ref.end
+		}
+	}
+	return references
+}
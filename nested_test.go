@@ -0,0 +1,93 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_NestedPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		values   PlaceholderMap
+		expected string
+		err      error
+		chain    string
+	}{
+		{
+			name:    "nested values",
+			options: []Option{WithNestedPlaceholders(0)},
+			values: PlaceholderMap{
+				"greeting": "Dear {title} {last_name},", "title": "{degree}", "degree": "Dr.", "last_name": "Abay",
+				"amount": 42, "line": "{greeting} you owe {amount} {unknown}",
+			},
+			expected: "Dear Dr. Abay, you owe 42 {unknown}",
+		},
+		{
+			name:     "disabled",
+			values:   PlaceholderMap{"line": "Dear {title}", "title": "Dr."},
+			expected: "Dear {title}",
+		},
+		{
+			name:    "cycle",
+			options: []Option{WithNestedPlaceholders(0)},
+			values:  PlaceholderMap{"line": "{a}", "a": "x {b}", "b": "{a}"},
+			err:     ErrPlaceholderCycle,
+			chain:   "a -> b -> a",
+		},
+		{
+			name:    "too deep",
+			options: []Option{WithNestedPlaceholders(2)},
+			values:  PlaceholderMap{"line": "{a}", "a": "{b}", "b": "{c}", "c": "c"},
+			err:     ErrNestingTooDeep,
+			chain:   "line -> a -> b -> c",
+		},
+		{
+			name:     "maximum depth",
+			options:  []Option{WithNestedPlaceholders(3)},
+			values:   PlaceholderMap{"line": "{a}", "a": "{b}", "b": "{c}", "c": "c"},
+			expected: "c",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{line}</w:t></w:r></w:p>`),
+			}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = doc.ReplaceAll(tt.values)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) || !strings.Contains(err.Error(), tt.chain) {
+					t.Errorf("expected %v with %s, got %v", tt.err, tt.chain, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			text, _ := doc.PlainText()
+			if text != tt.expected+"\n" {
+				t.Errorf("want=%q, have=%q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestDocument_Render_NestedPlaceholdersEscaping(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{greeting}</w:t></w:r></w:p>`),
+	}), WithNestedPlaceholders(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the company is expanded first and escaped once, as part of the greeting
+	if _, err := doc.render("letter", map[string]interface{}{"greeting": "Dear {company},", "company": "Smith & <Sons>"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<w:t>Dear Smith &amp; &lt;Sons&gt;,</w:t>`; !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+		t.Errorf("expected %s in %s", expected, doc.GetFile(DocumentXml))
+	}
+}
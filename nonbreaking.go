@@ -0,0 +1,143 @@
+package docx
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordJoiner is the invisible character (U+2060) which prevents a line break between the characters around it.
+const WordJoiner = "\u2060"
+
+// WithNonBreakingValues keeps text values together with the characters they are directly adjacent to.
+// If the character before or after a placeholder is not a space, e.g. the period in 'total: {amount}.',
+// a word joiner is inserted between it and the value, so that Word never wraps the line there.
+// The adjacent characters are determined across runs, but never across paragraphs, tabs or breaks.
+func WithNonBreakingValues() Option {
+	return func(d *Document) {
+		d.nonBreakingValues = true
+	}
+}
+
+// joinAdjacent returns the value with word joiners added on the sides on which the value and the text around
+// the placeholder would touch without a space.
+func joinAdjacent(data []byte, placeholder *Placeholder, value string) string {
+	if value == "" || strings.HasPrefix(value, "<") || strings.HasSuffix(value, ">") {
+		return value
+	}
+	text := html.UnescapeString(value)
+	first, _ := utf8.DecodeRuneInString(text)
+	last, _ := utf8.DecodeLastRuneInString(text)
+
+	if before := precedingRune(data, placeholder.StartPos()); touches(before, first) {
+		value = WordJoiner + value
+	}
+	if after := followingRune(data, placeholder.EndPos()); touches(after, last) {
+		value += WordJoiner
+	}
+	return value
+}
+
+// touches returns true if both characters are visible and not separated by a space.
+func touches(a, b rune) bool {
+	return a != 0 && b != 0 && !unicode.IsSpace(a) && !unicode.IsSpace(b)
+}
+
+// precedingRune returns the visible character before the given text position, or 0 if the text position is at
+// the start of the paragraph or preceded by a tab or break.
+func precedingRune(data []byte, pos int64) rune {
+	inText := true
+	for pos > 0 {
+		gt := int64(bytes.LastIndexByte(data[:pos], '>'))
+		if inText {
+			if text := html.UnescapeString(string(data[gt+1 : pos])); text != "" {
+				r, _ := utf8.DecodeLastRuneInString(text)
+				return r
+			}
+		}
+		if gt < 0 {
+			return 0
+		}
+		lt := int64(bytes.LastIndexByte(data[:gt], '<'))
+		if lt < 0 {
+			return 0
+		}
+		tag := data[lt : gt+1]
+		switch name := tagName(tag); name {
+		case "/w:t":
+			inText = true
+		case "w:noBreakHyphen":
+			return '\u2011'
+		default:
+			if isTextBoundary(name) {
+				return 0
+			}
+			inText = false
+		}
+		pos = lt
+	}
+	return 0
+}
+
+// followingRune returns the visible character after the given text position, or 0 if the text position is at
+// the end of the paragraph or followed by a tab or break.
+func followingRune(data []byte, pos int64) rune {
+	inText := true
+	for pos < int64(len(data)) {
+		lt := int64(bytes.IndexByte(data[pos:], '<'))
+		if lt < 0 {
+			lt = int64(len(data))
+		} else {
+			lt += pos
+		}
+		if inText {
+			if text := html.UnescapeString(string(data[pos:lt])); text != "" {
+				r, _ := utf8.DecodeRuneInString(text)
+				return r
+			}
+		}
+		gt := bytes.IndexByte(data[lt:], '>')
+		if gt < 0 {
+			return 0
+		}
+		tag := data[lt : lt+int64(gt)+1]
+		switch name := tagName(tag); name {
+		case "w:t":
+			inText = !bytes.HasSuffix(tag, []byte("/>"))
+		case "w:noBreakHyphen":
+			return '\u2011'
+		default:
+			if isTextBoundary(name) {
+				return 0
+			}
+			inText = false
+		}
+		pos = lt + int64(gt) + 1
+	}
+	return 0
+}
+
+// tagName returns the qualified name of the tag, prefixed with '/' for close tags.
+func tagName(tag []byte) string {
+	name := strings.TrimPrefix(string(tag), "<")
+	closing := strings.HasPrefix(name, "/")
+	name = strings.TrimPrefix(name, "/")
+	if i := strings.IndexAny(name, " \t\r\n/>"); i >= 0 {
+		name = name[:i]
+	}
+	if closing {
+		return "/" + name
+	}
+	return name
+}
+
+// isTextBoundary returns true for the elements at which adjacent text ends, i.e. paragraphs, tabs and breaks.
+func isTextBoundary(name string) bool {
+	switch strings.TrimPrefix(name, "/") {
+	case "w:p", "w:tab", "w:br", "w:cr", "w:tc":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_NonBreakingValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "trailing punctuation",
+			body:     `<w:p><w:r><w:t xml:space="preserve">total: {amount}.</w:t></w:r></w:p>`,
+			expected: `total: 1,234.00` + WordJoiner + `.`,
+		},
+		{
+			name:     "adjacent text in other runs",
+			body:     `<w:p><w:r><w:t>(</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>{amount}</w:t></w:r><w:r><w:t>&amp;</w:t></w:r></w:p>`,
+			expected: `<w:t>(</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>` + WordJoiner + `1,234.00` + WordJoiner + `</w:t></w:r><w:r><w:t>&amp;</w:t>`,
+		},
+		{
+			name:     "spaces and paragraph boundaries",
+			body:     `<w:p><w:r><w:t>x</w:t></w:r></w:p><w:p><w:r><w:t xml:space="preserve">{amount} </w:t></w:r></w:p>`,
+			expected: `<w:t xml:space="preserve">1,234.00 </w:t>`,
+		},
+		{
+			name:     "tabs",
+			body:     `<w:p><w:r><w:t>x</w:t><w:tab/><w:t>{amount}</w:t></w:r></w:p>`,
+			expected: `<w:tab/><w:t>1,234.00</w:t>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)}), WithNonBreakingValues())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"amount": "1,234.00"}); err != nil {
+				t.Fatal(err)
+			}
+			if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, tt.expected) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", tt.expected, result)
+			}
+		})
+	}
+}
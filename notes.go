@@ -0,0 +1,57 @@
+package docx
+
+import (
+	"regexp"
+)
+
+var (
+	// NoteSeparatorRegex matches the separator pseudo-notes of the footnotes and endnotes (w:type 'separator',
+	// 'continuationSeparator' and 'continuationNotice'), which Word uses to render the line above the notes
+	NoteSeparatorRegex = regexp.MustCompile(`(?s)<w:(?:footnote|endnote)\s[^>]*w:type="(?:separator|continuationSeparator|continuationNotice)"[^>]*>.*?</w:(?:footnote|endnote)>`)
+)
+
+// isNoteFile returns true if the file contains the footnotes or endnotes of the document.
+func isNoteFile(file string) bool {
+	return file == FootnotesXml || file == EndnotesXml
+}
+
+// separatorNotes returns the positions of all separator pseudo-notes of the note file data, in ascending order.
+// Other files do not have separators.
+func separatorNotes(file string, data []byte) []Position {
+	if !isNoteFile(file) {
+		return nil
+	}
+	var separators []Position
+	for _, loc := range NoteSeparatorRegex.FindAllIndex(data, -1) {
+		separators = append(separators, Position{Start: int64(loc[0]), End: int64(loc[1])})
+	}
+	return separators
+}
+
+// withoutSeparatorRuns returns the runs of the file which are not part of a separator pseudo-note.
+// Placeholders are only searched in these runs, so that the separators are never modified.
+func withoutSeparatorRuns(file string, data []byte, runs DocumentRuns) DocumentRuns {
+	separators := separatorNotes(file, data)
+	if len(separators) == 0 {
+		return runs
+	}
+	var kept DocumentRuns
+	for _, run := range runs {
+		if !positionInside(separators, run.OpenTag.Start) {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+// withoutSeparators returns the data with all separator pseudo-notes removed.
+func withoutSeparators(file string, data []byte) []byte {
+	var edits []edit
+	for _, pos := range separatorNotes(file, data) {
+		edits = append(edits, edit{Position: pos})
+	}
+	if len(edits) == 0 {
+		return data
+	}
+	return applyEdits(data, edits)
+}
@@ -0,0 +1,33 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_Footnotes(t *testing.T) {
+	separators := `<w:footnote w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r><w:r><w:t>{name}</w:t></w:r></w:p></w:footnote>` +
+		`<w:footnote w:type="continuationSeparator" w:id="0"><w:p><w:r><w:continuationSeparator/></w:r></w:p></w:footnote>`
+	footnotes := func(notes string) string {
+		return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<w:footnotes xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + notes + `</w:footnotes>`
+	}
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Dear {name}</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r></w:p>`),
+		FootnotesXml: footnotes(separators +
+			`<w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> See {source}.</w:t></w:r></w:p></w:footnote>`),
+	})
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "source": "the annex"}); err != nil {
+		t.Fatal(err)
+	}
+
+	doc = reopen(t, doc)
+	expected := footnotes(separators +
+		`<w:footnote w:id="1"><w:p><w:r><w:footnoteRef/></w:r><w:r><w:t xml:space="preserve"> See the annex.</w:t></w:r></w:p></w:footnote>`)
+	if result := string(doc.GetFile(FootnotesXml)); result != expected {
+		t.Errorf("unexpected footnotes\nwant=%s\nhave=%s", expected, result)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "Dear Jane") {
+		t.Errorf("document was not replaced: %s", result)
+	}
+}
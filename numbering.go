@@ -0,0 +1,428 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// NumberingXml is the relative path of the numbering definitions inside the docx-archive.
+	NumberingXml = "word/numbering.xml"
+	// NumberingRelationshipType is the relationship type of the numbering definitions.
+	NumberingRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering"
+	// NumberingContentType is the content type of the numbering definitions.
+	NumberingContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+
+	// maxNumberingLevels is the amount of levels of a numbering definition
+	maxNumberingLevels = 9
+)
+
+var (
+	// NumberingPropertiesRegex matches the numbering properties (<w:numPr>) of paragraph properties
+	NumberingPropertiesRegex = regexp.MustCompile(`(?s)<w:numPr(?:\s[^>]*)?>.*?</w:numPr>`)
+	// NumberingIdTagRegex matches the numbering reference of numbering properties (<w:numId w:val="3"/>)
+	NumberingIdTagRegex = regexp.MustCompile(`<w:numId(?:\s[^>]*)?/?>`)
+	// NumberingLevelTagRegex matches the level of numbering properties (<w:ilvl w:val="1"/>)
+	NumberingLevelTagRegex = regexp.MustCompile(`<w:ilvl(?:\s[^>]*)?/?>`)
+
+	// levelTextPlaceholderRegex matches the placeholders of level texts like '%1.%2'
+	levelTextPlaceholderRegex = regexp.MustCompile(`%[1-9]`)
+)
+
+// valueAttribute is used to unmarshal elements which only carry a w:val attribute
+type valueAttribute struct {
+	Val string `xml:"val,attr"`
+}
+
+// numberingDefinitions is used to unmarshal the numbering part
+type numberingDefinitions struct {
+	AbstractNums []abstractNumbering `xml:"abstractNum"`
+	Nums         []numberingInstance `xml:"num"`
+}
+
+// abstractNumbering is an abstract numbering definition (<w:abstractNum>) which defines the levels of a list
+type abstractNumbering struct {
+	ID           string           `xml:"abstractNumId,attr"`
+	NumStyleLink valueAttribute   `xml:"numStyleLink"`
+	Levels       []numberingLevel `xml:"lvl"`
+}
+
+// numberingLevel is a single level of a numbering definition (<w:lvl>)
+type numberingLevel struct {
+	Level   int             `xml:"ilvl,attr"`
+	Start   *valueAttribute `xml:"start"`
+	Format  valueAttribute  `xml:"numFmt"`
+	Text    valueAttribute  `xml:"lvlText"`
+	Restart *valueAttribute `xml:"lvlRestart"`
+	Legal   *valueAttribute `xml:"isLgl"`
+	Suffix  valueAttribute  `xml:"suff"`
+}
+
+// numberingInstance is a numbering definition instance (<w:num>) which is referenced by paragraphs
+type numberingInstance struct {
+	ID            string         `xml:"numId,attr"`
+	AbstractNumID valueAttribute `xml:"abstractNumId"`
+	Overrides     []struct {
+		Level      int             `xml:"ilvl,attr"`
+		Start      *valueAttribute `xml:"startOverride"`
+		Definition *numberingLevel `xml:"lvl"`
+	} `xml:"lvlOverride"`
+}
+
+// startValue returns the value of the first paragraph of the level, 0 if it is not defined.
+func (l *numberingLevel) startValue() int {
+	if l == nil || l.Start == nil {
+		return 0
+	}
+	start, _ := strconv.Atoi(l.Start.Val)
+	return start
+}
+
+// restartsAfter returns true if the level restarts after a paragraph of the given (lower) level.
+// By default, levels restart after every lower level.
+func (l *numberingLevel) restartsAfter(level int) bool {
+	if l == nil || l.Restart == nil {
+		return true
+	}
+	restart, err := strconv.Atoi(l.Restart.Val)
+	if err != nil {
+		return true
+	}
+	return level < restart
+}
+
+// legal returns true if the level displays all numbers as decimal (<w:isLgl>), as used by legal numbering.
+func (l *numberingLevel) legal() bool {
+	if l == nil || l.Legal == nil {
+		return false
+	}
+	switch l.Legal.Val {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
+
+// numberingList is the resolved definition of a numbering instance
+type numberingList struct {
+	// counterKey identifies the counters of the list, instances of the same abstract numbering share them
+	counterKey string
+	levels     [maxNumberingLevels]*numberingLevel
+	// startOverrides are the start values of levels which do not continue the abstract numbering
+	startOverrides map[int]int
+}
+
+// numberingCounters are the current values of the levels of a list
+type numberingCounters struct {
+	values [maxNumberingLevels]int
+	used   [maxNumberingLevels]bool
+}
+
+// paragraphLabel is the resolved numbering label of a paragraph and the text which separates it from the content
+type paragraphLabel struct {
+	text   string
+	suffix string
+}
+
+// Paragraph is a handle on a paragraph of the main document, it is obtained using Document.Paragraphs.
+// The paragraph is identified by its position among all paragraphs of the document. A handle therefore stays
+// valid as long as no paragraphs are added or removed before it.
+type Paragraph struct {
+	doc   *Document
+	index int
+}
+
+// Paragraphs returns all paragraphs of the main document in the order of their start, including the paragraphs
+// of tables and text boxes.
+func (d *Document) Paragraphs() []*Paragraph {
+	var paragraphs []*Paragraph
+	for i := range ParagraphOpenTagRegex.FindAllIndex(d.GetFile(DocumentXml), -1) {
+		paragraphs = append(paragraphs, &Paragraph{doc: d, index: i})
+	}
+	return paragraphs
+}
+
+// Bytes returns the current markup of the paragraph.
+func (p *Paragraph) Bytes() ([]byte, error) {
+	data := p.doc.GetFile(DocumentXml)
+	tags := ParagraphOpenTagRegex.FindAllIndex(data, -1)
+	if p.index >= len(tags) {
+		return nil, fmt.Errorf("paragraph %d does not exist anymore", p.index)
+	}
+	tag := tags[p.index]
+	if bytes.HasSuffix(data[tag[0]:tag[1]], []byte("/>")) {
+		return data[tag[0]:tag[1]], nil
+	}
+	end, err := closingTagEnd(data, int64(tag[1]))
+	if err != nil {
+		return nil, err
+	}
+	return data[tag[0]:end], nil
+}
+
+// NumberingLabel returns the text which Word displays in front of the paragraph if it is part of a numbered list,
+// e.g. '3.2.' or 'Article IV'. The label is resolved from the numbering definitions by counting all numbered
+// paragraphs before it, honoring start values, overrides, restarts and legal numbering. Bullets are returned as
+// defined, usually as a character of a symbol font. The label is empty for paragraphs which are not numbered.
+func (p *Paragraph) NumberingLabel() string {
+	labels, err := p.doc.numberingLabels()
+	if err != nil {
+		p.doc.warnOnce(err.Error())
+		return ""
+	}
+	if p.index >= len(labels) {
+		return ""
+	}
+	return labels[p.index].text
+}
+
+// numberingLabels returns the numbering labels of all paragraphs of the main document, in the order of
+// ParagraphOpenTagRegex. Paragraphs of fallback content (mc:Fallback) duplicate other paragraphs, they are neither
+// labeled nor counted.
+func (d *Document) numberingLabels() ([]paragraphLabel, error) {
+	data := d.GetFile(DocumentXml)
+	tags := ParagraphOpenTagRegex.FindAllIndex(data, -1)
+	labels := make([]paragraphLabel, len(tags))
+	if !d.partExists(NumberingXml) {
+		return labels, nil
+	}
+
+	numberingData, err := d.getPart(NumberingXml)
+	if err != nil {
+		return nil, err
+	}
+	var numbering numberingDefinitions
+	if err := xml.Unmarshal(numberingData, &numbering); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", NumberingXml, err)
+	}
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	fallbacks := alternateContentFallbacks(data)
+	lists := make(map[string]*numberingList)
+	counters := make(map[string]*numberingCounters)
+	for i, tag := range tags {
+		if positionInside(fallbacks, int64(tag[0])) {
+			continue
+		}
+		var pPr []byte
+		if !bytes.HasSuffix(data[tag[0]:tag[1]], []byte("/>")) {
+			pPr = ParagraphPropertiesRegex.Find(data[tag[1]:])
+		}
+
+		numID, level := d.paragraphNumbering(styles, pPr)
+		if numID == "" || numID == "0" {
+			continue
+		}
+		list, exists := lists[numID]
+		if !exists {
+			list = numbering.resolve(styles, numID)
+			lists[numID] = list
+		}
+		if list == nil || level < 0 || level >= maxNumberingLevels || list.levels[level] == nil {
+			continue
+		}
+		state, exists := counters[list.counterKey]
+		if !exists {
+			state = &numberingCounters{}
+			counters[list.counterKey] = state
+		}
+		labels[i] = list.next(state, level)
+	}
+	return labels, nil
+}
+
+// paragraphNumbering returns the numbering instance and level of a paragraph with the given properties.
+// Numbering properties of the paragraph take precedence over the ones of its style chain.
+func (d *Document) paragraphNumbering(styles styleDefinitions, pPr []byte) (numID string, level int) {
+	level = -1
+	if numPr := NumberingPropertiesRegex.Find(pPr); numPr != nil {
+		numID = tagValue(NumberingIdTagRegex, numPr)
+		if ilvl, err := strconv.Atoi(tagValue(NumberingLevelTagRegex, numPr)); err == nil {
+			level = ilvl
+		}
+	}
+	if numID == "" || level < 0 {
+		styleID := styles.defaultStyle("paragraph")
+		if s := tagValue(ParagraphStyleTagRegex, pPr); s != "" {
+			styleID = s
+		}
+		for _, style := range d.styleChain(styles, styleID, "paragraph") {
+			numPr := NumberingPropertiesRegex.FindString(style.ParagraphProperties.Inner)
+			if numPr == "" {
+				continue
+			}
+			if numID == "" {
+				numID = tagValue(NumberingIdTagRegex, []byte(numPr))
+			}
+			if ilvl, err := strconv.Atoi(tagValue(NumberingLevelTagRegex, []byte(numPr))); err == nil && level < 0 {
+				level = ilvl
+			}
+			break
+		}
+	}
+	if level < 0 {
+		level = 0
+	}
+	return numID, level
+}
+
+// resolve returns the definition of the numbering instance, or nil if it does not exist.
+// Abstract numberings which only link to a numbering style (<w:numStyleLink>) are replaced by the abstract numbering
+// of the instance referenced by that style.
+func (n numberingDefinitions) resolve(styles styleDefinitions, numID string) *numberingList {
+	instance := n.instance(numID)
+	if instance == nil {
+		return nil
+	}
+	abstract := n.abstract(instance.AbstractNumID.Val)
+	if abstract != nil && abstract.NumStyleLink.Val != "" {
+		if style := styles.style(abstract.NumStyleLink.Val); style != nil {
+			linkedID := tagValue(NumberingIdTagRegex, NumberingPropertiesRegex.Find([]byte(style.ParagraphProperties.Inner)))
+			if linked := n.instance(linkedID); linked != nil {
+				abstract = n.abstract(linked.AbstractNumID.Val)
+			}
+		}
+	}
+	if abstract == nil {
+		return nil
+	}
+
+	list := &numberingList{counterKey: "abstract:" + abstract.ID, startOverrides: make(map[int]int)}
+	for i := range abstract.Levels {
+		if l := abstract.Levels[i].Level; l >= 0 && l < maxNumberingLevels {
+			list.levels[l] = &abstract.Levels[i]
+		}
+	}
+	for _, override := range instance.Overrides {
+		if override.Level < 0 || override.Level >= maxNumberingLevels {
+			continue
+		}
+		if override.Definition != nil {
+			list.levels[override.Level] = override.Definition
+		}
+		if override.Start != nil {
+			start, _ := strconv.Atoi(override.Start.Val)
+			list.startOverrides[override.Level] = start
+			// the instance restarts the numbering, it does not continue the one of other instances
+			list.counterKey = "num:" + numID
+		}
+	}
+	return list
+}
+
+// instance returns the numbering instance with the given ID.
+func (n numberingDefinitions) instance(numID string) *numberingInstance {
+	for i := range n.Nums {
+		if n.Nums[i].ID == numID {
+			return &n.Nums[i]
+		}
+	}
+	return nil
+}
+
+// abstract returns the abstract numbering with the given ID.
+func (n numberingDefinitions) abstract(abstractNumID string) *abstractNumbering {
+	for i := range n.AbstractNums {
+		if n.AbstractNums[i].ID == abstractNumID {
+			return &n.AbstractNums[i]
+		}
+	}
+	return nil
+}
+
+// start returns the start value of the given level of the list.
+func (list *numberingList) start(level int) int {
+	if start, exists := list.startOverrides[level]; exists {
+		return start
+	}
+	return list.levels[level].startValue()
+}
+
+// next counts a paragraph of the given level and returns its label.
+func (list *numberingList) next(state *numberingCounters, level int) paragraphLabel {
+	if state.used[level] {
+		state.values[level]++
+	} else {
+		state.values[level] = list.start(level)
+		state.used[level] = true
+	}
+	for deeper := level + 1; deeper < maxNumberingLevels; deeper++ {
+		if list.levels[deeper].restartsAfter(level) {
+			state.used[deeper] = false
+		}
+	}
+
+	definition := list.levels[level]
+	legal := definition.legal()
+	text := levelTextPlaceholderRegex.ReplaceAllStringFunc(definition.Text.Val, func(placeholder string) string {
+		referenced := int(placeholder[1] - '1')
+		value := list.start(referenced)
+		if state.used[referenced] {
+			value = state.values[referenced]
+		}
+		format := "decimal"
+		if list.levels[referenced] != nil && !legal {
+			format = list.levels[referenced].Format.Val
+		}
+		return formatNumber(value, format)
+	})
+	if definition.Format.Val == "none" {
+		text = levelTextPlaceholderRegex.ReplaceAllString(definition.Text.Val, "")
+	}
+
+	suffix := "\t"
+	switch definition.Suffix.Val {
+	case "space":
+		suffix = " "
+	case "nothing":
+		suffix = ""
+	}
+	return paragraphLabel{text: text, suffix: suffix}
+}
+
+// formatNumber returns the number in the given number format (<w:numFmt>).
+// Unsupported formats are rendered as decimal numbers.
+func formatNumber(value int, format string) string {
+	switch format {
+	case "lowerLetter":
+		return strings.ToLower(letterNumber(value))
+	case "upperLetter":
+		return letterNumber(value)
+	case "lowerRoman":
+		return strings.ToLower(romanNumber(value))
+	case "upperRoman":
+		return romanNumber(value)
+	case "decimalZero":
+		if value >= 0 && value < 10 {
+			return "0" + strconv.Itoa(value)
+		}
+	case "ordinal":
+		return strconv.Itoa(value) + ordinalSuffix(value)
+	case "none":
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// ordinalSuffix returns the English ordinal suffix of the number, e.g. 'nd' for 2 and 'th' for 12.
+func ordinalSuffix(value int) string {
+	if value%100 >= 11 && value%100 <= 13 {
+		return "th"
+	}
+	switch value % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
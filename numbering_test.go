@@ -0,0 +1,76 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParagraph_NumberingLabel(t *testing.T) {
+	numbering := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:abstractNum w:abstractNumId="0">` +
+		`<w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="upperRoman"/><w:lvlText w:val="Article %1"/><w:pStyle w:val="Heading1"/></w:lvl>` +
+		`<w:lvl w:ilvl="1"><w:start w:val="1"/><w:numFmt w:val="decimal"/><w:lvlText w:val="Section %2"/><w:suff w:val="space"/></w:lvl>` +
+		`<w:lvl w:ilvl="2"><w:start w:val="1"/><w:numFmt w:val="lowerLetter"/><w:lvlText w:val="%2(%3)"/></w:lvl>` +
+		`<w:lvl w:ilvl="3"><w:start w:val="1"/><w:numFmt w:val="lowerRoman"/><w:lvlText w:val="%1.%2.%3.%4"/><w:isLgl/></w:lvl>` +
+		`</w:abstractNum>` +
+		`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="decimalZero"/><w:lvlText w:val="%1."/></w:lvl></w:abstractNum>` +
+		`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>` +
+		`<w:num w:numId="2"><w:abstractNumId w:val="0"/><w:lvlOverride w:ilvl="0"><w:startOverride w:val="5"/></w:lvlOverride></w:num>` +
+		`<w:num w:numId="3"><w:abstractNumId w:val="0"/></w:num>` +
+		`<w:num w:numId="4"><w:abstractNumId w:val="1"/></w:num>` +
+		`</w:numbering>`
+	styles := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"/>` +
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:basedOn w:val="Normal"/><w:pPr><w:numPr><w:numId w:val="1"/></w:numPr></w:pPr></w:style>` +
+		`</w:styles>`
+	numbered := func(numID, level, text string) string {
+		return `<w:p><w:pPr><w:numPr><w:ilvl w:val="` + level + `"/><w:numId w:val="` + numID + `"/></w:numPr></w:pPr>` +
+			`<w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	body := numbered("1", "0", "Definitions") +
+		numbered("1", "1", "Terms") +
+		numbered("1", "2", "first") +
+		numbered("1", "2", "second") +
+		numbered("1", "1", "Scope") +
+		numbered("1", "2", "third") +
+		numbered("1", "3", "detail") +
+		`<w:p><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:txbxContent><w:p><w:r><w:t>box</w:t></w:r></w:p></w:txbxContent></mc:Choice>` +
+		`<mc:Fallback><w:txbxContent>` + numbered("1", "0", "box") + `</w:txbxContent></mc:Fallback></mc:AlternateContent></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Obligations</w:t></w:r></w:p>` +
+		numbered("3", "1", "Payment") +
+		numbered("2", "0", "Annex") +
+		numbered("4", "0", "zero padded") +
+		numbered("0", "0", "removed numbering")
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:  testDocumentXml(body),
+		NumberingXml: numbering,
+		StylesXml:    styles,
+	})
+
+	expected := []string{"Article I", "Section 1", "1(a)", "1(b)", "Section 2", "2(a)", "1.2.1.1",
+		"", "", "", "Article II", "Section 1", "Article V", "01.", ""}
+	paragraphs := doc.Paragraphs()
+	if len(paragraphs) != len(expected) {
+		t.Fatalf("expected %d paragraphs, got %d", len(expected), len(paragraphs))
+	}
+	for i, paragraph := range paragraphs {
+		if label := paragraph.NumberingLabel(); label != expected[i] {
+			t.Errorf("paragraph %d: got label %q, expected %q", i, label, expected[i])
+		}
+	}
+
+	text, err := doc.PlainTextWith(TextOptions{NumberingLabels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"Article I\tDefinitions\n", "Section 1 Terms\n", "1(a)\tfirst\n", "Article II\tObligations\n", "\nremoved numbering\n"} {
+		if !strings.Contains(text, line) {
+			t.Errorf("expected %q in text %q", line, text)
+		}
+	}
+	if plain, _ := doc.PlainText(); strings.Contains(plain, "Article") {
+		t.Errorf("unexpected labels in plain text %q", plain)
+	}
+}
@@ -0,0 +1,342 @@
+package docx
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// RelationshipReferenceRegex matches attributes referencing a relationship of the part, e.g. r:id="rId5" or r:embed="rId7"
+	RelationshipReferenceRegex = regexp.MustCompile(`(\sr:[A-Za-z]+=")([^"]*)(")`)
+	// OutlineLevelTagRegex matches the outline level of a paragraph (<w:outlineLvl w:val="0"/>), 0 is the first level
+	OutlineLevelTagRegex = regexp.MustCompile(`<w:outlineLvl(?:\s[^>]*)?/?>`)
+)
+
+// SectionMapping maps a bookmarked section or the section of a heading of the base document to the bookmarked
+// section or the section of a heading of an overlay document which should replace it.
+//
+// The section of a heading consists of the elements of the body after the heading paragraph up to the next heading
+// of the same or a higher level, the heading itself is kept like a bookmark. Headings are the paragraphs of the body
+// with a heading style ('Heading1' to 'Heading9') or an outline level, they are identified by their text.
+type SectionMapping struct {
+	// Bookmark is the name of the bookmark inside the base document whose content is going to be replaced.
+	Bookmark string
+	// OverlayBookmark is the name of the bookmark inside the overlay document which provides the new content.
+	// If it is empty, the overlay bookmark is expected to have the same name as Bookmark.
+	OverlayBookmark string
+	// Heading is the text of the heading inside the base document whose section is replaced, instead of Bookmark.
+	Heading string
+	// OverlayHeading is the text of the heading inside the overlay document which provides the new content,
+	// instead of OverlayBookmark. If neither is set, the overlay section has the same bookmark or heading as the base.
+	OverlayHeading string
+}
+
+// edit describes a single modification of a byte slice: everything inside Position is replaced by Replacement.
+type edit struct {
+	Position    Position
+	Replacement []byte
+}
+
+// Overlay replaces the content of bookmarked sections or sections of headings inside the base document with the
+// content of the sections of the overlay document, as specified by the mapping. The bookmarks and headings
+// themselves are kept.
+// Relationships referenced by the overlay content (images, hyperlinks, ...) are imported into the base document,
+// including the parts they are pointing to.
+//
+// Only bookmarks and headings inside the main document (word/document.xml) are considered. Styles and numbering
+// definitions are not imported, the overlay content is expected to use the same style IDs as the base document.
+//
+// All mappings are validated before the base document is modified. An error is returned if a mapping names both
+// a bookmark and a heading, if a mapped bookmark or heading does not exist, if the base sections of multiple
+// mappings overlap, or if the result would not be well-formed XML.
+// In all of these cases the base document remains untouched.
+func Overlay(base, overlay *Document, mapping []SectionMapping) error {
+	baseBytes := base.GetFile(DocumentXml)
+	overlayBytes := overlay.GetFile(DocumentXml)
+
+	baseSections, err := newSections(baseBytes)
+	if err != nil {
+		return fmt.Errorf("unable to find the sections of the base document: %w", err)
+	}
+	overlaySections, err := newSections(overlayBytes)
+	if err != nil {
+		return fmt.Errorf("unable to find the sections of the overlay document: %w", err)
+	}
+
+	var edits []edit
+	for i, m := range mapping {
+		overlayBookmark, overlayHeading := m.OverlayBookmark, m.OverlayHeading
+		if overlayBookmark == "" && overlayHeading == "" {
+			overlayBookmark, overlayHeading = m.Bookmark, m.Heading
+		}
+		if (m.Bookmark != "") == (m.Heading != "") || overlayBookmark != "" && overlayHeading != "" {
+			return fmt.Errorf("mapping %d must name either a bookmark or a heading of each document", i+1)
+		}
+
+		baseContent, err := baseSections.content(m.Bookmark, m.Heading)
+		if err != nil {
+			return fmt.Errorf("%s does not exist in base document", err)
+		}
+		content, err := overlaySections.content(overlayBookmark, overlayHeading)
+		if err != nil {
+			return fmt.Errorf("%s does not exist in overlay document", err)
+		}
+
+		replacement := make([]byte, content.End-content.Start)
+		copy(replacement, overlayBytes[content.Start:content.End])
+
+		edits = append(edits, edit{Position: baseContent, Replacement: replacement})
+	}
+
+	// ensure that no section is replaced twice
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Position.Start < edits[j].Position.Start
+	})
+	for i := 1; i < len(edits); i++ {
+		if edits[i].Position.Start < edits[i-1].Position.End {
+			return fmt.Errorf("overlapping sections at offset %d", edits[i].Position.Start)
+		}
+	}
+
+	result := applyEdits(baseBytes, edits)
+	if err := checkWellFormed(result); err != nil {
+		return fmt.Errorf("overlay would produce invalid XML: %w", err)
+	}
+
+	// importing relationships is the first step which touches the base document, restore the parts on failure
+	partsBackup := make(FileMap, len(base.modifiedParts))
+	for name, data := range base.modifiedParts {
+		partsBackup[name] = data
+	}
+	importer := newRelationshipImporter(base, overlay)
+	result, err = importer.rewriteReferences(result, edits)
+	if err != nil {
+		for name := range base.modifiedParts {
+			base.markChanged(name)
+		}
+		base.modifiedParts = partsBackup
+		return err
+	}
+
+	if err := base.SetFile(DocumentXml, result); err != nil {
+		return err
+	}
+	return base.parseFile(DocumentXml)
+}
+
+// sections are the bookmarked sections and the sections of the headings of a main document, see SectionMapping.
+type sections struct {
+	bookmarks map[string]Bookmark
+	headings  map[string]Position
+}
+
+// newSections finds the sections of the main document.
+func newSections(data []byte) (*sections, error) {
+	headings, err := headingSections(data)
+	if err != nil {
+		return nil, err
+	}
+	return &sections{bookmarks: bookmarksByName(FindBookmarks(data)), headings: headings}, nil
+}
+
+// content returns the content of the section with the given bookmark or heading.
+// The error names the missing bookmark or heading.
+func (s *sections) content(bookmark, heading string) (Position, error) {
+	if bookmark != "" {
+		if b, exists := s.bookmarks[bookmark]; exists {
+			return b.Content(), nil
+		}
+		return Position{}, fmt.Errorf("bookmark '%s'", bookmark)
+	}
+	if content, exists := s.headings[heading]; exists {
+		return content, nil
+	}
+	return Position{}, fmt.Errorf("heading '%s'", heading)
+}
+
+// headingSections returns the content of the sections of all headings of the body by the text of the heading.
+// If a text occurs multiple times, the first heading wins.
+func headingSections(data []byte) (map[string]Position, error) {
+	elements, err := BodyElements(data)
+	if err != nil {
+		return nil, err
+	}
+	levels := make([]int, len(elements))
+	for i, element := range elements {
+		if element.Name == ParagraphElementName {
+			levels[i] = headingLevel(element.Bytes(data))
+		}
+	}
+
+	headings := make(map[string]Position)
+	for i, element := range elements {
+		if levels[i] == 0 {
+			continue
+		}
+		text := headingText(element.Bytes(data))
+		if _, exists := headings[text]; exists {
+			continue
+		}
+		// the section ends before the next heading of the same or a higher level or the section properties of the body
+		end := element.Position.End
+		for j := i + 1; j < len(elements); j++ {
+			if elements[j].Name == SectionPropertiesElementName || levels[j] != 0 && levels[j] <= levels[i] {
+				break
+			}
+			end = elements[j].Position.End
+		}
+		headings[text] = Position{Start: element.Position.End, End: end}
+	}
+	return headings, nil
+}
+
+// headingLevel returns the level of the heading paragraph, from 1 to 9, or 0 if the paragraph is no heading.
+// The heading styles ('Heading1') take precedence over the outline level.
+func headingLevel(paragraph []byte) int {
+	if style := tagValue(ParagraphStyleTagRegex, paragraph); strings.HasPrefix(style, "Heading") {
+		if level, err := strconv.Atoi(strings.TrimPrefix(style, "Heading")); err == nil && level >= 1 && level <= 9 {
+			return level
+		}
+	}
+	if outline := tagValue(OutlineLevelTagRegex, paragraph); outline != "" {
+		// outline level 9 is body text
+		if level, err := strconv.Atoi(outline); err == nil && level >= 0 && level <= 8 {
+			return level + 1
+		}
+	}
+	return 0
+}
+
+// headingText returns the text of the heading paragraph without surrounding whitespace.
+func headingText(paragraph []byte) string {
+	var text strings.Builder
+	for _, match := range TextContentRegex.FindAllSubmatch(paragraph, -1) {
+		text.WriteString(html.UnescapeString(string(match[1])))
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// bookmarksByName indexes the given bookmarks by their name.
+// If a name occurs multiple times, the first bookmark wins.
+func bookmarksByName(bookmarks []Bookmark) map[string]Bookmark {
+	index := make(map[string]Bookmark)
+	for _, bookmark := range bookmarks {
+		if _, exists := index[bookmark.Name]; !exists {
+			index[bookmark.Name] = bookmark
+		}
+	}
+	return index
+}
+
+// applyEdits returns a copy of data with all edits applied.
+// The edits must be sorted by their start position and must not overlap.
+func applyEdits(data []byte, edits []edit) []byte {
+	var result []byte
+	var last int64
+	for _, e := range edits {
+		result = append(result, data[last:e.Position.Start]...)
+		result = append(result, e.Replacement...)
+		last = e.Position.End
+	}
+	return append(result, data[last:]...)
+}
+
+// relationshipImporter copies relationships of the main document of one Document into another one.
+// Every relationship is imported at most once, repeated references reuse the new relationship ID.
+type relationshipImporter struct {
+	target   *Document
+	source   *Document
+	imported map[string]string
+}
+
+func newRelationshipImporter(target, source *Document) *relationshipImporter {
+	return &relationshipImporter{
+		target:   target,
+		source:   source,
+		imported: make(map[string]string),
+	}
+}
+
+// rewriteReferences imports all relationships referenced inside the replaced regions of data and rewrites the
+// references to the newly allocated IDs. The edits must be the ones which were applied to produce data.
+func (ri *relationshipImporter) rewriteReferences(data []byte, edits []edit) ([]byte, error) {
+	var rewritten []edit
+	var shift int64
+	for _, e := range edits {
+		start := e.Position.Start + shift
+		end := start + int64(len(e.Replacement))
+		shift += int64(len(e.Replacement)) - (e.Position.End - e.Position.Start)
+
+		var importErr error
+		replacement := RelationshipReferenceRegex.ReplaceAllFunc(data[start:end], func(match []byte) []byte {
+			parts := RelationshipReferenceRegex.FindSubmatch(match)
+			id, err := ri.importRelationship(string(parts[2]))
+			if err != nil {
+				importErr = err
+				return match
+			}
+			return []byte(string(parts[1]) + id + string(parts[3]))
+		})
+		if importErr != nil {
+			return nil, importErr
+		}
+		rewritten = append(rewritten, edit{Position: Position{start, end}, Replacement: replacement})
+	}
+	return applyEdits(data, rewritten), nil
+}
+
+// importRelationship copies the relationship with the given ID of the source main document into the
+// target main document and returns the new ID. Internal targets are copied into the target package.
+func (ri *relationshipImporter) importRelationship(id string) (string, error) {
+	if newID, ok := ri.imported[id]; ok {
+		return newID, nil
+	}
+
+	rels, err := ri.source.Relationships(DocumentXml)
+	if err != nil {
+		return "", err
+	}
+	var rel *Relationship
+	for i := range rels {
+		if rels[i].ID == id {
+			rel = &rels[i]
+			break
+		}
+	}
+	if rel == nil {
+		return "", fmt.Errorf("relationship %s does not exist in source document", id)
+	}
+
+	newRel := *rel
+	if !rel.IsExternal() {
+		sourcePart := resolveTarget(DocumentXml, rel.Target)
+		data, err := ri.source.getPart(sourcePart)
+		if err != nil {
+			return "", fmt.Errorf("unable to import relationship %s: %w", id, err)
+		}
+		contentType, err := ri.source.ContentType(sourcePart)
+		if err != nil {
+			return "", err
+		}
+
+		targetPart := ri.target.uniquePartName(sourcePart)
+		if err := ri.target.setPart(targetPart, data); err != nil {
+			return "", err
+		}
+		if err := ri.target.ensureContentType(targetPart, contentType); err != nil {
+			return "", err
+		}
+		newRel.Target = relativeTarget(DocumentXml, targetPart)
+	}
+
+	newID, err := ri.target.addRelationship(DocumentXml, newRel)
+	if err != nil {
+		return "", err
+	}
+	ri.imported[id] = newID
+	return newID, nil
+}
@@ -0,0 +1,162 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func overlayTestDocuments(t *testing.T) (*Document, *Document) {
+	base := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(
+			`<w:p><w:r><w:t>{title}</w:t></w:r></w:p>` +
+				`<w:bookmarkStart w:id="0" w:name="terms"/><w:p><w:r><w:t>base terms</w:t></w:r></w:p><w:bookmarkEnd w:id="0"/>` +
+				`<w:bookmarkStart w:id="1" w:name="footer"/><w:p><w:r><w:t>base footer</w:t></w:r></w:p><w:bookmarkEnd w:id="1"/>`),
+		RelsPath(DocumentXml): `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`,
+		"word/media/image1.png": "base-image",
+	})
+
+	overlay := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(
+			`<w:bookmarkStart w:id="5" w:name="terms-kz"/><w:p><w:r><w:t>{country} terms</w:t></w:r></w:p>` +
+				`<w:p><w:r><w:drawing><a:blip xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" r:embed="rId3"/></w:drawing></w:r></w:p>` +
+				`<w:p><w:hyperlink r:id="rId4"><w:r><w:t>link</w:t></w:r></w:hyperlink></w:p><w:bookmarkEnd w:id="5"/>`),
+		RelsPath(DocumentXml): `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.png"/>` +
+			`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/?a=1&amp;b=2" TargetMode="External"/>` +
+			`</Relationships>`,
+		ContentTypesXml: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="png" ContentType="image/png"/>` +
+			`</Types>`,
+		"word/media/image1.png": "overlay-image",
+	})
+	return base, overlay
+}
+
+func TestOverlay(t *testing.T) {
+	base, overlay := overlayTestDocuments(t)
+
+	err := Overlay(base, overlay, []SectionMapping{{Bookmark: "terms", OverlayBookmark: "terms-kz"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result := reopen(t, base)
+	documentXml := string(result.GetFile(DocumentXml))
+	if strings.Contains(documentXml, "base terms") {
+		t.Error("base section was not replaced")
+	}
+	if !strings.Contains(documentXml, "base footer") {
+		t.Error("unmapped section must not be replaced")
+	}
+	if !strings.Contains(documentXml, `w:name="terms"`) {
+		t.Error("base bookmark must be preserved")
+	}
+
+	// placeholders of the overlay are now part of the base document
+	if len(result.placeholderKeys()) != 2 {
+		t.Errorf("expected placeholders of base and overlay, have %v", result.placeholderKeys())
+	}
+
+	rels, err := result.Relationships(DocumentXml)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rels) != 3 {
+		t.Errorf("expected 3 relationships, have %d", len(rels))
+	}
+	for _, rel := range rels[1:] {
+		if !strings.Contains(documentXml, `r:embed="`+rel.ID+`"`) && !strings.Contains(documentXml, `r:id="`+rel.ID+`"`) {
+			t.Errorf("relationship %s is not referenced", rel.ID)
+		}
+		if rel.IsExternal() {
+			if rel.Target != "https://example.com/?a=1&b=2" {
+				t.Errorf("unexpected hyperlink target %s", rel.Target)
+			}
+			continue
+		}
+		image, err := result.getPart(resolveTarget(DocumentXml, rel.Target))
+		if err != nil || string(image) != "overlay-image" {
+			t.Errorf("image was not imported into %s", rel.Target)
+		}
+	}
+
+	original, _ := result.getPart("word/media/image1.png")
+	if string(original) != "base-image" {
+		t.Error("existing media of the base document must not be overwritten")
+	}
+	contentType, _ := result.ContentType("word/media/image1_1.png")
+	if contentType != "image/png" {
+		t.Errorf("unexpected content type of imported image: %s", contentType)
+	}
+}
+
+func TestOverlay_Headings(t *testing.T) {
+	heading := func(style, text string) string {
+		return `<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr><w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	paragraph := func(text string) string {
+		return `<w:p><w:r><w:t>` + text + `</w:t></w:r></w:p>`
+	}
+	base := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(
+		heading("Heading1", "Terms") + paragraph("base terms") + heading("Heading2", "Details") + paragraph("base details") +
+			heading("Heading1", "Signatures") + paragraph("base signatures") + `<w:sectPr/>`)})
+	overlay := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(
+		`<w:p><w:pPr><w:outlineLvl w:val="0"/></w:pPr><w:r><w:t xml:space="preserve"> Terms &amp; </w:t></w:r><w:r><w:t>conditions</w:t></w:r></w:p>` +
+			paragraph("kz terms") + heading("Heading1", "Other") + paragraph("other") +
+			`<w:bookmarkStart w:id="0" w:name="signatures-kz"/>` + paragraph("kz signatures") + `<w:bookmarkEnd w:id="0"/>`)})
+
+	err := Overlay(base, overlay, []SectionMapping{
+		{Heading: "Terms", OverlayHeading: "Terms & conditions"},
+		{Heading: "Signatures", OverlayBookmark: "signatures-kz"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the section of a heading contains its subsections, the headings are kept
+	expected := heading("Heading1", "Terms") + paragraph("kz terms") +
+		heading("Heading1", "Signatures") + paragraph("kz signatures") + `<w:sectPr/>`
+	if result := string(base.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestOverlay_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping []SectionMapping
+	}{
+		{"missing base bookmark", []SectionMapping{{Bookmark: "nope", OverlayBookmark: "terms-kz"}}},
+		{"missing overlay bookmark", []SectionMapping{{Bookmark: "terms"}}},
+		{"missing base heading", []SectionMapping{{Heading: "Terms", OverlayBookmark: "terms-kz"}}},
+		{"bookmark and heading", []SectionMapping{{Bookmark: "terms", Heading: "Terms", OverlayBookmark: "terms-kz"}}},
+		{"overlay bookmark and heading", []SectionMapping{{Bookmark: "terms", OverlayBookmark: "terms-kz", OverlayHeading: "Terms"}}},
+		{"overlapping sections", []SectionMapping{
+			{Bookmark: "terms", OverlayBookmark: "terms-kz"},
+			{Bookmark: "terms", OverlayBookmark: "terms-kz"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base, overlay := overlayTestDocuments(t)
+			before := string(base.GetFile(DocumentXml))
+
+			if err := Overlay(base, overlay, tt.mapping); err == nil {
+				t.Error("expected an error")
+			}
+			if before != string(base.GetFile(DocumentXml)) {
+				t.Error("base document must not be modified")
+			}
+			if len(base.modifiedParts) != 0 {
+				t.Error("base parts must not be modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package docx
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// StylesXml is the relative path of the style definitions inside the docx-archive.
+	StylesXml = "word/styles.xml"
+	// TableElementName is the local name of tables (<w:tbl>)
+	TableElementName = "tbl"
+
+	// defaultFontSize is the font size in points which applies if neither the run nor the defaults define one
+	defaultFontSize = 10.0
+	// lineHeightFactor is the ratio between the height of a single-spaced line and the font size
+	lineHeightFactor = 1.2
+)
+
+// EstimateConfidence describes how reliable a page count estimation is.
+type EstimateConfidence int
+
+const (
+	// ConfidenceLow means that the document contains content whose layout is not estimated at all,
+	// e.g. floating drawings or text boxes.
+	ConfidenceLow EstimateConfidence = iota
+	// ConfidenceMedium means that the document contains tables or inline drawings whose layout is roughly estimated.
+	ConfidenceMedium
+	// ConfidenceHigh means that the document only consists of text paragraphs and explicit breaks.
+	ConfidenceHigh
+)
+
+// String implements the Stringer interface.
+func (c EstimateConfidence) String() string {
+	switch c {
+	case ConfidenceHigh:
+		return "high"
+	case ConfidenceMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+var (
+	// RunRegex matches a complete run including its content
+	RunRegex = regexp.MustCompile(`(?s)<w:r(?:\s[^>]*)?>.*?</w:r>`)
+	// ParagraphRegex matches a paragraph including its content, nested paragraphs (e.g. in text boxes) are not supported
+	ParagraphRegex = regexp.MustCompile(`(?s)<w:p(?:\s[^>]*)?>.*?</w:p>|<w:p(?:\s[^>]*)?/>`)
+	// TableRowRegex matches a table row including its content
+	TableRowRegex = regexp.MustCompile(`(?s)<w:tr(?:\s[^>]*)?>.*?</w:tr>`)
+	// TableCellRegex matches a table cell including its content
+	TableCellRegex = regexp.MustCompile(`(?s)<w:tc(?:\s[^>]*)?>.*?</w:tc>`)
+	// LineBreakRegex matches text wrapping breaks (<w:br/>), page and column breaks are excluded
+	LineBreakRegex = regexp.MustCompile(`<w:br(?:\s+w:type="textWrapping")?\s*/>`)
+	// PageBreakBeforeRegex matches the paragraph property which forces the paragraph onto a new page
+	PageBreakBeforeRegex = regexp.MustCompile(`<w:pageBreakBefore(?:\s+w:val="(?:1|true|on)")?\s*/>`)
+	// InlineExtentRegex matches the extent of inline drawings, capturing the height in EMU
+	InlineExtentRegex = regexp.MustCompile(`(?s)<wp:inline[\s>].*?<wp:extent\s[^>]*cy="([0-9]+)"`)
+	// FloatingContentRegex matches content which floats and is therefore not part of the estimation
+	FloatingContentRegex = regexp.MustCompile(`<(?:wp:anchor|w:txbxContent|v:textbox)[\s>]`)
+	// SpacingTagRegex matches the paragraph spacing
+	SpacingTagRegex = regexp.MustCompile(`<w:spacing(?:\s[^>]*)?/?>`)
+	// FontSizeTagRegex matches the font size of run properties
+	FontSizeTagRegex = regexp.MustCompile(`<w:sz\s[^>]*w:val="([0-9]+)"`)
+	// DocDefaultsRegex matches the document defaults inside the styles
+	DocDefaultsRegex = regexp.MustCompile(`(?s)<w:docDefaults>.*?</w:docDefaults>`)
+)
+
+// pageEstimator keeps track of the state while estimating the page count.
+type pageEstimator struct {
+	pages      int
+	used       int // height in twips already used on the current page
+	setup      PageSetup
+	confidence EstimateConfidence
+
+	defaultFont string
+	defaultSize float64
+}
+
+// EstimatePageCount estimates the amount of pages of the document without an actual layout engine.
+// The estimation is based on the page setup of every section, explicit page breaks and the heights of paragraphs
+// whose line count is calculated from the estimated text width (see FontMetrics) and the column width.
+//
+// The result is only an approximation, it does not account for widow control, hyphenation, headers and footers,
+// footnotes or floating content. The returned confidence tells how much of the document could be considered.
+func (d *Document) EstimatePageCount() (int, EstimateConfidence) {
+	data := d.GetFile(DocumentXml)
+	elements, err := BodyElements(data)
+	if err != nil {
+		return 0, ConfidenceLow
+	}
+
+	estimator := &pageEstimator{
+		pages:      1,
+		confidence: ConfidenceHigh,
+	}
+	estimator.defaultFont, estimator.defaultSize = d.defaultRunFont()
+
+	// the properties of a section are defined at its end, either inside the last paragraph or the body
+	sections := sectionSetups(data, elements)
+	section := 0
+	estimator.setup = sections[0]
+
+	for _, elem := range elements {
+		markup := elem.Bytes(data)
+		if FloatingContentRegex.Match(markup) {
+			estimator.lower(ConfidenceLow)
+		}
+
+		switch elem.Name {
+		case ParagraphElementName:
+			estimator.paragraph(markup, estimator.setup.ContentWidth()/estimator.setup.Columns)
+
+			// the paragraph ends the current section, the next section may start on a new page
+			if SectionPropertiesRegex.Match(markup) && section+1 < len(sections) {
+				section++
+				estimator.setup = sections[section]
+				if estimator.setup.Type != SectionTypeContinuous {
+					estimator.newPage()
+				}
+			}
+		case TableElementName:
+			estimator.lower(ConfidenceMedium)
+			estimator.table(markup)
+		}
+	}
+
+	return estimator.pages, estimator.confidence
+}
+
+// defaultRunFont returns the font and size (in points) of runs which do not define them, as defined by
+// the document defaults of the styles.
+func (d *Document) defaultRunFont() (font string, size float64) {
+	size = defaultFontSize
+	if styles, err := d.getPart(StylesXml); err == nil {
+		if defaults := DocDefaultsRegex.Find(styles); defaults != nil {
+			font = runFont(string(defaults))
+			if s, ok := fontSize(defaults); ok {
+				size = s
+			}
+		}
+	}
+	return font, size
+}
+
+// sectionSetups returns the page setups of all sections in document order.
+// There is always at least one section.
+func sectionSetups(data []byte, elements []Element) []PageSetup {
+	var sections []PageSetup
+	for _, elem := range elements {
+		sectPr := SectionPropertiesRegex.Find(elem.Bytes(data))
+		if sectPr == nil {
+			continue
+		}
+		if elem.Name == ParagraphElementName || elem.Name == SectionPropertiesElementName {
+			sections = append(sections, ParsePageSetup(sectPr))
+		}
+	}
+	if len(sections) == 0 {
+		sections = append(sections, DefaultPageSetup)
+	}
+	return sections
+}
+
+// lower reduces the confidence to the given level.
+func (e *pageEstimator) lower(confidence EstimateConfidence) {
+	if confidence < e.confidence {
+		e.confidence = confidence
+	}
+}
+
+// newPage starts a new page.
+func (e *pageEstimator) newPage() {
+	e.pages++
+	e.used = 0
+}
+
+// add adds content of the given height to the current page, starting new pages as required.
+func (e *pageEstimator) add(height int) {
+	pageHeight := e.setup.ContentHeight() * e.setup.Columns
+	if pageHeight <= 0 {
+		return
+	}
+	e.used += height
+	for e.used > pageHeight {
+		e.pages++
+		e.used -= pageHeight
+	}
+}
+
+// paragraph adds the estimated height of the given paragraph, laid out in a column of the given width.
+// Explicit page breaks split the paragraph, the content after a break starts on a new page.
+func (e *pageEstimator) paragraph(paragraph []byte, columnWidth int) {
+	if PageBreakBeforeRegex.Match(paragraph) && e.used > 0 {
+		e.newPage()
+	}
+
+	before, after, lineFactor := paragraphSpacing(paragraph)
+	e.add(before)
+
+	block := e.newTextBlock()
+	flush := func() {
+		e.add(int(math.Ceil(float64(block.lines(columnWidth)) * block.lineHeight() * lineFactor)))
+		for _, height := range block.drawings {
+			e.lower(ConfidenceMedium)
+			e.add(height)
+		}
+	}
+
+	for _, run := range RunRegex.FindAll(paragraph, -1) {
+		for i, segment := range PageBreakRegex.Split(string(run), -1) {
+			if i > 0 {
+				flush()
+				e.newPage()
+				block = e.newTextBlock()
+			}
+			block.addRun(run, []byte(segment))
+		}
+	}
+	flush()
+	e.add(after)
+}
+
+// textBlock accumulates the text of consecutive runs in order to estimate the lines they occupy.
+type textBlock struct {
+	width    int // estimated width of all text in twips
+	breaks   int
+	maxSize  float64
+	hasText  bool
+	drawings []int // heights of inline drawings in twips
+
+	defaultFont string
+	defaultSize float64
+}
+
+func (e *pageEstimator) newTextBlock() *textBlock {
+	return &textBlock{defaultFont: e.defaultFont, defaultSize: e.defaultSize}
+}
+
+// addRun adds the content of the given segment of a run, the run itself is used to determine its properties.
+func (b *textBlock) addRun(run, segment []byte) {
+	props := ""
+	if match := RunPropertiesRegex.FindSubmatch(run); match != nil {
+		props = string(match[1])
+	}
+	font := runFont(props)
+	if font == "" {
+		font = b.defaultFont
+	}
+	size, ok := fontSize([]byte(props))
+	if !ok {
+		size = b.defaultSize
+	}
+	if size > b.maxSize {
+		b.maxSize = size
+	}
+
+	metrics := MetricsForFont(font)
+	for _, text := range TextContentRegex.FindAllSubmatch(segment, -1) {
+		b.hasText = true
+		b.width += metrics.TextWidth(string(text[1]), size)
+	}
+	b.breaks += len(LineBreakRegex.FindAllIndex(segment, -1))
+
+	for _, match := range InlineExtentRegex.FindAllSubmatch(segment, -1) {
+		if emu, err := strconv.Atoi(string(match[1])); err == nil {
+			b.drawings = append(b.drawings, emu/635) // 635 EMU per twip
+		}
+	}
+}
+
+// lines returns the amount of lines inside a column of the given width. There is always at least one line.
+func (b *textBlock) lines(columnWidth int) int {
+	lines := b.breaks
+	if b.hasText && columnWidth > 0 {
+		lines += int(math.Ceil(float64(b.width) / float64(columnWidth)))
+	}
+	if lines == 0 {
+		lines = 1
+	}
+	return lines
+}
+
+// lineHeight returns the height of a single-spaced line in twips based on the largest font size used.
+func (b *textBlock) lineHeight() float64 {
+	size := b.maxSize
+	if size == 0 {
+		size = b.defaultSize
+	}
+	return size * lineHeightFactor * 20
+}
+
+// table adds the estimated height of the given table. Every row is as high as its highest cell, the cells
+// are assumed to share the content width equally.
+func (e *pageEstimator) table(table []byte) {
+	for _, row := range TableRowRegex.FindAll(table, -1) {
+		cells := TableCellRegex.FindAll(row, -1)
+		if len(cells) == 0 {
+			continue
+		}
+		cellWidth := e.setup.ContentWidth() / e.setup.Columns / len(cells)
+
+		rowHeight := 0
+		for _, cell := range cells {
+			block := e.newTextBlock()
+			lines := 0
+			// every paragraph of the cell starts on a new line
+			for _, paragraph := range ParagraphRegex.FindAll(cell, -1) {
+				block = e.newTextBlock()
+				for _, run := range RunRegex.FindAll(paragraph, -1) {
+					block.addRun(run, run)
+				}
+				lines += block.lines(cellWidth)
+			}
+			if lines == 0 {
+				lines = 1
+			}
+			if height := int(math.Ceil(float64(lines) * block.lineHeight())); height > rowHeight {
+				rowHeight = height
+			}
+		}
+		e.add(rowHeight)
+	}
+}
+
+// paragraphSpacing returns the spacing before and after the paragraph in twips as well as the line spacing factor.
+func paragraphSpacing(paragraph []byte) (before, after int, lineFactor float64) {
+	lineFactor = 1
+	tag := SpacingTagRegex.Find(paragraph)
+	if tag == nil {
+		return 0, 0, lineFactor
+	}
+	if value, ok := attributeValue(tag, "w:before"); ok {
+		before, _ = strconv.Atoi(value)
+	}
+	if value, ok := attributeValue(tag, "w:after"); ok {
+		after, _ = strconv.Atoi(value)
+	}
+	// 'auto' line spacing is specified in 240ths of a line, exact spacing is not considered
+	rule, _ := attributeValue(tag, "w:lineRule")
+	if value, ok := attributeValue(tag, "w:line"); ok && (rule == "" || rule == "auto") {
+		if line, err := strconv.Atoi(value); err == nil && line > 0 {
+			lineFactor = float64(line) / 240
+		}
+	}
+	return before, after, lineFactor
+}
+
+// fontSize returns the font size in points of the given markup, if any.
+func fontSize(markup []byte) (float64, bool) {
+	match := FontSizeTagRegex.FindSubmatch(markup)
+	if match == nil {
+		return 0, false
+	}
+	halfPoints, err := strconv.Atoi(string(match[1]))
+	if err != nil {
+		return 0, false
+	}
+	return float64(halfPoints) / 2, true
+}
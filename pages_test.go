@@ -0,0 +1,83 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// courierParagraph returns a single-line paragraph of 12pt Courier text.
+// Every character is 144 twips wide, so an A4 page with one inch margins fits 62 characters per line
+// and 48 lines per page (288 twips per line).
+func courierParagraph(text string) string {
+	return `<w:p><w:r><w:rPr><w:rFonts w:ascii="Courier New"/><w:sz w:val="24"/></w:rPr><w:t>` + text + `</w:t></w:r></w:p>`
+}
+
+func TestDocument_EstimatePageCount(t *testing.T) {
+	a4 := `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr>`
+	letter := `<w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440"/></w:sectPr>`
+
+	tests := []struct {
+		name       string
+		body       string
+		pages      int
+		confidence EstimateConfidence
+	}{
+		{
+			name:       "single paragraph",
+			body:       courierParagraph("hello") + a4,
+			pages:      1,
+			confidence: ConfidenceHigh,
+		},
+		{
+			name:       "explicit page breaks",
+			body:       courierParagraph("one") + `<w:p><w:r><w:br w:type="page"/><w:t>two</w:t><w:br w:type="page"/><w:t>three</w:t></w:r></w:p>` + a4,
+			pages:      3,
+			confidence: ConfidenceHigh,
+		},
+		{
+			name:       "lines overflow",
+			body:       strings.Repeat(courierParagraph("line"), 100) + a4,
+			pages:      3,
+			confidence: ConfidenceHigh,
+		},
+		{
+			name:       "wrapped lines",
+			body:       courierParagraph(strings.Repeat("x", 62*60)) + a4,
+			pages:      2,
+			confidence: ConfidenceHigh,
+		},
+		{
+			name: "section break",
+			body: courierParagraph("first") + `<w:p><w:pPr>` + a4 + `</w:pPr></w:p>` +
+				courierParagraph("second") + letter,
+			pages:      2,
+			confidence: ConfidenceHigh,
+		},
+		{
+			name:       "table",
+			body:       `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>a</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` + a4,
+			pages:      1,
+			confidence: ConfidenceMedium,
+		},
+		{
+			name:       "floating drawing",
+			body:       `<w:p><w:r><w:drawing><wp:anchor></wp:anchor></w:drawing></w:r></w:p>` + a4,
+			pages:      1,
+			confidence: ConfidenceLow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)})
+
+			pages, confidence := doc.EstimatePageCount()
+			if pages < tt.pages-1 || pages > tt.pages+1 {
+				t.Errorf("estimate is off by more than one page, want=%d, have=%d", tt.pages, pages)
+			}
+			if confidence != tt.confidence {
+				t.Errorf("unexpected confidence, want=%s, have=%s", tt.confidence, confidence)
+			}
+		})
+	}
+}
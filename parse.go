@@ -15,23 +15,58 @@ const (
 	RunElementName = "r"
 	// TextElementName is the local name of the XML tag for text-runs (<w:t> and </w:t>)
 	TextElementName = "t"
+	// InstrTextElementName is the local name of the XML tag for field instructions (<w:instrText> and </w:instrText>)
+	InstrTextElementName = "instrText"
+	// DefaultMaxRunNesting is the maximum nesting depth of runs which the parser accepts by default.
+	// Runs are nested inside text boxes of runs, Word documents rarely nest more than a few levels.
+	DefaultMaxRunNesting = 1000
+	// MathNamespace is the namespace of Office Math (OMML), whose runs (<m:r>) and text (<m:t>) have the same local
+	// names as the runs and text of the document
+	MathNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/math"
+)
+
+const (
+	// attributesPattern matches any amount of attributes of a tag. Attribute values are quoted, thus they may
+	// contain any character except their quotes, including '>' and '/'.
+	attributesPattern = `(?:\s+[^\s=/>]+\s*=\s*(?:"[^"]*"|'[^']*'))*\s*`
 )
 
 var (
 	// RunOpenTagRegex matches all OpenTags for runs, including eventually set attributes
-	RunOpenTagRegex = regexp.MustCompile(`(<w:r).*>`)
+	RunOpenTagRegex = regexp.MustCompile(`^<w:r` + attributesPattern + `>$`)
 	// RunCloseTagRegex matches the close tag of runs
-	RunCloseTagRegex = regexp.MustCompile(`(</w:r>)`)
-	// RunSingletonTagRegex matches a singleton run tag
-	RunSingletonTagRegex = regexp.MustCompile(`(<w:r/>)`)
+	RunCloseTagRegex = regexp.MustCompile(`^</w:r\s*>$`)
+	// RunSingletonTagRegex matches a singleton run tag, including eventually set attributes
+	RunSingletonTagRegex = regexp.MustCompile(`^<w:r` + attributesPattern + `/>$`)
 	// TextOpenTagRegex matches all OpenTags for text-runs, including eventually set attributes
-	TextOpenTagRegex = regexp.MustCompile(`(<w:t).*>`)
+	TextOpenTagRegex = regexp.MustCompile(`^<w:t` + attributesPattern + `>$`)
 	// TextCloseTagRegex matches the close tag of text-runs
-	TextCloseTagRegex = regexp.MustCompile(`(</w:t>)`)
+	TextCloseTagRegex = regexp.MustCompile(`^</w:t\s*>$`)
+	// InstrTextOpenTagRegex matches all OpenTags for field instructions, including eventually set attributes
+	InstrTextOpenTagRegex = regexp.MustCompile(`^<w:instrText` + attributesPattern + `>$`)
+	// InstrTextCloseTagRegex matches the close tag of field instructions
+	InstrTextCloseTagRegex = regexp.MustCompile(`^</w:instrText\s*>$`)
+	// MathRunOpenTagRegex matches all OpenTags for math runs, including eventually set attributes
+	MathRunOpenTagRegex = regexp.MustCompile(`^<m:r` + attributesPattern + `>$`)
+	// MathRunCloseTagRegex matches the close tag of math runs
+	MathRunCloseTagRegex = regexp.MustCompile(`^</m:r\s*>$`)
+	// MathRunSingletonTagRegex matches a singleton math run tag, including eventually set attributes
+	MathRunSingletonTagRegex = regexp.MustCompile(`^<m:r` + attributesPattern + `/>$`)
+	// MathTextOpenTagRegex matches all OpenTags for the text of math runs, including eventually set attributes
+	MathTextOpenTagRegex = regexp.MustCompile(`^<m:t` + attributesPattern + `>$`)
+	// MathTextCloseTagRegex matches the close tag of the text of math runs
+	MathTextCloseTagRegex = regexp.MustCompile(`^</m:t\s*>$`)
 	// ErrTagsInvalid is returned if the parsing failed and the result cannot be used.
 	// Typically this means that one or more tag-offsets were not parsed correctly which
 	// would cause the document to become corrupted as soon as replacing starts.
 	ErrTagsInvalid = errors.New("one or more tags are invalid and will cause the XML to be corrupt")
+	// ErrEmptyDocument is returned if the parser is executed on an empty document.
+	// This usually indicates that reading the document failed upstream.
+	ErrEmptyDocument = errors.New("document is empty")
+	// ErrInvalidXml is returned if the document is not well-formed XML, e.g. because it is truncated.
+	ErrInvalidXml = errors.New("document is not well-formed XML")
+	// ErrRunNestingTooDeep is returned if runs are nested deeper than the maximum of the parser, see SetMaxNesting.
+	ErrRunNestingTooDeep = errors.New("runs are nested too deep")
 )
 
 // RunParser can parse a list of Runs from a given byte slice.
@@ -39,6 +74,15 @@ type RunParser struct {
 	doc      []byte
 	runs     DocumentRuns
 	runStack list.List
+	progress *progressReporter
+	// field instructions (<w:instrText>) are text of their runs, see WithFieldInstructionReplacement
+	fieldInstructions bool
+	// settings of the xml.Decoder, see SetDecoderSettings
+	decoderSettings DecoderSettings
+	// maximum nesting depth of runs, see SetMaxNesting
+	maxNesting int
+	// math runs (<m:r>) and their text (<m:t>) are runs as well, see WithMathPlaceholders
+	mathText bool
 }
 
 // NewRunParser returns an initialized RunParser given the source-bytes.
@@ -49,21 +93,38 @@ func NewRunParser(doc []byte) *RunParser {
 	}
 }
 
+// SetDecoderSettings configures the xml.Decoder which the parser uses, e.g. to parse documents which are not
+// well-formed in non-strict mode. It must be called before Execute.
+func (parser *RunParser) SetDecoderSettings(settings DecoderSettings) {
+	parser.decoderSettings = settings
+}
+
+// SetMaxNesting limits how deep runs may be nested, e.g. inside text boxes of runs. Deeper nested runs make
+// Execute fail with ErrRunNestingTooDeep instead of growing the stack of open runs without bounds.
+// A limit of 0 uses DefaultMaxRunNesting, a negative limit means unlimited. It must be called before Execute.
+func (parser *RunParser) SetMaxNesting(max int) {
+	parser.maxNesting = max
+}
+
 // Execute will fire up the parser.
 // The parser will do two passes on the given document.
 // First, all <w:r> tags are located and marked.
 // Then, inside that run tags the <w:t> tags are located.
+// If parsing fails, the parser has no runs, so that a partial result cannot be used.
 func (parser *RunParser) Execute() error {
 	err := parser.findRuns()
-	if err != nil {
-		return err
+	if err == nil {
+		err = parser.findTextRuns()
+	}
+	if err == nil {
+		err = ValidatePositions(parser.doc, parser.runs)
 	}
-	err = parser.findTextRuns()
 	if err != nil {
+		parser.runs = DocumentRuns{}
+		parser.runStack.Init()
 		return err
 	}
-
-	return ValidatePositions(parser.doc, parser.runs)
+	return nil
 }
 
 // Runs returns the all runs found by the parser.
@@ -74,16 +135,25 @@ func (parser *RunParser) Runs() DocumentRuns {
 // FindRuns will search through the document and return all runs found.
 // The text tags are not analyzed at this point, that'str the next step.
 func (parser *RunParser) findRuns() error {
+	if len(parser.doc) == 0 {
+		return ErrEmptyDocument
+	}
+
 	// use a custom reader which saves the current byte position
 	docReader := NewReader(string(parser.doc))
-	decoder := xml.NewDecoder(docReader)
+	decoder := parser.decoderSettings.newDecoder(docReader)
 
 	tmpRun := NewEmptyRun()
 	singleton := false
+	report := parser.progress.bytes(ProgressParseRuns, int64(len(parser.doc)))
 
 	// nestCount holds the nesting-level. It is going to be incremented on every OpenTag and decremented
 	// on every CloseTag.
 	nestCount := 0
+	maxNesting := parser.maxNesting
+	if maxNesting == 0 {
+		maxNesting = DefaultMaxRunNesting
+	}
 
 	// popRun will pop the last Run from the runStack if there is any on the stack
 	popRun := func() *Run {
@@ -108,21 +178,28 @@ func (parser *RunParser) findRuns() error {
 
 	for {
 		tok, err := decoder.Token()
-		if tok == nil || err == io.EOF {
+		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error getting token: %s", err)
+			return fmt.Errorf("%w: %s (near offset %d)", ErrInvalidXml, err, decoder.InputOffset())
+		}
+		if report != nil {
+			report(docReader.Pos())
 		}
 
 		switch elem := tok.(type) {
 		case xml.StartElement:
-			if elem.Name.Local == RunElementName {
+			if parser.isElement(elem.Name, RunElementName) {
 
 				nestCount += 1
+				if maxNesting > 0 && nestCount > maxNesting {
+					return fmt.Errorf("%w: more than %d levels (near offset %d)", ErrRunNestingTooDeep, maxNesting, decoder.InputOffset())
+				}
 				if nestCount > 1 {
 					parser.runStack.PushBack(tmpRun)
 					tmpRun = NewEmptyRun()
+					tmpRun.enclosing = parser.runStack.Back().Value.(*Run)
 				}
 
 				// tagEndPos points to '>' of the tag
@@ -138,13 +215,13 @@ func (parser *RunParser) findRuns() error {
 				// special case, a singleton tag: <w:r/> is also considered to be a start element
 				// since there is no real end tag, the element is marked for the EndElement case to handle it appropriately
 				tagStr := string(parser.doc[tagStartPos:tagEndPos])
-				if RunSingletonTagRegex.MatchString(tagStr) {
+				if RunSingletonTagRegex.MatchString(tagStr) || MathRunSingletonTagRegex.MatchString(tagStr) {
 					singleton = true
 				}
 			}
 
 		case xml.EndElement:
-			if elem.Name.Local == RunElementName {
+			if parser.isElement(elem.Name, RunElementName) {
 
 				// if the run is a singleton tag, it was already identified by the xml.StartElement case
 				// in that case, the CloseTag is the same as the openTag and no further work needs to be done
@@ -183,7 +260,9 @@ func (parser *RunParser) findRuns() error {
 func (parser *RunParser) findTextRuns() error {
 	// use a custom reader which saves the current byte position
 	docReader := NewReader(string(parser.doc))
-	decoder := xml.NewDecoder(docReader)
+	decoder := parser.decoderSettings.newDecoder(docReader)
+
+	report := parser.progress.bytes(ProgressParseText, int64(len(parser.doc)))
 
 	// based on the current position, find out in which run we're at
 	inRun := func(pos int64) *Run {
@@ -197,16 +276,19 @@ func (parser *RunParser) findTextRuns() error {
 
 	for {
 		tok, err := decoder.Token()
-		if tok == nil || err == io.EOF {
+		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("error getting token: %s", err)
+			return fmt.Errorf("%w: %s (near offset %d)", ErrInvalidXml, err, decoder.InputOffset())
+		}
+		if report != nil {
+			report(docReader.Pos())
 		}
 
 		switch elem := tok.(type) {
 		case xml.StartElement:
-			if elem.Name.Local == TextElementName {
+			if parser.isTextElement(elem.Name) {
 
 				// tagEndPos points to '>' of the tag
 				tagEndPos := docReader.Pos()
@@ -225,7 +307,7 @@ func (parser *RunParser) findTextRuns() error {
 			}
 
 		case xml.EndElement:
-			if elem.Name.Local == TextElementName {
+			if parser.isTextElement(elem.Name) {
 
 				// tagEndPos points to '>' of the tag
 				tagEndPos := docReader.Pos()
@@ -247,6 +329,28 @@ func (parser *RunParser) findTextRuns() error {
 	return nil
 }
 
+// isElement returns true if the element has the given local name and is not part of Office Math, unless math runs
+// are parsed as well.
+func (parser *RunParser) isElement(name xml.Name, local string) bool {
+	return name.Local == local && (parser.mathText || !isMathElement(name))
+}
+
+// isTextElement returns true if the element with the given name contains the text of a run.
+func (parser *RunParser) isTextElement(name xml.Name) bool {
+	return parser.isElement(name, TextElementName) || (parser.fieldInstructions && name.Local == InstrTextElementName)
+}
+
+// isMathElement returns true if the element belongs to the namespace of Office Math. Elements of documents which
+// do not declare the namespace keep their prefix as namespace.
+func isMathElement(name xml.Name) bool {
+	return name.Space == MathNamespace || name.Space == "m"
+}
+
+// checkWellFormed decodes the whole data and returns an error if it is not well-formed XML.
+func checkWellFormed(data []byte) error {
+	return DecoderSettings{}.checkWellFormed(data)
+}
+
 // findOpenBracketPos searches the matching '<' for a close bracket ('>') given it's position.
 func (parser *RunParser) findOpenBracketPos(endBracketPos int64) int64 {
 	var found bool
@@ -266,20 +370,39 @@ func ValidatePositions(document []byte, runs []*Run) error {
 	for _, run := range runs {
 
 		// singleton tags must not be validated
-		if run.OpenTag.Match(RunSingletonTagRegex, document) {
+		if run.OpenTag.Match(RunSingletonTagRegex, document) || run.OpenTag.Match(MathRunSingletonTagRegex, document) {
 			continue
 		}
 
-		if !run.OpenTag.Match(RunOpenTagRegex, document) {
-			log.Println("RunOpenTagRegex failed to match", run.String(document))
-			parsingFailed = true
-		}
-		if !run.CloseTag.Match(RunCloseTagRegex, document) {
-			log.Println("RunCloseTagRegex failed to match", run.String(document))
-			parsingFailed = true
+		// math runs are only found if the parser is configured to, see WithMathPlaceholders
+		if run.OpenTag.Match(MathRunOpenTagRegex, document) {
+			if !run.CloseTag.Match(MathRunCloseTagRegex, document) {
+				log.Println("MathRunCloseTagRegex failed to match", run.String(document))
+				parsingFailed = true
+			}
+		} else {
+			if !run.OpenTag.Match(RunOpenTagRegex, document) {
+				log.Println("RunOpenTagRegex failed to match", run.String(document))
+				parsingFailed = true
+			}
+			if !run.CloseTag.Match(RunCloseTagRegex, document) {
+				log.Println("RunCloseTagRegex failed to match", run.String(document))
+				parsingFailed = true
+			}
 		}
 
-		if run.HasText {
+		// the text of a run is either a text, a field instruction (see WithFieldInstructionReplacement) or math text
+		if run.HasText && run.Text.OpenTag.Match(InstrTextOpenTagRegex, document) {
+			if !run.Text.CloseTag.Match(InstrTextCloseTagRegex, document) {
+				log.Println("InstrTextCloseTagRegex failed to match", run.String(document))
+				parsingFailed = true
+			}
+		} else if run.HasText && run.Text.OpenTag.Match(MathTextOpenTagRegex, document) {
+			if !run.Text.CloseTag.Match(MathTextCloseTagRegex, document) {
+				log.Println("MathTextCloseTagRegex failed to match", run.String(document))
+				parsingFailed = true
+			}
+		} else if run.HasText {
 			if !run.Text.OpenTag.Match(TextOpenTagRegex, document) {
 				log.Println("TextOpenTagRegex failed to match", run.String(document))
 				parsingFailed = true
@@ -1,7 +1,10 @@
 package docx
 
 import (
+	"errors"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -42,6 +45,101 @@ func TestRunParser_FindTextRuns(t *testing.T) {
 	}
 }
 
+func TestRunParser_EmptyDocument(t *testing.T) {
+	for _, doc := range [][]byte{nil, {}} {
+		parser := NewRunParser(doc)
+		if err := parser.Execute(); !errors.Is(err, ErrEmptyDocument) {
+			t.Errorf("expected ErrEmptyDocument, got %v", err)
+		}
+	}
+
+	// a document without runs is not empty
+	if err := NewRunParser([]byte(testDocumentXml(`<w:p/>`))).Execute(); err != nil {
+		t.Errorf("unexpected error for a document without runs: %s", err)
+	}
+}
+
+func TestRunParser_TruncatedDocument(t *testing.T) {
+	doc := testDocumentXml(`<w:p><w:r><w:t>one</w:t></w:r><w:r><w:t>two</w:t></w:r></w:p>`)
+	truncated := doc[:strings.Index(doc, "two")]
+
+	parser := NewRunParser([]byte(truncated))
+	err := parser.Execute()
+	if !errors.Is(err, ErrInvalidXml) || !strings.Contains(err.Error(), "offset") {
+		t.Errorf("expected ErrInvalidXml with an offset, got %v", err)
+	}
+	if len(parser.Runs()) != 0 {
+		t.Errorf("expected no runs after a failed parse, got %d", len(parser.Runs()))
+	}
+
+	if _, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: truncated})); !errors.Is(err, ErrInvalidXml) {
+		t.Errorf("expected opening the document to fail with ErrInvalidXml, got %v", err)
+	}
+}
+
+func TestRunParser_NonStrict(t *testing.T) {
+	// '&nbsp;' is no XML entity and the ampersand of 'R&D' is not escaped, Word opens such documents anyway
+	doc := []byte(testDocumentXml(`<w:p><w:r><w:t>R&D&nbsp;team</w:t></w:r><w:r><w:t>two</w:t></w:r></w:p>`))
+
+	if err := NewRunParser(doc).Execute(); !errors.Is(err, ErrInvalidXml) {
+		t.Errorf("expected ErrInvalidXml in strict mode, got %v", err)
+	}
+
+	parser := NewRunParser(doc)
+	parser.SetDecoderSettings(DecoderSettings{NonStrict: true})
+	if err := parser.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	runs := parser.Runs()
+	if len(runs) != 2 || runs[0].GetText(doc) != "R&D&nbsp;team" || runs[1].GetText(doc) != "two" {
+		t.Errorf("unexpected runs %v", runs)
+	}
+}
+
+// testNestedRuns returns a paragraph of runs which are nested depth levels deep inside text boxes.
+func testNestedRuns(depth int) string {
+	open := strings.Repeat(`<w:r><w:t>x</w:t><w:pict><w:txbxContent><w:p>`, depth-1)
+	closing := strings.Repeat(`</w:p></w:txbxContent></w:pict></w:r>`, depth-1)
+	return `<w:p>` + open + `<w:r><w:t>x</w:t></w:r>` + closing + `</w:p>`
+}
+
+func TestRunParser_MaxNesting(t *testing.T) {
+	tests := []struct {
+		name       string
+		depth      int
+		maxNesting int
+		wantErr    bool
+	}{
+		{"within the default", 100, 0, false},
+		{"exceeding the default", DefaultMaxRunNesting + 1, 0, true},
+		{"within the limit", 3, 3, false},
+		{"exceeding the limit", 4, 3, true},
+		{"unlimited", DefaultMaxRunNesting + 1, -1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewRunParser([]byte(testDocumentXml(testNestedRuns(tt.depth))))
+			parser.SetMaxNesting(tt.maxNesting)
+			err := parser.Execute()
+			if tt.wantErr {
+				if !errors.Is(err, ErrRunNestingTooDeep) {
+					t.Errorf("expected ErrRunNestingTooDeep, got %v", err)
+				}
+				if len(parser.Runs()) != 0 {
+					t.Errorf("expected no runs after the failure, got %d", len(parser.Runs()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(parser.Runs()) != tt.depth {
+				t.Errorf("expected %d runs, got %d", tt.depth, len(parser.Runs()))
+			}
+		})
+	}
+}
+
 func TestRun_GetText(t *testing.T) {
 	docBytes := readFile(t, testFile)
 	sut := NewRunParser(docBytes)
@@ -95,3 +193,212 @@ func readFile(t testing.TB, path string) []byte {
 
 	return b
 }
+
+func TestRunParser_AttributeWithCloseBracket(t *testing.T) {
+	runTag := `<w:r w14:label="a>b" w:rsidR="00AB"
+	w:rsidRPr='x/>y'>`
+	textTag := `<w:t xml:space="preserve" w:hint=">">`
+	docBytes := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml">` +
+		`<w:body><w:p>` + runTag + textTag + `{key}</w:t></w:r><w:r w:rsidR="00AB"/></w:p></w:body></w:document>`)
+
+	sut := NewRunParser(docBytes)
+	err := sut.Execute()
+	if err != nil {
+		t.Errorf("parser.Execute failed: %s", err)
+		return
+	}
+
+	runs := sut.Runs()
+	if len(runs) != 2 {
+		t.Errorf("parser returned %d runs, expected %d", len(runs), 2)
+		return
+	}
+	run := runs[0]
+	if tag := string(docBytes[run.OpenTag.Start:run.OpenTag.End]); tag != runTag {
+		t.Errorf("run open tag is wrong, want=%s, have=%s", runTag, tag)
+	}
+	if tag := string(docBytes[run.Text.OpenTag.Start:run.Text.OpenTag.End]); tag != textTag {
+		t.Errorf("text open tag is wrong, want=%s, have=%s", textTag, tag)
+	}
+	if text := run.GetText(docBytes); text != "{key}" {
+		t.Errorf("run text is wrong: %s", text)
+	}
+	if !runs[1].OpenTag.Match(RunSingletonTagRegex, docBytes) {
+		t.Error("singleton run with attributes was not detected")
+	}
+}
+
+func TestRunParser_NestedRunProperties(t *testing.T) {
+	runTag := `<w:r w:rsidR="00AB">`
+	runProperties := `<w:rPr><w:rStyle w:val="Emphasis"/><w:rFonts w:ascii="Arial" w:hAnsi="Arial"/><w:b/>` +
+		`<w:color w:val="FF0000" w:themeColor="accent1"/><w:sz w:val="24"/><w:szCs w:val="24"/>` +
+		`<w:u w:val="single"/><w:lang w:val="en-US" w:eastAsia="ja-JP"/>` +
+		`<w:rPrChange w:id="1" w:author="a"><w:rPr><w:i/></w:rPr></w:rPrChange></w:rPr>`
+	docBytes := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:body><w:p>` + runTag + runProperties + `<w:t>{key}</w:t></w:r></w:p></w:body></w:document>`)
+
+	sut := NewRunParser(docBytes)
+	err := sut.Execute()
+	if err != nil {
+		t.Errorf("parser.Execute failed: %s", err)
+		return
+	}
+
+	runs := sut.Runs()
+	if len(runs) != 1 {
+		t.Errorf("parser returned %d runs, expected %d", len(runs), 1)
+		return
+	}
+	run := runs[0]
+	if tag := string(docBytes[run.OpenTag.Start:run.OpenTag.End]); tag != runTag {
+		t.Errorf("run open tag is wrong, want=%s, have=%s", runTag, tag)
+	}
+	if docBytes[run.OpenTag.End-1] != '>' || run.OpenTag.End != int64(strings.Index(string(docBytes), runProperties)) {
+		t.Errorf("run open tag must end right before the run properties, end=%d", run.OpenTag.End)
+	}
+	if tag := string(docBytes[run.CloseTag.Start:run.CloseTag.End]); tag != "</w:r>" {
+		t.Errorf("run close tag is wrong: %s", tag)
+	}
+	if text := run.GetText(docBytes); text != "{key}" {
+		t.Errorf("run text is wrong: %s", text)
+	}
+	if props := run.GetProperties(docBytes); !strings.HasPrefix(props, `<w:rStyle`) || !strings.HasSuffix(props, `</w:rPrChange>`) {
+		t.Errorf("run properties are wrong: %s", props)
+	}
+}
+
+func TestRunParser_OffsetsWithProlog(t *testing.T) {
+	content := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:body><w:p><w:r><w:t>{key}</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> b</w:t></w:r></w:p></w:body></w:document>`
+
+	parse := func(t *testing.T, prolog string) []*Run {
+		sut := NewRunParser([]byte(prolog + content))
+		if err := sut.Execute(); err != nil {
+			t.Fatalf("parser.Execute failed: %s", err)
+		}
+		return sut.Runs()
+	}
+	expected := parse(t, "")
+	if len(expected) != 2 {
+		t.Fatalf("parser returned %d runs, expected %d", len(expected), 2)
+	}
+
+	prologs := map[string]string{
+		"declaration":            `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+		"declaration and break":  "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?>\r\n",
+		"processing instruction": `<?xml version="1.0"?><?mso-application progid="Word.Document"?>`,
+		"directive and comment":  `<?xml version="1.0"?><!DOCTYPE document><!-- generated -->`,
+	}
+	for name, prolog := range prologs {
+		t.Run(name, func(t *testing.T) {
+			offset := int64(len(prolog))
+			runs := parse(t, prolog)
+			if len(runs) != len(expected) {
+				t.Fatalf("parser returned %d runs, expected %d", len(runs), len(expected))
+			}
+			for i, run := range runs {
+				positions := []Position{run.OpenTag, run.CloseTag, run.Text.OpenTag, run.Text.CloseTag}
+				expectedPositions := []Position{expected[i].OpenTag, expected[i].CloseTag, expected[i].Text.OpenTag, expected[i].Text.CloseTag}
+				for j, pos := range positions {
+					relative := Position{Start: pos.Start - offset, End: pos.End - offset}
+					if relative != expectedPositions[j] {
+						t.Errorf("run %d: position %d is %v relative to the content, expected %v", i, j, relative, expectedPositions[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentRuns_RunAt(t *testing.T) {
+	first := `<w:r><w:t>a</w:t></w:r>`
+	nested := `<w:r><w:t>c</w:t></w:r>`
+	outer := `<w:r><w:t>b</w:t><w:pict><w:txbxContent><w:p>` + nested + `</w:p></w:txbxContent></w:pict></w:r>`
+	last := `<w:r><w:t>d</w:t></w:r>`
+	content := `<w:p>` + first + outer + `</w:p><w:p>` + last + `</w:p>`
+	docBytes := []byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		content + `</w:body></w:document>`)
+
+	sut := NewRunParser(docBytes)
+	if err := sut.Execute(); err != nil {
+		t.Fatalf("parser.Execute failed: %s", err)
+	}
+	runs := sut.Runs()
+
+	firstStart := int64(strings.Index(string(docBytes), first))
+	outerStart := firstStart + int64(len(first))
+	nestedStart := int64(strings.Index(string(docBytes), nested))
+	lastStart := int64(strings.Index(string(docBytes), last))
+	tests := []struct {
+		name     string
+		pos      int64
+		expected string
+	}{
+		{"before all runs", firstStart - 1, ""},
+		{"start of open tag", firstStart, first},
+		{"last byte of close tag", outerStart - 1, first},
+		{"end of close tag is the start of the next run", outerStart, outer},
+		{"outer run in front of nested run", nestedStart - 1, outer},
+		{"nested run", nestedStart, nested},
+		{"outer run after nested run", nestedStart + int64(len(nested)), outer},
+		{"between runs", lastStart - 1, ""},
+		{"start of the run after the gap", lastStart, last},
+		{"after all runs", lastStart + int64(len(last)), ""},
+		{"offset beyond the data", int64(len(docBytes) + 10), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := runs.RunAt(tt.pos)
+			if tt.expected == "" {
+				if run != nil {
+					t.Errorf("expected no run, got %s", docBytes[run.OpenTag.Start:run.CloseTag.End])
+				}
+				return
+			}
+			if run == nil {
+				t.Fatal("expected a run, got nil")
+			}
+			if markup := string(docBytes[run.OpenTag.Start:run.CloseTag.End]); markup != tt.expected {
+				t.Errorf("unexpected run\nwant=%s\nhave=%s", tt.expected, markup)
+			}
+		})
+	}
+}
+
+func TestDocumentRuns_Map(t *testing.T) {
+	docBytes := []byte(testDocumentXml(`<w:p><w:r><w:t>Dear {na</w:t></w:r><w:r><w:t>me},</w:t></w:r><w:r><w:t xml:space="preserve"> {city}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">Bye {name}</w:t></w:r></w:p>`))
+	sut := NewRunParser(docBytes)
+	if err := sut.Execute(); err != nil {
+		t.Fatalf("parser.Execute failed: %s", err)
+	}
+	spans, err := sut.Runs().Map(docBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := func(groups [][]RunSpan) (result [][]string) {
+		for _, group := range groups {
+			var parts []string
+			for _, span := range group {
+				if span.Position.Start < span.Run.Text.OpenTag.End || span.Position.End > span.Run.Text.CloseTag.Start {
+					t.Errorf("span %v is outside of its run", span.Position)
+				}
+				parts = append(parts, string(docBytes[span.Position.Start:span.Position.End]))
+			}
+			result = append(result, parts)
+		}
+		return result
+	}
+	if len(spans) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(spans))
+	}
+	if name := texts(spans["name"]); !reflect.DeepEqual(name, [][]string{{"{na", "me}"}, {"{name}"}}) {
+		t.Errorf("unexpected spans of name: %v", name)
+	}
+	if city := texts(spans["city"]); !reflect.DeepEqual(city, [][]string{{"{city}"}}) {
+		t.Errorf("unexpected spans of city: %v", city)
+	}
+}
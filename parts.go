@@ -0,0 +1,77 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// PackagePart is a part of the package as it is going to be written, see Parts.
+type PackagePart struct {
+	// Name is the name of the part inside the archive, e.g. 'word/document.xml'.
+	Name string
+	// ContentType is the content type of the part, an empty string if the part has none.
+	ContentType string
+	// Size is the uncompressed size of the part in bytes.
+	Size int64
+	// Modified is true if the content of the part is read from memory. This applies to modified and added parts and
+	// to the files which are subject to replacing (document, headers, footers and notes).
+	Modified bool
+
+	zipFile *zip.File
+	data    []byte
+}
+
+// Open returns a reader of the content of the part. Unmodified parts are decompressed from the original archive while
+// reading, modified parts are read from memory. The reader must be closed.
+func (p PackagePart) Open() (io.ReadCloser, error) {
+	if p.zipFile != nil {
+		return p.zipFile.Open()
+	}
+	return ioutil.NopCloser(bytes.NewReader(p.data)), nil
+}
+
+// Parts returns all parts of the package in the order in which Write writes them: the parts of the original archive
+// followed by the added parts. The parts reflect all edits made so far, but nothing is read or decompressed until a
+// part is opened, which makes it possible to inspect the parts (e.g. to scan them for malware) without loading the
+// whole package into memory.
+func (d *Document) Parts() ([]PackagePart, error) {
+	types, err := d.parseContentTypes()
+	if err != nil {
+		return nil, err
+	}
+	return d.packageParts(types), nil
+}
+
+// packageParts returns all parts of the package in the order in which Write writes them, with the given content
+// types. The data of modified parts is shared with the document.
+func (d *Document) packageParts(types contentTypes) []PackagePart {
+	var parts []PackagePart
+	for _, file := range d.zipFile.File {
+		part := PackagePart{Name: file.Name, ContentType: types.lookup(file.Name)}
+		if data, isPart := d.modifiedParts[file.Name]; isPart {
+			part.data, part.Modified = data, true
+		} else if d.isModifiedFile(file.Name) {
+			part.data, part.Modified = d.unescapedFile(file.Name), true
+		} else {
+			part.zipFile = file
+			part.Size = int64(file.UncompressedSize64)
+		}
+		if part.Modified {
+			part.Size = int64(len(part.data))
+		}
+		parts = append(parts, part)
+	}
+	for _, name := range d.addedParts() {
+		data := d.modifiedParts[name]
+		parts = append(parts, PackagePart{
+			Name:        name,
+			ContentType: types.lookup(name),
+			Size:        int64(len(data)),
+			Modified:    true,
+			data:        data,
+		})
+	}
+	return parts
+}
@@ -0,0 +1,67 @@
+package docx
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDocument_Parts(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:        testDocumentXml(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`),
+		"word/media/a.bin": strings.Repeat("a", 5000),
+	})
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.setPart("word/media/b.png", []byte("png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ensureContentType("word/media/b.png", "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := doc.Parts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]PackagePart)
+	var names []string
+	for _, part := range parts {
+		byName[part.Name] = part
+		names = append(names, part.Name)
+	}
+	if last := names[len(names)-1]; last != "word/media/b.png" || len(names) != 4 {
+		t.Fatalf("expected the archive parts followed by the added part, got %v", names)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		modified    bool
+		content     string
+	}{
+		{DocumentXml, "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml", true, "<w:t>Jane</w:t>"},
+		{"word/media/a.bin", "", false, strings.Repeat("a", 5000)},
+		{"word/media/b.png", "image/png", true, "png"},
+		{ContentTypesXml, "application/xml", true, `<Override PartName="/word/media/b.png" ContentType="image/png"/>`},
+	}
+	for _, tt := range tests {
+		part := byName[tt.name]
+		if part.ContentType != tt.contentType || part.Modified != tt.modified {
+			t.Errorf("%s: unexpected content type %s or modified %t", tt.name, part.ContentType, part.Modified)
+		}
+		reader, err := part.Open()
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		reader.Close()
+		if int64(len(content)) != part.Size || !strings.Contains(string(content), tt.content) {
+			t.Errorf("%s: expected %s in %d bytes, got %s", tt.name, tt.content, part.Size, content)
+		}
+	}
+}
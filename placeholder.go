@@ -27,6 +27,28 @@ var (
 	CloseDelimiterRegex = regexp.MustCompile(string(CloseDelimiter))
 )
 
+// Delimiters is a pair of opening and closing delimiters of placeholders, e.g. '${' and '}'.
+// Additional pairs can be configured using WithDelimiters.
+type Delimiters struct {
+	Open  string
+	Close string
+}
+
+// DefaultDelimiters returns the delimiters defined by OpenDelimiter and CloseDelimiter.
+func DefaultDelimiters() Delimiters {
+	return Delimiters{Open: string(OpenDelimiter), Close: string(CloseDelimiter)}
+}
+
+// Wrap returns the given key enclosed in the delimiters.
+func (d Delimiters) Wrap(key string) string {
+	return d.Open + key + d.Close
+}
+
+// Delimits returns true if the given text starts and ends with the delimiters.
+func (d Delimiters) Delimits(text string) bool {
+	return len(text) >= len(d.Open)+len(d.Close) && strings.HasPrefix(text, d.Open) && strings.HasSuffix(text, d.Close)
+}
+
 // PlaceholderMap is the type used to map the placeholder keys (without delimiters) to the replacement values
 type PlaceholderMap map[string]interface{}
 
@@ -70,32 +92,58 @@ func (p Placeholder) Valid() bool {
 	return true
 }
 
+// WithEmptyRunsBreakPlaceholders configures how runs without text between the fragments of a placeholder are treated.
+// By default (false) they are skipped, so '{na' <empty run> 'me}' is the placeholder '{name}'. If enabled, an empty
+// run breaks the placeholder, which is useful for documents in which empty runs separate distinct tokens.
+func WithEmptyRunsBreakPlaceholders(breaks bool) Option {
+	return func(d *Document) {
+		d.emptyRunsBreakPlaceholders = breaks
+	}
+}
+
 // ParsePlaceholders will, given the document run positions and the bytes, parse out all placeholders including
 // their fragments.
 func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Placeholder, err error) {
+	return parsePlaceholders(runs, docBytes, placeholderGrammar{delimiters: DefaultDelimiters()}, false)
+}
+
+// parsePlaceholders parses all placeholders which use the delimiters of the grammar.
+func parsePlaceholders(runs DocumentRuns, docBytes []byte, grammar placeholderGrammar, emptyRunsBreak bool) (placeholders []*Placeholder, err error) {
+	delimiters := grammar.delimiters
+	closeLength := len(delimiters.Close)
+
 	// tmp vars used to preserve state across iterations
 	unclosedPlaceholder := new(Placeholder)
 	hasOpenPlaceholder := false
+	brokenPlaceholder := false // an unclosed placeholder was discarded because of an empty run
 
-	for _, run := range runs.WithText() {
-		runText := run.GetText(docBytes)
-
-		openDelimPositions := OpenDelimiterRegex.FindAllStringIndex(runText, -1)
-		closeDelimPositions := CloseDelimiterRegex.FindAllStringIndex(runText, -1)
+	for _, run := range runs {
+		runText := ""
+		if run.HasText {
+			runText = run.GetText(docBytes)
+		}
 
-		// FindAllStringIndex returns a [][]int whereas the nested []int has only 2 keys (0 and 1)
-		// We're only interested in the first key as that one indicates the position of the delimiter
-		delimPositions := func(positions [][]int) []int {
-			var pos []int
-			for _, position := range positions {
-				pos = append(pos, position[0])
+		// empty runs are skipped, unless they break the unclosed placeholder (see WithEmptyRunsBreakPlaceholders)
+		if runText == "" {
+			if emptyRunsBreak && hasOpenPlaceholder {
+				unclosedPlaceholder = new(Placeholder)
+				hasOpenPlaceholder = false
+				brokenPlaceholder = true
+			}
+			if !run.HasText || emptyRunsBreak {
+				continue
 			}
-			return pos
 		}
 
-		// index all delimiters
-		openPos := delimPositions(openDelimPositions)
-		closePos := delimPositions(closeDelimPositions)
+		// index all delimiters which are not escaped
+		openPos, closePos := grammar.delimiterPositions(runText)
+
+		// additional delimiters often share their close delimiter with other pairs (e.g. '${x}' and '{y}'),
+		// so only the close delimiters which actually close a placeholder are considered. The same applies
+		// to the close delimiters of escaped open delimiters (e.g. '\{x}').
+		if delimiters != DefaultDelimiters() || grammar.escape != "" {
+			closePos = closingPositions(openPos, closePos, hasOpenPlaceholder)
+		}
 
 		// In case there are the same amount of open and close delimiters.
 		// Here we will have three three different sub-cases.
@@ -119,7 +167,7 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 			isSpecialCase := func() bool {
 				for i := 0; i < len(openPos); i++ {
 					start := openPos[i]
-					end := closePos[i] + 1 // +1 is required to include the closing delimiter in the text
+					end := closePos[i] + closeLength // the closing delimiter is included in the text
 					if start > end {
 						return true
 					}
@@ -146,19 +194,29 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 				// handle the easy part (everything between the the culprit first '}' and last '{' in the example of '}foo{bar}foo{'
 				validOpenPos := openPos[:len(openPos)-1]
 				validClosePos := closePos[1:]
-				placeholders = append(placeholders, assembleFullPlaceholders(run, validOpenPos, validClosePos)...)
+				placeholders = append(placeholders, assembleFullPlaceholders(run, validOpenPos, validClosePos, closeLength)...)
 
 				// extract the first open and last close delimiter positions as they are the one causing issues.
 				lastOpenPos := openPos[len(openPos)-1]
 				firstClosePos := closePos[0]
 
+				// the remainder of a placeholder which was broken by an empty run is ignored
+				if !hasOpenPlaceholder && brokenPlaceholder {
+					unclosedPlaceholder = new(Placeholder)
+					unclosedPlaceholder.Fragments = append(unclosedPlaceholder.Fragments,
+						NewPlaceholderFragment(0, Position{int64(lastOpenPos), int64(len(runText))}, run))
+					hasOpenPlaceholder = true
+					brokenPlaceholder = false
+					continue
+				}
+
 				// we MUST be having an unclosedPlaceholder or the user made a typo like double-closing ('{foo}}{bar')
 				if !hasOpenPlaceholder {
-					return nil, fmt.Errorf("unexpected %c in run %d \"%s\"), missing preceeding %c", CloseDelimiter, run.ID, run.GetText(docBytes), OpenDelimiter)
+					return nil, fmt.Errorf("unexpected %s in run %d \"%s\"), missing preceeding %s", delimiters.Close, run.ID, run.GetText(docBytes), delimiters.Open)
 				}
 
 				// everything up to firstClosePos belongs to the currently open placeholder
-				fragment := NewPlaceholderFragment(0, Position{0, int64(firstClosePos) + 1}, run)
+				fragment := NewPlaceholderFragment(0, Position{0, int64(firstClosePos + closeLength)}, run)
 				unclosedPlaceholder.Fragments = append(unclosedPlaceholder.Fragments, fragment)
 				placeholders = append(placeholders, unclosedPlaceholder)
 
@@ -181,7 +239,7 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 			}
 
 			// case 1, assemble and continue
-			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos, closePos)...)
+			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos, closePos, closeLength)...)
 			continue
 		}
 
@@ -193,7 +251,7 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 		if len(openPos) > len(closePos) {
 			// merge full placeholders in the run, leaving out the last openPos since
 			// we know that the one is left over and must be handled separately below
-			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos[:len(openPos)-1], closePos)...)
+			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos[:len(openPos)-1], closePos, closeLength)...)
 
 			// add the unclosed part of the placeholder to a tmp placeholder var
 			unclosedOpenPos := openPos[len(openPos)-1]
@@ -209,15 +267,16 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 		if len(openPos) < len(closePos) {
 			// merge full placeholders in the run, leaving out the last closePos since
 			// we know that the one is left over and must be handled separately below
-			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos, closePos[:len(closePos)-1])...)
+			placeholders = append(placeholders, assembleFullPlaceholders(run, openPos, closePos[:len(closePos)-1], closeLength)...)
 
 			// there is only a closePos and no open pos
 			if len(closePos) == 1 {
-				fragment := NewPlaceholderFragment(0, Position{0, int64(int64(closePos[0]) + 1)}, run)
+				fragment := NewPlaceholderFragment(0, Position{0, int64(closePos[0] + closeLength)}, run)
 				unclosedPlaceholder.Fragments = append(unclosedPlaceholder.Fragments, fragment)
 				placeholders = append(placeholders, unclosedPlaceholder)
 				unclosedPlaceholder = new(Placeholder)
 				hasOpenPlaceholder = false
+				brokenPlaceholder = false
 				continue
 			}
 			continue
@@ -248,10 +307,9 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 			continue
 		}
 
-		// in order to catch false positives, ensure that all placeholders have BOTH delimiters
-		text := placeholder.Text(docBytes)
-		if !strings.ContainsRune(text, OpenDelimiter) ||
-			!strings.ContainsRune(text, CloseDelimiter) {
+		// in order to catch false positives, ensure that all placeholders have BOTH delimiters, which are not escaped
+		openPos, closePos := grammar.delimiterPositions(placeholder.Text(docBytes))
+		if len(openPos) == 0 || len(closePos) == 0 {
 			continue
 		}
 
@@ -261,15 +319,34 @@ func ParsePlaceholders(runs DocumentRuns, docBytes []byte) (placeholders []*Plac
 	return validPlaceholders, nil
 }
 
+// closingPositions returns the close delimiter positions which follow an open delimiter, or the first one if the
+// run continues an open placeholder.
+func closingPositions(openPos, closePos []int, isOpen bool) []int {
+	var result []int
+	o := 0
+	for _, pos := range closePos {
+		for o < len(openPos) && openPos[o] < pos {
+			isOpen = true
+			o++
+		}
+		if isOpen {
+			result = append(result, pos)
+			isOpen = false
+		}
+	}
+	return result
+}
+
 // assembleFullPlaceholders will extract all complete placeholders inside the run given a open and close position.
 // The open and close positions are the positions of the Delimiters which must already be known at this point.
 // openPos and closePos are expected to be symmetrical (e.g. same length).
 // Example: openPos := []int{10,20,30}; closePos := []int{13, 23, 33} resulting in 3 fragments (10,13),(20,23),(30,33)
 // The n-th elements inside openPos and closePos must be matching delimiter positions.
-func assembleFullPlaceholders(run *Run, openPos, closePos []int) (placeholders []*Placeholder) {
+// closeLength is the length of the closing delimiter, which is part of the placeholder.
+func assembleFullPlaceholders(run *Run, openPos, closePos []int, closeLength int) (placeholders []*Placeholder) {
 	for i := 0; i < len(openPos); i++ {
 		start := openPos[i]
-		end := closePos[i] + 1 // +1 is required to include the closing delimiter in the text
+		end := closePos[i] + closeLength // the closing delimiter is included in the text
 		fragment := NewPlaceholderFragment(0, Position{int64(start), int64(end)}, run)
 		p := &Placeholder{Fragments: []*PlaceholderFragment{fragment}}
 		placeholders = append(placeholders, p)
@@ -1,6 +1,9 @@
 package docx
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 var (
 	textMapping = PlaceholderMap{
@@ -52,8 +55,61 @@ func TestPlaceholder_AssembleFullPlaceholders(t *testing.T) {
 	openPos := []int{10, 18}
 	closePos := []int{17, 25}
 
-	placeholders := assembleFullPlaceholders(&Run{}, openPos, closePos)
+	placeholders := assembleFullPlaceholders(&Run{}, openPos, closePos, 1)
 	if len(placeholders) != expectedCount {
 		t.Errorf("not all full placeholders were parsed, want=%d, have=%d", expectedCount, len(placeholders))
 	}
 }
+
+func TestParsePlaceholders_EmptyRuns(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		skipped  []string
+		breaking []string
+	}{
+		{
+			name:     "singleton run",
+			body:     `<w:p><w:r><w:t>{na</w:t></w:r><w:r/><w:r><w:t>me}</w:t></w:r></w:p>`,
+			skipped:  []string{"{name}"},
+			breaking: nil,
+		},
+		{
+			name:     "run with empty text",
+			body:     `<w:p><w:r><w:t>{na</w:t></w:r><w:r><w:t></w:t></w:r><w:r><w:t>me}</w:t></w:r></w:p>`,
+			skipped:  []string{"{name}"},
+			breaking: nil,
+		},
+		{
+			name:     "run without text",
+			body:     `<w:p><w:r><w:t>{a</w:t></w:r><w:r><w:tab/></w:r><w:r><w:t>b} and {c</w:t></w:r><w:r><w:t>}</w:t></w:r></w:p>`,
+			skipped:  []string{"{ab}", "{c}"},
+			breaking: []string{"{c}"},
+		},
+		{
+			name:     "empty run outside of placeholders",
+			body:     `<w:p><w:r><w:t>{a}</w:t></w:r><w:r/><w:r><w:t>{b}</w:t></w:r></w:p>`,
+			skipped:  []string{"{a}", "{b}"},
+			breaking: []string{"{a}", "{b}"},
+		},
+	}
+	for _, tt := range tests {
+		for _, breaks := range []bool{false, true} {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)}), WithEmptyRunsBreakPlaceholders(breaks))
+			if err != nil {
+				t.Fatalf("%s: %s", tt.name, err)
+			}
+			var texts []string
+			for _, placeholder := range doc.Placeholders() {
+				texts = append(texts, placeholder.Text(doc.GetFile(DocumentXml)))
+			}
+			expected := tt.skipped
+			if breaks {
+				expected = tt.breaking
+			}
+			if fmt.Sprint(texts) != fmt.Sprint(expected) {
+				t.Errorf("%s (breaking=%v): got placeholders %v, expected %v", tt.name, breaks, texts, expected)
+			}
+		}
+	}
+}
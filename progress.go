@@ -0,0 +1,177 @@
+package docx
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// ProgressRead is the stage of reading the parts from the archive, done and total are uncompressed bytes.
+	ProgressRead = "read"
+	// ProgressParseRuns is the first parse pass of a part, which locates the runs.
+	// Done and total are bytes of the part, they restart at zero for every part.
+	ProgressParseRuns = "parse runs"
+	// ProgressParseText is the second parse pass of a part, which locates the text of the runs.
+	// Done and total are bytes of the part, they restart at zero for every part.
+	ProgressParseText = "parse text"
+	// ProgressReplace is the replacement pass of ReplaceAll, done and total are placeholder occurrences.
+	ProgressReplace = "replace"
+
+	// progressInterval is the amount of bytes after which the progress of reading and parsing is reported
+	progressInterval = 1 << 20
+)
+
+// ProgressFunc receives the progress of long operations, see WithProgress.
+type ProgressFunc func(stage string, done, total int64)
+
+// WithProgress registers a function which receives the progress of opening and replacing the document:
+// reading the archive (ProgressRead), both parse passes of every part (ProgressParseRuns, ProgressParseText)
+// and the replacement pass (ProgressReplace).
+//
+// The function is called from a separate goroutine, one call at a time and in the order of the updates of a stage.
+// It never slows down the operation: if the function is slower than the updates, intermediate updates of a stage
+// are dropped and only the latest one is delivered. The final update of a stage is therefore always delivered,
+// but possibly after the operation returned.
+func WithProgress(fn ProgressFunc) Option {
+	return func(d *Document) {
+		if fn == nil {
+			d.progress = nil
+			return
+		}
+		d.progress = &progressReporter{fn: fn}
+	}
+}
+
+// progressUpdate is a single update of a ProgressFunc.
+type progressUpdate struct {
+	stage       string
+	done, total int64
+}
+
+// progressReporter delivers updates to a ProgressFunc without blocking the reporting goroutine.
+// All methods can be called on a nil reporter, which discards the updates.
+type progressReporter struct {
+	fn ProgressFunc
+
+	mu         sync.Mutex
+	pending    []progressUpdate // latest update per stage, in the order of the stages
+	delivering bool
+
+	// occurrences of the current replacement pass, guarded by mu so that the updates of concurrent workers are
+	// recorded in the order of their counts
+	replaceDone, replaceTotal int64
+}
+
+// report records the update. If the previous update of the stage was not delivered yet, it is replaced.
+func (p *progressReporter) report(stage string, done, total int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.record(stage, done, total)
+}
+
+// record records the update while p.mu is held, see report.
+func (p *progressReporter) record(stage string, done, total int64) {
+	update := progressUpdate{stage: stage, done: done, total: total}
+	replaced := false
+	for i := range p.pending {
+		if p.pending[i].stage == stage {
+			p.pending[i] = update
+			replaced = true
+		}
+	}
+	if !replaced {
+		p.pending = append(p.pending, update)
+	}
+	if !p.delivering {
+		p.delivering = true
+		go p.deliver()
+	}
+}
+
+// deliver passes the pending updates to the ProgressFunc until there are none left.
+func (p *progressReporter) deliver() {
+	for {
+		p.mu.Lock()
+		updates := p.pending
+		p.pending = nil
+		if len(updates) == 0 {
+			p.delivering = false
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+
+		for _, u := range updates {
+			p.fn(u.stage, u.done, u.total)
+		}
+	}
+}
+
+// startReplace starts a replacement pass with the given amount of planned occurrences.
+func (p *progressReporter) startReplace(total int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replaceDone, p.replaceTotal = 0, total
+	p.record(ProgressReplace, 0, total)
+}
+
+// replaced adds the given amount of occurrences to the current replacement pass.
+func (p *progressReporter) replaced(occurrences int64) {
+	if p == nil || occurrences == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replaceDone += occurrences
+	p.record(ProgressReplace, p.replaceDone, p.replaceTotal)
+}
+
+// bytes returns a function which reports the bytes done of the stage, at most every progressInterval bytes and
+// always once all bytes are done. It returns nil for a nil reporter.
+func (p *progressReporter) bytes(stage string, total int64) func(done int64) {
+	if p == nil {
+		return nil
+	}
+	var next int64
+	return func(done int64) {
+		if done < next && done < total {
+			return
+		}
+		next = done + progressInterval
+		p.report(stage, done, total)
+	}
+}
+
+// progressReader reports the amount of bytes read from the underlying reader.
+type progressReader struct {
+	reader io.Reader
+	read   *int64
+	report func(done int64)
+}
+
+// Read implements the io.Reader interface.
+func (r progressReader) Read(b []byte) (int, error) {
+	n, err := r.reader.Read(b)
+	*r.read += int64(n)
+	r.report(*r.read)
+	return n, err
+}
+
+// planReplacements starts the replacement pass of the progress with the occurrences of the text values.
+func (d *Document) planReplacements(placeholderMap PlaceholderMap) {
+	if d.progress == nil {
+		return
+	}
+	textValues, _ := splitValues(placeholderMap)
+	var total int64
+	for _, name := range d.fileNames() {
+		total += int64(d.countPlaceholders(name, textValues))
+	}
+	d.progress.startReplace(total)
+}
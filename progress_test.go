@@ -0,0 +1,117 @@
+package docx
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithProgress(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{a} {b}</w:t></w:r></w:p><w:p><w:r><w:t>{a}</w:t></w:r></w:p>`
+	data := newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	var (
+		mu     sync.Mutex
+		latest = make(map[string][2]int64)
+	)
+	release := make(chan struct{})
+	progress := func(stage string, done, total int64) {
+		// a blocked consumer must not stall opening and replacing
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		if previous, seen := latest[stage]; seen && previous[1] == total && done < previous[0] {
+			t.Errorf("%s: progress went backwards from %d to %d", stage, previous[0], done)
+		}
+		latest[stage] = [2]int64{done, total}
+	}
+
+	doc, err := OpenBytes(data, WithProgress(progress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"a": "A", "b": "B"}); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	size := int64(len(testDocumentXml(body)))
+	expected := map[string][2]int64{
+		ProgressRead:      {size, size},
+		ProgressParseRuns: {size, size},
+		ProgressParseText: {size, size},
+		ProgressReplace:   {3, 3},
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		complete := len(latest) == len(expected)
+		for stage, progress := range expected {
+			complete = complete && latest[stage] == progress
+		}
+		mu.Unlock()
+		if complete {
+			break
+		}
+		if time.Now().After(deadline) {
+			mu.Lock()
+			t.Fatalf("unexpected progress %v, expected %v", latest, expected)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithProgress_Omitted(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{a}</w:t></w:r></w:p>`)}),
+		WithProgress(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"a": "A"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProgressReporter_ConcurrentReplace(t *testing.T) {
+	const workers, occurrences = 8, 500
+	var (
+		mu     sync.Mutex
+		latest int64
+	)
+	progress := &progressReporter{fn: func(stage string, done, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if done < latest {
+			t.Errorf("progress went backwards from %d to %d", latest, done)
+		}
+		latest = done
+	}}
+
+	progress.startReplace(workers * occurrences)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < occurrences; i++ {
+				progress.replaced(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the final update is always delivered, the latest count of the stage is the total
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		done := latest
+		mu.Unlock()
+		if done == workers*occurrences {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the final progress %d, got %d", workers*occurrences, done)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,49 @@
+package docx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+)
+
+const (
+	// AppXml is the relative path of the extended properties part inside the docx-archive.
+	AppXml = "docProps/app.xml"
+)
+
+// CreatorInfo describes the application which produced the document.
+// Both fields are taken as-is from the extended properties (docProps/app.xml) and may be empty.
+type CreatorInfo struct {
+	Application string `xml:"Application"`
+	AppVersion  string `xml:"AppVersion"`
+}
+
+// CreatorInfo returns the application and version which created the document.
+// An error is returned if the document has no extended properties part or if it cannot be parsed.
+func (d *Document) CreatorInfo() (CreatorInfo, error) {
+	var info CreatorInfo
+
+	data, err := d.getPart(AppXml)
+	if err != nil {
+		return info, err
+	}
+	if err := xml.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("unable to parse %s: %s", AppXml, err)
+	}
+	return info, nil
+}
+
+// readArchiveFile reads the given file directly from the docx zip archive.
+// This is used for files which are not part of the FileMap since they are never modified.
+func (d *Document) readArchiveFile(name string) ([]byte, error) {
+	file := d.zipFileByName(name)
+	if file == nil {
+		return nil, fmt.Errorf("file %s not found in archive", name)
+	}
+	readCloser, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %s", name, err)
+	}
+	defer readCloser.Close()
+	return ioutil.ReadAll(readCloser)
+}
@@ -0,0 +1,26 @@
+package docx
+
+import "testing"
+
+func TestDocument_CreatorInfo(t *testing.T) {
+	doc, err := Open("./test/template.docx")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer doc.Close()
+
+	info, err := doc.CreatorInfo()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := "TextMaker free rev.980"
+	if info.Application != expected {
+		t.Errorf("unexpected application, want=%s, have=%s", expected, info.Application)
+	}
+	if info.AppVersion != "" {
+		t.Errorf("expected empty app version, have=%s", info.AppVersion)
+	}
+}
@@ -0,0 +1,154 @@
+package docx
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+)
+
+var (
+	// PlaceholderPropertyRegex matches the core and extended properties which are scanned for placeholders by
+	// WithPropertyPlaceholders, the groups contain the tag, the raw text and the start of the close tag
+	PlaceholderPropertyRegex = regexp.MustCompile(`(<(dc:title|dc:subject|dc:description|cp:keywords|cp:category|` +
+		`Company|Manager)(?:\s[^>]*)?>)([^<]*)(<)`)
+)
+
+// PlaceholderLocationKind is the kind of markup which contains a placeholder, see PlaceholderLocations.
+type PlaceholderLocationKind string
+
+const (
+	// LocationText is the text of a run of the document, headers, footers or notes.
+	LocationText PlaceholderLocationKind = "text"
+	// LocationAttribute is an attribute of a drawing or shape, see WithAttributePlaceholders.
+	LocationAttribute PlaceholderLocationKind = "attribute"
+	// LocationProperty is a core or extended property of the document, see WithPropertyPlaceholders.
+	LocationProperty PlaceholderLocationKind = "property"
+)
+
+// PlaceholderLocation is an occurrence of a placeholder, see PlaceholderLocations.
+type PlaceholderLocation struct {
+	// Key is the key of the placeholder without delimiters.
+	Key string
+	// Part is the name of the part which contains the placeholder.
+	Part string
+	// Kind is the kind of markup which contains the placeholder.
+	Kind PlaceholderLocationKind
+	// Name is the name of the attribute or property which contains the placeholder, e.g. 'descr' or 'dc:title'.
+	// It is empty for text.
+	Name string
+}
+
+// PlainTextOnly returns true if the value of the placeholder must be plain text: values of attributes and properties
+// cannot be formatted and MarkupValue or BlockValue values are rejected there.
+func (l PlaceholderLocation) PlainTextOnly() bool {
+	return l.Kind != LocationText
+}
+
+// WithPropertyPlaceholders replaces placeholders inside the core and extended properties as well, i.e. the title,
+// subject, description, keywords and category (docProps/core.xml) and the company and manager (docProps/app.xml).
+// Word shows them in the document information and fields like TITLE or DOCPROPERTY display them.
+//
+// The values are taken like text values: ReplaceAll expects them to be escaped already, Render escapes them.
+// Placeholders without a value are kept, they are not reported as missing.
+func WithPropertyPlaceholders() Option {
+	return func(d *Document) {
+		d.propertyPlaceholders = true
+	}
+}
+
+// PlaceholderLocations returns all occurrences of placeholders which are replaced: the placeholders of the text in
+// the order of Placeholders, followed by the placeholders of the attributes (if WithAttributePlaceholders is used)
+// and of the properties (if WithPropertyPlaceholders is used). Tools which build forms for the values can use the
+// kind to restrict the values of attributes and properties to plain text, see PlaceholderLocation.PlainTextOnly.
+func (d *Document) PlaceholderLocations() ([]PlaceholderLocation, error) {
+	var locations []PlaceholderLocation
+	for _, name := range d.fileNames() {
+		data := d.GetFile(name)
+		for _, placeholder := range d.scanPart(name, false).placeholders {
+			locations = append(locations, PlaceholderLocation{
+				Key:  RemovePlaceholderDelimiter(placeholder.Text(data)),
+				Part: name,
+				Kind: LocationText,
+			})
+		}
+	}
+	if d.attributePlaceholders {
+		for _, name := range d.fileNames() {
+			data := d.GetFile(name)
+			for _, element := range placeholderAttributes {
+				for _, tag := range element.tag.FindAll(data, -1) {
+					for _, attribute := range element.attributes {
+						raw, exists := attributeValue(tag, attribute)
+						if !exists {
+							continue
+						}
+						for _, ref := range d.nestedReferences(html.UnescapeString(raw)) {
+							locations = append(locations, PlaceholderLocation{Key: ref.key, Part: name, Kind: LocationAttribute, Name: attribute})
+						}
+					}
+				}
+			}
+		}
+	}
+	if d.propertyPlaceholders {
+		for _, name := range []string{CoreXml, AppXml} {
+			if !d.partExists(name) {
+				continue
+			}
+			data, err := d.getPart(name)
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range PlaceholderPropertyRegex.FindAllSubmatch(data, -1) {
+				for _, ref := range d.nestedReferences(html.UnescapeString(string(match[3]))) {
+					locations = append(locations, PlaceholderLocation{Key: ref.key, Part: name, Kind: LocationProperty, Name: string(match[2])})
+				}
+			}
+		}
+	}
+	return locations, nil
+}
+
+// replaceProperties replaces the placeholders inside the properties, if enabled, and returns the keys of the replaced
+// placeholders. Escaped values are unescaped before they are escaped for the property.
+func (d *Document) replaceProperties(placeholderMap PlaceholderMap, escaped bool) ([]string, error) {
+	if !d.propertyPlaceholders {
+		return nil, nil
+	}
+	var replacedKeys []string
+	for _, name := range []string{CoreXml, AppXml} {
+		if !d.partExists(name) {
+			continue
+		}
+		data, err := d.getPart(name)
+		if err != nil {
+			return nil, err
+		}
+		var edits []edit
+		for _, loc := range PlaceholderPropertyRegex.FindAllSubmatchIndex(data, -1) {
+			text := html.UnescapeString(string(data[loc[6]:loc[7]]))
+			value, keys, err := d.replaceReferences(placeholderMap, text, func(value string) string {
+				if escaped {
+					return html.UnescapeString(value)
+				}
+				return value
+			})
+			if err != nil {
+				return nil, fmt.Errorf("unable to replace property %s in %s: %w", data[loc[4]:loc[5]], name, err)
+			}
+			if len(keys) > 0 {
+				edits = append(edits, edit{Position: Position{Start: int64(loc[6]), End: int64(loc[7])}, Replacement: []byte(xmlEscape(value))})
+				replacedKeys = append(replacedKeys, keys...)
+			}
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		sort.Slice(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+		if err := d.updatePart(name, applyEdits(data, edits)); err != nil {
+			return nil, err
+		}
+	}
+	return replacedKeys, nil
+}
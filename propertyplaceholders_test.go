@@ -0,0 +1,60 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithPropertyPlaceholders(t *testing.T) {
+	body := `<w:p><w:r><w:t>{company}</w:t></w:r><w:r><w:drawing><wp:inline>` +
+		`<wp:docPr id="1" name="Logo" descr="Logo of {company}"/></wp:inline></w:drawing></w:r></w:p>`
+	parts := map[string]string{
+		DocumentXml: testDocumentXml(body),
+		CoreXml: `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+			`xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Offer for {company}</dc:title><dc:creator>{author}</dc:creator></cp:coreProperties>`,
+		AppXml: `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">` +
+			`<Company>{company}</Company><Manager/></Properties>`,
+	}
+	doc, err := OpenBytes(newTestDocx(t, parts), WithAttributePlaceholders(), WithPropertyPlaceholders())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locations, err := doc.PlaceholderLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PlaceholderLocation{
+		{Key: "company", Part: DocumentXml, Kind: LocationText},
+		{Key: "company", Part: DocumentXml, Kind: LocationAttribute, Name: "descr"},
+		{Key: "company", Part: CoreXml, Kind: LocationProperty, Name: "dc:title"},
+		{Key: "company", Part: AppXml, Kind: LocationProperty, Name: "Company"},
+	}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("expected the locations %+v, got %+v", expected, locations)
+	}
+	if locations[0].PlainTextOnly() || !locations[1].PlainTextOnly() {
+		t.Errorf("expected only attributes and properties to be plain text only")
+	}
+
+	if _, err := doc.render("offer", map[string]interface{}{"company": "Smith & \"Sons\"\nLtd"}); err != nil {
+		t.Fatal(err)
+	}
+	for part, expected := range map[string]string{
+		DocumentXml: `descr="Logo of Smith &amp; &#34;Sons&#34;&#10;Ltd"`,
+		CoreXml:     `<dc:title>Offer for Smith &amp; &#34;Sons&#34;&#xA;Ltd</dc:title><dc:creator>{author}</dc:creator>`,
+		AppXml:      `<Company>Smith &amp; &#34;Sons&#34;&#xA;Ltd</Company>`,
+	} {
+		data, err := doc.getPart(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in %s", expected, data)
+		}
+		if err := checkWellFormed(data); err != nil {
+			t.Errorf("%s: %s", part, err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CustomXml is the relative path of the custom properties part inside the docx-archive.
+	CustomXml = "docProps/custom.xml"
+	// CustomRelationshipType is the relationship type of the custom properties.
+	CustomRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties"
+	// CustomContentType is the content type of the custom properties.
+	CustomContentType = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+
+	// customPropertyFormatID is the format id which Word uses for all user defined custom properties
+	customPropertyFormatID = "{D5CDD505-2E9C-101B-9397-08002B2CF9AE}"
+	// provenancePrefix marks the text of the hidden provenance run
+	provenancePrefix = "docx-provenance:"
+)
+
+var (
+	// CustomPropertyRegex matches a single custom property and captures its name
+	CustomPropertyRegex = regexp.MustCompile(`(?s)<property\s[^>]*?name="([^"]*)"[^>]*>.*?</property>`)
+	// CustomPropertiesCloseTagRegex matches the close tag of the custom properties
+	CustomPropertiesCloseTagRegex = regexp.MustCompile(`</Properties>`)
+	// ProvenanceRunRegex matches the hidden run which carries the provenance inside a footer
+	ProvenanceRunRegex = regexp.MustCompile(`<w:r><w:rPr><w:vanish/></w:rPr><w:t>` + provenancePrefix + `([^<]*)</w:t></w:r>`)
+
+	// provenanceProperties are the names of the custom properties, in the order of the Provenance fields
+	provenanceProperties = []string{"ProvenanceTemplateID", "ProvenanceTemplateVersion", "ProvenanceRenderedAt", "ProvenanceDataHash", "ProvenanceRendererVersion"}
+)
+
+// Provenance describes how a document was rendered.
+type Provenance struct {
+	TemplateID      string
+	TemplateVersion string
+	RenderedAt      time.Time
+	// DataHash identifies the data the document was rendered with, e.g. a hash of the placeholder values.
+	DataHash        string
+	RendererVersion string
+}
+
+// ProvenanceOptions configure how the provenance is stored.
+type ProvenanceOptions struct {
+	// HiddenRun additionally stores the provenance as a hidden (vanish) run at the end of every footer.
+	// Custom properties are often stripped when documents are converted by other tools, the run survives.
+	HiddenRun bool
+}
+
+// values returns the provenance as strings, in the order of provenanceProperties.
+func (p Provenance) values() []string {
+	renderedAt := ""
+	if !p.RenderedAt.IsZero() {
+		renderedAt = p.RenderedAt.UTC().Format(time.RFC3339)
+	}
+	return []string{p.TemplateID, p.TemplateVersion, renderedAt, p.DataHash, p.RendererVersion}
+}
+
+// set sets the field which belongs to the given custom property name.
+func (p *Provenance) set(name, value string) {
+	switch name {
+	case provenanceProperties[0]:
+		p.TemplateID = value
+	case provenanceProperties[1]:
+		p.TemplateVersion = value
+	case provenanceProperties[2]:
+		p.RenderedAt, _ = time.Parse(time.RFC3339, value)
+	case provenanceProperties[3]:
+		p.DataHash = value
+	case provenanceProperties[4]:
+		p.RendererVersion = value
+	}
+}
+
+// SetProvenance stores the provenance as custom properties of the document, replacing a previously set provenance.
+// Other custom properties are kept. Empty fields are not stored.
+func (d *Document) SetProvenance(provenance Provenance, options ProvenanceOptions) error {
+	custom, err := d.customProperties()
+	if err != nil {
+		return err
+	}
+
+	// remove the properties of a previous provenance and find the highest property id
+	var edits []edit
+	maxID := 1
+	for _, loc := range CustomPropertyRegex.FindAllSubmatchIndex(custom, -1) {
+		property := custom[loc[0]:loc[1]]
+		if value, ok := attributeValue(property, "pid"); ok {
+			if id, _ := strconv.Atoi(value); id > maxID {
+				maxID = id
+			}
+		}
+		if isProvenanceProperty(string(custom[loc[2]:loc[3]])) {
+			edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[1])}})
+		}
+	}
+
+	loc := CustomPropertiesCloseTagRegex.FindIndex(custom)
+	if loc == nil {
+		return fmt.Errorf("invalid custom properties part %s", CustomXml)
+	}
+	var properties strings.Builder
+	for i, value := range provenance.values() {
+		if value == "" {
+			continue
+		}
+		maxID++
+		valueType := "lpwstr"
+		if provenanceProperties[i] == "ProvenanceRenderedAt" {
+			valueType = "filetime"
+		}
+		properties.WriteString(fmt.Sprintf(`<property fmtid="%s" pid="%d" name="%s"><vt:%s>%s</vt:%s></property>`,
+			customPropertyFormatID, maxID, provenanceProperties[i], valueType, xmlEscape(value), valueType))
+	}
+	edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[0])}, Replacement: []byte(properties.String())})
+	if err := d.setPart(CustomXml, applyEdits(custom, edits)); err != nil {
+		return err
+	}
+
+	if options.HiddenRun {
+		return d.setProvenanceRun(provenance)
+	}
+	return nil
+}
+
+// Provenance returns the provenance of the document. The custom properties take precedence,
+// if there are none, the hidden run of the footers is used.
+// The provenance is empty if the document has none.
+func (d *Document) Provenance() (Provenance, error) {
+	var provenance Provenance
+	found := false
+	if d.partExists(CustomXml) {
+		custom, err := d.getPart(CustomXml)
+		if err != nil {
+			return provenance, err
+		}
+		for _, match := range CustomPropertyRegex.FindAllSubmatch(custom, -1) {
+			name := string(match[1])
+			if !isProvenanceProperty(name) {
+				continue
+			}
+			provenance.set(name, html.UnescapeString(innerMarkup([]byte(innerMarkup(match[0])))))
+			found = true
+		}
+	}
+	if found {
+		return provenance, nil
+	}
+
+	for _, footer := range d.footerFiles {
+		match := ProvenanceRunRegex.FindSubmatch(d.GetFile(footer))
+		if match == nil {
+			continue
+		}
+		values, err := url.ParseQuery(html.UnescapeString(string(match[1])))
+		if err != nil {
+			return provenance, fmt.Errorf("invalid provenance in %s: %s", footer, err)
+		}
+		for _, name := range provenanceProperties {
+			provenance.set(name, values.Get(name))
+		}
+		break
+	}
+	return provenance, nil
+}
+
+// ReadProvenance opens the document at the given path and returns its provenance.
+func ReadProvenance(path string) (Provenance, error) {
+	doc, err := Open(path)
+	if err != nil {
+		return Provenance{}, err
+	}
+	defer doc.Close()
+	return doc.Provenance()
+}
+
+// setProvenanceRun adds the hidden provenance run at the end of the last paragraph of every footer,
+// replacing a previous provenance run. A document without footers is recorded as a warning.
+func (d *Document) setProvenanceRun(provenance Provenance) error {
+	if len(d.footerFiles) == 0 {
+		d.warnings = append(d.warnings, "the document has no footer, the provenance is only stored in the custom properties")
+		return nil
+	}
+
+	values := url.Values{}
+	for i, value := range provenance.values() {
+		if value != "" {
+			values.Set(provenanceProperties[i], value)
+		}
+	}
+	run := `<w:r><w:rPr><w:vanish/></w:rPr><w:t>` + provenancePrefix + xmlEscape(values.Encode()) + `</w:t></w:r>`
+
+	for _, footer := range d.footerFiles {
+		data := ProvenanceRunRegex.ReplaceAll(d.GetFile(footer), nil)
+		var changed []byte
+		if i := bytes.LastIndex(data, []byte("</w:p>")); i >= 0 {
+			changed = applyEdits(data, []edit{{Position: Position{Start: int64(i), End: int64(i)}, Replacement: []byte(run)}})
+		} else if i := bytes.LastIndex(data, []byte("</w:ftr>")); i >= 0 {
+			changed = applyEdits(data, []edit{{Position: Position{Start: int64(i), End: int64(i)}, Replacement: []byte("<w:p>" + run + "</w:p>")}})
+		} else {
+			return fmt.Errorf("invalid footer %s", footer)
+		}
+		if err := d.SetFile(footer, changed); err != nil {
+			return err
+		}
+		if err := d.parseFile(footer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// customProperties returns the custom properties. If there are none, an empty custom properties part is added.
+func (d *Document) customProperties() ([]byte, error) {
+	if d.partExists(CustomXml) {
+		return d.getPart(CustomXml)
+	}
+
+	custom := []byte(xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" ` +
+		`xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"></Properties>`)
+	if err := d.setPart(CustomXml, custom); err != nil {
+		return nil, err
+	}
+	if err := d.ensureContentType(CustomXml, CustomContentType); err != nil {
+		return nil, err
+	}
+	if _, err := d.addRelationship("", Relationship{
+		Type:   CustomRelationshipType,
+		Target: CustomXml,
+	}); err != nil {
+		return nil, err
+	}
+	return custom, nil
+}
+
+// isProvenanceProperty returns true if the custom property with the given name is part of the provenance.
+func isProvenanceProperty(name string) bool {
+	for _, n := range provenanceProperties {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
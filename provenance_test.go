@@ -0,0 +1,143 @@
+package docx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocument_SetProvenance(t *testing.T) {
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>Page</w:t></w:r></w:p></w:ftr>`
+	custom := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">` +
+		`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="Department"><vt:lpwstr>Sales</vt:lpwstr></property>` +
+		`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="3" name="ProvenanceTemplateID"><vt:lpwstr>old</vt:lpwstr></property>` +
+		`</Properties>`
+	provenance := Provenance{
+		TemplateID:      "invoice & co",
+		TemplateVersion: "3.1",
+		RenderedAt:      time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC),
+		DataHash:        "9f86d081",
+		RendererVersion: "go-docx 1.0",
+	}
+
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:        testDocumentXml(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`),
+		"word/footer1.xml": footer,
+		CustomXml:          custom,
+	})
+	if err := doc.SetProvenance(provenance, ProvenanceOptions{HiddenRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	// setting the provenance twice replaces it
+	if err := doc.SetProvenance(provenance, ProvenanceOptions{HiddenRun: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "provenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rendered.docx")
+	if err := doc.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadProvenance(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != provenance {
+		t.Errorf("unexpected provenance %+v", read)
+	}
+
+	written, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer written.Close()
+	customData, err := written.getPart(CustomXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(customData), `name="Department"`) || strings.Contains(string(customData), "old") ||
+		strings.Count(string(customData), "ProvenanceTemplateID") != 1 {
+		t.Errorf("unexpected custom properties %s", customData)
+	}
+	if count := len(ProvenanceRunRegex.FindAll(written.GetFile("word/footer1.xml"), -1)); count != 1 {
+		t.Errorf("expected one provenance run, got %d: %s", count, written.GetFile("word/footer1.xml"))
+	}
+}
+
+func TestDocument_Provenance_HiddenRun(t *testing.T) {
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"></w:ftr>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:        testDocumentXml(`<w:p/>`),
+		"word/footer1.xml": footer,
+	})
+	provenance := Provenance{TemplateID: "letter", RenderedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := doc.SetProvenance(provenance, ProvenanceOptions{HiddenRun: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the custom properties were stripped by another tool
+	delete(doc.modifiedParts, CustomXml)
+	if read, err := doc.Provenance(); err != nil || read != provenance {
+		t.Errorf("unexpected provenance %+v (%v)", read, err)
+	}
+}
+
+func TestDocument_SetProvenance_NewCustomProperties(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p/>`)})
+	if err := doc.SetProvenance(Provenance{TemplateID: "letter"}, ProvenanceOptions{HiddenRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Warnings()) != 1 {
+		t.Errorf("expected a warning about the missing footer, got %v", doc.Warnings())
+	}
+	doc = reopen(t, doc)
+
+	if contentType, _ := doc.ContentType(CustomXml); contentType != CustomContentType {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+	rels, err := doc.Relationships("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rels) != 1 || rels[0].Type != CustomRelationshipType {
+		t.Errorf("unexpected package relationships %+v", rels)
+	}
+	if read, _ := doc.Provenance(); read.TemplateID != "letter" {
+		t.Errorf("unexpected provenance %+v", read)
+	}
+}
+
+func TestDocument_SetProvenance_ReplaceFooter(t *testing.T) {
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{company}</w:t></w:r></w:p></w:ftr>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:        testDocumentXml(`<w:p/>`),
+		"word/footer1.xml": footer,
+	})
+	provenance := Provenance{TemplateID: "letter"}
+	if err := doc.SetProvenance(provenance, ProvenanceOptions{HiddenRun: true}); err != nil {
+		t.Fatal(err)
+	}
+	// the placeholders of the footer are parsed again after the provenance run was added
+	if err := doc.ReplaceAll(PlaceholderMap{"company": "Acme"}); err != nil {
+		t.Fatal(err)
+	}
+	written := reopen(t, doc)
+	data := written.GetFile("word/footer1.xml")
+	if !strings.Contains(string(data), `<w:r><w:t>Acme</w:t></w:r>`) || len(ProvenanceRunRegex.FindAll(data, -1)) != 1 {
+		t.Errorf("expected the replaced placeholder and the provenance run, got %s", data)
+	}
+	if read, err := written.Provenance(); err != nil || read != provenance {
+		t.Errorf("unexpected provenance %+v (%v)", read, err)
+	}
+}
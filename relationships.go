@@ -0,0 +1,197 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TargetModeExternal is the TargetMode of relationships pointing outside of the package (e.g. hyperlinks).
+	TargetModeExternal = "External"
+	// PackageRelsPath is the path of the relationships of the package itself.
+	PackageRelsPath = "_rels/.rels"
+)
+
+var (
+	// RelationshipsCloseTagRegex matches the close tag of the relationships part
+	RelationshipsCloseTagRegex = regexp.MustCompile(`</Relationships>`)
+	// relationshipIdRegex matches the numeric suffix of relationship IDs like 'rId12'
+	relationshipIdRegex = regexp.MustCompile(`^rId([0-9]+)$`)
+)
+
+// Relationship is a single relationship of a part as defined in the .rels file of that part.
+type Relationship struct {
+	ID         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr,omitempty"`
+}
+
+// IsExternal returns true if the relationship target is outside of the package.
+func (r Relationship) IsExternal() bool {
+	return r.TargetMode == TargetModeExternal
+}
+
+// relationships is used to unmarshal a .rels part
+type relationships struct {
+	Relationships []Relationship `xml:"Relationship"`
+}
+
+// RelsPath returns the path of the relationships part which belongs to the given part.
+// Example: 'word/document.xml' => 'word/_rels/document.xml.rels'
+// The relationships of the package itself belong to the empty part name ('_rels/.rels').
+func RelsPath(part string) string {
+	if part == "" {
+		return PackageRelsPath
+	}
+	return path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+}
+
+// resolveTarget returns the absolute part name (without leading slash) of an internal relationship target
+// which is relative to the given source part.
+func resolveTarget(sourcePart, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+	return path.Join(path.Dir(sourcePart), target)
+}
+
+// relativeTarget is the inverse of resolveTarget and returns the target of partName relative to the source part.
+func relativeTarget(sourcePart, partName string) string {
+	dir := path.Dir(sourcePart) + "/"
+	if strings.HasPrefix(partName, dir) {
+		return strings.TrimPrefix(partName, dir)
+	}
+	return "/" + partName
+}
+
+// Relationships returns all relationships of the given part.
+// If the part does not have any relationships, an empty slice is returned.
+func (d *Document) Relationships(part string) ([]Relationship, error) {
+	if !d.partExists(RelsPath(part)) {
+		return nil, nil
+	}
+	data, err := d.getPart(RelsPath(part))
+	if err != nil {
+		return nil, err
+	}
+
+	var rels relationships
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", RelsPath(part), err)
+	}
+	return rels.Relationships, nil
+}
+
+// addRelationship adds the relationship to the .rels part of the given part.
+// The ID of the given relationship is ignored, a new unique ID is allocated and returned instead.
+// The relationship is inserted right before the closing tag, all other bytes of the part are left untouched.
+func (d *Document) addRelationship(part string, rel Relationship) (string, error) {
+	existing, err := d.Relationships(part)
+	if err != nil {
+		return "", err
+	}
+
+	// allocate the next free 'rId<N>'
+	maxID := 0
+	for _, r := range existing {
+		match := relationshipIdRegex.FindStringSubmatch(r.ID)
+		if match == nil {
+			continue
+		}
+		if id, _ := strconv.Atoi(match[1]); id > maxID {
+			maxID = id
+		}
+	}
+	rel.ID = fmt.Sprintf("rId%d", maxID+1)
+
+	relsPath := RelsPath(part)
+	data := []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`)
+	if d.partExists(relsPath) {
+		data, err = d.getPart(relsPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	loc := RelationshipsCloseTagRegex.FindIndex(data)
+	if loc == nil {
+		return "", fmt.Errorf("invalid relationships part %s", relsPath)
+	}
+
+	relXml := fmt.Sprintf(`<Relationship Id="%s" Type="%s" Target="%s"`, rel.ID, xmlEscape(rel.Type), xmlEscape(rel.Target))
+	if rel.TargetMode != "" {
+		relXml += fmt.Sprintf(` TargetMode="%s"`, xmlEscape(rel.TargetMode))
+	}
+	relXml += "/>"
+
+	var buf bytes.Buffer
+	buf.Write(data[:loc[0]])
+	buf.WriteString(relXml)
+	buf.Write(data[loc[0]:])
+
+	return rel.ID, d.setPart(relsPath, buf.Bytes())
+}
+
+// partExists returns true if the part exists in the document, either in the original archive or as added part.
+func (d *Document) partExists(name string) bool {
+	if _, exists := d.files[name]; exists {
+		return true
+	}
+	if _, exists := d.modifiedParts[name]; exists {
+		return true
+	}
+	return d.zipFileByName(name) != nil
+}
+
+// uniquePartName returns the given name if no such part exists yet.
+// Otherwise a counter is added to the base name until the name is unique, e.g. 'word/media/image1_2.png'.
+func (d *Document) uniquePartName(name string) string {
+	if !d.partExists(name) {
+		return name
+	}
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if !d.partExists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// xmlEscape returns the given string with all special characters escaped so it can be used in attributes and text.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// removeRelationships removes the relationships with the given IDs from the .rels part of the given part.
+// All other bytes of the part are left untouched.
+func (d *Document) removeRelationships(part string, ids map[string]bool) error {
+	relsPath := RelsPath(part)
+	if len(ids) == 0 || !d.partExists(relsPath) {
+		return nil
+	}
+	data, err := d.getPart(relsPath)
+	if err != nil {
+		return err
+	}
+
+	var edits []edit
+	for _, loc := range RelationshipTagRegex.FindAllIndex(data, -1) {
+		if id, _ := attributeValue(data[loc[0]:loc[1]], "Id"); ids[id] {
+			edits = append(edits, edit{Position: Position{Start: int64(loc[0]), End: int64(loc[1])}})
+		}
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	return d.setPart(relsPath, applyEdits(data, edits))
+}
@@ -0,0 +1,282 @@
+package docx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrMissingValues is returned by RenderFile if the template contains placeholders without a value.
+	ErrMissingValues = errors.New("missing values for placeholders")
+)
+
+// ReplaceReport summarizes the result of RenderFile.
+type ReplaceReport struct {
+	// Replaced is how often every key was replaced.
+	Replaced map[string]int
+	// Unused are the keys of the data which do not occur in the template, sorted.
+	Unused []string
+	// Provenance is the provenance stamped into the output.
+	Provenance Provenance
+	// Warnings are the warnings of the document, see Document.Warnings.
+	Warnings []string
+	// Languages is the language variant every placeholder key received, if WithLanguageVariants is used.
+	Languages map[string]string
+	// Targets are the replacements inside relationship targets, if WithRelationshipTargets is used.
+	Targets []TargetReplacement
+	// Typography is the number of substitutions in the value of every key, if WithTypography is used.
+	Typography map[string]int
+	// Formats are the formats which the rules of WithFormatRule applied to the value of every key.
+	Formats map[string]RunFormat
+	// Anchors are the names of the bookmarks of the replacements of every key, if WithAnchorsForReplacements is used.
+	Anchors map[string][]string
+}
+
+// RenderFile renders the template at templatePath with the given data into outputPath:
+//
+//	report, err := docx.RenderFile("template.docx", "output.docx", map[string]interface{}{"name": "Jane"})
+//
+// The defaults differ from the lower-level API:
+//   - Text values are XML escaped (unlike Replace), so '<' or '&' can be used in values.
+//     MarkupValue and BlockValue values (e.g. RichText and TableValue) are inserted as they are.
+//   - Every placeholder of the template must have a value, otherwise ErrMissingValues is returned and nothing is
+//     written. Data keys which do not occur in the template are allowed and listed in the report.
+//   - The provenance is stamped as custom properties: the template ID is the file name of the template and the
+//     data hash is the SHA-256 of the data. No render timestamp is stamped.
+//   - The output is deterministic: the same template and data always result in the same bytes.
+//
+// All options of the Document can be passed, e.g. WithDelimiters.
+func RenderFile(templatePath, outputPath string, data map[string]interface{}, opts ...Option) (ReplaceReport, error) {
+	doc, err := Open(templatePath, opts...)
+	if err != nil {
+		return ReplaceReport{}, err
+	}
+	defer doc.Close()
+
+	report, err := doc.render(filepath.Base(templatePath), data)
+	if err != nil {
+		return report, err
+	}
+	if err := doc.WriteToFile(outputPath); err != nil {
+		return report, err
+	}
+	report.Warnings = doc.Warnings()
+	return report, nil
+}
+
+// Render renders the template at templatePath with the given data and writes the result to out, in one call:
+//
+//	type Letter struct {
+//		Name     string `docx:"name"`
+//		Amount   docx.CurrencyValue
+//		Internal string `docx:"-"`
+//	}
+//	err := docx.Render("template.docx", Letter{Name: "Jane", Amount: docx.Currency(10, "$")}, w)
+//
+// The data is either a map with string keys (e.g. a PlaceholderMap) or a struct, or a pointer to one.
+// The exported fields of a struct are mapped to placeholder keys as follows:
+//   - The key is the value of the 'docx' tag of the field, or the field name if it has no tag ('{Amount}').
+//     Fields tagged with 'docx:"-"' and unexported fields are skipped.
+//   - Fields of nested structs are prefixed with the key of the struct and a dot, e.g. '{customer.name}'.
+//     The fields of embedded structs are used without prefix.
+//   - Fields whose type implements fmt.Stringer (e.g. time.Time), MarkupValue or BlockValue are values, even if
+//     they are structs. Nil pointers are replaced by an empty text.
+//
+// The document body, headers, footers and notes are replaced with the same defaults as RenderFile: text values are
+// escaped, every placeholder must have a value (ErrMissingValues) and the provenance is stamped.
+func Render(templatePath string, data interface{}, out io.Writer, opts ...Option) error {
+	values, err := renderValues(data)
+	if err != nil {
+		return err
+	}
+	doc, err := Open(templatePath, opts...)
+	if err != nil {
+		return err
+	}
+	defer doc.Close()
+
+	if _, err := doc.render(filepath.Base(templatePath), values); err != nil {
+		return err
+	}
+	return doc.Write(out)
+}
+
+// render replaces all placeholders of the document with the escaped data and stamps the provenance.
+// If a placeholder has no value, ErrMissingValues is returned and the document is not modified.
+func (d *Document) render(templateID string, data map[string]interface{}) (ReplaceReport, error) {
+	var report ReplaceReport
+
+	values, err := d.prepareValues(PlaceholderMap(data), &report)
+	if err != nil {
+		return report, err
+	}
+
+	occurrences := d.placeholderOccurrences()
+	var missing []string
+	for _, key := range d.placeholderKeys() {
+		if _, ok := lookupValue(values, key); !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return report, fmt.Errorf("%w: %s", ErrMissingValues, strings.Join(missing, ", "))
+	}
+
+	// the targets are URL encoded instead of escaped, the attributes and properties are escaped by themselves
+	if report.Targets, err = d.replaceRelationshipTargets(values); err != nil {
+		return report, err
+	}
+	attributeKeys, err := d.replaceAttributes(values, false)
+	if err != nil {
+		return report, err
+	}
+	propertyKeys, err := d.replaceProperties(values, false)
+	if err != nil {
+		return report, err
+	}
+
+	// keys which are only used by variants, targets, attributes or properties are not unused either
+	usedKeys := make(map[string]bool)
+	for _, key := range append(attributeKeys, propertyKeys...) {
+		usedKeys[key] = true
+	}
+	for key := range report.Languages {
+		base, _ := splitLanguage(key)
+		usedKeys[base] = true
+	}
+	for _, target := range report.Targets {
+		for _, key := range target.Keys {
+			usedKeys[key] = true
+		}
+	}
+
+	placeholderMap := make(PlaceholderMap, len(values))
+	report.Replaced = make(map[string]int)
+	for key, value := range values {
+		placeholderMap[key] = escapeValue(value)
+		if format, exists := report.Formats[key]; exists {
+			placeholderMap[key] = formatValue(placeholderMap[key], format)
+		}
+		if count := occurrences[RemovePlaceholderDelimiter(key)]; count > 0 {
+			report.Replaced[RemovePlaceholderDelimiter(key)] = count
+		} else if !usedKeys[RemovePlaceholderDelimiter(key)] {
+			report.Unused = append(report.Unused, key)
+		}
+	}
+	sort.Strings(report.Unused)
+
+	if err := d.replaceAll(placeholderMap); err != nil {
+		return report, err
+	}
+	if d.anchorPrefix != "" {
+		report.Anchors = d.Anchors()
+	}
+
+	report.Provenance = Provenance{TemplateID: templateID, DataHash: dataHash(data)}
+	if err := d.SetProvenance(report.Provenance, ProvenanceOptions{}); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// renderValues returns the placeholder values of the data given to Render.
+func renderValues(data interface{}) (map[string]interface{}, error) {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	values := make(map[string]interface{})
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unable to render %T, map keys must be strings", data)
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			values[iter.Key().String()] = iter.Value().Interface()
+		}
+	case reflect.Struct:
+		structValues(values, "", value)
+	default:
+		return nil, fmt.Errorf("unable to render %T, expected a struct or a map", data)
+	}
+	return values, nil
+}
+
+var (
+	stringerType    = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	markupValueType = reflect.TypeOf((*MarkupValue)(nil)).Elem()
+	blockValueType  = reflect.TypeOf((*BlockValue)(nil)).Elem()
+)
+
+// structValues adds the values of all exported fields of the struct to values, see Render.
+func structValues(values map[string]interface{}, prefix string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		key, tagged := field.Tag.Lookup("docx")
+		if key == "-" {
+			continue
+		}
+		if !tagged || key == "" {
+			key = field.Name
+		}
+
+		fieldValue := value.Field(i)
+		isValue := fieldValue.Type().Implements(stringerType) || fieldValue.Type().Implements(markupValueType) ||
+			fieldValue.Type().Implements(blockValueType)
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				values[prefix+key] = ""
+				continue
+			}
+			if !isValue {
+				fieldValue = fieldValue.Elem()
+			}
+		}
+		if fieldValue.Kind() == reflect.Struct && !isValue {
+			if field.Anonymous && !tagged {
+				structValues(values, prefix, fieldValue)
+			} else {
+				structValues(values, prefix+key+".", fieldValue)
+			}
+			continue
+		}
+		values[prefix+key] = fieldValue.Interface()
+	}
+}
+
+// escapeValue returns text values as escaped strings, all other values are returned as they are.
+func escapeValue(value interface{}) interface{} {
+	switch value.(type) {
+	case MarkupValue, BlockValue:
+		return value
+	}
+	return html.EscapeString(fmt.Sprint(value))
+}
+
+// dataHash returns the hex encoded SHA-256 hash of the data, independent of the order of the map.
+func dataHash(data map[string]interface{}) string {
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%q=%q\n", key, fmt.Sprint(data[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
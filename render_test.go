@@ -0,0 +1,139 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	template := filepath.Join(dir, "letter.docx")
+	body := `<w:p><w:r><w:t xml:space="preserve">Dear {name}, you owe {amount}. {name}!</w:t></w:r></w:p>`
+	if err := ioutil.WriteFile(template, newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"name": "Smith & Sons", "amount": 42, "unused": "x"}
+
+	first := filepath.Join(dir, "first.docx")
+	report, err := RenderFile(template, first, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Replaced["name"] != 2 || report.Replaced["amount"] != 1 || len(report.Unused) != 1 || report.Unused[0] != "unused" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Provenance.TemplateID != "letter.docx" || report.Provenance.DataHash == "" {
+		t.Errorf("unexpected provenance %+v", report.Provenance)
+	}
+
+	doc, err := Open(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "Dear Smith &amp; Sons, you owe 42. Smith &amp; Sons!") {
+		t.Errorf("unexpected document %s", result)
+	}
+	if provenance, _ := doc.Provenance(); provenance != report.Provenance {
+		t.Errorf("the provenance was not stamped: %+v", provenance)
+	}
+
+	// rendering again results in the same bytes
+	second := filepath.Join(dir, "second.docx")
+	if _, err := RenderFile(template, second, data); err != nil {
+		t.Fatal(err)
+	}
+	firstBytes, _ := ioutil.ReadFile(first)
+	secondBytes, _ := ioutil.ReadFile(second)
+	if !bytes.Equal(firstBytes, secondBytes) {
+		t.Error("the output is not deterministic")
+	}
+
+	// missing values fail without writing the output
+	missing := filepath.Join(dir, "missing.docx")
+	if _, err := RenderFile(template, missing, map[string]interface{}{"name": "Jane"}); !errors.Is(err, ErrMissingValues) || !strings.Contains(err.Error(), "amount") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("the output was written despite missing values")
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "render")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	template := filepath.Join(dir, "invoice.docx")
+	body := `<w:p><w:r><w:t xml:space="preserve">{number}: {customer.name} ({customer.City}), {Total} {note} {Reference} {area}</w:t></w:r></w:p>`
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t>{Company}</w:t></w:r></w:p></w:ftr>`
+	if err := ioutil.WriteFile(template, newTestDocx(t, map[string]string{
+		DocumentXml:        testDocumentXml(body),
+		"word/footer1.xml": footer,
+	}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type Customer struct {
+		Name string `docx:"name"`
+		City string
+	}
+	type Sender struct {
+		Company string
+	}
+	type Invoice struct {
+		Sender
+		Number    int      `docx:"number"`
+		Customer  Customer `docx:"customer"`
+		Total     CurrencyValue
+		Note      *string `docx:"note"`
+		Reference *Customer
+		Area      RichText `docx:"area"`
+		Internal  string   `docx:"-"`
+		secret    string
+	}
+	invoice := &Invoice{
+		Sender:   Sender{Company: "Smith & Sons"},
+		Number:   7,
+		Customer: Customer{Name: "Jane", City: "Berlin"},
+		Total:    Currency(12.5, "$"),
+		Area:     RichText{Plain("m"), Superscript("2")},
+		Internal: "x",
+		secret:   "y",
+	}
+	var buf bytes.Buffer
+	if err := Render(template, invoice, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := OpenBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := doc.PlainText(); text != "7: Jane (Berlin), $12.50   m2\n" {
+		t.Errorf("unexpected text %q", text)
+	}
+	if result := string(doc.GetFile("word/footer1.xml")); !strings.Contains(result, "Smith &amp; Sons") {
+		t.Errorf("unexpected footer %s", result)
+	}
+
+	if err := Render(template, map[string]string{"number": "1"}, &buf); !errors.Is(err, ErrMissingValues) {
+		t.Errorf("expected ErrMissingValues, got %v", err)
+	}
+	if err := Render(template, 42, &buf); err == nil {
+		t.Error("expected an error for data which is neither a struct nor a map")
+	}
+}
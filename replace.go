@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
 )
 
@@ -20,9 +19,19 @@ type Replacer struct {
 	distinctRuns []*Run // slice of all distinct runs extracted from the placeholders used for validation
 	ReplaceCount int
 	BytesChanged int64
+	replacedRuns []*Run                   // runs which contained a replaced placeholder
+	joinAdjacent bool                     // join text values with adjacent characters, see WithNonBreakingValues
+	formatting   FragmentFormattingPolicy // fragment which receives the value, see WithFragmentFormatting
+	replacements []replacement            // values inserted so far, see CheckRoundTrip
 	mu           sync.Mutex
 }
 
+// replacement is a value which the Replacer inserted into the given fragment.
+type replacement struct {
+	fragment *PlaceholderFragment
+	value    string
+}
+
 // NewReplacer returns a new Replacer.
 func NewReplacer(docBytes []byte, placeholder []*Placeholder) *Replacer {
 	r := &Replacer{
@@ -38,10 +47,29 @@ func NewReplacer(docBytes []byte, placeholder []*Placeholder) *Replacer {
 // Replace will replace all occurrences of the placeholderKey with the given value.
 // The function is synced with a mutex as it is not concurrency safe.
 func (r *Replacer) Replace(placeholderKey string, value string) error {
+	// ensure html escaping of special chars
+	// reassign to prevent overwriting the actual value which would cause multiple-escapes
+	//tmpVal := html.EscapeString(value)
+	tmpVal := value
+	valueInBytes := bytes.Replace(
+		[]byte(tmpVal),
+		[]byte("\n"), []byte("</w:t><w:br/><w:t>"), -1)
+
+	return r.replace(placeholderKey, func(placeholder *Placeholder) string {
+		if r.joinAdjacent {
+			return joinAdjacent(r.document, placeholder, string(valueInBytes))
+		}
+		return string(valueInBytes)
+	})
+}
+
+// replace will replace all occurrences of the placeholderKey with the markup returned by valueFunc.
+// The valueFunc is called once per occurrence, so the markup may depend on the placeholder (e.g. its run properties).
+func (r *Replacer) replace(placeholderKey string, valueFunc func(placeholder *Placeholder) string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if !strings.ContainsRune(placeholderKey, OpenDelimiter) ||
-		!strings.ContainsRune(placeholderKey, CloseDelimiter) {
+	// keys which are the literal text of a placeholder are used as they are, e.g. with other delimiters
+	if !r.hasPlaceholder(placeholderKey) {
 		placeholderKey = AddPlaceholderDelimiter(placeholderKey)
 	}
 
@@ -53,20 +81,21 @@ func (r *Replacer) Replace(placeholderKey string, value string) error {
 		if placeholder.Text(r.document) == placeholderKey {
 			found = true
 
-			// ensure html escaping of special chars
-			// reassign to prevent overwriting the actual value which would cause multiple-escapes
-			//tmpVal := html.EscapeString(value)
-			tmpVal := value
-			valueInBytes := bytes.Replace(
-				[]byte(tmpVal),
-				[]byte("\n"), []byte("</w:t><w:br/><w:t>"), -1)
-
-			// replace text of the placeholder'str first fragment with the actual value
-			r.replaceFragmentValue(placeholder.Fragments[0], string(valueInBytes))
+			// replace text of the placeholder's value fragment (the first one by default) with the actual value
+			value := valueFunc(placeholder)
+			valueFragment := placeholder.valueFragment(r.document, r.formatting)
+			r.replaceFragmentValue(placeholder.Fragments[valueFragment], value)
+			r.replacements = append(r.replacements, replacement{fragment: placeholder.Fragments[valueFragment], value: value})
 
 			// the other fragments of the placeholder are cut, leaving only the value inside the document.
-			for i := 1; i < len(placeholder.Fragments); i++ {
-				r.cutFragment(placeholder.Fragments[i])
+			for i := 0; i < len(placeholder.Fragments); i++ {
+				if i != valueFragment {
+					r.cutFragment(placeholder.Fragments[i])
+				}
+			}
+
+			for _, fragment := range placeholder.Fragments {
+				r.replacedRuns = append(r.replacedRuns, fragment.Run)
 			}
 		}
 	}
@@ -83,6 +112,16 @@ func (r *Replacer) Replace(placeholderKey string, value string) error {
 	return nil
 }
 
+// hasPlaceholder returns true if the text of any placeholder equals the given text.
+func (r *Replacer) hasPlaceholder(text string) bool {
+	for _, placeholder := range r.placeholders {
+		if placeholder.Text(r.document) == text {
+			return true
+		}
+	}
+	return false
+}
+
 // replaceFragmentValue will replace the fragment text with the given value, adjusting all following
 // fragments afterwards.
 func (r *Replacer) replaceFragmentValue(fragment *PlaceholderFragment, value string) {
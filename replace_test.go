@@ -3,6 +3,7 @@ package docx
 import (
 	"encoding/xml"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -55,3 +56,81 @@ func TestReplacer_Replace(t *testing.T) {
 	// cleanup
 	_ = os.Remove("./test/out.docx")
 }
+
+func TestDocument_ReplaceAll_DrawingTextBox(t *testing.T) {
+	textBox := `<w:txbxContent><w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Dear {name},</w:t></w:r></w:p></w:txbxContent>`
+	body := `<w:p><w:r><w:t>{name}</w:t></w:r><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:drawing>` +
+		`<wp:anchor><wp:extent cx="100" cy="100"/><wp:docPr id="1" name="Text Box 1"/>` +
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main">` +
+		`<a:graphicData uri="http://schemas.microsoft.com/office/word/2010/wordprocessingShape">` +
+		`<wps:wsp><wps:cNvSpPr txBox="1"/><wps:spPr/><wps:txbx>` + textBox + `</wps:txbx><wps:bodyPr/></wps:wsp>` +
+		`</a:graphicData></a:graphic></wp:anchor></w:drawing></mc:Choice>` +
+		`<mc:Fallback><w:pict><v:shape><v:textbox>` + textBox + `</v:textbox></v:shape></w:pict></mc:Fallback>` +
+		`</mc:AlternateContent></w:r></w:p>`
+
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	if count := len(doc.Placeholders()); count != 3 {
+		t.Errorf("unexpected amount of placeholders, want=%d, have=%d", 3, count)
+	}
+
+	err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result := string(doc.GetFile(DocumentXml))
+	if strings.Contains(result, "{name}") {
+		t.Errorf("not all placeholders were replaced: %s", result)
+	}
+	// Word writes the DrawingML shape together with a VML fallback, both must be replaced
+	if count := strings.Count(result, `<w:t>Dear Jane,</w:t>`); count != 2 {
+		t.Errorf("expected the text box and its fallback to be replaced, have=%d", count)
+	}
+	if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocument_ReplaceAll_HiddenRuns(t *testing.T) {
+	hidden := `<w:rPr><w:vanish/><w:color w:val="FF0000"/></w:rPr>`
+	body := `<w:p><w:r>` + hidden + `<w:t>{marker}</w:t></w:r><w:r><w:t xml:space="preserve"> visible {name}</w:t></w:r></w:p>` +
+		`<w:p><w:r>` + hidden + `<w:t>{i</w:t></w:r><w:r>` + hidden + `<w:t>d}</w:t></w:r></w:p>` +
+		`<w:p><w:r>` + hidden + `<w:t>{lines}</w:t></w:r></w:p>` +
+		`<w:p><w:r>` + hidden + `<w:t>{rich}</w:t></w:r></w:p>`
+
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	if count := len(doc.Placeholders()); count != 5 {
+		t.Errorf("placeholders in hidden runs are not found, want=%d, have=%d", 5, count)
+	}
+	err := doc.ReplaceAll(PlaceholderMap{
+		"marker": "doc-1",
+		"name":   "Jane",
+		"id":     "42",
+		"lines":  "a\nb",
+		"rich":   RichText{Plain("x"), Superscript("2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the run properties of the hidden runs are kept, including the ones of the runs created for the values
+	expected := []string{
+		`<w:r>` + hidden + `<w:t>doc-1</w:t></w:r><w:r><w:t xml:space="preserve"> visible Jane</w:t></w:r>`,
+		`<w:r>` + hidden + `<w:t>42</w:t></w:r><w:r>` + hidden + `<w:t></w:t></w:r>`,
+		`<w:r>` + hidden + `<w:t>a</w:t><w:br/><w:t>b</w:t></w:r>`,
+		`<w:r><w:rPr><w:vanish/><w:color w:val="FF0000"/><w:vertAlign w:val="superscript"/></w:rPr><w:t xml:space="preserve">2</w:t></w:r>`,
+	}
+	result := string(doc.GetFile(DocumentXml))
+	for _, markup := range expected {
+		if !strings.Contains(result, markup) {
+			t.Errorf("expected %s in %s", markup, result)
+		}
+	}
+	if count := strings.Count(result, "<w:vanish/>"); count != 7 {
+		t.Errorf("expected 7 hidden runs, have=%d: %s", count, result)
+	}
+	if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,45 @@
+package docx
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"time"
+)
+
+// ReproducibleTimestamp is the modification time of all entries written with WithReproducibleOutput. It is the
+// earliest time which can be stored in a zip archive.
+var ReproducibleTimestamp = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// WithReproducibleOutput writes all entries of the archive with ReproducibleTimestamp and a fixed compression level,
+// so the same template and values result in byte-identical documents, e.g. for caching or content-addressed storage.
+// The entries are written in the order of the original archive, followed by the added parts sorted by name.
+func WithReproducibleOutput() Option {
+	return func(d *Document) {
+		d.reproducibleOutput = true
+	}
+}
+
+// createEntry creates the entry with the given name in the archive, see WithReproducibleOutput.
+func (d *Document) createEntry(zipWriter *zip.Writer, name string) (io.Writer, error) {
+	if !d.reproducibleOutput {
+		return zipWriter.Create(name)
+	}
+	return zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: ReproducibleTimestamp,
+	})
+}
+
+// newZipWriter returns the writer of the archive, which uses a fixed compression level if WithReproducibleOutput
+// is used.
+func (d *Document) newZipWriter(writer io.Writer) *zip.Writer {
+	zipWriter := zip.NewWriter(writer)
+	if d.reproducibleOutput {
+		zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		})
+	}
+	return zipWriter
+}
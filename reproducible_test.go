@@ -0,0 +1,44 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestWithReproducibleOutput(t *testing.T) {
+	archive := newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p>`),
+	})
+	write := func() []byte {
+		doc, err := OpenBytes(archive, WithReproducibleOutput())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.setPart("word/added.xml", []byte("<added/>")); err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		if err := doc.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	first, second := write(), write()
+	if !bytes.Equal(first, second) {
+		t.Errorf("expected identical bytes across two runs")
+	}
+	reader, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range reader.File {
+		if !file.Modified.Equal(ReproducibleTimestamp) || file.Method != zip.Deflate {
+			t.Errorf("expected %s to be deflated with the timestamp %s, got %s", file.Name, ReproducibleTimestamp, file.Modified)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package docx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultResolverWorkers is the maximum amount of keys which ReplaceResolved resolves at the same time by default.
+const DefaultResolverWorkers = 8
+
+// Resolver is used to lazily fetch the value of a single placeholder key.
+// The key is passed without delimiters, just like the keys of a PlaceholderMap.
+type Resolver func(ctx context.Context, key string) (interface{}, error)
+
+// WithResolver registers a Resolver on the Document which is used by ReplaceResolved.
+// The resolver is only consulted for keys which are actually present in the document.
+func WithResolver(resolver Resolver) Option {
+	return func(d *Document) {
+		d.resolver = resolver
+	}
+}
+
+// WithResolverWorkers limits how many keys are resolved at the same time, e.g. to respect the rate limits of the
+// backend of the Resolver. A limit <= 0 uses DefaultResolverWorkers.
+func WithResolverWorkers(workers int) Option {
+	return func(d *Document) {
+		d.resolverWorkers = workers
+	}
+}
+
+// WithResolverTimeout sets a timeout which is applied to every single call of the Resolver.
+// A timeout <= 0 disables the per-key timeout, which is the default.
+func WithResolverTimeout(timeout time.Duration) Option {
+	return func(d *Document) {
+		d.resolverTimeout = timeout
+	}
+}
+
+// ReplaceResolved will resolve all placeholder keys found in the document using the registered Resolver
+// and replace them afterwards.
+// If WithLanguageVariants is used, the keys are resolved without their language suffix.
+// All keys are resolved concurrently (see WithResolverWorkers) before the first byte is modified. If any key fails to resolve,
+// an error is returned and the document remains untouched.
+func (d *Document) ReplaceResolved(ctx context.Context) error {
+	if d.resolver == nil {
+		return fmt.Errorf("no resolver registered, use WithResolver()")
+	}
+
+	keys := d.placeholderKeys()
+	if d.languageVariants {
+		keys = languageBaseKeys(keys)
+	}
+	placeholderMap, err := d.resolve(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	return d.ReplaceAll(placeholderMap)
+}
+
+// resolve consults the resolver for every given key using a bounded pool of workers and assembles the results into
+// a PlaceholderMap. The first error encountered is returned, in which case the remaining resolvers are canceled and
+// the remaining keys are not resolved anymore.
+func (d *Document) resolve(ctx context.Context, keys []string) (PlaceholderMap, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	placeholderMap := make(PlaceholderMap, len(keys))
+
+	resolveKey := func(key string) {
+		keyCtx := ctx
+		if d.resolverTimeout > 0 {
+			var keyCancel context.CancelFunc
+			keyCtx, keyCancel = context.WithTimeout(ctx, d.resolverTimeout)
+			defer keyCancel()
+		}
+
+		// keys which are still queued when resolving failed or was canceled are not resolved anymore
+		err := ctx.Err()
+		var value interface{}
+		if err == nil {
+			value, err = d.resolver(keyCtx, key)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unable to resolve placeholder '%s': %w", key, err)
+				cancel()
+			}
+			return
+		}
+		placeholderMap[key] = value
+	}
+
+	workers := d.resolverWorkers
+	if workers <= 0 {
+		workers = DefaultResolverWorkers
+	}
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+	jobs := make(chan string)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				resolveKey(key)
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return placeholderMap, nil
+}
+
+// placeholderKeys returns the sorted and distinct keys (without delimiters) of all placeholders in all files.
+// Placeholders which have already been replaced are not delimited anymore and thus skipped.
+func (d *Document) placeholderKeys() []string {
+	var keys []string
+	for key := range d.placeholderOccurrences() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
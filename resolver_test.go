@@ -0,0 +1,123 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDocument_ReplaceResolved(t *testing.T) {
+	var mu sync.Mutex
+	var resolvedKeys []string
+	resolver := func(ctx context.Context, key string) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		resolvedKeys = append(resolvedKeys, key)
+		return strings.ToUpper(key), nil
+	}
+
+	doc, err := Open("./test/template.docx", WithResolver(resolver))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer doc.Close()
+
+	expectedKeys := doc.placeholderKeys()
+	err = doc.ReplaceResolved(context.Background())
+	if err != nil {
+		t.Error("replacing failed", err)
+		return
+	}
+
+	if len(resolvedKeys) != len(expectedKeys) {
+		t.Errorf("resolver called for unexpected keys, want=%d, have=%d", len(expectedKeys), len(resolvedKeys))
+	}
+	if len(doc.placeholderKeys()) != 0 {
+		t.Errorf("not all placeholders were replaced: %v", doc.placeholderKeys())
+	}
+}
+
+func TestDocument_ReplaceResolved_Error(t *testing.T) {
+	errResolver := errors.New("resolver failed")
+	resolver := func(ctx context.Context, key string) (interface{}, error) {
+		if key == "key" {
+			return nil, errResolver
+		}
+		return key, nil
+	}
+
+	doc, err := Open("./test/template.docx", WithResolver(resolver))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer doc.Close()
+
+	before := append([]byte{}, doc.GetFile(DocumentXml)...)
+	err = doc.ReplaceResolved(context.Background())
+	if !errors.Is(err, errResolver) {
+		t.Errorf("expected resolver error, got %v", err)
+	}
+	if !bytes.Equal(before, doc.GetFile(DocumentXml)) {
+		t.Error("document was modified although resolving failed")
+	}
+}
+
+func TestWithResolverWorkers(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 20; i++ {
+		body.WriteString(fmt.Sprintf(`<w:p><w:r><w:t>{key%d}</w:t></w:r></w:p>`, i))
+	}
+	tests := []struct {
+		name    string
+		options []Option
+		max     int
+	}{
+		{"default", nil, DefaultResolverWorkers},
+		{"limited", []Option{WithResolverWorkers(2)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu             sync.Mutex
+				active, peak   int
+				resolvedValues int
+			)
+			resolver := func(ctx context.Context, key string) (interface{}, error) {
+				mu.Lock()
+				active++
+				if active > peak {
+					peak = active
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				active--
+				resolvedValues++
+				mu.Unlock()
+				return key, nil
+			}
+
+			options := append([]Option{WithResolver(resolver)}, tt.options...)
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body.String())}), options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceResolved(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if resolvedValues != 20 {
+				t.Errorf("expected 20 resolved keys, got %d", resolvedValues)
+			}
+			if peak > tt.max {
+				t.Errorf("expected at most %d keys to be resolved at the same time, got %d", tt.max, peak)
+			}
+		})
+	}
+}
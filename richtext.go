@@ -0,0 +1,178 @@
+package docx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lukasjarosch/go-docx/measure"
+)
+
+// VerticalAlignment defines the vertical alignment of text relative to the baseline (<w:vertAlign>).
+type VerticalAlignment string
+
+const (
+	// VertAlignBaseline is the default alignment, no <w:vertAlign> is emitted.
+	VertAlignBaseline VerticalAlignment = ""
+	// VertAlignSuperscript raises the text above the baseline and renders it smaller.
+	VertAlignSuperscript VerticalAlignment = "superscript"
+	// VertAlignSubscript lowers the text below the baseline and renders it smaller.
+	VertAlignSubscript VerticalAlignment = "subscript"
+)
+
+// MarkupValue is a replacement value which renders its own markup.
+// The markup replaces the placeholder text inside a <w:t> element and may depend on the (inner) run properties
+// of the run in which the placeholder starts.
+type MarkupValue interface {
+	Markup(runProperties string) string
+}
+
+// TextSpan is a piece of text inside a RichText value which has its own formatting.
+// The formatting is applied on top of the run properties of the replaced placeholder.
+type TextSpan struct {
+	Text      string
+	Bold      bool
+	Italic    bool
+	VertAlign VerticalAlignment
+	// Size is the font size in points, 0 keeps the inherited size.
+	Size float64
+	// FitText is the width in twips into which Word fits the text of the span (<w:fitText>), 0 disables it.
+	FitText int
+	// Color is the text color as hex RGB value (e.g. 'FF0000'), an empty color keeps the inherited color.
+	Color string
+}
+
+// Plain returns an unformatted TextSpan.
+func Plain(text string) TextSpan {
+	return TextSpan{Text: text}
+}
+
+// Bold returns a bold TextSpan.
+func Bold(text string) TextSpan {
+	return TextSpan{Text: text, Bold: true}
+}
+
+// Italic returns an italic TextSpan.
+func Italic(text string) TextSpan {
+	return TextSpan{Text: text, Italic: true}
+}
+
+// Superscript returns a superscript TextSpan, e.g. for the '2' in 'x²'.
+func Superscript(text string) TextSpan {
+	return TextSpan{Text: text, VertAlign: VertAlignSuperscript}
+}
+
+// Subscript returns a subscript TextSpan, e.g. for the '2' in 'H₂O'.
+func Subscript(text string) TextSpan {
+	return TextSpan{Text: text, VertAlign: VertAlignSubscript}
+}
+
+// WordFitText returns a TextSpan which is fitted into the given width (twips) by Word itself
+// by adjusting the character spacing.
+//
+// Deprecated: use WordFitTextWidth, which accepts a measure.Length.
+func WordFitText(text string, widthTwips int) TextSpan {
+	return WordFitTextWidth(text, measure.Twips(widthTwips))
+}
+
+// WordFitTextWidth returns a TextSpan which is fitted into the given width by Word itself
+// by adjusting the character spacing, e.g. WordFitTextWidth(total, measure.Cm(3)).
+func WordFitTextWidth(text string, width measure.Length) TextSpan {
+	return TextSpan{Text: text, FitText: int(measure.ToEMU(width).Twips())}
+}
+
+// formatted returns true if the span has any formatting which requires a separate run.
+func (s TextSpan) formatted() bool {
+	return s.Bold || s.Italic || s.VertAlign != VertAlignBaseline || s.Size > 0 || s.FitText > 0 || s.Color != ""
+}
+
+// properties returns the inner run properties of the span based on the inherited properties.
+func (s TextSpan) properties(inherited string) string {
+	props := inherited
+	if s.Bold {
+		props = SetRunProperty(props, "w:b", "<w:b/>")
+	}
+	if s.Italic {
+		props = SetRunProperty(props, "w:i", "<w:i/>")
+	}
+	if s.Color != "" {
+		props = SetRunProperty(props, "w:color", fmt.Sprintf(`<w:color w:val="%s"/>`, xmlEscape(s.Color)))
+	}
+	if s.VertAlign != VertAlignBaseline {
+		props = SetRunProperty(props, "w:vertAlign", fmt.Sprintf(`<w:vertAlign w:val="%s"/>`, s.VertAlign))
+	}
+	if s.Size > 0 {
+		// sizes are specified in half-points
+		halfPoints := int(s.Size*2 + 0.5)
+		props = SetRunProperty(props, "w:sz", fmt.Sprintf(`<w:sz w:val="%d"/>`, halfPoints))
+		props = SetRunProperty(props, "w:szCs", fmt.Sprintf(`<w:szCs w:val="%d"/>`, halfPoints))
+	}
+	if s.FitText > 0 {
+		props = SetRunProperty(props, "w:fitText", fmt.Sprintf(`<w:fitText w:val="%d"/>`, s.FitText))
+	}
+	return props
+}
+
+// RichText is a replacement value which consists of multiple, differently formatted, TextSpans.
+// It can be used as value inside a PlaceholderMap.
+//
+//	docx.PlaceholderMap{
+//		"formula": docx.RichText{docx.Plain("x"), docx.Superscript("2")},
+//	}
+type RichText []TextSpan
+
+// Markup returns the markup which replaces the placeholder text inside a <w:t> element.
+// Unformatted spans are inserted as-is, formatted spans are placed into runs of their own, after which
+// the original run is continued using the given (inner) run properties.
+func (rt RichText) Markup(runProperties string) string {
+	var markup strings.Builder
+	for _, span := range rt {
+		var lines []string
+		for _, line := range strings.Split(span.Text, "\n") {
+			lines = append(lines, xmlEscape(line))
+		}
+		markup.WriteString(span.markup(strings.Join(lines, "</w:t><w:br/><w:t>"), runProperties))
+	}
+	return markup.String()
+}
+
+// markup returns the given text markup with the formatting of the span. Unformatted text is returned as-is,
+// formatted text is placed into a run of its own, after which the original run is continued.
+func (s TextSpan) markup(text, runProperties string) string {
+	if !s.formatted() {
+		return text
+	}
+	var markup strings.Builder
+	markup.WriteString("</w:t></w:r>")
+	markup.WriteString(fmt.Sprintf(`<w:r><w:rPr>%s</w:rPr><w:t xml:space="preserve">%s</w:t></w:r>`, s.properties(runProperties), text))
+	markup.WriteString("<w:r>")
+	if runProperties != "" {
+		markup.WriteString(fmt.Sprintf("<w:rPr>%s</w:rPr>", runProperties))
+	}
+	markup.WriteString(`<w:t xml:space="preserve">`)
+	return markup.String()
+}
+
+// String returns the plain text of all spans.
+func (rt RichText) String() string {
+	var text strings.Builder
+	for _, span := range rt {
+		text.WriteString(span.Text)
+	}
+	return text.String()
+}
+
+// ReplaceRichText will replace all occurrences of the placeholderKey with the given RichText.
+// The formatting of every span is applied on top of the run properties of the run in which the placeholder starts.
+func (r *Replacer) ReplaceRichText(placeholderKey string, value RichText) error {
+	return r.ReplaceMarkup(placeholderKey, value)
+}
+
+// ReplaceMarkup will replace all occurrences of the placeholderKey with the markup of the given value.
+// The value receives the run properties of the run in which the respective placeholder starts, unless the
+// placeholder has mixed formatting and another fragment is chosen (see WithFragmentFormatting).
+func (r *Replacer) ReplaceMarkup(placeholderKey string, value MarkupValue) error {
+	return r.replace(placeholderKey, func(placeholder *Placeholder) string {
+		fragment := placeholder.Fragments[placeholder.valueFragment(r.document, r.formatting)]
+		return value.Markup(fragment.Run.GetProperties(r.document))
+	})
+}
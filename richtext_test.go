@@ -0,0 +1,52 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRichText_Markup(t *testing.T) {
+	value := RichText{Plain("x"), Superscript("2"), Plain(" + H"), Subscript("2"), Plain("O")}
+
+	markup := value.Markup(`<w:color w:val="ff0000"/><w:vertAlign w:val="baseline"/>`)
+	expected := `x</w:t></w:r>` +
+		`<w:r><w:rPr><w:color w:val="ff0000"/><w:vertAlign w:val="superscript"/></w:rPr><w:t xml:space="preserve">2</w:t></w:r>` +
+		`<w:r><w:rPr><w:color w:val="ff0000"/><w:vertAlign w:val="baseline"/></w:rPr><w:t xml:space="preserve"> + H</w:t></w:r>` +
+		`<w:r><w:rPr><w:color w:val="ff0000"/><w:vertAlign w:val="subscript"/></w:rPr><w:t xml:space="preserve">2</w:t></w:r>` +
+		`<w:r><w:rPr><w:color w:val="ff0000"/><w:vertAlign w:val="baseline"/></w:rPr><w:t xml:space="preserve">O`
+	if markup != expected {
+		t.Errorf("unexpected markup\nwant=%s\nhave=%s", expected, markup)
+	}
+	if markup := (RichText{Plain("a < b\nc")}).Markup(""); markup != "a &lt; b</w:t><w:br/><w:t>c" {
+		t.Errorf("text was not escaped properly: %s", markup)
+	}
+	if value.String() != "x2 + H2O" {
+		t.Errorf("unexpected plain text %s", value.String())
+	}
+}
+
+func TestDocument_ReplaceAll_RichText(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>area: {area} total</w:t></w:r></w:p>`),
+	})
+
+	err := doc.ReplaceAll(PlaceholderMap{
+		"area": RichText{Plain("10 m"), Superscript("2")},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	result := reopen(t, doc)
+	documentXml := string(result.GetFile(DocumentXml))
+	expected := `<w:r><w:rPr><w:b/></w:rPr><w:t>area: 10 m</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/><w:vertAlign w:val="superscript"/></w:rPr><w:t xml:space="preserve">2</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> total</w:t></w:r>`
+	if !strings.Contains(documentXml, expected) {
+		t.Errorf("superscript was not rendered as expected: %s", documentXml)
+	}
+	if err := checkWellFormed(result.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,166 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrRoundTripMismatch is returned by CheckRoundTrip if re-parsing a part does not yield the runs, placeholders
+	// and values which the document expects, which means that the offsets of the document are corrupt.
+	ErrRoundTripMismatch = errors.New("part does not round-trip through re-parsing")
+)
+
+// WithRoundTripCheck makes Validate (and thus Finalize) run CheckRoundTrip as well. The check parses all parts
+// again, which is expensive for large documents, it is meant for development and tests.
+func WithRoundTripCheck() Option {
+	return func(d *Document) {
+		d.roundTripCheck = true
+	}
+}
+
+// CheckRoundTrip parses the document, headers, footers and notes again and compares the result with the state of
+// the document, which catches corrupt offsets right after a mutation instead of when Word opens the document:
+//   - the parts must be parsed without errors, using the same settings as when the document was opened
+//   - the text of every parsed run must be the text content of its text element
+//   - every value which was inserted must still be found where it was inserted, text values must be the text of
+//     a run
+//   - every placeholder which was not replaced yet must still be found as placeholder where it is expected
+//
+// The first mismatch is returned as ErrRoundTripMismatch.
+func (d *Document) CheckRoundTrip() error {
+	var names []string
+	for name := range d.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := d.checkRoundTrip(name); err != nil {
+			return fmt.Errorf("%w: %s: %s", ErrRoundTripMismatch, name, err)
+		}
+	}
+	return nil
+}
+
+// checkRoundTrip checks a single parsed file, see CheckRoundTrip.
+func (d *Document) checkRoundTrip(name string) error {
+	data := d.files[name]
+	parser := NewRunParser(data)
+	if previous, parsed := d.runParsers[name]; parsed {
+		parser.fieldInstructions = previous.fieldInstructions
+		parser.decoderSettings = previous.decoderSettings
+		parser.maxNesting = previous.maxNesting
+		parser.mathText = previous.mathText
+	}
+	if err := parser.Execute(); err != nil {
+		return fmt.Errorf("unable to parse: %s", err)
+	}
+	runs := parser.Runs().WithText()
+
+	texts, err := parser.textContents()
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		expected, exists := texts[run.Text.OpenTag.End]
+		if !exists {
+			return fmt.Errorf("the text of the run at offset %d does not start after a text element", run.OpenTag.Start)
+		}
+		if text := html.UnescapeString(run.GetText(data)); text != expected {
+			return fmt.Errorf("the run at offset %d has the text %q instead of %q", run.OpenTag.Start, text, expected)
+		}
+	}
+
+	// the state of a replacer is only known as long as its part was not set otherwise
+	replacer, exists := d.fileReplacers[name]
+	if !exists || !bytes.Equal(replacer.document, data) {
+		return nil
+	}
+	replaced := make(map[*PlaceholderFragment]bool)
+	for _, r := range replacer.replacements {
+		replaced[r.fragment] = true
+		if text := r.fragment.Text(data); text != r.value {
+			return fmt.Errorf("the value %q is expected at offset %d, found %q", r.value, r.fragment.StartPos(), text)
+		}
+		if strings.Contains(r.value, "<") {
+			continue
+		}
+		inRun := false
+		for _, run := range runs {
+			if run.Text.OpenTag.End <= r.fragment.StartPos() && r.fragment.EndPos() <= run.Text.CloseTag.Start {
+				inRun = true
+				break
+			}
+		}
+		if !inRun {
+			return fmt.Errorf("the value %q at offset %d is not the text of a run", r.value, r.fragment.StartPos())
+		}
+	}
+
+	placeholders, err := d.parsePlaceholders(withoutSeparatorRuns(name, data, parser.Runs()), data)
+	if err != nil {
+		return fmt.Errorf("unable to parse the placeholders: %s", err)
+	}
+	found := make(map[int64]string)
+	for _, placeholder := range d.dropCrossingPlaceholders(name, data, placeholders) {
+		found[placeholder.StartPos()] = placeholder.Text(data)
+	}
+	for _, placeholder := range replacer.placeholders {
+		isReplaced := false
+		for _, fragment := range placeholder.Fragments {
+			isReplaced = isReplaced || replaced[fragment]
+		}
+		if isReplaced {
+			continue
+		}
+		if text := placeholder.Text(data); found[placeholder.StartPos()] != text {
+			return fmt.Errorf("the placeholder %s is expected at offset %d", text, placeholder.StartPos())
+		}
+	}
+	return nil
+}
+
+// textContents returns the unescaped text content of all text elements by the offset after their open tag, decoded
+// independently of the offsets of the parser.
+func (parser *RunParser) textContents() (map[int64]string, error) {
+	decoder := parser.decoderSettings.newDecoder(bytes.NewReader(parser.doc))
+	texts := make(map[int64]string)
+	var (
+		text   strings.Builder
+		start  int64
+		inText bool
+	)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return texts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s (near offset %d)", ErrInvalidXml, err, decoder.InputOffset())
+		}
+		switch elem := tok.(type) {
+		case xml.StartElement:
+			if parser.isTextElement(elem.Name) {
+				inText = true
+				start = decoder.InputOffset()
+				text.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(elem)
+			}
+		case xml.EndElement:
+			if inText && parser.isTextElement(elem.Name) {
+				inText = false
+				texts[start] = text.String()
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocument_CheckRoundTrip(t *testing.T) {
+	body := `<w:p><w:r><w:t>Dear {na</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>me},</w:t></w:r><w:r><w:t xml:space="preserve"> &amp; {formula}</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>{address} and {later}</w:t></w:r></w:p>`
+	values := PlaceholderMap{"name": "Jane", "formula": RichText{Plain("x"), Superscript("2")}, "address": "Main St. 1\nSpringfield"}
+
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	if err := doc.ReplaceAll(values); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.CheckRoundTrip(); err != nil {
+		t.Fatalf("expected the replaced document to round-trip, got %s", err)
+	}
+
+	tests := []struct {
+		name     string
+		corrupt  func(r *Replacer)
+		expected string
+	}{
+		{"shifted value", func(r *Replacer) {
+			r.replacements[0].fragment.Position.Start++
+			r.replacements[0].fragment.Position.End++
+		}, `the value "Jane" is expected at offset`},
+		{"shifted placeholder", func(r *Replacer) {
+			for _, placeholder := range r.placeholders {
+				if placeholder.Text(r.document) == "{later}" {
+					placeholder.Fragments[0].Position.Start--
+				}
+			}
+		}, "the placeholder  {later} is expected at offset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithRoundTripCheck())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(values); err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(doc.fileReplacers[DocumentXml])
+			err = doc.Validate()
+			if !errors.Is(err, ErrRoundTripMismatch) || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected the mismatch %s, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package docx
+
+import (
+	"fmt"
+)
+
+// HeightRule defines how the height of a table row is interpreted (w:hRule).
+type HeightRule string
+
+const (
+	// HeightAtLeast makes the row at least as high as the given height, it grows with its content.
+	HeightAtLeast HeightRule = "atLeast"
+	// HeightExact makes the row exactly as high as the given height. Word clips content which does not fit at the
+	// bottom of the row, the content itself is kept: changing the rule to HeightAtLeast shows it again.
+	HeightExact HeightRule = "exact"
+)
+
+// TableRow is a handle on a row of a table, it is obtained using Table.Rows.
+// The row is identified by its position among the rows of the table, nested tables are not included.
+type TableRow struct {
+	table *Table
+	index int
+}
+
+// Rows returns all rows of the table.
+func (t *Table) Rows() ([]*TableRow, error) {
+	markup, err := t.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	children, err := childElements(markup)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse table: %s", err)
+	}
+	var rows []*TableRow
+	for _, child := range children {
+		if child.Name == "tr" {
+			rows = append(rows, &TableRow{table: t, index: len(rows)})
+		}
+	}
+	return rows, nil
+}
+
+// SetHeight sets the height of the row in twips. With HeightExact, content which does not fit is clipped.
+func (r *TableRow) SetHeight(height int, rule HeightRule) error {
+	markup, err := rowHeightMarkup(height, rule)
+	if err != nil {
+		return err
+	}
+	return r.setProperty("w:trHeight", markup)
+}
+
+// SetCantSplit prevents (or allows) the row to break across pages. Rows higher than a page still break.
+func (r *TableRow) SetCantSplit(cantSplit bool) error {
+	markup := ""
+	if cantSplit {
+		markup = "<w:cantSplit/>"
+	}
+	return r.setProperty("w:cantSplit", markup)
+}
+
+// setProperty replaces the row property with the given qualified name by markup, an empty markup removes it.
+func (r *TableRow) setProperty(name, markup string) error {
+	table, err := r.table.Bytes()
+	if err != nil {
+		return err
+	}
+	children, err := childElements(table)
+	if err != nil {
+		return fmt.Errorf("unable to parse table: %s", err)
+	}
+	index := 0
+	for _, child := range children {
+		if child.Name != "tr" {
+			continue
+		}
+		if index == r.index {
+			row := setRowProperty(child.Bytes(table), name, markup)
+			return r.table.update(applyEdits(append([]byte(nil), table...), []edit{{Position: child.Position, Replacement: row}}))
+		}
+		index++
+	}
+	return fmt.Errorf("row %d does not exist in table %d", r.index, r.table.index)
+}
+
+// rowHeightMarkup returns the <w:trHeight> of the height and rule.
+func rowHeightMarkup(height int, rule HeightRule) (string, error) {
+	if height <= 0 {
+		return "", fmt.Errorf("invalid row height %d", height)
+	}
+	if rule != HeightAtLeast && rule != HeightExact {
+		return "", fmt.Errorf("invalid height rule %s", rule)
+	}
+	return fmt.Sprintf(`<w:trHeight w:val="%d" w:hRule="%s"/>`, height, rule), nil
+}
+
+// setRowProperty returns the row markup with the row property of the given qualified name replaced by markup,
+// respecting the order of the schema. An empty markup removes the property, empty row properties are removed.
+func setRowProperty(row []byte, name, markup string) []byte {
+	openTag := TableRowOpenTagRegex.Find(row)
+	pos := Position{Start: int64(len(openTag)), End: int64(len(openTag))}
+	inner := ""
+	if children, err := childElements(row); err == nil {
+		for _, child := range children {
+			if child.Name == "trPr" {
+				pos = child.Position
+				inner = innerMarkup(child.Bytes(row))
+				break
+			}
+		}
+	}
+
+	inner = setProperty(inner, rowPropertyOrder, name, markup)
+	replacement := ""
+	if inner != "" {
+		replacement = "<w:trPr>" + inner + "</w:trPr>"
+	}
+	return applyEdits(append([]byte(nil), row...), []edit{{Position: pos, Replacement: []byte(replacement)}})
+}
@@ -0,0 +1,353 @@
+package docx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// TableRowOpenTagRegex matches the open tag of a table row (but not of its properties, <w:trPr>)
+	TableRowOpenTagRegex = regexp.MustCompile(`<w:tr(?:\s[^>]*)?>`)
+)
+
+// ColumnAggregate computes the value of a column inside the totals row of ExpandTableRow.
+type ColumnAggregate struct {
+	Key       string
+	Aggregate func(values []interface{}) interface{}
+}
+
+// SumColumn returns a ColumnAggregate which sums up all numeric values of the column with the given key.
+// The sum keeps the type of the first value, e.g. the sum of CurrencyValues is a CurrencyValue with the same symbol.
+// Values which are not numeric are ignored.
+func SumColumn(key string) ColumnAggregate {
+	return ColumnAggregate{
+		Key: RemovePlaceholderDelimiter(key),
+		Aggregate: func(values []interface{}) interface{} {
+			var sum float64
+			var result interface{}
+			for _, value := range values {
+				var number float64
+				switch v := value.(type) {
+				case NumberValue:
+					number = v.Value
+					if result == nil {
+						result = v
+					}
+				case CurrencyValue:
+					number = v.Amount
+					if result == nil {
+						result = v
+					}
+				case int:
+					number = float64(v)
+				case int64:
+					number = float64(v)
+				case float64:
+					number = v
+				default:
+					continue
+				}
+				sum += number
+			}
+
+			switch r := result.(type) {
+			case NumberValue:
+				r.Value = sum
+				return r
+			case CurrencyValue:
+				r.Amount = sum
+				return r
+			}
+			return sum
+		},
+	}
+}
+
+// ExpandOptions configure how ExpandTableRow fills the table.
+type ExpandOptions struct {
+	// Alignments override the alignment of the cells containing the placeholder with the given key.
+	// Without an override, the alignment is inferred from the values (see AlignedValue).
+	Alignments map[string]Alignment
+	// Totals are computed from the rows and placed into an additional row after all rows.
+	Totals []ColumnAggregate
+	// TotalsRow contains fixed values of the totals row, e.g. a label.
+	TotalsRow PlaceholderMap
+	// RowHeight is the height of every generated row in twips, including the totals row (see TableRow.SetHeight).
+	// The height of the template row is kept if zero.
+	RowHeight int
+	// RowHeightRule defines how RowHeight is interpreted, HeightAtLeast if empty.
+	RowHeightRule HeightRule
+	// CantSplit prevents every generated row from breaking across pages (see TableRow.SetCantSplit).
+	CantSplit bool
+}
+
+// ExpandTableRow repeats every table row which contains the placeholder with the given key once for every
+// element of rows. The placeholders of every copy are replaced with the values of the respective element.
+// Placeholders whose key does not occur in any element of rows are kept as they are.
+//
+// Values which implement AlignedValue (e.g. NumberValue) align the paragraphs of their cell, these can be overridden
+// using ExpandOptions.Alignments. If totals are configured, one additional row containing all totals is appended.
+// LocalDateValue values without a language are formatted in the language of WithLocale or of the document.
+func (d *Document) ExpandTableRow(key string, rows []PlaceholderMap, options ExpandOptions) error {
+	key = RemovePlaceholderDelimiter(key)
+
+	// the dates without a language are formatted in the language of the document, like by ReplaceAll
+	localize := d.localizer()
+	localized := make([]PlaceholderMap, len(rows))
+	for i, row := range rows {
+		localized[i] = localize(row)
+	}
+	rows = localized
+	options.TotalsRow = localize(options.TotalsRow)
+
+	// the totals are computed from the filtered values and filtered themselves
+	if d.valueFilter != nil {
+		filtered := make([]PlaceholderMap, len(rows))
+		for i, row := range rows {
+			filtered[i] = d.filterValues(row)
+		}
+		rows = filtered
+		options.TotalsRow = d.filterValues(options.TotalsRow)
+	}
+
+	// all keys which are filled by the expansion
+	columns := make(map[string]bool)
+	for _, row := range append(append([]PlaceholderMap(nil), rows...), options.TotalsRow) {
+		for k := range row {
+			columns[RemovePlaceholderDelimiter(k)] = true
+		}
+	}
+	for _, total := range options.Totals {
+		columns[total.Key] = true
+	}
+	alignments := columnAlignments(rows, options.Alignments)
+
+	var rowHeight string
+	if options.RowHeight != 0 {
+		rule := options.RowHeightRule
+		if rule == "" {
+			rule = HeightAtLeast
+		}
+		markup, err := rowHeightMarkup(options.RowHeight, rule)
+		if err != nil {
+			return err
+		}
+		rowHeight = markup
+	}
+
+	var totals PlaceholderMap
+	if len(options.Totals) > 0 {
+		totals = make(PlaceholderMap)
+		for k, v := range options.TotalsRow {
+			totals[RemovePlaceholderDelimiter(k)] = v
+		}
+		for _, total := range options.Totals {
+			var values []interface{}
+			for _, row := range rows {
+				if value, ok := lookupValue(row, total.Key); ok {
+					values = append(values, value)
+				}
+			}
+			totals[total.Key] = d.filterValue(total.Key, total.Aggregate(values))
+		}
+	}
+
+	for name := range d.files {
+		data := d.GetFile(name)
+		var edits []edit
+		seen := make(map[int64]bool)
+
+		for _, placeholder := range d.filePlaceholders[name] {
+			if placeholder.Text(data) != AddPlaceholderDelimiter(key) {
+				continue
+			}
+			start, end, err := enclosingElement(data, TableRowOpenTagRegex, placeholder.StartPos())
+			if err != nil {
+				return fmt.Errorf("placeholder %s is not inside a table row: %w", AddPlaceholderDelimiter(key), err)
+			}
+			if seen[start] {
+				continue
+			}
+			seen[start] = true
+
+			template := alignCells(data[start:end], columns, alignments)
+			if rowHeight != "" {
+				template = setRowProperty(template, "w:trHeight", rowHeight)
+			}
+			if options.CantSplit {
+				template = setRowProperty(template, "w:cantSplit", "<w:cantSplit/>")
+			}
+			var expanded []byte
+			for _, row := range rows {
+				filled, err := fillRow(template, columns, row, d.decoderSettings)
+				if err != nil {
+					return err
+				}
+				expanded = append(expanded, filled...)
+			}
+			if totals != nil {
+				filled, err := fillRow(template, columns, totals, d.decoderSettings)
+				if err != nil {
+					return err
+				}
+				expanded = append(expanded, filled...)
+			}
+			edits = append(edits, edit{Position: Position{Start: start, End: end}, Replacement: expanded})
+		}
+		if len(edits) == 0 {
+			continue
+		}
+
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].Position.Start < edits[j].Position.Start
+		})
+		if err := d.SetFile(name, applyEdits(data, edits)); err != nil {
+			return err
+		}
+		if err := d.parseFile(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupValue returns the value of the given key, the keys of the map may contain delimiters.
+func lookupValue(values PlaceholderMap, key string) (interface{}, bool) {
+	if value, ok := values[key]; ok {
+		return value, true
+	}
+	value, ok := values[AddPlaceholderDelimiter(key)]
+	return value, ok
+}
+
+// columnAlignments returns the alignment of every column. Overrides take precedence over the alignment
+// of the first value of a column which implements AlignedValue.
+func columnAlignments(rows []PlaceholderMap, overrides map[string]Alignment) map[string]Alignment {
+	alignments := make(map[string]Alignment)
+	for _, row := range rows {
+		for k, value := range row {
+			k = RemovePlaceholderDelimiter(k)
+			if _, exists := alignments[k]; exists {
+				continue
+			}
+			if aligned, ok := value.(AlignedValue); ok {
+				alignments[k] = aligned.DefaultAlignment()
+			}
+		}
+	}
+	for k, alignment := range overrides {
+		alignments[RemovePlaceholderDelimiter(k)] = alignment
+	}
+	return alignments
+}
+
+// alignCells sets the alignment of all paragraphs inside the cells of the row which contain an aligned column.
+func alignCells(row []byte, columns map[string]bool, alignments map[string]Alignment) []byte {
+	return TableCellRegex.ReplaceAllFunc(row, func(cell []byte) []byte {
+		var text strings.Builder
+		for _, match := range TextContentRegex.FindAllSubmatch(cell, -1) {
+			text.Write(match[1])
+		}
+
+		var alignment Alignment
+		for k := range columns {
+			if a, ok := alignments[k]; ok && strings.Contains(text.String(), AddPlaceholderDelimiter(k)) {
+				alignment = a
+				break
+			}
+		}
+		if alignment == "" {
+			return cell
+		}
+		return setParagraphAlignment(cell, alignment)
+	})
+}
+
+// setParagraphAlignment sets the alignment of all paragraphs inside markup.
+func setParagraphAlignment(markup []byte, alignment Alignment) []byte {
+	jc := fmt.Sprintf(`<w:jc w:val="%s"/>`, alignment)
+
+	var result []byte
+	last := 0
+	for _, loc := range ParagraphOpenTagRegex.FindAllIndex(markup, -1) {
+		openTag := markup[loc[0]:loc[1]]
+		if strings.HasSuffix(string(openTag), "/>") {
+			continue
+		}
+		result = append(result, markup[last:loc[1]]...)
+		last = loc[1]
+
+		if pPr := ParagraphPropertiesRegex.Find(markup[loc[1]:]); pPr != nil {
+			inner := innerMarkup(pPr)
+			result = append(result, "<w:pPr>"+SetParagraphProperty(inner, "w:jc", jc)+"</w:pPr>"...)
+			last += len(pPr)
+			continue
+		}
+		result = append(result, "<w:pPr>"+jc+"</w:pPr>"...)
+	}
+	return append(result, markup[last:]...)
+}
+
+// fillRow replaces all placeholders of the given columns inside the row. Columns without a value become empty.
+// The row is parsed using the decoder settings of its document.
+func fillRow(row []byte, columns map[string]bool, values PlaceholderMap, settings DecoderSettings) ([]byte, error) {
+	// the replacer modifies the bytes in place, but the row is used as template for every copy
+	row = append([]byte(nil), row...)
+
+	parser := NewRunParser(row)
+	parser.SetDecoderSettings(settings)
+	if err := parser.Execute(); err != nil {
+		return nil, err
+	}
+	placeholders, err := ParsePlaceholders(parser.Runs(), row)
+	if err != nil {
+		return nil, err
+	}
+	replacer := NewReplacer(row, placeholders)
+
+	for k := range columns {
+		value, _ := lookupValue(values, k)
+		if value == nil {
+			value = ""
+		}
+		switch v := value.(type) {
+		case MarkupValue:
+			err = replacer.ReplaceMarkup(k, v)
+		default:
+			err = replacer.Replace(k, fmt.Sprint(value))
+		}
+		if err != nil && !errors.Is(err, ErrPlaceholderNotFound) {
+			return nil, err
+		}
+	}
+	return replacer.Bytes(), nil
+}
+
+// enclosingElement returns the start and end of the innermost element, whose open tag matches openTagRegex,
+// which contains the given position.
+func enclosingElement(data []byte, openTagRegex *regexp.Regexp, pos int64) (start, end int64, err error) {
+	candidates := openTagRegex.FindAllIndex(data[:pos], -1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		end, err := closingTagEnd(data, int64(candidates[i][1]))
+		if err != nil {
+			continue
+		}
+		if end > pos {
+			return int64(candidates[i][0]), end, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no enclosing element found at offset %d", pos)
+}
+
+// innerMarkup returns the content of the given element markup without its open and close tag.
+func innerMarkup(element []byte) string {
+	openEnd := strings.Index(string(element), ">")
+	closeStart := strings.LastIndex(string(element), "</")
+	if openEnd < 0 || closeStart < openEnd {
+		return ""
+	}
+	return string(element[openEnd+1 : closeStart])
+}
@@ -0,0 +1,172 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocument_ExpandTableRow(t *testing.T) {
+	row := `<w:tr><w:tc><w:p><w:r><w:t>{item}</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:p><w:pPr><w:spacing w:after="0"/></w:pPr><w:r><w:t>{amount}</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:p><w:r><w:t>{date}</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:p><w:r><w:t>{currency}</w:t></w:r></w:p></w:tc></w:tr>`
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Item</w:t></w:r></w:p></w:tc></w:tr>` + row + `</w:tbl>`),
+	})
+
+	day := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	err := doc.ExpandTableRow("item", []PlaceholderMap{
+		{"item": "Basic", "amount": Currency(1200, "$"), "date": Date(day, "")},
+		{"item": "Premium", "amount": Currency(34.5, "$"), "date": Date(day.AddDate(0, 0, 1), "")},
+	}, ExpandOptions{
+		Alignments: map[string]Alignment{"date": AlignCenter},
+		Totals:     []ColumnAggregate{SumColumn("amount")},
+		TotalsRow:  PlaceholderMap{"item": "Total"},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	document := string(doc.GetFile(DocumentXml))
+	if count := strings.Count(document, "<w:tr>"); count != 4 {
+		t.Errorf("expected 4 rows, have=%d", count)
+	}
+	expected := []string{
+		`<w:t>Basic</w:t>`,
+		`<w:p><w:pPr><w:spacing w:after="0"/><w:jc w:val="right"/></w:pPr><w:r><w:t>$1,200.00</w:t>`,
+		`<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>2021-03-15</w:t>`,
+		`<w:t>Total</w:t>`,
+		`<w:t>$1,234.50</w:t>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(document, e) {
+			t.Errorf("document does not contain %s\n%s", e, document)
+		}
+	}
+
+	// placeholders which are not part of the rows are kept for later replacing
+	if count := strings.Count(document, "{currency}"); count != 3 {
+		t.Errorf("expected {currency} in every row, have=%d", count)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"currency": "USD"}); err != nil {
+		t.Error(err)
+	}
+	if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocument_ExpandTableRow_NoTable(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{item}</w:t></w:r></w:p>`),
+	})
+	if err := doc.ExpandTableRow("item", []PlaceholderMap{{"item": "a"}}, ExpandOptions{}); err == nil {
+		t.Error("expected an error for a placeholder outside of a table")
+	}
+}
+
+func TestTableRow_SetHeight(t *testing.T) {
+	overflowing := strings.Repeat(`<w:p><w:r><w:t>line</w:t></w:r></w:p>`, 20)
+	tests := []struct {
+		name     string
+		row      string
+		height   int
+		rule     HeightRule
+		expected string
+	}{
+		{
+			name:     "no row properties",
+			row:      `<w:tr><w:tc><w:p/></w:tc></w:tr>`,
+			height:   400,
+			rule:     HeightAtLeast,
+			expected: `<w:tr><w:trPr><w:cantSplit/><w:trHeight w:val="400" w:hRule="atLeast"/></w:trPr><w:tc><w:p/></w:tc></w:tr>`,
+		},
+		{
+			name:     "existing properties keep the schema order",
+			row:      `<w:tr w:rsidR="00A1"><w:trPr><w:trHeight w:val="200"/><w:tblHeader/></w:trPr><w:tc><w:p/></w:tc></w:tr>`,
+			height:   300,
+			rule:     HeightExact,
+			expected: `<w:tr w:rsidR="00A1"><w:trPr><w:cantSplit/><w:trHeight w:val="300" w:hRule="exact"/><w:tblHeader/></w:trPr><w:tc><w:p/></w:tc></w:tr>`,
+		},
+		{
+			name:   "exact height with overflowing content",
+			row:    `<w:tr><w:tc>` + overflowing + `</w:tc></w:tr>`,
+			height: 240,
+			rule:   HeightExact,
+			// the content is kept as it is, Word clips it at the bottom of the row
+			expected: `<w:tr><w:trPr><w:cantSplit/><w:trHeight w:val="240" w:hRule="exact"/></w:trPr><w:tc>` + overflowing + `</w:tc></w:tr>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(`<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr>` + tt.row + `</w:tbl><w:p/>`),
+			})
+			rows, err := doc.Tables()[0].Rows()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("expected 2 rows, have=%d", len(rows))
+			}
+			// setting the height twice replaces it
+			if err := rows[1].SetHeight(100, HeightAtLeast); err != nil {
+				t.Fatal(err)
+			}
+			if err := rows[1].SetHeight(tt.height, tt.rule); err != nil {
+				t.Fatal(err)
+			}
+			if err := rows[1].SetCantSplit(true); err != nil {
+				t.Fatal(err)
+			}
+
+			document := doc.GetFile(DocumentXml)
+			if err := checkWellFormed(document); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(document), `<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr>`+tt.expected+`</w:tbl>`) {
+				t.Errorf("expected %s in %s", tt.expected, document)
+			}
+
+			if err := rows[1].SetCantSplit(false); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(doc.GetFile(DocumentXml)), "cantSplit") {
+				t.Errorf("expected cantSplit to be removed")
+			}
+		})
+	}
+
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:tbl><w:tr><w:tc><w:p/></w:tc></w:tr></w:tbl><w:p/>`)})
+	rows, _ := doc.Tables()[0].Rows()
+	if err := rows[0].SetHeight(0, HeightExact); err == nil {
+		t.Error("expected an error for an invalid height")
+	}
+}
+
+func TestDocument_ExpandTableRow_RowHeight(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:tbl><w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:p><w:r><w:t>{item}</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p/>`),
+	})
+	err := doc.ExpandTableRow("item", []PlaceholderMap{{"item": "a"}, {"item": "b"}}, ExpandOptions{
+		Totals:    []ColumnAggregate{SumColumn("amount")},
+		TotalsRow: PlaceholderMap{"item": "Total"},
+		RowHeight: 360,
+		CantSplit: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	properties := `<w:trPr><w:cantSplit/><w:trHeight w:val="360" w:hRule="atLeast"/><w:tblHeader/></w:trPr>`
+	if count := strings.Count(document, properties); count != 3 {
+		t.Errorf("expected the properties in all 3 rows, have=%d\n%s", count, document)
+	}
+
+	err = doc.ExpandTableRow("item", nil, ExpandOptions{RowHeight: -1})
+	if err == nil {
+		t.Error("expected an error for an invalid height")
+	}
+}
@@ -1,11 +1,21 @@
 package docx
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
 
 var (
 	runId = 0 // global Run ID counter. Incremented by NewRun()
 )
 
+var (
+	// RunPropertiesRegex matches the run properties (<w:rPr>) of a run. The first group contains the inner properties.
+	// Tracked property changes (<w:rPrChange>) contain nested run properties and are skipped as a whole.
+	RunPropertiesRegex = regexp.MustCompile(`(?s)<w:rPr(?:\s[^>]*)?>((?:<w:rPrChange[\s>].*?</w:rPrChange>|.)*?)</w:rPr>|<w:rPr\s*/>`)
+)
+
 // TagPair describes an opening and closing tag position.
 type TagPair struct {
 	OpenTag  Position
@@ -20,6 +30,9 @@ type Run struct {
 	ID      int
 	Text    TagPair // Text is the <w:t> tag pair which is always within a run and cannot be standalone.
 	HasText bool
+
+	// enclosing is the run which contains the nested run (e.g. inside a text box), set by RunParser
+	enclosing *Run
 }
 
 // NewEmptyRun returns a new, empty run which has only an ID set.
@@ -45,6 +58,23 @@ func (r *Run) GetText(documentBytes []byte) string {
 	return string(documentBytes[startPos:endPos])
 }
 
+// GetProperties returns the inner XML of the run properties (<w:rPr>) of a text run.
+// If the run has no text or no properties, an empty string is returned.
+func (r *Run) GetProperties(documentBytes []byte) string {
+	if !r.HasText {
+		return ""
+	}
+	if int64(len(documentBytes)) < r.Text.OpenTag.Start || r.OpenTag.End > r.Text.OpenTag.Start {
+		return ""
+	}
+
+	match := RunPropertiesRegex.FindSubmatch(documentBytes[r.OpenTag.End:r.Text.OpenTag.Start])
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
 // String returns a string representation of the run, given the source bytes.
 // It may be helpful in debugging.
 func (r *Run) String(bytes []byte) string {
@@ -81,6 +111,65 @@ func (dr DocumentRuns) WithText() DocumentRuns {
 	return r
 }
 
+// RunAt returns the innermost run whose span (from the start of its open tag to the end of its close tag) contains
+// the given byte offset, or nil if there is none.
+// The runs must be ordered by the end of their close tag, which is the order in which RunParser finds them.
+// The candidate is found using a binary search. Only if the offset is in front of it, the runs which enclose the
+// candidate (nested runs, e.g. inside text boxes) are checked, so the lookup takes O(log n) plus the nesting depth.
+func (dr DocumentRuns) RunAt(pos int64) *Run {
+	i := sort.Search(len(dr), func(i int) bool {
+		return dr[i].CloseTag.End > pos
+	})
+	if i == len(dr) {
+		return nil
+	}
+	// every run which ends after the candidate and contains the offset encloses the candidate
+	for run := dr[i]; run != nil; run = run.enclosing {
+		if run.OpenTag.Start <= pos {
+			return run
+		}
+	}
+	return nil
+}
+
+// RunSpan is the part of the text of a run which belongs to a placeholder.
+type RunSpan struct {
+	Run *Run
+	// Position is the absolute position of the part inside the document bytes.
+	Position Position
+}
+
+// Map returns the spans of the runs which make up the placeholders of the document bytes, by placeholder key
+// (without delimiters). Every occurrence of a key is a group of spans, the groups are in document order. A placeholder
+// which is split across several runs has a span in each of them. The placeholders are assembled just like
+// ParsePlaceholders assembles them, the runs must belong to the document bytes.
+func (dr DocumentRuns) Map(docBytes []byte) (map[string][][]RunSpan, error) {
+	placeholders, err := ParsePlaceholders(dr, docBytes)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(placeholders, func(i, j int) bool { return placeholders[i].StartPos() < placeholders[j].StartPos() })
+
+	spans := make(map[string][][]RunSpan)
+	for _, placeholder := range placeholders {
+		text := placeholder.Text(docBytes)
+		if !IsDelimitedPlaceholder(text) {
+			continue
+		}
+		var group []RunSpan
+		for _, fragment := range placeholder.Fragments {
+			offset := fragment.Run.Text.OpenTag.End
+			group = append(group, RunSpan{
+				Run:      fragment.Run,
+				Position: Position{Start: offset + fragment.Position.Start, End: offset + fragment.Position.End},
+			})
+		}
+		key := RemovePlaceholderDelimiter(text)
+		spans[key] = append(spans[key], group)
+	}
+	return spans, nil
+}
+
 // Push will push a new Run onto the DocumentRuns stack
 func (dr *DocumentRuns) Push(run *Run) {
 	*dr = append(*dr, run)
@@ -0,0 +1,184 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// runPropertyOrder is the sequence in which the children of <w:rPr> must occur according to the schema.
+	runPropertyOrder = []string{
+		"rStyle", "rFonts", "b", "bCs", "i", "iCs", "caps", "smallCaps", "strike", "dstrike", "outline",
+		"shadow", "emboss", "imprint", "noProof", "snapToGrid", "vanish", "webHidden", "color", "spacing",
+		"w", "kern", "position", "sz", "szCs", "highlight", "u", "effect", "bdr", "shd", "fitText",
+		"vertAlign", "rtl", "cs", "em", "lang", "eastAsianLayout", "specVanish", "oMath",
+	}
+
+	// paragraphPropertyOrder is the sequence in which the children of <w:pPr> must occur according to the schema.
+	paragraphPropertyOrder = []string{
+		"pStyle", "keepNext", "keepLines", "pageBreakBefore", "framePr", "widowControl", "numPr",
+		"suppressLineNumbers", "pBdr", "shd", "tabs", "suppressAutoHyphens", "kinsoku", "wordWrap",
+		"overflowPunct", "topLinePunct", "autoSpaceDE", "autoSpaceDN", "bidi", "adjustRightInd", "snapToGrid",
+		"spacing", "ind", "contextualSpacing", "mirrorIndents", "suppressOverlap", "jc", "textDirection",
+		"textAlignment", "textboxTightWrap", "outlineLvl", "divId", "cnfStyle", "rPr", "sectPr", "pPrChange",
+	}
+
+	// propertyTagRegex matches the start of a property element and captures its qualified name
+	propertyTagRegex = regexp.MustCompile(`<([A-Za-z0-9]+:[A-Za-z0-9]+)[\s/>]`)
+)
+
+// property is a single child element of a properties element like <w:rPr>.
+type property struct {
+	Name   string // qualified name, e.g. 'w:b'
+	Markup string
+}
+
+// splitProperties splits the inner XML of a properties element into its child elements.
+// Anything which is not an element (e.g. whitespace) is dropped.
+func splitProperties(props string) []property {
+	var properties []property
+	for len(props) > 0 {
+		loc := propertyTagRegex.FindStringSubmatchIndex(props)
+		if loc == nil {
+			break
+		}
+		name := props[loc[2]:loc[3]]
+		rest := props[loc[0]:]
+
+		tagEnd := strings.Index(rest, ">")
+		if tagEnd < 0 {
+			break
+		}
+		end := tagEnd + 1
+		if rest[tagEnd-1] != '/' {
+			// the element has content, it ends with its close tag
+			closeTag := "</" + name + ">"
+			closePos := strings.Index(rest, closeTag)
+			if closePos < 0 {
+				break
+			}
+			end = closePos + len(closeTag)
+		}
+		properties = append(properties, property{Name: name, Markup: rest[:end]})
+		props = rest[end:]
+	}
+	return properties
+}
+
+// propertyOrder returns the index of the (local) name inside the order, unknown names go last.
+func propertyOrder(order []string, name string) int {
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	for i, n := range order {
+		if n == name {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// setProperty returns the inner XML of a properties element in which the property with the given qualified name
+// is replaced by the given markup. The property is inserted at the position defined by order.
+// If the markup is empty, the property is removed.
+func setProperty(props string, order []string, name, markup string) string {
+	var result strings.Builder
+	inserted := markup == ""
+	position := propertyOrder(order, name)
+
+	for _, p := range splitProperties(props) {
+		if p.Name == name {
+			continue
+		}
+		if !inserted && propertyOrder(order, p.Name) > position {
+			result.WriteString(markup)
+			inserted = true
+		}
+		result.WriteString(p.Markup)
+	}
+	if !inserted {
+		result.WriteString(markup)
+	}
+	return result.String()
+}
+
+// SetRunProperty returns the given inner run properties with the property of the given qualified name
+// (e.g. 'w:b') replaced by markup, respecting the order of the schema. An empty markup removes the property.
+func SetRunProperty(runProperties, name, markup string) string {
+	return setProperty(runProperties, runPropertyOrder, name, markup)
+}
+
+// SetParagraphProperty returns the given inner paragraph properties with the property of the given qualified name
+// (e.g. 'w:jc') replaced by markup, respecting the order of the schema. An empty markup removes the property.
+func SetParagraphProperty(paragraphProperties, name, markup string) string {
+	return setProperty(paragraphProperties, paragraphPropertyOrder, name, markup)
+}
+
+// SetRunProperties replaces the run properties (<w:rPr>) of the run with the given inner properties, without changing
+// its text. Runs without properties get a <w:rPr> right after their open tag, empty properties remove the <w:rPr>.
+// The run must be one of the current runs of the document (see Runs). As the file is parsed again afterwards,
+// all runs of the file are replaced by new ones.
+//
+//	props := docx.SetRunProperty(run.GetProperties(doc.GetFile(docx.DocumentXml)), "w:b", "<w:b/>")
+//	err := doc.SetRunProperties(run, docx.RunProperties(props))
+func (d *Document) SetRunProperties(run *Run, props RunProperties) error {
+	file := d.runFile(run)
+	if file == "" {
+		return fmt.Errorf("run %d is not part of the document", run.ID)
+	}
+	if run.CloseTag.Start < run.OpenTag.End {
+		return fmt.Errorf("run %d has no content", run.ID)
+	}
+	data := d.GetFile(file)
+
+	markup := ""
+	if props != "" {
+		markup = "<w:rPr>" + string(props) + "</w:rPr>"
+	}
+	pos, _ := runPropertiesPosition(data, run)
+	if err := d.SetFile(file, applyEdits(data, []edit{{Position: pos, Replacement: []byte(markup)}})); err != nil {
+		return err
+	}
+	return d.parseFile(file)
+}
+
+// CopyRunFormatting applies the run properties of src to dst, e.g. to normalize the formatting of runs which were
+// found by their text. Both runs must be current runs of the document (see Runs), they may be part of different
+// files. Tracked formatting changes (<w:rPrChange>) of src are not copied.
+// As the file containing dst is parsed again, all of its runs are replaced by new ones.
+func (d *Document) CopyRunFormatting(src, dst *Run) error {
+	file := d.runFile(src)
+	if file == "" {
+		return fmt.Errorf("run %d is not part of the document", src.ID)
+	}
+	data := d.GetFile(file)
+
+	var props strings.Builder
+	if pos, exists := runPropertiesPosition(data, src); exists {
+		for _, p := range splitProperties(innerMarkup(data[pos.Start:pos.End])) {
+			if p.Name != "w:rPrChange" {
+				props.WriteString(p.Markup)
+			}
+		}
+	}
+	return d.SetRunProperties(dst, RunProperties(props.String()))
+}
+
+// runPropertiesPosition returns the position of the run properties (<w:rPr>) of the run, which are its first child.
+// Properties further inside the run (e.g. of the runs in a text box of a drawing) belong to other runs.
+// If the run has no properties, the empty position right after its open tag is returned.
+func runPropertiesPosition(data []byte, run *Run) (Position, bool) {
+	end := run.CloseTag.Start
+	if run.HasText {
+		end = run.Text.OpenTag.Start
+	}
+	if run.CloseTag.Start >= run.OpenTag.End && end >= run.OpenTag.End {
+		start := end - int64(len(bytes.TrimLeft(data[run.OpenTag.End:end], " \t\r\n")))
+		if loc := RunPropertiesRegex.FindIndex(data[start:end]); loc != nil && loc[0] == 0 {
+			return Position{Start: start, End: start + int64(loc[1])}, true
+		}
+	}
+	return Position{Start: run.OpenTag.End, End: run.OpenTag.End}, false
+}
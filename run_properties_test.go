@@ -0,0 +1,122 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetRunProperty(t *testing.T) {
+	tests := []struct {
+		name     string
+		props    string
+		property string
+		markup   string
+		expected string
+	}{
+		{"empty", "", "w:b", "<w:b/>", "<w:b/>"},
+		{"schema order", `<w:rFonts w:ascii="Lato"/><w:color w:val="ff0000"/>`, "w:b", "<w:b/>",
+			`<w:rFonts w:ascii="Lato"/><w:b/><w:color w:val="ff0000"/>`},
+		{"replace existing", `<w:b w:val="0"/><w:sz w:val="20"/>`, "w:b", "<w:b/>", `<w:b/><w:sz w:val="20"/>`},
+		{"remove", `<w:b/><w:i/>`, "w:b", "", `<w:i/>`},
+		{"unknown properties go last", `<w14:glow w14:rad="1"></w14:glow>`, "w:lang", `<w:lang w:val="kk-KZ"/>`,
+			`<w:lang w:val="kk-KZ"/><w14:glow w14:rad="1"></w14:glow>`},
+		{"nested content", `<w:rPrChange w:id="1"><w:rPr><w:b/></w:rPr></w:rPrChange>`, "w:b", "<w:b/>",
+			`<w:b/><w:rPrChange w:id="1"><w:rPr><w:b/></w:rPr></w:rPrChange>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := SetRunProperty(tt.props, tt.property, tt.markup); result != tt.expected {
+				t.Errorf("unexpected properties\nwant=%s\nhave=%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestDocument_SetRunProperties(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">This is </w:t></w:r><w:r><w:t>important</w:t></w:r>` +
+		`<w:r><w:rPr><w:i/><w:sz w:val="24"/></w:rPr><w:t>, really</w:t></w:r></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	bold := func(text string) {
+		data := doc.GetFile(DocumentXml)
+		for _, run := range doc.Runs() {
+			if run.GetText(data) != text {
+				continue
+			}
+			props := SetRunProperty(run.GetProperties(data), "w:b", "<w:b/>")
+			if err := doc.SetRunProperties(run, RunProperties(props)); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+		t.Fatalf("no run with the text %s", text)
+	}
+	bold("important")
+	bold(", really")
+
+	expected := `<w:r><w:t xml:space="preserve">This is </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>important</w:t></w:r>` +
+		`<w:r><w:rPr><w:b/><w:i/><w:sz w:val="24"/></w:rPr><w:t>, really</w:t></w:r>`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+
+	// empty properties remove the run properties
+	if err := doc.SetRunProperties(doc.Runs()[1], ""); err != nil {
+		t.Fatal(err)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `<w:r><w:t>important</w:t></w:r>`) {
+		t.Errorf("run properties were not removed: %s", result)
+	}
+
+	if err := doc.SetRunProperties(&Run{}, "<w:b/>"); err == nil {
+		t.Error("expected an error for a run which is not part of the document")
+	}
+}
+
+func TestDocument_SetRunProperties_WithoutText(t *testing.T) {
+	inner := `<w:r><w:rPr><w:i/></w:rPr><w:t>inside</w:t></w:r>`
+	body := `<w:p><w:r><w:pict><v:shape><v:textbox><w:txbxContent><w:p>` + inner + `</w:p></w:txbxContent></v:textbox></v:shape></w:pict></w:r></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	var outer *Run
+	for _, run := range doc.Runs() {
+		if !run.HasText {
+			outer = run
+		}
+	}
+	// the properties of the run inside the text box are not the properties of the run of the drawing
+	if pos, exists := runPropertiesPosition(doc.GetFile(DocumentXml), outer); exists {
+		t.Errorf("expected the run without text to have no properties, got %+v", pos)
+	}
+	if err := doc.SetRunProperties(outer, "<w:b/>"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:p><w:r><w:rPr><w:b/></w:rPr><w:pict><v:shape><v:textbox><w:txbxContent><w:p>` + inner + `</w:p>`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestDocument_CopyRunFormatting(t *testing.T) {
+	body := `<w:p><w:r><w:rPr><w:b/><w:color w:val="C00000"/><w:rPrChange w:id="1" w:author="x"><w:rPr/></w:rPrChange></w:rPr><w:t>Warning</w:t></w:r>` +
+		`<w:r><w:t xml:space="preserve"> and </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>caution</w:t></w:r></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+
+	runs := doc.Runs()
+	if err := doc.CopyRunFormatting(runs[0], runs[2]); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:r><w:rPr><w:b/><w:color w:val="C00000"/></w:rPr><w:t>caution</w:t></w:r>`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+
+	// a source without properties removes the formatting
+	runs = doc.Runs()
+	if err := doc.CopyRunFormatting(runs[1], runs[2]); err != nil {
+		t.Fatal(err)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `<w:r><w:t>caution</w:t></w:r>`) {
+		t.Errorf("run properties were not removed: %s", result)
+	}
+}
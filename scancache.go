@@ -0,0 +1,108 @@
+package docx
+
+import (
+	"sync"
+)
+
+// scanCache caches the results of scanning the parsed parts for placeholders, so that repeated read-only queries
+// (e.g. Placeholders) do not scan the parts again. Every part has a version which is bumped on every mutation of the
+// part, a cached scan is only used as long as the version of its part is unchanged. The cached placeholders are
+// shared, they are not copied.
+type scanCache struct {
+	mu       sync.Mutex
+	versions map[string]uint64
+	scans    map[string]*partScan
+}
+
+// partScan is the scan result of a single part at the given version.
+type partScan struct {
+	version uint64
+	// placeholders of the part in document order
+	placeholders []*Placeholder
+	// occurrences of every delimited placeholder key (without delimiters)
+	occurrences map[string]int
+	// text of the part without tags, used to count the placeholders before replacing, see countPlaceholders
+	text    string
+	hasText bool
+}
+
+// newScanCache returns an empty scanCache.
+func newScanCache() *scanCache {
+	return &scanCache{versions: make(map[string]uint64), scans: make(map[string]*partScan)}
+}
+
+// InvalidateCaches drops all cached scan results of the document. The library invalidates the results of a part
+// whenever it modifies the part, this is only required if the bytes returned by GetFile are modified in place.
+func (d *Document) InvalidateCaches() {
+	if d.scanCache == nil {
+		return
+	}
+	d.scanCache.mu.Lock()
+	defer d.scanCache.mu.Unlock()
+	d.scanCache.scans = make(map[string]*partScan)
+}
+
+// touch bumps the version of the part, which invalidates its cached scan results.
+func (d *Document) touch(part string) {
+	if d.scanCache == nil {
+		return
+	}
+	d.scanCache.mu.Lock()
+	defer d.scanCache.mu.Unlock()
+	d.scanCache.versions[part]++
+	delete(d.scanCache.scans, part)
+}
+
+// partVersion returns the current version of the part.
+func (d *Document) partVersion(part string) uint64 {
+	if d.scanCache == nil {
+		return 0
+	}
+	d.scanCache.mu.Lock()
+	defer d.scanCache.mu.Unlock()
+	return d.scanCache.versions[part]
+}
+
+// scanPart returns the placeholder scan of the parsed part, from the cache if the part did not change.
+// The text of the part is only included if withText is set.
+func (d *Document) scanPart(part string, withText bool) *partScan {
+	cache := d.scanCache
+	if cache != nil {
+		cache.mu.Lock()
+		scan, cached := cache.scans[part]
+		cache.mu.Unlock()
+		if cached && (scan.hasText || !withText) {
+			return scan
+		}
+	}
+
+	version := d.partVersion(part)
+	scan := &partScan{version: version, occurrences: make(map[string]int)}
+	scan.placeholders = d.orderedPlaceholders(d.filePlaceholders[part])
+	data := d.GetFile(part)
+	for _, placeholder := range scan.placeholders {
+		if text := placeholder.Text(data); IsDelimitedPlaceholder(text) {
+			scan.occurrences[RemovePlaceholderDelimiter(text)]++
+		}
+	}
+	if withText {
+		if !d.replaceFieldInstructions {
+			data = withoutFieldInstructions(data)
+		}
+		if !d.mathPlaceholders {
+			data = withoutMathText(data)
+		}
+		scan.text = d.stripXmlTags(string(withoutSeparators(part, data)))
+		scan.hasText = true
+	}
+
+	if cache != nil {
+		cache.mu.Lock()
+		// a part which changed while scanning is scanned again by the next query
+		if cache.versions[part] == version {
+			cache.scans[part] = scan
+		}
+		cache.mu.Unlock()
+	}
+	return scan
+}
@@ -0,0 +1,94 @@
+package docx
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// scanSummary returns the texts of all placeholders of a document without other parts and the occurrences of their keys.
+func scanSummary(doc *Document) ([]string, map[string]int) {
+	var texts []string
+	for _, placeholder := range doc.Placeholders() {
+		texts = append(texts, placeholder.Text(doc.GetFile(DocumentXml)))
+	}
+	return texts, doc.placeholderOccurrences()
+}
+
+func TestDocument_ScanCache(t *testing.T) {
+	body := `<w:p><w:r><w:t>{a} and {b}</w:t></w:r></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>{item}</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+		`<w:p><w:r><w:t>{c}</w:t></w:r></w:p>`
+
+	// every mutation must invalidate the cached scan of the changed part
+	tests := []struct {
+		name   string
+		mutate func(doc *Document) error
+	}{
+		{"ReplaceAll", func(doc *Document) error { return doc.ReplaceAll(PlaceholderMap{"a": "{x}"}) }},
+		{"Replace", func(doc *Document) error { return doc.Replace("b", "B") }},
+		{"ExpandTableRow", func(doc *Document) error {
+			return doc.ExpandTableRow("item", []PlaceholderMap{{"item": "{y}"}, {"item": "{z}"}}, ExpandOptions{})
+		}},
+		{"TrimTrailingEmptyParagraphs", func(doc *Document) error {
+			if err := doc.ReplaceAll(PlaceholderMap{"c": ""}); err != nil {
+				return err
+			}
+			_, err := doc.TrimTrailingEmptyParagraphs()
+			return err
+		}},
+		{"SetFile and parseFile", func(doc *Document) error {
+			if err := doc.SetFile(DocumentXml, []byte(testDocumentXml(`<w:p><w:r><w:t>{d}</w:t></w:r></w:p>`))); err != nil {
+				return err
+			}
+			return doc.parseFile(DocumentXml)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+			// fill the cache
+			scanSummary(doc)
+			if err := tt.mutate(doc); err != nil {
+				t.Fatal(err)
+			}
+			texts, occurrences := scanSummary(doc)
+			doc.InvalidateCaches()
+			expectedTexts, expectedOccurrences := scanSummary(doc)
+			if !reflect.DeepEqual(texts, expectedTexts) || !reflect.DeepEqual(occurrences, expectedOccurrences) {
+				t.Errorf("stale scan, want=%v %v, have=%v %v", expectedTexts, expectedOccurrences, texts, occurrences)
+			}
+		})
+	}
+
+	t.Run("repeated queries use the cache", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+		first := doc.scanPart(DocumentXml, false)
+		if second := doc.scanPart(DocumentXml, false); second != first {
+			t.Error("the part was scanned again")
+		}
+		if withText := doc.scanPart(DocumentXml, true); withText == first || !withText.hasText {
+			t.Error("the text was not scanned")
+		}
+		if again := doc.scanPart(DocumentXml, false); !again.hasText {
+			t.Error("the scan with text was not reused")
+		}
+	})
+
+	t.Run("InvalidateCaches", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+		if occurrences := doc.placeholderOccurrences(); occurrences["a"] != 1 {
+			t.Fatalf("unexpected occurrences %v", occurrences)
+		}
+		// modifying the bytes in place is not noticed by the library
+		data := doc.GetFile(DocumentXml)
+		copy(data[bytes.Index(data, []byte("{a}")):], "{e}")
+		if occurrences := doc.placeholderOccurrences(); occurrences["a"] != 1 {
+			t.Errorf("expected the cached occurrences, got %v", occurrences)
+		}
+		doc.InvalidateCaches()
+		if occurrences := doc.placeholderOccurrences(); occurrences["a"] != 0 || occurrences["e"] != 1 {
+			t.Errorf("expected the occurrences of the modified bytes, got %v", occurrences)
+		}
+	})
+}
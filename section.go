@@ -0,0 +1,241 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// SectionPropertiesRegex matches section properties (<w:sectPr>) including their content
+	SectionPropertiesRegex = regexp.MustCompile(`(?s)<w:sectPr(?:\s[^>]*)?>.*?</w:sectPr>|<w:sectPr(?:\s[^>]*)?/>`)
+	// PageSizeTagRegex matches the page size of a section
+	PageSizeTagRegex = regexp.MustCompile(`<w:pgSz(?:\s[^>]*)?/?>`)
+	// PageMarginTagRegex matches the page margins of a section
+	PageMarginTagRegex = regexp.MustCompile(`<w:pgMar(?:\s[^>]*)?/?>`)
+	// ColumnsTagRegex matches the column definition of a section
+	ColumnsTagRegex = regexp.MustCompile(`<w:cols(?:\s[^>]*)?/?>`)
+	// SectionTypeTagRegex matches the type of a section (e.g. continuous or nextPage)
+	SectionTypeTagRegex = regexp.MustCompile(`<w:type(?:\s[^>]*)?/?>`)
+)
+
+const (
+	// SectionTypeContinuous is the type of sections which start on the same page as the previous section.
+	SectionTypeContinuous = "continuous"
+)
+
+// PageSetup describes the page of a section. All values are in twips (1/20 point).
+type PageSetup struct {
+	Width        int
+	Height       int
+	MarginTop    int
+	MarginRight  int
+	MarginBottom int
+	MarginLeft   int
+	// Columns is the amount of text columns, it is at least 1.
+	Columns int
+	// Type is the section type, e.g. 'continuous'. An empty type means that the section starts on a new page.
+	Type string
+}
+
+// DefaultPageSetup is an A4 page with margins of one inch on every side.
+var DefaultPageSetup = PageSetup{
+	Width:        11906,
+	Height:       16838,
+	MarginTop:    1440,
+	MarginRight:  1440,
+	MarginBottom: 1440,
+	MarginLeft:   1440,
+	Columns:      1,
+}
+
+// ContentWidth returns the width between the left and right margins.
+func (p PageSetup) ContentWidth() int {
+	return p.Width - p.MarginLeft - p.MarginRight
+}
+
+// ContentHeight returns the height between the top and bottom margins.
+func (p PageSetup) ContentHeight() int {
+	return p.Height - p.MarginTop - p.MarginBottom
+}
+
+// ParsePageSetup parses the page setup of the given section properties (<w:sectPr>).
+// Values which are not defined are taken from DefaultPageSetup.
+func ParsePageSetup(sectPr []byte) PageSetup {
+	setup := DefaultPageSetup
+
+	intAttribute := func(tag []byte, name string, target *int) {
+		if value, ok := attributeValue(tag, name); ok {
+			if i, err := strconv.Atoi(value); err == nil {
+				*target = i
+			}
+		}
+	}
+
+	if tag := PageSizeTagRegex.Find(sectPr); tag != nil {
+		intAttribute(tag, "w:w", &setup.Width)
+		intAttribute(tag, "w:h", &setup.Height)
+	}
+	if tag := PageMarginTagRegex.Find(sectPr); tag != nil {
+		intAttribute(tag, "w:top", &setup.MarginTop)
+		intAttribute(tag, "w:right", &setup.MarginRight)
+		intAttribute(tag, "w:bottom", &setup.MarginBottom)
+		intAttribute(tag, "w:left", &setup.MarginLeft)
+	}
+	if tag := ColumnsTagRegex.Find(sectPr); tag != nil {
+		intAttribute(tag, "w:num", &setup.Columns)
+	}
+	if setup.Columns < 1 {
+		setup.Columns = 1
+	}
+	if tag := SectionTypeTagRegex.Find(sectPr); tag != nil {
+		setup.Type, _ = attributeValue(tag, "w:val")
+	}
+	return setup
+}
+
+// sectionPropertyOrder is the sequence in which the children of <w:sectPr> must occur according to the schema.
+var sectionPropertyOrder = []string{
+	"headerReference", "footerReference", "footnotePr", "endnotePr", "type", "pgSz", "pgMar", "paperSrc",
+	"pgBorders", "lnNumType", "pgNumType", "cols", "formProt", "vAlign", "noEndnote", "titlePg", "textDirection",
+	"bidi", "rtlGutter", "docGrid", "printerSettings", "sectPrChange",
+}
+
+// defaultSectionProperties is the section which is added to documents without body section properties.
+const defaultSectionProperties = `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>` +
+	`<w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="720" w:footer="720" w:gutter="0"/>` +
+	`<w:cols w:space="720"/></w:sectPr>`
+
+// PageSetup returns the page setup of the last section of the main document, which is defined by the section
+// properties at the end of the body. Without section properties, DefaultPageSetup is returned.
+func (d *Document) PageSetup() PageSetup {
+	data := d.GetFile(DocumentXml)
+	pos, ok, err := bodySectionProperties(data)
+	if err != nil || !ok {
+		return DefaultPageSetup
+	}
+	return ParsePageSetup(data[pos.Start:pos.End])
+}
+
+// SetPageSetup changes the page size, margins, columns and type of the last section of the main document.
+// Documents without section properties at the end of the body get a default section first.
+func (d *Document) SetPageSetup(setup PageSetup) error {
+	pos, err := d.ensureBodySectionProperties()
+	if err != nil {
+		return err
+	}
+	data := d.GetFile(DocumentXml)
+	sectPr := data[pos.Start:pos.End]
+
+	pgSz := string(PageSizeTagRegex.Find(sectPr))
+	if pgSz == "" {
+		pgSz = `<w:pgSz/>`
+	}
+	pgSz = setAttribute(pgSz, "w:w", strconv.Itoa(setup.Width))
+	pgSz = setAttribute(pgSz, "w:h", strconv.Itoa(setup.Height))
+
+	pgMar := string(PageMarginTagRegex.Find(sectPr))
+	if pgMar == "" {
+		pgMar = `<w:pgMar w:header="720" w:footer="720" w:gutter="0"/>`
+	}
+	pgMar = setAttribute(pgMar, "w:top", strconv.Itoa(setup.MarginTop))
+	pgMar = setAttribute(pgMar, "w:right", strconv.Itoa(setup.MarginRight))
+	pgMar = setAttribute(pgMar, "w:bottom", strconv.Itoa(setup.MarginBottom))
+	pgMar = setAttribute(pgMar, "w:left", strconv.Itoa(setup.MarginLeft))
+
+	cols := string(ColumnsTagRegex.Find(sectPr))
+	if cols == "" {
+		cols = `<w:cols w:space="720"/>`
+	}
+	columns := setup.Columns
+	if columns < 1 {
+		columns = 1
+	}
+	cols = setAttribute(cols, "w:num", strconv.Itoa(columns))
+
+	sectionType := ""
+	if setup.Type != "" {
+		sectionType = `<w:type w:val="` + setup.Type + `"/>`
+	}
+
+	inner := innerMarkup(sectPr)
+	inner = setProperty(inner, sectionPropertyOrder, "w:type", sectionType)
+	inner = setProperty(inner, sectionPropertyOrder, "w:pgSz", pgSz)
+	inner = setProperty(inner, sectionPropertyOrder, "w:pgMar", pgMar)
+	inner = setProperty(inner, sectionPropertyOrder, "w:cols", cols)
+
+	openTag := string(sectPr[:bytes.IndexByte(sectPr, '>')+1])
+	openTag = strings.TrimSuffix(strings.TrimSuffix(openTag, ">"), "/") + ">"
+	replacement := openTag + inner + "</w:sectPr>"
+
+	if err := d.SetFile(DocumentXml, applyEdits(data, []edit{{Position: pos, Replacement: []byte(replacement)}})); err != nil {
+		return err
+	}
+	return d.parseFile(DocumentXml)
+}
+
+// ensureBodySectionProperties returns the position of the section properties at the end of the body.
+// If there are none, default section properties (A4, 1 inch margins) are added and a warning is recorded.
+// As this changes the document, it must only be used by operations which actually need the section properties.
+func (d *Document) ensureBodySectionProperties() (Position, error) {
+	data := d.GetFile(DocumentXml)
+	pos, ok, err := bodySectionProperties(data)
+	if err != nil || ok {
+		return pos, err
+	}
+
+	bodyEnd := bytes.LastIndex(data, []byte("</w:body>"))
+	if bodyEnd < 0 {
+		return Position{}, fmt.Errorf("%s has no body", DocumentXml)
+	}
+	pos = Position{Start: int64(bodyEnd), End: int64(bodyEnd)}
+	data = applyEdits(data, []edit{{Position: pos, Replacement: []byte(defaultSectionProperties)}})
+	if err := d.SetFile(DocumentXml, data); err != nil {
+		return Position{}, err
+	}
+	if err := d.parseFile(DocumentXml); err != nil {
+		return Position{}, err
+	}
+	d.warnings = append(d.warnings, fmt.Sprintf("%s has no section properties, a default section (A4, 1 inch margins) was added", DocumentXml))
+
+	pos.End = pos.Start + int64(len(defaultSectionProperties))
+	return pos, nil
+}
+
+// bodySectionProperties returns the position of the section properties which are the last child of the body.
+func bodySectionProperties(data []byte) (pos Position, ok bool, err error) {
+	elements, err := BodyElements(data)
+	if err != nil {
+		return Position{}, false, err
+	}
+	if len(elements) == 0 || elements[len(elements)-1].Name != "sectPr" {
+		return Position{}, false, nil
+	}
+	return elements[len(elements)-1].Position, true, nil
+}
+
+// attributeWhitespaceReplacer replaces the whitespace characters which are not kept inside attribute values by
+// character references.
+var attributeWhitespaceReplacer = strings.NewReplacer("\n", "&#10;", "\r", "&#13;", "\t", "&#9;")
+
+// setAttribute returns the given tag with the attribute set to the given value. The attribute is added
+// at the end of the tag if it does not exist yet.
+func setAttribute(tag, name, value string) string {
+	// line breaks and tabs are normalized to spaces inside attribute values unless they are character references
+	escaped := attributeWhitespaceReplacer.Replace(html.EscapeString(value))
+	re := regexp.MustCompile(`(\s` + regexp.QuoteMeta(name) + `\s*=\s*)(?:"[^"]*"|'[^']*')`)
+	if re.MatchString(tag) {
+		return re.ReplaceAllLiteralString(tag, " "+name+`="`+escaped+`"`)
+	}
+	end := strings.LastIndex(tag, ">")
+	if strings.HasSuffix(tag, "/>") {
+		end--
+	}
+	if end < 0 {
+		return tag
+	}
+	return tag[:end] + " " + name + `="` + escaped + `"` + tag[end:]
+}
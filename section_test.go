@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDocument_SetPageSetup(t *testing.T) {
+	letter := PageSetup{Width: 12240, Height: 15840, MarginTop: 720, MarginRight: 1080, MarginBottom: 720, MarginLeft: 1080, Columns: 2}
+
+	t.Run("existing section properties", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{
+			DocumentXml: testDocumentXml(`<w:p/><w:sectPr w:rsidR="00AB"><w:headerReference w:type="default" r:id="rId1"/>` +
+				`<w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1440" w:right="1440" w:bottom="1440" w:left="1440" w:header="708" w:footer="708" w:gutter="0"/>` +
+				`<w:docGrid w:linePitch="360"/></w:sectPr>`),
+		})
+
+		if err := doc.SetPageSetup(letter); err != nil {
+			t.Fatal(err)
+		}
+		expected := `<w:sectPr w:rsidR="00AB"><w:headerReference w:type="default" r:id="rId1"/><w:pgSz w:w="12240" w:h="15840"/>` +
+			`<w:pgMar w:top="720" w:right="1080" w:bottom="720" w:left="1080" w:header="708" w:footer="708" w:gutter="0"/>` +
+			`<w:cols w:space="720" w:num="2"/><w:docGrid w:linePitch="360"/></w:sectPr>`
+		if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+			t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+		}
+		if setup := doc.PageSetup(); setup != letter {
+			t.Errorf("unexpected page setup %+v", setup)
+		}
+		if len(doc.Warnings()) != 0 {
+			t.Errorf("unexpected warnings %v", doc.Warnings())
+		}
+	})
+
+	t.Run("missing section properties", func(t *testing.T) {
+		doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`)})
+		if setup := doc.PageSetup(); setup != DefaultPageSetup {
+			t.Errorf("expected the default page setup, got %+v", setup)
+		}
+
+		if err := doc.SetPageSetup(letter); err != nil {
+			t.Fatal(err)
+		}
+		result := string(doc.GetFile(DocumentXml))
+		if !strings.HasSuffix(result, `<w:cols w:space="720" w:num="2"/></w:sectPr></w:body></w:document>`) {
+			t.Errorf("section properties were not added at the end of the body: %s", result)
+		}
+		if setup := doc.PageSetup(); setup != letter {
+			t.Errorf("unexpected page setup %+v", setup)
+		}
+		if len(doc.Warnings()) != 1 {
+			t.Errorf("expected one warning, got %v", doc.Warnings())
+		}
+
+		// the document is parsed again, so that replacing still works
+		if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestDocument_MissingSectionPropertiesRoundTrip(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`)})
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	doc.EstimatePageCount()
+	doc.PageSetup()
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	written, err := OpenBytes(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SectionPropertiesRegex.Match(written.GetFile(DocumentXml)) {
+		t.Errorf("section properties were added without using a section feature: %s", written.GetFile(DocumentXml))
+	}
+	if len(doc.Warnings()) != 0 {
+		t.Errorf("unexpected warnings %v", doc.Warnings())
+	}
+}
@@ -0,0 +1,116 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// SnapshotPart is a single, read-only, part of a PackageSnapshot.
+type SnapshotPart struct {
+	name        string
+	contentType string
+	modified    bool
+	// data holds the content of parts which differ from the original archive
+	data []byte
+	// file references the original archive for all parts which were not modified
+	file *zip.File
+}
+
+// Name returns the name of the part inside the archive, e.g. 'word/document.xml'.
+func (p SnapshotPart) Name() string {
+	return p.name
+}
+
+// ContentType returns the content type of the part as defined by [Content_Types].xml.
+// An empty string is returned if the part has no content type.
+func (p SnapshotPart) ContentType() string {
+	return p.contentType
+}
+
+// Modified returns true if the content of the part differs from the original archive, or if the part was added.
+// Parsed files (document, headers, footers and notes) are always considered modified.
+func (p SnapshotPart) Modified() bool {
+	return p.modified
+}
+
+// Open returns a reader on the content of the part.
+func (p SnapshotPart) Open() (io.ReadCloser, error) {
+	if p.file == nil {
+		return ioutil.NopCloser(bytes.NewReader(p.data)), nil
+	}
+	readCloser, err := p.file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %s", p.name, err)
+	}
+	return readCloser, nil
+}
+
+// Bytes returns the content of the part. The returned slice is owned by the caller.
+func (p SnapshotPart) Bytes() ([]byte, error) {
+	if p.file == nil {
+		return append([]byte(nil), p.data...), nil
+	}
+	readCloser, err := p.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+	return ioutil.ReadAll(readCloser)
+}
+
+// PackageSnapshot is an immutable view of all parts of a Document as they would be written by Document.Write.
+// It can be used to inspect the exact output (e.g. by converters or scanners) without writing an archive.
+type PackageSnapshot struct {
+	parts []SnapshotPart
+	index map[string]int
+}
+
+// Snapshot returns a PackageSnapshot of the document with all pending edits applied.
+// The content of modified parts is copied, unmodified parts are read from the original archive on demand.
+// Later modifications of the document do not affect the snapshot and vice versa.
+func (d *Document) Snapshot() PackageSnapshot {
+	snapshot := PackageSnapshot{index: make(map[string]int)}
+
+	// errors are ignored on purpose, parts without a content type are reported with an empty one
+	types, _ := d.parseContentTypes()
+	for _, packagePart := range d.packageParts(types) {
+		part := SnapshotPart{
+			name:        packagePart.Name,
+			contentType: packagePart.ContentType,
+			modified:    packagePart.Modified,
+			file:        packagePart.zipFile,
+		}
+		if packagePart.Modified {
+			part.data = append([]byte(nil), packagePart.data...)
+		}
+		snapshot.index[part.name] = len(snapshot.parts)
+		snapshot.parts = append(snapshot.parts, part)
+	}
+	return snapshot
+}
+
+// Parts returns all parts of the snapshot in the order in which they are written.
+func (s PackageSnapshot) Parts() []SnapshotPart {
+	return append([]SnapshotPart(nil), s.parts...)
+}
+
+// Names returns the names of all parts of the snapshot in the order in which they are written.
+func (s PackageSnapshot) Names() []string {
+	names := make([]string, 0, len(s.parts))
+	for _, part := range s.parts {
+		names = append(names, part.name)
+	}
+	return names
+}
+
+// Part returns the part with the given name. If there is no such part, false is returned.
+func (s PackageSnapshot) Part(name string) (SnapshotPart, bool) {
+	i, exists := s.index[name]
+	if !exists {
+		return SnapshotPart{}, false
+	}
+	return s.parts[i], true
+}
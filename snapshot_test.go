@@ -0,0 +1,63 @@
+package docx
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_Snapshot(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml:                    testDocumentXml(`<w:p><w:r><w:t>{name} {city}</w:t></w:r></w:p>`),
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+	})
+	if err := doc.Replace("name", "Jane"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.setPart("word/media/image1.png", []byte("png")); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := doc.Snapshot()
+
+	expectedNames := []string{ContentTypesXml, "word/_rels/document.xml.rels", DocumentXml, "word/media/image1.png"}
+	if !reflect.DeepEqual(snapshot.Names(), expectedNames) {
+		t.Errorf("unexpected parts, want=%v, have=%v", expectedNames, snapshot.Names())
+	}
+
+	documentPart, _ := snapshot.Part(DocumentXml)
+	if documentPart.ContentType() != "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml" {
+		t.Errorf("unexpected content type %s", documentPart.ContentType())
+	}
+	data, err := documentPart.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("Jane {city}")) {
+		t.Errorf("pending edits are not part of the snapshot: %s", data)
+	}
+
+	relsPart, _ := snapshot.Part("word/_rels/document.xml.rels")
+	if relsPart.Modified() {
+		t.Error("unmodified part is reported as modified")
+	}
+	if _, exists := snapshot.Part("word/styles.xml"); exists {
+		t.Error("unexpected part word/styles.xml")
+	}
+
+	// neither the caller nor later edits may change the snapshot
+	data[0] = 'X'
+	if err := doc.SetFile(DocumentXml, bytes.Replace(doc.GetFile(DocumentXml), []byte("{city}"), []byte("Paris"), 1)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = documentPart.Bytes()
+	if !bytes.Contains(data, []byte("Jane {city}")) || data[0] == 'X' {
+		t.Error("snapshot was modified")
+	}
+
+	// the snapshot does not interfere with writing the document
+	doc = reopen(t, doc)
+	if !bytes.Contains(doc.GetFile(DocumentXml), []byte("Jane Paris")) {
+		t.Errorf("unexpected document after writing: %s", doc.GetFile(DocumentXml))
+	}
+}
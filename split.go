@@ -0,0 +1,141 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// SplitRun splits the run into two runs at the given character offset of its text (see Run.GetText). Characters are
+// counted after unescaping, an entity like '&amp;' or '&#x1F600;' is a single character, as is every supplementary
+// character (e.g. an emoji) which would be a surrogate pair in UTF-16. Neither of them is ever divided.
+//
+// Both runs get a copy of the run properties. Markup of the run before its text (e.g. a tab) stays in the first run,
+// markup after its text moves into the second. Both texts preserve their whitespace.
+// If the offset is at the start or end of the text, the run is not split and nil is returned for the empty side.
+//
+// The run must be one of the current runs of the document (see Runs). As the file is parsed again afterwards,
+// all runs and placeholders of the file are replaced by new ones with up-to-date positions. If the split file cannot
+// be parsed, e.g. as the placeholders of the runs are not balanced anymore, an error is returned and the file is
+// restored.
+func (d *Document) SplitRun(run *Run, charOffset int) (*Run, *Run, error) {
+	file := d.runFile(run)
+	if file == "" {
+		return nil, nil, fmt.Errorf("run %d is not part of the document", run.ID)
+	}
+	if !run.HasText {
+		return nil, nil, fmt.Errorf("run %d has no text", run.ID)
+	}
+	data := d.GetFile(file)
+	text := data[run.Text.OpenTag.End:run.Text.CloseTag.Start]
+
+	split, err := characterOffset(text, charOffset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to split run %d: %w", run.ID, err)
+	}
+	if split == 0 {
+		return nil, run, nil
+	}
+	if split == len(text) {
+		return run, nil, nil
+	}
+
+	var props []byte
+	if pos, exists := runPropertiesPosition(data, run); exists {
+		props = data[pos.Start:pos.End]
+	}
+	textOpenTag := setAttribute(string(data[run.Text.OpenTag.Start:run.Text.OpenTag.End]), "xml:space", "preserve")
+
+	var markup bytes.Buffer
+	markup.Write(data[run.OpenTag.Start:run.Text.OpenTag.Start])
+	markup.WriteString(textOpenTag)
+	markup.Write(text[:split])
+	// the close tags of the run itself, e.g. </m:t></m:r> or </w:instrText></w:r>
+	markup.Write(data[run.Text.CloseTag.Start:run.Text.CloseTag.End])
+	markup.Write(data[run.CloseTag.Start:run.CloseTag.End])
+	secondStart := run.OpenTag.Start + int64(markup.Len())
+	markup.Write(data[run.OpenTag.Start:run.OpenTag.End])
+	markup.Write(props)
+	markup.WriteString(textOpenTag)
+	markup.Write(text[split:])
+	markup.Write(data[run.Text.CloseTag.Start:run.CloseTag.End])
+
+	changed := applyEdits(data, []edit{{
+		Position:    Position{Start: run.OpenTag.Start, End: run.CloseTag.End},
+		Replacement: markup.Bytes(),
+	}})
+	if err := d.SetFile(file, changed); err != nil {
+		return nil, nil, err
+	}
+	if err := d.parseFile(file); err != nil {
+		// the original data was parsed before, restoring it cannot fail
+		d.SetFile(file, data)
+		d.parseFile(file)
+		return nil, nil, fmt.Errorf("unable to split run %d: %w", run.ID, err)
+	}
+
+	var first, second *Run
+	for _, r := range d.runParsers[file].Runs() {
+		switch r.OpenTag.Start {
+		case run.OpenTag.Start:
+			first = r
+		case secondStart:
+			second = r
+		}
+	}
+	if first == nil || second == nil {
+		return nil, nil, fmt.Errorf("unable to find the runs after splitting run %d", run.ID)
+	}
+	return first, second, nil
+}
+
+// SplitRunAtByte splits the run like SplitRun, but at the given byte offset of its raw (still escaped) text, i.e.
+// relative to the end of its <w:t> open tag. This suits callers which work with the byte positions of the parser,
+// e.g. to insert a break or an image at a Position. The offset must be at a character boundary, an error is
+// returned if it is inside an entity or a multi-byte character.
+func (d *Document) SplitRunAtByte(run *Run, byteOffset int64) (*Run, *Run, error) {
+	file := d.runFile(run)
+	if file == "" {
+		return nil, nil, fmt.Errorf("run %d is not part of the document", run.ID)
+	}
+	if !run.HasText {
+		return nil, nil, fmt.Errorf("run %d has no text", run.ID)
+	}
+	text := d.GetFile(file)[run.Text.OpenTag.End:run.Text.CloseTag.Start]
+	if byteOffset < 0 || byteOffset > int64(len(text)) {
+		return nil, nil, fmt.Errorf("unable to split run %d: byte offset %d is outside of the text of %d bytes", run.ID, byteOffset, len(text))
+	}
+
+	chars := 0
+	for pos := 0; int64(pos) < byteOffset; chars++ {
+		next, _ := characterOffset(text[pos:], 1)
+		pos += next
+		if int64(pos) > byteOffset {
+			return nil, nil, fmt.Errorf("unable to split run %d: byte offset %d is inside a character", run.ID, byteOffset)
+		}
+	}
+	return d.SplitRun(run, chars)
+}
+
+// characterOffset returns the byte offset of the given character offset inside the (escaped) text.
+// Entities count as a single character.
+func characterOffset(text []byte, charOffset int) (int, error) {
+	if charOffset < 0 {
+		return 0, fmt.Errorf("negative character offset %d", charOffset)
+	}
+	pos := 0
+	for i := 0; i < charOffset; i++ {
+		if pos >= len(text) {
+			return 0, fmt.Errorf("character offset %d is beyond the text of %d characters", charOffset, i)
+		}
+		if text[pos] == '&' {
+			if end := bytes.IndexByte(text[pos:], ';'); end > 0 {
+				pos += end + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRune(text[pos:])
+		pos += size
+	}
+	return pos, nil
+}
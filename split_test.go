@@ -0,0 +1,214 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_SplitRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		run     string
+		options []Option
+		offset  int
+		// expected is the markup of the runs after splitting, empty if the run is not split
+		expected    string
+		first       string
+		second      string
+		expectError bool
+	}{
+		{
+			name:     "middle",
+			run:      `<w:r w:rsidR="00AB"><w:rPr><w:b/></w:rPr><w:t>Hello World</w:t></w:r>`,
+			offset:   5,
+			expected: `<w:r w:rsidR="00AB"><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Hello</w:t></w:r><w:r w:rsidR="00AB"><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> World</w:t></w:r>`,
+			first:    "Hello",
+			second:   " World",
+		},
+		{
+			name:     "markup around the text",
+			run:      `<w:r><w:tab/><w:t xml:space="preserve">ab</w:t><w:br/></w:r>`,
+			offset:   1,
+			expected: `<w:r><w:tab/><w:t xml:space="preserve">a</w:t></w:r><w:r><w:t xml:space="preserve">b</w:t><w:br/></w:r>`,
+			first:    "a",
+			second:   "b",
+		},
+		{
+			name:     "after an entity",
+			run:      `<w:r><w:t>a&amp;b</w:t></w:r>`,
+			offset:   2,
+			expected: `<w:r><w:t xml:space="preserve">a&amp;</w:t></w:r><w:r><w:t xml:space="preserve">b</w:t></w:r>`,
+			first:    "a&amp;",
+			second:   "b",
+		},
+		{
+			name:     "before a numeric entity",
+			run:      `<w:r><w:t>a&#x1F600;b</w:t></w:r>`,
+			offset:   1,
+			expected: `<w:r><w:t xml:space="preserve">a</w:t></w:r><w:r><w:t xml:space="preserve">&#x1F600;b</w:t></w:r>`,
+			first:    "a",
+			second:   "&#x1F600;b",
+		},
+		{
+			name:     "after a supplementary character",
+			run:      `<w:r><w:t>ä😀b</w:t></w:r>`,
+			offset:   2,
+			expected: `<w:r><w:t xml:space="preserve">ä😀</w:t></w:r><w:r><w:t xml:space="preserve">b</w:t></w:r>`,
+			first:    "ä😀",
+			second:   "b",
+		},
+		{
+			name:     "math",
+			run:      `<m:oMath xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math"><m:r><m:t>abcd</m:t></m:r></m:oMath>`,
+			options:  []Option{WithMathPlaceholders()},
+			offset:   2,
+			expected: `<m:oMath xmlns:m="http://schemas.openxmlformats.org/officeDocument/2006/math"><m:r><m:t xml:space="preserve">ab</m:t></m:r><m:r><m:t xml:space="preserve">cd</m:t></m:r></m:oMath>`,
+			first:    "ab",
+			second:   "cd",
+		},
+		{
+			name:     "field instruction",
+			run:      `<w:r><w:rPr><w:b/></w:rPr><w:instrText> PAGE </w:instrText></w:r>`,
+			options:  []Option{WithFieldInstructionReplacement()},
+			offset:   3,
+			expected: `<w:r><w:rPr><w:b/></w:rPr><w:instrText xml:space="preserve"> PA</w:instrText></w:r><w:r><w:rPr><w:b/></w:rPr><w:instrText xml:space="preserve">GE </w:instrText></w:r>`,
+			first:    " PA",
+			second:   "GE ",
+		},
+		{name: "at the start", run: `<w:r><w:t>abc</w:t></w:r>`, offset: 0, second: "abc"},
+		{name: "at the end", run: `<w:r><w:t>a&lt;c</w:t></w:r>`, offset: 3, first: "a&lt;c"},
+		{name: "beyond the end", run: `<w:r><w:t>abc</w:t></w:r>`, offset: 4, expectError: true},
+		{name: "negative", run: `<w:r><w:t>abc</w:t></w:r>`, offset: -1, expectError: true},
+		{name: "without text", run: `<w:r><w:tab/></w:r>`, offset: 0, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p>` + tt.run + `</w:p>`)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			first, second, err := doc.SplitRun(doc.Runs()[0], tt.offset)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := doc.GetFile(DocumentXml)
+			expected := tt.expected
+			if expected == "" {
+				expected = tt.run
+			}
+			if result := string(data); !strings.Contains(result, `<w:p>`+expected+`</w:p>`) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+			}
+			if err := checkWellFormed(data); err != nil {
+				t.Error(err)
+			}
+			for _, r := range []struct {
+				run      *Run
+				expected string
+			}{{first, tt.first}, {second, tt.second}} {
+				if (r.run == nil) != (r.expected == "") {
+					t.Fatalf("unexpected runs %v and %v", first, second)
+				}
+				if r.run != nil && r.run.GetText(data) != r.expected {
+					t.Errorf("unexpected run text %q, expected %q", r.run.GetText(data), r.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_SplitRun_Placeholders(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(
+		`<w:p><w:r><w:t>Dear {name},</w:t></w:r><w:r><w:t>{greeting}</w:t></w:r></w:p>`)})
+	if _, _, err := doc.SplitRun(doc.Runs()[0], 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane", "greeting": "Welcome"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:r><w:t xml:space="preserve">Dear </w:t></w:r><w:r><w:t xml:space="preserve">Jane,</w:t></w:r><w:r><w:t>Welcome</w:t></w:r>`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestDocument_SplitRun_Restore(t *testing.T) {
+	body := testDocumentXml(`<w:p><w:r><w:t>}}a{</w:t></w:r><w:r><w:t>{name}</w:t></w:r></w:p>`)
+	doc := openTestDocx(t, map[string]string{DocumentXml: body})
+	// '}' and '}a{' are not balanced anymore as separate runs
+	if _, _, err := doc.SplitRun(doc.Runs()[0], 1); err == nil {
+		t.Fatal("expected an error")
+	}
+	if result := string(doc.GetFile(DocumentXml)); result != body {
+		t.Errorf("the file was not restored: %s", result)
+	}
+	if runs := doc.Runs(); len(runs) != 2 {
+		t.Errorf("expected the original 2 runs, got %d", len(runs))
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, `<w:t>Jane</w:t>`) {
+		t.Errorf("the restored file was not parsed again: %s", result)
+	}
+}
+
+func TestDocument_SplitRunAtByte(t *testing.T) {
+	tests := []struct {
+		name        string
+		offset      int64
+		first       string
+		second      string
+		expectError bool
+	}{
+		{name: "middle", offset: 2, first: "a ", second: "&amp; ü"},
+		{name: "after an entity", offset: 7, first: "a &amp;", second: " ü"},
+		{name: "at the start", offset: 0, second: "a &amp; ü"},
+		{name: "at the end", offset: 10, first: "a &amp; ü"},
+		{name: "inside an entity", offset: 4, expectError: true},
+		{name: "inside a multi-byte character", offset: 9, expectError: true},
+		{name: "beyond the end", offset: 11, expectError: true},
+		{name: "negative", offset: -1, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>a &amp; ü</w:t></w:r></w:p>`)})
+			first, second, err := doc.SplitRunAtByte(doc.Runs()[0], tt.offset)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := doc.GetFile(DocumentXml)
+			for _, r := range []struct {
+				run      *Run
+				expected string
+			}{{first, tt.first}, {second, tt.second}} {
+				if (r.run == nil) != (r.expected == "") {
+					t.Fatalf("unexpected runs %v and %v", first, second)
+				}
+				if r.run == nil {
+					continue
+				}
+				if text := string(data[r.run.Text.OpenTag.End:r.run.Text.CloseTag.Start]); text != r.expected {
+					t.Errorf("unexpected run text %q, expected %q", text, r.expected)
+				}
+				if props := r.run.GetProperties(data); props != "<w:b/>" {
+					t.Errorf("the run properties were not copied: %q", props)
+				}
+			}
+		})
+	}
+}
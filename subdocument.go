@@ -0,0 +1,341 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// DocumentOpenTagRegex matches the open tag of the root element of the main document (<w:document>)
+	DocumentOpenTagRegex = regexp.MustCompile(`<w:document(?:\s[^>]*)?>`)
+	// NamespaceDeclarationRegex matches a namespace declaration with a prefix, the groups contain prefix and namespace
+	NamespaceDeclarationRegex = regexp.MustCompile(`\sxmlns:([A-Za-z0-9_.-]+)\s*=\s*"([^"]*)"`)
+	// StyleReferenceRegex matches a reference to a style (<w:pStyle>, <w:rStyle>, <w:tblStyle>), the group contains its ID
+	StyleReferenceRegex = regexp.MustCompile(`<w:(?:pStyle|rStyle|tblStyle)\s+w:val="([^"]*)"`)
+	// StyleDefinitionRegex matches the definition of a single style of the styles part
+	StyleDefinitionRegex = regexp.MustCompile(`(?s)<w:style\s[^>]*/>|<w:style\s[^>]*[^/]>.*?</w:style>`)
+
+	// noteReferenceRegex matches the references of a paragraph to notes and comments
+	noteReferenceRegex = regexp.MustCompile(`<w:(?:footnoteReference|endnoteReference|commentReference|commentRangeStart|commentRangeEnd)\s[^>]*/>`)
+	// numberingPropertiesRegex matches the numbering properties of a paragraph
+	numberingPropertiesRegex = regexp.MustCompile(`(?s)<w:numPr>.*?</w:numPr>|<w:numPr\s*/>`)
+	// styleChainReferenceRegex matches the references of a style definition to other styles
+	styleChainReferenceRegex = regexp.MustCompile(`<w:(?:basedOn|link|next)\s+w:val="([^"]*)"`)
+	// stylesCloseTagRegex matches the close tag of the styles part
+	stylesCloseTagRegex = regexp.MustCompile(`</w:styles>`)
+)
+
+// ReplaceWithDocument replaces every occurrence of the placeholder key in the main document with the body of the
+// sub-document, e.g. to assemble '{include:cover}' from a reusable cover page. Every placeholder must be the only
+// content of its paragraph or a direct child of it, just like block values (see TableValue).
+//
+//   - Images and other parts referenced by the inserted content are copied into the package, the relationship IDs
+//     are relocated. Hyperlinks and other external relationships are added to the main document.
+//   - Styles used by the inserted content which the main document does not define are copied, including the
+//     styles they are based on. Styles which both documents define are not copied, the inserted content uses the
+//     definition of the main document; Word behaves the same when pasting with 'use destination styles'.
+//   - The section properties of the sub-document are dropped, the content becomes part of the section of the
+//     placeholder.
+//   - Footnotes, endnotes, comments and list numbering are not carried over. Their references are removed, a
+//     warning is recorded if list numbering is removed.
+//
+// ErrPlaceholderNotFound is returned if the main document does not contain the placeholder.
+func (d *Document) ReplaceWithDocument(key string, sub *Document) error {
+	key = RemovePlaceholderDelimiter(key)
+	if err := d.checkReplacementLimits(PlaceholderMap{key: nil}); err != nil {
+		return err
+	}
+
+	literals := make(map[string]bool)
+	for _, literal := range d.placeholderLiterals(key) {
+		literals[literal] = true
+	}
+	data := d.GetFile(DocumentXml)
+	var placeholders []*Placeholder
+	for _, placeholder := range d.orderedPlaceholders(d.filePlaceholders[DocumentXml]) {
+		if literals[placeholder.Text(data)] {
+			placeholders = append(placeholders, placeholder)
+		}
+	}
+	if len(placeholders) == 0 {
+		return fmt.Errorf("%w: %s", ErrPlaceholderNotFound, key)
+	}
+
+	body, err := sub.bodyContent()
+	if err != nil {
+		return err
+	}
+	if numberingPropertiesRegex.Match(body) {
+		d.warnOnce(fmt.Sprintf("the list numbering of the document inserted at %s is removed", key))
+		body = numberingPropertiesRegex.ReplaceAll(body, nil)
+	}
+	copyRelationship := func(rel Relationship) (string, error) { return d.copyRelationship(sub, rel) }
+	if body, err = d.relocateRelationships(sub, body, copyRelationship); err != nil {
+		return err
+	}
+	if err := d.copyStyles(sub, body); err != nil {
+		return err
+	}
+
+	var edits []edit
+	nextID := maxDrawingID(data) + 1
+	for _, placeholder := range placeholders {
+		var markup []byte
+		markup, nextID = renumberDrawings(body, nextID)
+		e, err := blockEdit(data, placeholder, string(markup))
+		if err != nil {
+			return fmt.Errorf("unable to replace %s: %w", placeholder.Text(data), err)
+		}
+		edits = append(edits, e)
+	}
+	for i := 1; i < len(edits); i++ {
+		if edits[i].Position.Start < edits[i-1].Position.End {
+			return fmt.Errorf("only one block value per paragraph is supported")
+		}
+	}
+	data = applyEdits(data, edits)
+
+	data, err = declareNamespaces(data, sub.GetFile(DocumentXml))
+	if err != nil {
+		return err
+	}
+	if err := d.SetFile(DocumentXml, data); err != nil {
+		return err
+	}
+	return d.parseFile(DocumentXml)
+}
+
+// bodyContent returns the markup of all body elements of the main document, without any section properties and
+// without references to notes and comments.
+func (d *Document) bodyContent() ([]byte, error) {
+	data := d.GetFile(DocumentXml)
+	elements, err := BodyElements(data)
+	if err != nil {
+		return nil, err
+	}
+	var body []byte
+	for _, element := range elements {
+		if element.Name == SectionPropertiesElementName {
+			continue
+		}
+		body = append(body, element.Bytes(data)...)
+	}
+	body = SectionPropertiesRegex.ReplaceAll(body, nil)
+	return noteReferenceRegex.ReplaceAll(body, nil), nil
+}
+
+// relocateRelationships adds the relationships of the sub-document which the body references to the main document
+// using copyRelationship, and returns the body with the new relationship IDs.
+func (d *Document) relocateRelationships(sub *Document, body []byte, copyRelationship func(Relationship) (string, error)) ([]byte, error) {
+	rels, err := sub.Relationships(DocumentXml)
+	if err != nil {
+		return nil, err
+	}
+	subRels := make(map[string]Relationship)
+	for _, rel := range rels {
+		subRels[rel.ID] = rel
+	}
+
+	relocated := make(map[string]string)
+	var edits []edit
+	for _, ref := range RelationshipReferenceRegex.FindAllSubmatchIndex(body, -1) {
+		id := string(body[ref[4]:ref[5]])
+		newID, done := relocated[id]
+		if !done {
+			rel, exists := subRels[id]
+			if !exists {
+				return nil, fmt.Errorf("relationship %s of the inserted document does not exist", id)
+			}
+			if newID, err = copyRelationship(rel); err != nil {
+				return nil, err
+			}
+			relocated[id] = newID
+		}
+		edits = append(edits, edit{
+			Position:    Position{Start: int64(ref[4]), End: int64(ref[5])},
+			Replacement: []byte(newID),
+		})
+	}
+	return applyEdits(body, edits), nil
+}
+
+// copyRelationship adds the relationship of the main document of the sub-document to the main document and returns
+// its new ID. The target part of internal relationships is copied, unless it has relationships of its own.
+func (d *Document) copyRelationship(sub *Document, rel Relationship) (string, error) {
+	if rel.IsExternal() {
+		return d.addRelationship(DocumentXml, Relationship{Type: rel.Type, Target: rel.Target, TargetMode: rel.TargetMode})
+	}
+
+	source := resolveTarget(DocumentXml, rel.Target)
+	if sub.partExists(RelsPath(source)) {
+		return "", fmt.Errorf("part %s of the inserted document has relationships of its own, which is not supported", source)
+	}
+	data, err := sub.getPart(source)
+	if err != nil {
+		return "", err
+	}
+	contentType, err := sub.ContentType(source)
+	if err != nil {
+		return "", err
+	}
+
+	target := d.uniquePartName(source)
+	if err := d.setPart(target, data); err != nil {
+		return "", err
+	}
+	if err := d.ensureContentType(target, contentType); err != nil {
+		return "", err
+	}
+	return d.addRelationship(DocumentXml, Relationship{Type: rel.Type, Target: relativeTarget(DocumentXml, target)})
+}
+
+// copyStyles copies the styles which the body references and the main document does not define from the
+// sub-document, together with the styles they are based on, linked to or followed by.
+func (d *Document) copyStyles(sub *Document, body []byte) error {
+	if !sub.partExists(StylesXml) {
+		return nil
+	}
+	subStyles, err := sub.getPart(StylesXml)
+	if err != nil {
+		return err
+	}
+	definitions := make(map[string][]byte)
+	for _, definition := range StyleDefinitionRegex.FindAll(subStyles, -1) {
+		openTag := definition[:bytes.IndexByte(definition, '>')+1]
+		if id, ok := attributeValue(openTag, "w:styleId"); ok {
+			definitions[id] = definition
+		}
+	}
+
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return err
+	}
+	var (
+		missing []string
+		queue   []string
+		visited = make(map[string]bool)
+	)
+	for _, ref := range StyleReferenceRegex.FindAllSubmatch(body, -1) {
+		queue = append(queue, string(ref[1]))
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		definition, defined := definitions[id]
+		if !defined || styles.style(id) != nil {
+			continue
+		}
+		missing = append(missing, id)
+		for _, ref := range styleChainReferenceRegex.FindAllSubmatch(definition, -1) {
+			queue = append(queue, string(ref[1]))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if !d.partExists(StylesXml) {
+		d.warnOnce(fmt.Sprintf("the styles %s of the inserted document are not copied, the document has no styles", strings.Join(missing, ", ")))
+		return nil
+	}
+
+	sort.Strings(missing)
+	var markup []byte
+	for _, id := range missing {
+		markup = append(markup, definitions[id]...)
+	}
+	data, err := d.getPart(StylesXml)
+	if err != nil {
+		return err
+	}
+	loc := stylesCloseTagRegex.FindIndex(data)
+	if loc == nil {
+		return fmt.Errorf("invalid styles part %s", StylesXml)
+	}
+	return d.setPart(StylesXml, applyEdits(data, []edit{{Position: Position{Start: int64(loc[0]), End: int64(loc[0])}, Replacement: markup}}))
+}
+
+// renumberDrawings returns the markup with the ids of all drawings (<wp:docPr>) renumbered, starting with nextID,
+// and the next free id.
+func renumberDrawings(markup []byte, nextID int) ([]byte, int) {
+	var edits []edit
+	for _, match := range DrawingPropertiesIdRegex.FindAllSubmatchIndex(markup, -1) {
+		edits = append(edits, edit{
+			Position:    Position{Start: int64(match[2]), End: int64(match[3])},
+			Replacement: []byte(strconv.Itoa(nextID)),
+		})
+		nextID++
+	}
+	return applyEdits(markup, edits), nextID
+}
+
+// declareNamespaces adds the namespace declarations of the root element of source which the root element of the
+// main document data does not declare, and extends its ignorable namespaces accordingly.
+func declareNamespaces(data, source []byte) ([]byte, error) {
+	rootLoc := DocumentOpenTagRegex.FindIndex(data)
+	sourceRoot := DocumentOpenTagRegex.Find(source)
+	if rootLoc == nil || sourceRoot == nil {
+		return nil, fmt.Errorf("missing root element %s", DocumentXml)
+	}
+	root := string(data[rootLoc[0]:rootLoc[1]])
+
+	declared := make(map[string]bool)
+	for _, match := range NamespaceDeclarationRegex.FindAllStringSubmatch(root, -1) {
+		declared[match[1]] = true
+	}
+	var declarations strings.Builder
+	for _, match := range NamespaceDeclarationRegex.FindAllStringSubmatch(string(sourceRoot), -1) {
+		if !declared[match[1]] {
+			declared[match[1]] = true
+			declarations.WriteString(match[0])
+		}
+	}
+
+	var ignorable []string
+	if match := IgnorableAttributeRegex.FindStringSubmatch(string(sourceRoot)); match != nil {
+		ignorable = strings.Fields(match[1])
+	}
+	current := IgnorableAttributeRegex.FindStringSubmatchIndex(root)
+	var added []string
+	for _, prefix := range ignorable {
+		if current == nil || !containsField(root[current[2]:current[3]], prefix) {
+			added = append(added, prefix)
+		}
+	}
+
+	end := len(root) - 1
+	if strings.HasSuffix(root, "/>") {
+		end--
+	}
+	updated := root[:end] + declarations.String() + root[end:]
+	if len(added) > 0 {
+		if current != nil {
+			updated = updated[:current[3]] + " " + strings.Join(added, " ") + updated[current[3]:]
+		} else {
+			prefix := markupCompatibilityPrefix([]byte(updated))
+			updated = updated[:end] + fmt.Sprintf(` %s:Ignorable="%s"`, prefix, strings.Join(added, " ")) + updated[end:]
+		}
+	}
+	if updated == root {
+		return data, nil
+	}
+	return applyEdits(data, []edit{{Position: Position{Start: int64(rootLoc[0]), End: int64(rootLoc[1])}, Replacement: []byte(updated)}}), nil
+}
+
+// containsField returns true if the whitespace separated list contains the field.
+func containsField(list, field string) bool {
+	for _, f := range strings.Fields(list) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testStylesXml(styles string) string {
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` + styles + `</w:styles>`
+}
+
+func TestDocument_ReplaceWithDocument(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Intro</w:t></w:r></w:p><w:p><w:r><w:t>{include:cover}</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:drawing><wp:inline><wp:docPr id="1" name="Logo"/></wp:inline></w:drawing></w:r></w:p>` +
+			`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`),
+		StylesXml: testStylesXml(`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/></w:style>`),
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + ImageRelationshipType + `" Target="media/image1.png"/></Relationships>`,
+		"word/media/image1.png": "main",
+	})
+
+	subDocument := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" ` +
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml" ` +
+		`xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006" mc:Ignorable="w14"><w:body>` +
+		`<w:p w14:paraId="00000001"><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Cover</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Subtitle"/><w:sectPr/></w:pPr><w:r><w:t>See </w:t></w:r>` +
+		`<w:hyperlink r:id="rId3"><w:r><w:t>site</w:t></w:r></w:hyperlink><w:r><w:footnoteReference w:id="1"/></w:r></w:p>` +
+		`<w:p><w:r><w:drawing><wp:inline><wp:docPr id="1" name="Picture"/><a:blip r:embed="rId2"/></wp:inline></w:drawing></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="12240" w:h="15840"/></w:sectPr></w:body></w:document>`
+	sub := openTestDocx(t, map[string]string{
+		DocumentXml: subDocument,
+		StylesXml: testStylesXml(`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="sub heading"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Title"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Unused"><w:name w:val="Unused"/></w:style>`),
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId2" Type="` + ImageRelationshipType + `" Target="media/image1.png"/>` +
+			`<Relationship Id="rId3" Type="` + HyperlinkRelationshipType + `" Target="https://example.com" TargetMode="External"/></Relationships>`,
+		"word/media/image1.png": "sub",
+	})
+
+	if err := doc.ReplaceWithDocument("{include:cover}", sub); err != nil {
+		t.Fatal(err)
+	}
+	doc = reopen(t, doc)
+
+	document := string(doc.GetFile(DocumentXml))
+	if err := checkWellFormed([]byte(document)); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<w:t>Intro</w:t>`, `<w:t>Cover</w:t>`, `<w:pgSz w:w="11906"`,
+		`xmlns:w14="http://schemas.microsoft.com/office/word/2010/wordml"`, `mc:Ignorable="wps w14"`,
+		`<wp:docPr id="2" name="Picture"/>`, `<wp:docPr id="1" name="Logo"/>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in document %s", expected, document)
+		}
+	}
+	for _, unexpected := range []string{`{include:cover}`, `<w:pgSz w:w="12240"`, `<w:sectPr/>`, `footnoteReference`} {
+		if strings.Contains(document, unexpected) {
+			t.Errorf("unexpected %s in document %s", unexpected, document)
+		}
+	}
+
+	rels, err := doc.Relationships(DocumentXml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := make(map[string]Relationship)
+	for _, rel := range rels {
+		targets[rel.Target] = rel
+	}
+	image, copied := targets["media/image1_1.png"]
+	if !copied || !strings.Contains(document, `r:embed="`+image.ID+`"`) {
+		t.Errorf("expected the image to be copied and referenced, relationships: %v", rels)
+	}
+	link, added := targets["https://example.com"]
+	if !added || !link.IsExternal() || !strings.Contains(document, `r:id="`+link.ID+`"`) {
+		t.Errorf("expected the hyperlink relationship to be added, relationships: %v", rels)
+	}
+	if data, err := doc.getPart("word/media/image1_1.png"); err != nil || string(data) != "sub" {
+		t.Errorf("expected the copied image, have=%q err=%v", data, err)
+	}
+	if data, _ := doc.getPart("word/media/image1.png"); string(data) != "main" {
+		t.Errorf("expected the image of the document to be kept, have=%q", data)
+	}
+
+	styles, _ := doc.getPart(StylesXml)
+	for _, expected := range []string{`w:styleId="Subtitle"`, `w:styleId="Title"`, `<w:name w:val="heading 1"/>`} {
+		if !strings.Contains(string(styles), expected) {
+			t.Errorf("expected %s in styles %s", expected, styles)
+		}
+	}
+	for _, unexpected := range []string{`w:styleId="Unused"`, `sub heading`} {
+		if strings.Contains(string(styles), unexpected) {
+			t.Errorf("unexpected %s in styles %s", unexpected, styles)
+		}
+	}
+}
+
+func TestDocument_ReplaceWithDocument_NotFound(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{other}</w:t></w:r></w:p>`)})
+	sub := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Sub</w:t></w:r></w:p>`)})
+	if err := doc.ReplaceWithDocument("include", sub); !errors.Is(err, ErrPlaceholderNotFound) {
+		t.Errorf("expected ErrPlaceholderNotFound, got %v", err)
+	}
+}
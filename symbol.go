@@ -0,0 +1,145 @@
+package docx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SymbolFont is the font Word uses for bullets.
+const SymbolFont = "Symbol"
+
+// BulletSymbol is the bullet glyph of the 'Symbol' font, the default bullet of Word.
+var BulletSymbol = Symbol{Font: SymbolFont, Char: 0xF0B7, Text: "•"}
+
+// Symbol is a replacement value which inserts a character of a symbol font (<w:sym>), e.g. a bullet or checkbox glyph.
+// The symbol is placed into a run of its own which keeps the run properties of the placeholder.
+type Symbol struct {
+	// Font is the symbol font, e.g. 'Symbol' or 'Wingdings'.
+	Font string
+	// Char is the code of the character inside the font. Symbol fonts use the private use area starting at
+	// U+F000, e.g. 0xF0B7 is the bullet of the 'Symbol' font.
+	Char rune
+	// Text is the plain text equivalent of the symbol which is returned by String.
+	Text string
+}
+
+// Markup implements the MarkupValue interface.
+func (s Symbol) Markup(runProperties string) string {
+	var rPr string
+	if runProperties != "" {
+		rPr = fmt.Sprintf("<w:rPr>%s</w:rPr>", runProperties)
+	}
+	return fmt.Sprintf(`</w:t></w:r><w:r>%s<w:sym w:font="%s" w:char="%04X"/></w:r><w:r>%s<w:t xml:space="preserve">`,
+		rPr, xmlEscape(s.Font), s.Char, rPr)
+}
+
+// String returns the plain text equivalent of the symbol.
+func (s Symbol) String() string {
+	return s.Text
+}
+
+// ListMarkerFormat is the format of an inline list marker.
+type ListMarkerFormat int
+
+const (
+	// MarkerBullet is the bullet glyph of the 'Symbol' font, like the default bullet of Word.
+	MarkerBullet ListMarkerFormat = iota
+	// MarkerDecimal numbers like '1.', '2.', '3.'
+	MarkerDecimal
+	// MarkerLowerLetter numbers like 'a.', 'b.', ..., 'z.', 'aa.'
+	MarkerLowerLetter
+	// MarkerUpperLetter numbers like 'A.', 'B.', ..., 'Z.', 'AA.'
+	MarkerUpperLetter
+	// MarkerLowerRoman numbers like 'i.', 'ii.', 'iii.'
+	MarkerLowerRoman
+	// MarkerUpperRoman numbers like 'I.', 'II.', 'III.'
+	MarkerUpperRoman
+)
+
+// ListMarker is a replacement value which inserts a single list marker into running text, e.g. for inline
+// enumerations like '(a) first, (b) second'. Unlike lists, it does not use any numbering definitions;
+// the marker is static text, or a Symbol for bullets.
+type ListMarker struct {
+	Format ListMarkerFormat
+	// Number is the (1-based) number of the marker, it is ignored for bullets.
+	Number int
+	// Prefix and Suffix surround numbers, e.g. '(' and ')'. The suffix defaults to '.' if both are empty.
+	Prefix string
+	Suffix string
+}
+
+// Bullet returns an inline bullet marker.
+func Bullet() ListMarker {
+	return ListMarker{Format: MarkerBullet}
+}
+
+// NumberMarker returns an inline marker with the given number in the given format, e.g. 'b.' for number 2.
+func NumberMarker(format ListMarkerFormat, number int) ListMarker {
+	return ListMarker{Format: format, Number: number}
+}
+
+// Markup implements the MarkupValue interface. Bullets are rendered as Symbol, numbers as plain text.
+func (m ListMarker) Markup(runProperties string) string {
+	if m.Format == MarkerBullet {
+		return BulletSymbol.Markup(runProperties)
+	}
+	return xmlEscape(m.String())
+}
+
+// String returns the marker as plain text.
+func (m ListMarker) String() string {
+	if m.Format == MarkerBullet {
+		return BulletSymbol.Text
+	}
+
+	var number string
+	switch m.Format {
+	case MarkerLowerLetter:
+		number = strings.ToLower(letterNumber(m.Number))
+	case MarkerUpperLetter:
+		number = letterNumber(m.Number)
+	case MarkerLowerRoman:
+		number = strings.ToLower(romanNumber(m.Number))
+	case MarkerUpperRoman:
+		number = romanNumber(m.Number)
+	default:
+		number = strconv.Itoa(m.Number)
+	}
+	if m.Prefix == "" && m.Suffix == "" {
+		return number + "."
+	}
+	return m.Prefix + number + m.Suffix
+}
+
+// letterNumber returns the number in letters like Word does: 'A' to 'Z', followed by 'AA' to 'ZZ' and so on.
+// Numbers below 1 are returned as decimal.
+func letterNumber(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	letter := string(rune('A' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
+
+// romanNumber returns the number in roman numerals. Numbers below 1 are returned as decimal.
+func romanNumber(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+		{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+	var result strings.Builder
+	for _, numeral := range numerals {
+		for n >= numeral.value {
+			result.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,46 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListMarker_String(t *testing.T) {
+	tests := []struct {
+		marker   ListMarker
+		expected string
+	}{
+		{Bullet(), "•"},
+		{NumberMarker(MarkerDecimal, 12), "12."},
+		{NumberMarker(MarkerLowerLetter, 2), "b."},
+		{NumberMarker(MarkerUpperLetter, 28), "BB."},
+		{NumberMarker(MarkerLowerRoman, 4), "iv."},
+		{NumberMarker(MarkerUpperRoman, 1994), "MCMXCIV."},
+		{ListMarker{Format: MarkerLowerLetter, Number: 1, Prefix: "(", Suffix: ")"}, "(a)"},
+	}
+	for _, tt := range tests {
+		if result := tt.marker.String(); result != tt.expected {
+			t.Errorf("%+v: got %q, expected %q", tt.marker, result, tt.expected)
+		}
+	}
+}
+
+func TestDocument_ReplaceAll_ListMarker(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>{first} apples {second} pears</w:t></w:r></w:p>`),
+	})
+	if err := doc.ReplaceAll(PlaceholderMap{"first": Bullet(), "second": NumberMarker(MarkerDecimal, 2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := reopen(t, doc).GetFile(DocumentXml)
+	expected := `<w:r><w:rPr><w:b/></w:rPr><w:t></w:t></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:sym w:font="Symbol" w:char="F0B7"/></w:r>` +
+		`<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> apples 2. pears</w:t></w:r>`
+	if !strings.Contains(string(result), expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+	if err := checkWellFormed(result); err != nil {
+		t.Error(err)
+	}
+}
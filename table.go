@@ -0,0 +1,424 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// TableOpenTagRegex matches the open tag of a table (but not of its properties, <w:tblPr>)
+	TableOpenTagRegex = regexp.MustCompile(`<w:tbl(?:\s[^>]*)?>`)
+	// ParagraphOpenTagRegex matches the open tag of a paragraph (but not of its properties, <w:pPr>)
+	ParagraphOpenTagRegex = regexp.MustCompile(`<w:p(?:\s[^>]*)?/?>`)
+	// ParagraphPropertiesRegex matches the paragraph properties (<w:pPr>) at the beginning of the given data.
+	// Tracked property changes (<w:pPrChange>) contain nested paragraph properties and are skipped as a whole.
+	ParagraphPropertiesRegex = regexp.MustCompile(`^(?s:<w:pPr(?:\s[^>]*)?>(?:<w:pPrChange[\s>].*?</w:pPrChange>|.)*?</w:pPr>|<w:pPr\s*/>)`)
+	// blockFollowerRegex matches the markup following a block which requires the block to be followed by a paragraph
+	blockFollowerRegex = regexp.MustCompile(`^\s*(?:</w:tc>|</w:body>|<w:sectPr[\s/>])`)
+)
+
+// BlockValue is a replacement value which consists of block-level content such as tables.
+// Block-level content cannot be part of a run, the paragraph containing the placeholder is therefore split
+// at the placeholder and the block is inserted between both halves. Halves without content are removed.
+type BlockValue interface {
+	// BlockMarkup returns the markup of the block. The width is the available width in twips.
+	BlockMarkup(width int) string
+}
+
+// TableSpec describes a simple table which can be used as replacement value using TableValue.
+type TableSpec struct {
+	// Header is an optional header row which is repeated on every page and rendered bold.
+	Header []string
+	Rows   [][]string
+	// ColumnWidths in twips. Columns without a width share the remaining width of the table equally.
+	ColumnWidths []int
+	// Style is the name or ID of the table style, e.g. 'TableGrid' or 'Light Grid Accent 1', see Table.SetStyle.
+	Style string
+	// Look defines which conditional formats of the style apply, DefaultTableLook if nil.
+	Look *TableLook
+	// Borders adds single line borders to all cells.
+	Borders bool
+}
+
+// tableValue is the BlockValue of a TableSpec
+type tableValue TableSpec
+
+// TableValue returns a replacement value which replaces the placeholder with a table.
+//
+//	docx.PlaceholderMap{
+//		"price_breakdown": docx.TableValue(docx.TableSpec{
+//			Header: []string{"Item", "Price"},
+//			Rows:   [][]string{{"Basic", "10 €"}, {"Premium", "20 €"}},
+//		}),
+//	}
+func TableValue(spec TableSpec) BlockValue {
+	return tableValue(spec)
+}
+
+// columns returns the amount of columns of the table.
+func (t tableValue) columns() int {
+	columns := len(t.Header)
+	if len(t.ColumnWidths) > columns {
+		columns = len(t.ColumnWidths)
+	}
+	for _, row := range t.Rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	return columns
+}
+
+// widths returns the width of every column, columns without a width share the remaining width equally.
+func (t tableValue) widths(width int) []int {
+	columns := t.columns()
+	widths := make([]int, columns)
+	remaining, unset := width, 0
+	for i := range widths {
+		if i < len(t.ColumnWidths) && t.ColumnWidths[i] > 0 {
+			widths[i] = t.ColumnWidths[i]
+			remaining -= widths[i]
+		} else {
+			unset++
+		}
+	}
+	for i := range widths {
+		if widths[i] == 0 && unset > 0 && remaining > 0 {
+			widths[i] = remaining / unset
+		}
+	}
+	return widths
+}
+
+// BlockMarkup returns the <w:tbl> of the table.
+func (t tableValue) BlockMarkup(width int) string {
+	widths := t.widths(width)
+
+	var markup strings.Builder
+	markup.WriteString("<w:tbl><w:tblPr>")
+	if t.Style != "" {
+		markup.WriteString(fmt.Sprintf(`<w:tblStyle w:val="%s"/>`, xmlEscape(t.Style)))
+	}
+	markup.WriteString(`<w:tblW w:w="0" w:type="auto"/>`)
+	if t.Borders {
+		markup.WriteString("<w:tblBorders>")
+		for _, border := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+			markup.WriteString(fmt.Sprintf(`<w:%s w:val="single" w:sz="4" w:space="0" w:color="auto"/>`, border))
+		}
+		markup.WriteString("</w:tblBorders>")
+	}
+	look := DefaultTableLook
+	if t.Look != nil {
+		look = *t.Look
+	}
+	markup.WriteString(look.markup())
+	markup.WriteString("</w:tblPr><w:tblGrid>")
+	for _, w := range widths {
+		markup.WriteString(fmt.Sprintf(`<w:gridCol w:w="%d"/>`, w))
+	}
+	markup.WriteString("</w:tblGrid>")
+
+	writeRow := func(cells []string, header bool) {
+		markup.WriteString("<w:tr>")
+		if header {
+			markup.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+		}
+		for i, w := range widths {
+			text := ""
+			if i < len(cells) {
+				text = cells[i]
+			}
+			markup.WriteString(fmt.Sprintf(`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/></w:tcPr><w:p>`, w))
+			if text != "" {
+				markup.WriteString("<w:r>")
+				if header {
+					markup.WriteString("<w:rPr><w:b/></w:rPr>")
+				}
+				var lines []string
+				for _, line := range strings.Split(text, "\n") {
+					lines = append(lines, xmlEscape(line))
+				}
+				markup.WriteString(`<w:t xml:space="preserve">` + strings.Join(lines, "</w:t><w:br/><w:t xml:space=\"preserve\">") + "</w:t></w:r>")
+			}
+			markup.WriteString("</w:p></w:tc>")
+		}
+		markup.WriteString("</w:tr>")
+	}
+
+	if len(t.Header) > 0 {
+		writeRow(t.Header, true)
+	}
+	for _, row := range t.Rows {
+		writeRow(row, false)
+	}
+	markup.WriteString("</w:tbl>")
+	return markup.String()
+}
+
+// replaceBlocks replaces all placeholders of the given file, whose key is part of values, with the block values.
+// The placeholders of the file must be up-to-date.
+func (d *Document) replaceBlocks(file string, values map[string]BlockValue) ([]byte, error) {
+	data := d.GetFile(file)
+
+	// the available width is taken from the last section, nested tables are as wide as the page as well
+	width := DefaultPageSetup.ContentWidth()
+	if sections := SectionPropertiesRegex.FindAll(data, -1); len(sections) > 0 {
+		width = ParsePageSetup(sections[len(sections)-1]).ContentWidth()
+	}
+
+	var edits []edit
+	for _, placeholder := range d.filePlaceholders[file] {
+		text := placeholder.Text(data)
+		if !IsDelimitedPlaceholder(text) {
+			continue
+		}
+		value, ok := values[RemovePlaceholderDelimiter(text)]
+		if !ok {
+			continue
+		}
+		if file == CommentsXml {
+			d.warnOnce(fmt.Sprintf("block value of placeholder %s is not supported in %s, the placeholder is kept", text, file))
+			continue
+		}
+		if table, isTable := value.(tableValue); isTable && table.Style != "" {
+			// styles which cannot be resolved are kept as they are, Word falls back to the default table style
+			if id, err := d.tableStyleID(table.Style); err != nil {
+				d.warnOnce(err.Error())
+			} else {
+				table.Style = id
+				value = table
+			}
+		}
+
+		e, err := blockEdit(data, placeholder, value.BlockMarkup(width))
+		if err != nil {
+			return nil, fmt.Errorf("unable to replace %s: %w", text, err)
+		}
+		edits = append(edits, e)
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Position.Start < edits[j].Position.Start
+	})
+	for i := 1; i < len(edits); i++ {
+		if edits[i].Position.Start < edits[i-1].Position.End {
+			return nil, fmt.Errorf("only one block value per paragraph is supported")
+		}
+	}
+	return applyEdits(data, edits), nil
+}
+
+// blockEdit returns the edit which splits the paragraph containing the placeholder and inserts the block markup.
+func blockEdit(data []byte, placeholder *Placeholder, block string) (edit, error) {
+	firstRun := placeholder.Fragments[0].Run
+	lastRun := placeholder.Fragments[len(placeholder.Fragments)-1].Run
+
+	start, err := paragraphStart(data, firstRun.OpenTag.Start)
+	if err != nil {
+		return edit{}, err
+	}
+	if depth, min, err := relativeDepth(data[start:lastRun.OpenTag.Start]); err != nil || depth != 1 || min < 1 {
+		return edit{}, fmt.Errorf("placeholder is not a direct child of a paragraph")
+	}
+	end, err := closingTagEnd(data, lastRun.CloseTag.End)
+	if err != nil {
+		return edit{}, err
+	}
+
+	openTag := ParagraphOpenTagRegex.Find(data[start:])
+	pPr := ParagraphPropertiesRegex.Find(data[start+int64(len(openTag)):])
+	contentStart := start + int64(len(openTag)) + int64(len(pPr))
+
+	inner := ""
+	if pPr != nil {
+		inner = SectionPropertiesRegex.ReplaceAllString(string(pPr), "")
+	}
+
+	// the leading half keeps everything before the placeholder, without the section properties
+	var leading bytes.Buffer
+	leading.Write(openTag)
+	leading.WriteString(inner)
+	leading.Write(data[contentStart:placeholder.StartPos()])
+	leading.WriteString("</w:t></w:r></w:p>")
+
+	// the trailing half continues the last run of the placeholder and keeps the section properties
+	var trailing bytes.Buffer
+	trailing.WriteString("<w:p>")
+	trailing.Write(pPr)
+	trailing.WriteString("<w:r>")
+	if props := lastRun.GetProperties(data); props != "" {
+		trailing.WriteString("<w:rPr>" + props + "</w:rPr>")
+	}
+	trailing.WriteString(`<w:t xml:space="preserve">`)
+	trailing.Write(data[placeholder.EndPos():end])
+
+	var replacement bytes.Buffer
+	if !isEmptyParagraph(leading.Bytes()) {
+		replacement.Write(leading.Bytes())
+	}
+	replacement.WriteString(block)
+	if !isEmptyParagraph(trailing.Bytes()) {
+		replacement.Write(trailing.Bytes())
+	} else if blockFollowerRegex.Match(data[end:]) {
+		// table cells and the body must end with a paragraph
+		replacement.WriteString("<w:p>")
+		replacement.Write(pPr)
+		replacement.WriteString("</w:p>")
+	}
+
+	return edit{Position: Position{Start: start, End: end}, Replacement: replacement.Bytes()}, nil
+}
+
+// paragraphStart returns the start of the open tag of the paragraph which directly contains the given position.
+func paragraphStart(data []byte, pos int64) (int64, error) {
+	candidates := ParagraphOpenTagRegex.FindAllIndex(data[:pos], -1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		start := int64(candidates[i][0])
+		depth, min, err := relativeDepth(data[start:pos])
+		if err != nil {
+			continue
+		}
+		if depth == 1 && min >= 1 {
+			return start, nil
+		}
+	}
+	return 0, fmt.Errorf("no paragraph found at offset %d", pos)
+}
+
+// relativeDepth returns the element depth at the end of the given markup, relative to its beginning, as well as
+// the minimum depth after the first element.
+func relativeDepth(markup []byte) (depth, min int, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(markup))
+	first := true
+	for {
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			return depth, min, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		default:
+			continue
+		}
+		if first || depth < min {
+			min = depth
+			first = false
+		}
+	}
+}
+
+// closingTagEnd returns the offset after the close tag of the element which contains the given position.
+func closingTagEnd(data []byte, pos int64) (int64, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data[pos:]))
+	depth := 0
+	for {
+		tok, err := decoder.RawToken()
+		if err != nil {
+			return 0, fmt.Errorf("unable to find the end of the element at offset %d: %s", pos, err)
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		if depth < 0 {
+			return pos + decoder.InputOffset(), nil
+		}
+	}
+}
+
+// Table is a handle on a table of the main document, it is obtained using Document.Tables.
+// The table is identified by its position among all tables of the document. A handle therefore stays valid
+// as long as no tables are added or removed before it.
+type Table struct {
+	doc   *Document
+	index int
+}
+
+// Tables returns all tables of the main document, including nested tables, in the order of their start.
+func (d *Document) Tables() []*Table {
+	var tables []*Table
+	for i := range TableOpenTagRegex.FindAllIndex(d.GetFile(DocumentXml), -1) {
+		tables = append(tables, &Table{doc: d, index: i})
+	}
+	return tables
+}
+
+// position returns the current position of the table inside the main document.
+func (t *Table) position() (Position, error) {
+	data := t.doc.GetFile(DocumentXml)
+	tags := TableOpenTagRegex.FindAllIndex(data, -1)
+	if t.index >= len(tags) {
+		return Position{}, fmt.Errorf("table %d does not exist anymore", t.index)
+	}
+	end, err := closingTagEnd(data, int64(tags[t.index][1]))
+	if err != nil {
+		return Position{}, err
+	}
+	return Position{Start: int64(tags[t.index][0]), End: end}, nil
+}
+
+// Bytes returns the current markup of the table.
+func (t *Table) Bytes() ([]byte, error) {
+	pos, err := t.position()
+	if err != nil {
+		return nil, err
+	}
+	return t.doc.GetFile(DocumentXml)[pos.Start:pos.End], nil
+}
+
+// update replaces the markup of the table and parses the main document again.
+func (t *Table) update(markup []byte) error {
+	pos, err := t.position()
+	if err != nil {
+		return err
+	}
+	data := applyEdits(t.doc.GetFile(DocumentXml), []edit{{Position: pos, Replacement: markup}})
+	if err := t.doc.SetFile(DocumentXml, data); err != nil {
+		return err
+	}
+	return t.doc.parseFile(DocumentXml)
+}
+
+// childElements returns all direct children of the root element of data. The positions are relative to data.
+func childElements(data []byte) ([]Element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var elements []Element
+	var current Element
+	depth := 0
+	for {
+		start := decoder.InputOffset()
+		tok, err := decoder.RawToken()
+		if err == io.EOF {
+			return elements, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch elem := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				current = Element{Name: elem.Name.Local, Position: Position{Start: start}}
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				current.Position.End = decoder.InputOffset()
+				elements = append(elements, current)
+			}
+			depth--
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableValue_BlockMarkup(t *testing.T) {
+	value := TableValue(TableSpec{
+		Header:       []string{"Item", "Price"},
+		Rows:         [][]string{{"Basic & more", "10"}, {"Premium"}},
+		ColumnWidths: []int{3000},
+	})
+
+	markup := value.BlockMarkup(9000)
+	expected := []string{
+		`<w:tblGrid><w:gridCol w:w="3000"/><w:gridCol w:w="6000"/></w:tblGrid>`,
+		`<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:tcW w:w="3000" w:type="dxa"/></w:tcPr><w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Item</w:t></w:r></w:p></w:tc>`,
+		`<w:t xml:space="preserve">Basic &amp; more</w:t>`,
+		// missing cells are filled with empty paragraphs
+		`<w:tc><w:tcPr><w:tcW w:w="6000" w:type="dxa"/></w:tcPr><w:p></w:p></w:tc></w:tr></w:tbl>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(markup, e) {
+			t.Errorf("markup does not contain %s\n%s", e, markup)
+		}
+	}
+	if err := checkWellFormed([]byte(`<w:body xmlns:w="w">` + markup + `</w:body>`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocument_ReplaceAll_TableValue(t *testing.T) {
+	table := TableValue(TableSpec{Rows: [][]string{{"a", "b"}}})
+	tableMarkup := table.BlockMarkup(DefaultPageSetup.ContentWidth())
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "placeholder alone",
+			body:     `<w:p><w:pPr><w:jc w:val="center"/></w:pPr><w:r><w:t>{table}</w:t></w:r></w:p><w:p><w:r><w:t>{name}</w:t></w:r></w:p><w:sectPr/>`,
+			expected: `<w:body>` + tableMarkup + `<w:p><w:r><w:t>Jane</w:t></w:r></w:p><w:sectPr/>`,
+		},
+		{
+			name: "paragraph split",
+			body: `<w:p><w:pPr><w:sectPr/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>{name}: {table} incl. VAT</w:t></w:r></w:p>`,
+			expected: `<w:body><w:p><w:pPr></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t>Jane: </w:t></w:r></w:p>` + tableMarkup +
+				`<w:p><w:pPr><w:sectPr/></w:pPr><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> incl. VAT</w:t></w:r></w:p></w:body>`,
+		},
+		{
+			name: "placeholder in multiple runs",
+			body: `<w:p><w:r><w:t>{ta</w:t></w:r><w:r><w:t>ble}</w:t></w:r><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			expected: `<w:body>` + tableMarkup +
+				`<w:p><w:r><w:t xml:space="preserve"></w:t></w:r><w:r><w:t>Jane</w:t></w:r></w:p></w:body>`,
+		},
+		{
+			name:     "nested table",
+			body:     `<w:tbl><w:tr><w:tc><w:tcPr/><w:p><w:r><w:t>{table}</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p><w:r><w:t>{name}</w:t></w:r></w:p>`,
+			expected: `<w:tc><w:tcPr/>` + tableMarkup + `<w:p></w:p></w:tc>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)})
+
+			err := doc.ReplaceAll(PlaceholderMap{"table": table, "name": "Jane"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			result := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(result, tt.expected) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", tt.expected, result)
+			}
+			if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+				t.Error(err)
+			}
+			if count := len(doc.Placeholders()); count != 0 {
+				t.Errorf("document still contains %d placeholders", count)
+			}
+		})
+	}
+}
+
+func TestDocument_ReplaceAll_TableValueInHyperlink(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:hyperlink r:id="rId1"><w:r><w:t>{table}</w:t></w:r></w:hyperlink></w:p>`),
+	})
+	err := doc.ReplaceAll(PlaceholderMap{"table": TableValue(TableSpec{Rows: [][]string{{"a"}}})})
+	if err == nil {
+		t.Error("expected an error for a table inside a hyperlink")
+	}
+}
@@ -0,0 +1,458 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// tableLookFirstRow and the following constants are the bits of the legacy w:val of <w:tblLook>
+	tableLookFirstRow     = 0x0020
+	tableLookLastRow      = 0x0040
+	tableLookFirstColumn  = 0x0080
+	tableLookLastColumn   = 0x0100
+	tableLookNoHorizontal = 0x0200
+	tableLookNoVertical   = 0x0400
+)
+
+var (
+	// TableLookTagRegex matches the conditional formatting flags of a table (<w:tblLook>)
+	TableLookTagRegex = regexp.MustCompile(`<w:tblLook(?:\s[^>]*)?/?>`)
+
+	// styleNameNormalizer matches the characters which are ignored when comparing style names and IDs
+	styleNameNormalizer = regexp.MustCompile(`[\s_-]+`)
+	// builtinAccentRegex matches the accent suffix of the name of a built-in table style, e.g. 'Accent 1'
+	builtinAccentRegex = regexp.MustCompile(`^(Light Shading|Light List|Light Grid)(?: Accent ([1-6]))?$`)
+
+	// accentColors are the accent colors of the default Office theme, index 0 is the color of styles without accent
+	accentColors = []string{"000000", "4F81BD", "C0504D", "9BBB59", "8064A2", "4BACC6", "F79646"}
+)
+
+// TableLook defines which conditional formats of the table style apply to a table (<w:tblLook>).
+type TableLook struct {
+	FirstRow      bool
+	LastRow       bool
+	FirstColumn   bool
+	LastColumn    bool
+	BandedRows    bool
+	BandedColumns bool
+}
+
+// DefaultTableLook is the look Word applies to new tables: header row, first column and banded rows.
+var DefaultTableLook = TableLook{FirstRow: true, FirstColumn: true, BandedRows: true}
+
+// markup returns the <w:tblLook> of the look. The legacy w:val is written as well, Word 2007 only reads that one.
+func (l TableLook) markup() string {
+	val := 0
+	flag := func(on bool, bit int) string {
+		if on {
+			val |= bit
+			return "1"
+		}
+		return "0"
+	}
+	attributes := fmt.Sprintf(`w:firstRow="%s" w:lastRow="%s" w:firstColumn="%s" w:lastColumn="%s"`,
+		flag(l.FirstRow, tableLookFirstRow), flag(l.LastRow, tableLookLastRow),
+		flag(l.FirstColumn, tableLookFirstColumn), flag(l.LastColumn, tableLookLastColumn))
+	attributes += fmt.Sprintf(` w:noHBand="%s" w:noVBand="%s"`,
+		flag(!l.BandedRows, tableLookNoHorizontal), flag(!l.BandedColumns, tableLookNoVertical))
+	return fmt.Sprintf(`<w:tblLook w:val="%04X" %s/>`, val, attributes)
+}
+
+// parseTableLook returns the look of a <w:tblLook> tag. The attributes take precedence over the legacy w:val.
+// Without a tag, all bits are off, i.e. only the banded rows and columns apply.
+func parseTableLook(tag []byte) TableLook {
+	var val int64
+	if hex, ok := attributeValue(tag, "w:val"); ok {
+		val, _ = strconv.ParseInt(hex, 16, 32)
+	}
+	flag := func(name string, bit int64) bool {
+		if value, ok := attributeValue(tag, name); ok {
+			return value == "1" || value == "true" || value == "on"
+		}
+		return val&bit != 0
+	}
+	return TableLook{
+		FirstRow:      flag("w:firstRow", tableLookFirstRow),
+		LastRow:       flag("w:lastRow", tableLookLastRow),
+		FirstColumn:   flag("w:firstColumn", tableLookFirstColumn),
+		LastColumn:    flag("w:lastColumn", tableLookLastColumn),
+		BandedRows:    !flag("w:noHBand", tableLookNoHorizontal),
+		BandedColumns: !flag("w:noVBand", tableLookNoVertical),
+	}
+}
+
+// SetStyle applies the table style with the given name or ID, e.g. 'Light Grid Accent 1' or 'LightGrid-Accent1'.
+// Names and IDs are compared ignoring case, whitespace, dashes and underscores.
+//
+// Word only stores the styles a document uses. If the styles part does not define the style, the definitions of
+// the built-in styles 'Table Grid', 'Light Shading', 'Light List' and 'Light Grid' (each also with 'Accent 1' to
+// 'Accent 6') are added in the colors of the default Office theme. Other styles must be defined by the template.
+//
+// The style does not change the look of the table, see SetLook.
+func (t *Table) SetStyle(name string) error {
+	id, err := t.doc.tableStyleID(name)
+	if err != nil {
+		return err
+	}
+	return t.updateProperties(func(props string) string {
+		return setProperty(props, tablePropertyOrder, "w:tblStyle", fmt.Sprintf(`<w:tblStyle w:val="%s"/>`, xmlEscape(id)))
+	})
+}
+
+// Look returns the conditional formats of the table style which apply to the table.
+func (t *Table) Look() (TableLook, error) {
+	markup, err := t.Bytes()
+	if err != nil {
+		return TableLook{}, err
+	}
+	props, _, err := tableProperties(markup)
+	if err != nil {
+		return TableLook{}, err
+	}
+	return parseTableLook([]byte(TableLookTagRegex.FindString(props))), nil
+}
+
+// SetLook defines which conditional formats of the table style apply to the table (<w:tblLook>).
+func (t *Table) SetLook(look TableLook) error {
+	return t.updateProperties(func(props string) string {
+		return setProperty(props, tablePropertyOrder, "w:tblLook", look.markup())
+	})
+}
+
+// CellConditions returns the conditional formats of the table style (<w:tblStylePr>) which apply to the cell at
+// the given row and cell index, ordered by ascending precedence: 'wholeTable', the vertical bands
+// ('band1Vert', 'band2Vert'), the horizontal bands ('band1Horz', 'band2Horz'), 'firstCol', 'lastCol', 'firstRow',
+// 'lastRow' and the corner cells ('nwCell', 'neCell', 'swCell', 'seCell'). Later formats override properties
+// of earlier ones, e.g. the header row of a banded table is not shaded by the band.
+//
+// The conditional formats only provide the defaults of a cell: direct formatting of the cell (<w:tcPr>, e.g. its
+// shading), its paragraphs and runs always takes precedence over the table style, even over the header row.
+//
+// Bands skip the header and total row, their size is defined by the style (w:tblStyleRowBandSize,
+// w:tblStyleColBandSize). The formats are returned whether or not the style defines them.
+func (t *Table) CellConditions(row, cell int) ([]string, error) {
+	markup, err := t.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	props, _, err := tableProperties(markup)
+	if err != nil {
+		return nil, err
+	}
+	look := parseTableLook([]byte(TableLookTagRegex.FindString(props)))
+
+	children, err := childElements(markup)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse table: %s", err)
+	}
+	var rows []Element
+	for _, child := range children {
+		if child.Name == "tr" {
+			rows = append(rows, child)
+		}
+	}
+	if row < 0 || row >= len(rows) {
+		return nil, fmt.Errorf("row %d does not exist in table %d", row, t.index)
+	}
+	rowMarkup := rows[row].Bytes(markup)
+	rowChildren, err := childElements(rowMarkup)
+	if err != nil {
+		return nil, err
+	}
+	cells := 0
+	for _, child := range rowChildren {
+		if child.Name == "tc" {
+			cells++
+		}
+	}
+	if cell < 0 || cell >= cells {
+		return nil, fmt.Errorf("cell %d does not exist in row %d of table %d", cell, row, t.index)
+	}
+
+	rowBand, columnBand := 1, 1
+	if id, ok := propertyValue(props, "w:tblStyle"); ok {
+		rowBand, columnBand = t.doc.tableStyleBandSizes(id)
+	}
+
+	first := func(on bool, index int) bool { return on && index == 0 }
+	last := func(on bool, index, count int) bool { return on && index == count-1 }
+	band := func(index, count, size int, skipFirst, skipLast bool) string {
+		if skipFirst {
+			if index == 0 {
+				return ""
+			}
+			index--
+		}
+		if skipLast && index >= count-1-btoi(skipFirst) {
+			return ""
+		}
+		if (index/size)%2 == 0 {
+			return "1"
+		}
+		return "2"
+	}
+
+	firstRow, lastRow := first(look.FirstRow, row), last(look.LastRow, row, len(rows))
+	firstColumn, lastColumn := first(look.FirstColumn, cell), last(look.LastColumn, cell, cells)
+
+	conditions := []string{"wholeTable"}
+	if look.BandedColumns {
+		if b := band(cell, cells, columnBand, look.FirstColumn, look.LastColumn); b != "" {
+			conditions = append(conditions, "band"+b+"Vert")
+		}
+	}
+	if look.BandedRows {
+		if b := band(row, len(rows), rowBand, look.FirstRow, look.LastRow); b != "" {
+			conditions = append(conditions, "band"+b+"Horz")
+		}
+	}
+	for _, c := range []struct {
+		name string
+		on   bool
+	}{
+		{"firstCol", firstColumn}, {"lastCol", lastColumn}, {"firstRow", firstRow}, {"lastRow", lastRow},
+		{"nwCell", firstRow && firstColumn}, {"neCell", firstRow && lastColumn},
+		{"swCell", lastRow && firstColumn}, {"seCell", lastRow && lastColumn},
+	} {
+		if c.on {
+			conditions = append(conditions, c.name)
+		}
+	}
+	return conditions, nil
+}
+
+// btoi returns 1 for true and 0 for false.
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// updateProperties replaces the inner table properties (<w:tblPr>) of the table with the result of fn.
+func (t *Table) updateProperties(fn func(props string) string) error {
+	markup, err := t.Bytes()
+	if err != nil {
+		return err
+	}
+	props, pos, err := tableProperties(markup)
+	if err != nil {
+		return err
+	}
+	replacement := []byte("<w:tblPr>" + fn(props) + "</w:tblPr>")
+	return t.update(applyEdits(append([]byte(nil), markup...), []edit{{Position: pos, Replacement: replacement}}))
+}
+
+// tableProperties returns the inner table properties (<w:tblPr>) of the table markup and their position.
+// If the table has no properties, the position is the empty position after the open tag.
+func tableProperties(markup []byte) (string, Position, error) {
+	children, err := childElements(markup)
+	if err != nil {
+		return "", Position{}, fmt.Errorf("unable to parse table: %s", err)
+	}
+	for _, child := range children {
+		if child.Name == "tblPr" {
+			return innerMarkup(child.Bytes(markup)), child.Position, nil
+		}
+	}
+	start := int64(len(TableOpenTagRegex.Find(markup)))
+	return "", Position{Start: start, End: start}, nil
+}
+
+// tableStyleID returns the ID of the table style with the given name or ID. Built-in styles which the styles part
+// does not define are added. Without a styles part the name is returned as it is.
+func (d *Document) tableStyleID(name string) (string, error) {
+	if !d.partExists(StylesXml) {
+		d.warnOnce(fmt.Sprintf("table style %s is not defined, the document has no styles", name))
+		return name, nil
+	}
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return "", err
+	}
+	key := normalizeStyleName(name)
+	for _, style := range styles.Styles {
+		if style.Type == "table" && (normalizeStyleName(style.ID) == key || normalizeStyleName(style.Name.Val) == key) {
+			return style.ID, nil
+		}
+	}
+
+	id, markup, ok := builtinTableStyle(key)
+	if !ok {
+		return "", fmt.Errorf("table style %s is not defined and not a known built-in style", name)
+	}
+	if styles.style(id) != nil {
+		return "", fmt.Errorf("unable to add table style %s, a style with ID %s exists", name, id)
+	}
+	data, err := d.getPart(StylesXml)
+	if err != nil {
+		return "", err
+	}
+	loc := stylesCloseTagRegex.FindIndex(data)
+	if loc == nil {
+		return "", fmt.Errorf("invalid styles part %s", StylesXml)
+	}
+	data = applyEdits(data, []edit{{Position: Position{Start: int64(loc[0]), End: int64(loc[0])}, Replacement: []byte(markup)}})
+	return id, d.setPart(StylesXml, data)
+}
+
+// tableStyleBandSizes returns the amount of rows and columns of a band of the table style, 1 by default.
+func (d *Document) tableStyleBandSizes(id string) (rows, columns int) {
+	rows, columns = 1, 1
+	if !d.partExists(StylesXml) {
+		return
+	}
+	data, err := d.getPart(StylesXml)
+	if err != nil {
+		return
+	}
+	for _, definition := range StyleDefinitionRegex.FindAll(data, -1) {
+		openTag := definition[:strings.IndexByte(string(definition), '>')+1]
+		if styleID, _ := attributeValue(openTag, "w:styleId"); styleID != id {
+			continue
+		}
+		for _, child := range splitProperties(innerMarkup(definition)) {
+			if child.Name != "w:tblPr" {
+				continue
+			}
+			props := innerMarkup([]byte(child.Markup))
+			if value, ok := propertyValue(props, "w:tblStyleRowBandSize"); ok {
+				if n, err := strconv.Atoi(value); err == nil && n > 0 {
+					rows = n
+				}
+			}
+			if value, ok := propertyValue(props, "w:tblStyleColBandSize"); ok {
+				if n, err := strconv.Atoi(value); err == nil && n > 0 {
+					columns = n
+				}
+			}
+		}
+	}
+	return
+}
+
+// normalizeStyleName returns the name or ID in the form used to compare styles.
+func normalizeStyleName(name string) string {
+	return strings.ToLower(styleNameNormalizer.ReplaceAllString(name, ""))
+}
+
+// builtinTableStyle returns the ID and the definition of the built-in table style with the normalized name.
+func builtinTableStyle(key string) (id, markup string, ok bool) {
+	if key == "tablegrid" {
+		return "TableGrid", `<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:uiPriority w:val="59"/>` +
+			`<w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr><w:tblPr>` + tableBorders("000000", 4, true) +
+			`<w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>`, true
+	}
+
+	for _, family := range []string{"Light Shading", "Light List", "Light Grid"} {
+		for accent := range accentColors {
+			name := family
+			if accent > 0 {
+				name += " Accent " + strconv.Itoa(accent)
+			}
+			if normalizeStyleName(name) == key {
+				id, markup := lightTableStyle(name)
+				return id, markup, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// lightTableStyle returns the ID and the definition of a 'Light Shading', 'Light List' or 'Light Grid' style.
+func lightTableStyle(name string) (string, string) {
+	match := builtinAccentRegex.FindStringSubmatch(name)
+	family := match[1]
+	accent, _ := strconv.Atoi(match[2])
+	color := accentColors[accent]
+	tint := tintColor(color, 0.25)
+
+	id := strings.Replace(family, " ", "", -1)
+	if accent > 0 {
+		id += "-Accent" + strconv.Itoa(accent)
+	}
+
+	condition := func(kind, pPr, rPr, tcPr string) string {
+		return `<w:tblStylePr w:type="` + kind + `">` + pPr + `<w:rPr>` + rPr + `</w:rPr><w:tblPr/><w:tcPr>` + tcPr + `</w:tcPr></w:tblStylePr>`
+	}
+	border := func(side string, size int, value string) string {
+		return fmt.Sprintf(`<w:%s w:val="%s" w:sz="%d" w:space="0" w:color="%s"/>`, side, value, size, color)
+	}
+	shading := `<w:shd w:val="clear" w:color="auto" w:fill="` + tint + `"/>`
+	lastRowBorder := `<w:tcBorders>` + border("top", 6, "double") + `</w:tcBorders>`
+	bold := `<w:b/><w:bCs/>`
+
+	var props, conditions string
+	switch family {
+	case "Light Shading":
+		props = `<w:tblBorders>` + border("top", 8, "single") + border("bottom", 8, "single") + `</w:tblBorders>`
+		conditions = condition("firstRow", `<w:pPr><w:spacing w:before="0" w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`, bold,
+			`<w:tcBorders>`+border("top", 8, "single")+border("bottom", 8, "single")+`</w:tcBorders>`) +
+			condition("lastRow", "", bold, `<w:tcBorders>`+border("top", 8, "single")+border("bottom", 8, "single")+`</w:tcBorders>`) +
+			condition("firstCol", "", bold, "") + condition("lastCol", "", bold, "") +
+			condition("band1Vert", "", "", shading) + condition("band1Horz", "", "", shading)
+	case "Light List":
+		props = tableBorders(color, 8, false)
+		conditions = condition("firstRow", `<w:pPr><w:spacing w:before="0" w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`,
+			bold+`<w:color w:val="FFFFFF"/>`, `<w:shd w:val="clear" w:color="auto" w:fill="`+color+`"/>`) +
+			condition("lastRow", "", bold, lastRowBorder) +
+			condition("firstCol", "", bold, "") + condition("lastCol", "", bold, "") +
+			condition("band1Vert", "", "", tableCellBorders(color, 8)) + condition("band1Horz", "", "", tableCellBorders(color, 8))
+	default:
+		props = tableBorders(color, 8, true)
+		conditions = condition("firstRow", `<w:pPr><w:spacing w:before="0" w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>`, bold,
+			`<w:tcBorders>`+border("bottom", 18, "single")+`</w:tcBorders>`) +
+			condition("lastRow", "", bold, lastRowBorder) +
+			condition("firstCol", "", bold, "") + condition("lastCol", "", bold, "") +
+			condition("band1Vert", "", "", shading) + condition("band1Horz", "", "", shading)
+	}
+
+	return id, `<w:style w:type="table" w:styleId="` + id + `"><w:name w:val="` + name + `"/><w:uiPriority w:val="60"/>` +
+		`<w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>` +
+		`<w:tblPr><w:tblStyleRowBandSize w:val="1"/><w:tblStyleColBandSize w:val="1"/>` + props +
+		`<w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr>` +
+		conditions + `</w:style>`
+}
+
+// tableBorders returns the single line borders of a table, optionally including the inside borders.
+func tableBorders(color string, size int, inside bool) string {
+	sides := []string{"top", "left", "bottom", "right"}
+	if inside {
+		sides = append(sides, "insideH", "insideV")
+	}
+	var markup strings.Builder
+	markup.WriteString("<w:tblBorders>")
+	for _, side := range sides {
+		markup.WriteString(fmt.Sprintf(`<w:%s w:val="single" w:sz="%d" w:space="0" w:color="%s"/>`, side, size, color))
+	}
+	markup.WriteString("</w:tblBorders>")
+	return markup.String()
+}
+
+// tableCellBorders returns the single line borders around a cell.
+func tableCellBorders(color string, size int) string {
+	var markup strings.Builder
+	markup.WriteString("<w:tcBorders>")
+	for _, side := range []string{"top", "left", "bottom", "right"} {
+		markup.WriteString(fmt.Sprintf(`<w:%s w:val="single" w:sz="%d" w:space="0" w:color="%s"/>`, side, size, color))
+	}
+	markup.WriteString("</w:tcBorders>")
+	return markup.String()
+}
+
+// tintColor returns the hex color mixed with white, keeping the given share of the color.
+func tintColor(color string, share float64) string {
+	value, _ := strconv.ParseUint(color, 16, 32)
+	var tinted uint64
+	for shift := uint(16); ; shift -= 8 {
+		channel := float64((value >> shift) & 0xFF)
+		tinted |= uint64(channel*share+255*(1-share)+0.5) << shift
+		if shift == 0 {
+			break
+		}
+	}
+	return fmt.Sprintf("%06X", tinted)
+}
@@ -0,0 +1,174 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func testTableStyleDocx(t *testing.T, styles string) *Document {
+	row := func(cells ...string) string {
+		markup := "<w:tr>"
+		for _, cell := range cells {
+			markup += `<w:tc><w:p><w:r><w:t>` + cell + `</w:t></w:r></w:p></w:tc>`
+		}
+		return markup + "</w:tr>"
+	}
+	table := `<w:tbl><w:tblPr><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="2000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+		row("Item", "Qty", "Price") + row("a", "1", "10") + row("b", "2", "20") + row("c", "3", "30") + row("Total", "6", "60") + `</w:tbl>`
+	parts := map[string]string{DocumentXml: testDocumentXml(table + `<w:p/>`)}
+	if styles != "" {
+		parts[StylesXml] = styles
+	}
+	return openTestDocx(t, parts)
+}
+
+func TestTable_SetStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		styles   string
+		style    string
+		expected string
+		added    bool
+		err      bool
+	}{
+		{
+			name:     "existing style by name",
+			styles:   testStylesXml(`<w:style w:type="table" w:styleId="Custom1"><w:name w:val="My Table"/></w:style>`),
+			style:    "my table",
+			expected: "Custom1",
+		},
+		{
+			name:     "built-in style is added",
+			styles:   testStylesXml(``),
+			style:    "LightGrid-Accent1",
+			expected: "LightGrid-Accent1",
+			added:    true,
+		},
+		{
+			name:     "built-in style by name",
+			styles:   testStylesXml(``),
+			style:    "Table Grid",
+			expected: "TableGrid",
+			added:    true,
+		},
+		{
+			name:   "unknown style",
+			styles: testStylesXml(``),
+			style:  "Fancy",
+			err:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := testTableStyleDocx(t, tt.styles)
+			err := doc.Tables()[0].SetStyle(tt.style)
+			if tt.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc = reopen(t, doc)
+
+			document := string(doc.GetFile(DocumentXml))
+			if !strings.Contains(document, `<w:tblPr><w:tblStyle w:val="`+tt.expected+`"/><w:tblW`) {
+				t.Errorf("expected style %s in %s", tt.expected, document)
+			}
+			styles, _ := doc.getPart(StylesXml)
+			if err := checkWellFormed(styles); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Count(string(styles), `w:styleId="`+tt.expected+`"`) != 1 {
+				t.Errorf("expected one definition of %s in %s", tt.expected, styles)
+			}
+			if tt.added != strings.Contains(string(styles), `<w:uiPriority`) {
+				t.Errorf("unexpected styles %s", styles)
+			}
+		})
+	}
+}
+
+func TestTable_SetLook(t *testing.T) {
+	doc := testTableStyleDocx(t, testStylesXml(``))
+	table := doc.Tables()[0]
+	look := TableLook{FirstRow: true, LastRow: true, BandedColumns: true}
+	if err := table.SetLook(look); err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	expected := `<w:tblLook w:val="0260" w:firstRow="1" w:lastRow="1" w:firstColumn="0" w:lastColumn="0" w:noHBand="1" w:noVBand="0"/></w:tblPr>`
+	if !strings.Contains(document, expected) {
+		t.Errorf("expected %s in %s", expected, document)
+	}
+	if have, err := table.Look(); err != nil || have != look {
+		t.Errorf("unexpected look %+v (%v)", have, err)
+	}
+	if legacy := parseTableLook([]byte(`<w:tblLook w:val="04A0"/>`)); legacy != DefaultTableLook {
+		t.Errorf("unexpected legacy look %+v", legacy)
+	}
+}
+
+func TestTable_CellConditions(t *testing.T) {
+	doc := testTableStyleDocx(t, testStylesXml(``))
+	table := doc.Tables()[0]
+	if err := table.SetStyle("Light Shading Accent 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		look      TableLook
+		row, cell int
+		expected  string
+	}{
+		{DefaultTableLook, 0, 0, "wholeTable firstCol firstRow nwCell"},
+		{DefaultTableLook, 0, 1, "wholeTable firstRow"},
+		{DefaultTableLook, 1, 1, "wholeTable band1Horz"},
+		{DefaultTableLook, 2, 1, "wholeTable band2Horz"},
+		{DefaultTableLook, 4, 1, "wholeTable band2Horz"},
+		{TableLook{LastRow: true, LastColumn: true, BandedRows: true, BandedColumns: true}, 4, 2, "wholeTable lastCol lastRow seCell"},
+		{TableLook{LastRow: true, BandedRows: true, BandedColumns: true}, 3, 1, "wholeTable band2Vert band2Horz"},
+		{TableLook{}, 2, 2, "wholeTable"},
+	}
+	for _, tt := range tests {
+		if err := table.SetLook(tt.look); err != nil {
+			t.Fatal(err)
+		}
+		conditions, err := table.CellConditions(tt.row, tt.cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(conditions, " ") != tt.expected {
+			t.Errorf("%+v cell %d/%d: want=%s, have=%v", tt.look, tt.row, tt.cell, tt.expected, conditions)
+		}
+	}
+	if _, err := table.CellConditions(5, 0); err == nil {
+		t.Error("expected an error for a missing row")
+	}
+}
+
+func TestDocument_ReplaceAll_TableValueStyle(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{table}</w:t></w:r></w:p>`),
+		StylesXml:   testStylesXml(``),
+	})
+	err := doc.ReplaceAll(PlaceholderMap{"table": TableValue(TableSpec{
+		Rows:  [][]string{{"a", "b"}},
+		Style: "Light List Accent 3",
+		Look:  &TableLook{FirstRow: true},
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{`<w:tblStyle w:val="LightList-Accent3"/>`, `<w:tblLook w:val="0620"`} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+	if styles, _ := doc.getPart(StylesXml); !strings.Contains(string(styles), `w:color="9BBB59"`) {
+		t.Errorf("expected the style definition in %s", styles)
+	}
+}
@@ -0,0 +1,118 @@
+package docx
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// TargetReplacement is a placeholder replacement inside the target of a relationship, see WithRelationshipTargets.
+type TargetReplacement struct {
+	// Part is the relationships part, e.g. 'word/_rels/document.xml.rels'.
+	Part string
+	// ID is the ID of the relationship.
+	ID string
+	// Keys are the keys of the replaced placeholders, in the order of the target.
+	Keys []string
+	// Original and Target are the targets before and after replacing.
+	Original string
+	Target   string
+}
+
+// WithRelationshipTargets replaces placeholders inside the targets of relationships as well, e.g. inside the
+// hyperlink 'https://portal.example.com/{tenant}/login'. Most users do not expect the targets to change, thus it
+// has to be enabled explicitly.
+//
+// The values are URL encoded (like url.QueryEscape, but spaces become '%20'), so a value cannot change the
+// structure of the URL: '/', '?', '&' and '=' are encoded as well and the value fits into the path and the query.
+// All other attributes of the relationship are kept as they are, including TargetMode="External". Placeholders
+// without a value are kept, they are not reported as missing.
+// The replacements are listed separately from the placeholders of the text, see TargetReplacements.
+func WithRelationshipTargets() Option {
+	return func(d *Document) {
+		d.replaceTargets = true
+	}
+}
+
+// TargetReplacements returns all replacements inside relationship targets so far, see WithRelationshipTargets.
+func (d *Document) TargetReplacements() []TargetReplacement {
+	return d.targetReplacements
+}
+
+// replaceRelationshipTargets replaces the placeholders inside the targets of all relationships parts, if enabled.
+// The values must not be escaped.
+func (d *Document) replaceRelationshipTargets(placeholderMap PlaceholderMap) ([]TargetReplacement, error) {
+	if !d.replaceTargets {
+		return nil, nil
+	}
+	var replacements []TargetReplacement
+	for _, part := range d.packagePartNames() {
+		if !strings.HasSuffix(part, ".rels") {
+			continue
+		}
+		data, err := d.getPart(part)
+		if err != nil {
+			return nil, err
+		}
+
+		var edits []edit
+		for _, loc := range RelationshipTagRegex.FindAllIndex(data, -1) {
+			tag := data[loc[0]:loc[1]]
+			rawTarget, _ := attributeValue(tag, "Target")
+			original := html.UnescapeString(rawTarget)
+			target, keys, err := d.replaceTarget(placeholderMap, original)
+			if err != nil {
+				return nil, fmt.Errorf("unable to replace target of %s: %w", part, err)
+			}
+			if len(keys) == 0 {
+				continue
+			}
+			id, _ := attributeValue(tag, "Id")
+			replacements = append(replacements, TargetReplacement{Part: part, ID: id, Keys: keys, Original: original, Target: target})
+			edits = append(edits, edit{
+				Position:    Position{Start: int64(loc[0]), End: int64(loc[1])},
+				Replacement: []byte(setAttribute(string(tag), "Target", target)),
+			})
+		}
+		if len(edits) > 0 {
+			if err := d.setPart(part, applyEdits(data, edits)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	d.targetReplacements = append(d.targetReplacements, replacements...)
+	return replacements, nil
+}
+
+// replaceTarget returns the target with all placeholders which have a value replaced by the URL encoded value,
+// together with the keys of the replaced placeholders.
+func (d *Document) replaceTarget(placeholderMap PlaceholderMap, target string) (string, []string, error) {
+	return d.replaceReferences(placeholderMap, target, func(value string) string {
+		return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+	})
+}
+
+// replaceReferences returns the text with all placeholders which have a value replaced by the encoded value,
+// together with the keys of the replaced placeholders. Placeholders without a value are kept.
+func (d *Document) replaceReferences(placeholderMap PlaceholderMap, text string, encode func(string) string) (string, []string, error) {
+	var result strings.Builder
+	var keys []string
+	last := 0
+	for _, ref := range d.nestedReferences(text) {
+		value, exists := lookupValue(placeholderMap, ref.key)
+		if !exists {
+			continue
+		}
+		switch value.(type) {
+		case MarkupValue, BlockValue:
+			return "", nil, fmt.Errorf("%s is not a text value", ref.key)
+		}
+		result.WriteString(text[last:ref.start])
+		result.WriteString(encode(fmt.Sprint(value)))
+		last = ref.end
+		keys = append(keys, ref.key)
+	}
+	result.WriteString(text[last:])
+	return result.String(), keys, nil
+}
@@ -0,0 +1,105 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_RelationshipTargets(t *testing.T) {
+	rels := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + HyperlinkRelationshipType + `" Target="https://portal.example.com/{tenant}/login?lang={lang}&amp;x={unknown}" TargetMode="External"/>` +
+		`<Relationship Id="rId2" Type="` + ImageRelationshipType + `" Target="media/image1.png"/>` +
+		`</Relationships>`
+	parts := map[string]string{
+		DocumentXml:           testDocumentXml(`<w:p><w:hyperlink r:id="rId1"><w:r><w:t>{tenant} portal</w:t></w:r></w:hyperlink></w:p>`),
+		RelsPath(DocumentXml): rels,
+	}
+
+	tests := []struct {
+		name         string
+		options      []Option
+		target       string
+		replacements []TargetReplacement
+	}{
+		{
+			name:   "disabled by default",
+			target: `Target="https://portal.example.com/{tenant}/login?lang={lang}&amp;x={unknown}" TargetMode="External"`,
+		},
+		{
+			name:    "enabled",
+			options: []Option{WithRelationshipTargets()},
+			target:  `Target="https://portal.example.com/acme%20sons%2Feu/login?lang=kk&amp;x={unknown}" TargetMode="External"`,
+			replacements: []TargetReplacement{{
+				Part:     RelsPath(DocumentXml),
+				ID:       "rId1",
+				Keys:     []string{"tenant", "lang"},
+				Original: "https://portal.example.com/{tenant}/login?lang={lang}&x={unknown}",
+				Target:   "https://portal.example.com/acme%20sons%2Feu/login?lang=kk&x={unknown}",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, parts), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"tenant": "acme sons/eu", "lang": "kk"}); err != nil {
+				t.Fatal(err)
+			}
+			doc = reopen(t, doc)
+
+			data, err := doc.getPart(RelsPath(DocumentXml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), tt.target) {
+				t.Errorf("expected %s in %s", tt.target, data)
+			}
+			if !strings.Contains(string(data), `Target="media/image1.png"`) {
+				t.Errorf("other relationships were changed: %s", data)
+			}
+			if !strings.Contains(string(doc.GetFile(DocumentXml)), "<w:t>acme sons/eu portal</w:t>") {
+				t.Errorf("the text was not replaced: %s", doc.GetFile(DocumentXml))
+			}
+		})
+	}
+
+	t.Run("report", func(t *testing.T) {
+		doc, err := OpenBytes(newTestDocx(t, parts), WithRelationshipTargets())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.ReplaceAll(PlaceholderMap{"tenant": "acme sons/eu", "lang": "kk"}); err != nil {
+			t.Fatal(err)
+		}
+		if replacements := doc.TargetReplacements(); !reflect.DeepEqual(replacements, tests[1].replacements) {
+			t.Errorf("want=%+v, have=%+v", tests[1].replacements, replacements)
+		}
+	})
+}
+
+func TestDocument_Render_RelationshipTargets(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:hyperlink r:id="rId1"><w:r><w:t>Login</w:t></w:r></w:hyperlink></w:p>`),
+		RelsPath(DocumentXml): `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + HyperlinkRelationshipType + `" Target="https://portal.example.com/{tenant}" TargetMode="External"/>` +
+			`</Relationships>`,
+	}), WithRelationshipTargets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.render("portal", map[string]interface{}{"tenant": "a&b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Targets) != 1 || report.Targets[0].Target != "https://portal.example.com/a%26b" {
+		t.Errorf("unexpected target replacements %+v", report.Targets)
+	}
+	if len(report.Unused) != 0 || len(report.Replaced) != 0 {
+		t.Errorf("the target replacements are reported separately: %+v", report)
+	}
+}
@@ -0,0 +1,36 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"text/template"
+)
+
+// ReplaceTemplated replaces the given key in every file with the result of the Go text/template tmpl
+// executed against data. Newlines of the result become line breaks, just like with Replace.
+//
+// The result is treated as plain text: the XML special characters (&, <, >, ' and ") are escaped after executing the
+// template, so neither the template nor the data can inject markup into the document. Consequently the template
+// must not escape on its own (e.g. using the html function), as the escaped entities would appear literally.
+// Missing map keys are reported as errors instead of producing '<no value>'.
+func (d *Document) ReplaceTemplated(key, tmpl string, data interface{}) error {
+	value, err := executeTemplate(key, tmpl, data)
+	if err != nil {
+		return err
+	}
+	return d.Replace(key, value)
+}
+
+// executeTemplate executes the given template and returns the escaped result.
+func executeTemplate(name, tmpl string, data interface{}) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse template of %s: %s", name, err)
+	}
+	var result bytes.Buffer
+	if err := t.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("unable to execute template of %s: %s", name, err)
+	}
+	return html.EscapeString(result.String()), nil
+}
@@ -0,0 +1,63 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceTemplated(t *testing.T) {
+	data := map[string]interface{}{
+		"Items": []string{"Books & more", "<b>Pens</b>"},
+		"Total": 12.5,
+	}
+
+	tests := []struct {
+		name     string
+		tmpl     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "computed text",
+			tmpl:     `{{len .Items}} items, total {{printf "%.2f" .Total}}`,
+			expected: `<w:t>2 items, total 12.50</w:t>`,
+		},
+		{
+			name:     "escaping and line breaks",
+			tmpl:     `{{range $i, $item := .Items}}{{if $i}}{{"\n"}}{{end}}{{$item}}{{end}}`,
+			expected: `<w:t>Books &amp; more</w:t><w:br/><w:t>&lt;b&gt;Pens&lt;/b&gt;</w:t>`,
+		},
+		{
+			name:    "missing key",
+			tmpl:    `{{.Customer}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid template",
+			tmpl:    `{{.Total`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{
+				DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{summary}</w:t></w:r></w:p>`),
+			})
+
+			err := doc.ReplaceTemplated("summary", tt.tmpl, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, tt.expected) {
+				t.Errorf("unexpected document\nwant=%s\nhave=%s", tt.expected, result)
+			}
+			if err := checkWellFormed(doc.GetFile(DocumentXml)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
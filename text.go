@@ -0,0 +1,213 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// TextOptions configure the text extraction of PlainTextWith.
+type TextOptions struct {
+	// NumberingLabels prefixes the paragraphs of numbered lists with their label (see Paragraph.NumberingLabel),
+	// separated from the text as defined by the list, usually by a tab.
+	NumberingLabels bool
+}
+
+// PlainText returns the text of the main document. Paragraphs and line breaks end with a newline, tabs are
+// represented by '\t'. Formatting and run fragmentation are ignored, deleted text and the fallback content of
+// alternate content (e.g. VML text boxes duplicating DrawingML ones) are excluded.
+func (d *Document) PlainText() (string, error) {
+	return d.PlainTextWith(TextOptions{})
+}
+
+// PlainTextWith returns the text of the main document like PlainText, using the given options.
+func (d *Document) PlainTextWith(options TextOptions) (string, error) {
+	text, _, err := d.plainText(options, -1, 0)
+	return text, err
+}
+
+// PlainTextPreview returns at most the first maxChars characters (runes) of the text of the main document, as
+// returned by PlainText. The document is only scanned until enough characters are collected, which makes it cheap
+// for snippets of large documents. The text is never cut inside a character. An empty string is returned if
+// maxChars is not positive or the scanned part of the document cannot be parsed.
+func (d *Document) PlainTextPreview(maxChars int) string {
+	if maxChars <= 0 {
+		return ""
+	}
+	text, _, err := d.plainText(TextOptions{}, -1, maxChars)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// SectionText returns the text of a single section of the main document like PlainText. The sections are indexed
+// from zero in document order: a section ends with every paragraph whose properties contain section properties
+// (<w:pPr><w:sectPr>), the last section ends with the section properties of the body.
+// An error is returned if the document has no section with the given index, see SectionCount.
+func (d *Document) SectionText(index int) (string, error) {
+	text, count, err := d.plainText(TextOptions{}, index, 0)
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= count {
+		return "", fmt.Errorf("invalid section index %d, the document has %d sections", index, count)
+	}
+	return text, nil
+}
+
+// SectionCount returns the amount of sections of the main document, see SectionText.
+func (d *Document) SectionCount() (int, error) {
+	_, count, err := d.plainText(TextOptions{}, -1, 0)
+	return count, err
+}
+
+// plainText returns the text of the given section of the main document, or of all sections if the section is
+// negative, together with the amount of sections. If limit is positive, the scan stops as soon as the text
+// contains limit runes, the amount of sections is then the amount found so far.
+func (d *Document) plainText(options TextOptions, section, limit int) (string, int, error) {
+	var labels []paragraphLabel
+	if options.NumberingLabels {
+		var err error
+		if labels, err = d.numberingLabels(); err != nil {
+			return "", 0, err
+		}
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(d.GetFile(DocumentXml)))
+	var text strings.Builder
+	inText := false
+	fallbackDepth := 0
+	paragraph := -1
+
+	// the index of the current section, and whether the current paragraph ends it
+	current := 0
+	paragraphDepth := 0
+	endsSection := false
+	bodySection := false
+	active := func() bool { return section < 0 || current == section }
+
+	// write adds s to the text and returns true once the limit is reached
+	runes := 0
+	write := func(s string) bool {
+		if limit <= 0 {
+			text.WriteString(s)
+			return false
+		}
+		for _, r := range s {
+			if runes == limit {
+				return true
+			}
+			text.WriteRune(r)
+			runes++
+		}
+		return runes == limit
+	}
+
+	full := false
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			// without section properties of the body, the content after the last section is a section of its own
+			if !bodySection {
+				current++
+			}
+			return text.String(), current, nil
+		}
+		if err != nil {
+			return "", 0, fmt.Errorf("unable to parse %s: %s", DocumentXml, err)
+		}
+
+		switch elem := tok.(type) {
+		case xml.StartElement:
+			switch elem.Name.Local {
+			case ParagraphElementName:
+				paragraph++
+				paragraphDepth++
+			case SectionPropertiesElementName:
+				// section properties of a paragraph end the section with the paragraph, those of the body directly
+				if paragraphDepth > 0 {
+					endsSection = true
+				} else {
+					bodySection = true
+					current++
+				}
+			}
+			if elem.Name.Local == "Fallback" || fallbackDepth > 0 {
+				fallbackDepth++
+				continue
+			}
+			if !active() {
+				continue
+			}
+			switch elem.Name.Local {
+			case ParagraphElementName:
+				if paragraph < len(labels) && labels[paragraph].text != "" {
+					full = write(labels[paragraph].text + labels[paragraph].suffix)
+				}
+			case "t":
+				inText = true
+			case "tab":
+				full = write("\t")
+			case "br", "cr":
+				full = write("\n")
+			}
+		case xml.EndElement:
+			if elem.Name.Local == ParagraphElementName {
+				paragraphDepth--
+			}
+			if fallbackDepth > 0 {
+				fallbackDepth--
+				continue
+			}
+			switch elem.Name.Local {
+			case "t":
+				inText = false
+			case ParagraphElementName:
+				if active() {
+					full = write("\n")
+				}
+				if endsSection && paragraphDepth == 0 {
+					endsSection = false
+					current++
+				}
+			}
+		case xml.CharData:
+			if inText && fallbackDepth == 0 && active() {
+				full = write(string(elem))
+			}
+		}
+		if full {
+			return text.String(), current, nil
+		}
+	}
+}
+
+// NormalizeText normalizes text for comparison: all sequences of whitespace (including newlines and tabs)
+// are collapsed into a single space and leading and trailing whitespace is removed. The case is preserved.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// TextFingerprint returns the hex encoded SHA-256 hash of the normalized plain text of the document
+// (see PlainText and NormalizeText). Documents with the same text have the same fingerprint, regardless of
+// their formatting or how the text is split into runs.
+func (d *Document) TextFingerprint() (string, error) {
+	return d.TextFingerprintWith(sha256.New)
+}
+
+// TextFingerprintWith returns the hex encoded hash of the normalized plain text using the given hash algorithm.
+func (d *Document) TextFingerprintWith(newHash func() hash.Hash) (string, error) {
+	text, err := d.PlainText()
+	if err != nil {
+		return "", err
+	}
+	h := newHash()
+	h.Write([]byte(NormalizeText(text)))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,135 @@
+package docx
+
+import (
+	"crypto/md5"
+	"testing"
+)
+
+func TestDocument_PlainText(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Hello</w:t><w:tab/><w:t>W</w:t></w:r><w:r><w:t>orld</w:t><w:br/><w:t>&amp; more</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:delText>deleted</w:delText></w:r><w:r><mc:AlternateContent><mc:Choice Requires="wps"><w:txbxContent><w:p><w:r><w:t>box</w:t></w:r></w:p></w:txbxContent></mc:Choice>` +
+			`<mc:Fallback><w:txbxContent><w:p><w:r><w:t>box</w:t></w:r></w:p></w:txbxContent></mc:Fallback></mc:AlternateContent></w:r></w:p>`),
+	})
+
+	text, err := doc.PlainText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Hello\tWorld\n& more\nbox\n\n"; text != expected {
+		t.Errorf("unexpected text, want=%q, have=%q", expected, text)
+	}
+}
+
+func TestDocument_TextFingerprint(t *testing.T) {
+	fingerprint := func(body string) string {
+		f, err := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}).TextFingerprint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	original := fingerprint(`<w:p><w:r><w:t>Hello World</w:t></w:r></w:p>`)
+	fragmented := fingerprint(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">Hello  </w:t></w:r><w:r><w:t>Wor</w:t></w:r><w:r><w:t>ld</w:t></w:r></w:p><w:p/>`)
+	different := fingerprint(`<w:p><w:r><w:t>Hello world</w:t></w:r></w:p>`)
+
+	if original != fragmented {
+		t.Error("documents with the same text must have the same fingerprint")
+	}
+	if original == different {
+		t.Error("documents with different text must have different fingerprints")
+	}
+	if len(original) != 64 {
+		t.Errorf("expected a hex encoded SHA-256 hash, have=%s", original)
+	}
+
+	md5Fingerprint, err := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(`<w:p/>`)}).TextFingerprintWith(md5.New)
+	if err != nil || len(md5Fingerprint) != 32 {
+		t.Errorf("unexpected fingerprint %s: %v", md5Fingerprint, err)
+	}
+}
+
+func TestDocument_SectionText(t *testing.T) {
+	sectPr := `<w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name: "paragraph and body sections",
+			body: `<w:p><w:r><w:t>Cover</w:t></w:r></w:p><w:p><w:pPr>` + sectPr + `</w:pPr><w:r><w:t>End of cover</w:t></w:r></w:p>` +
+				`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+				`<w:p><w:pPr>` + sectPr + `</w:pPr></w:p><w:p><w:r><w:t>Appendix</w:t></w:r></w:p>` + sectPr,
+			expected: []string{"Cover\nEnd of cover\n", "Cell\n\n", "Appendix\n"},
+		},
+		{
+			name:     "single section",
+			body:     `<w:p><w:r><w:t>Text</w:t></w:r></w:p>` + sectPr,
+			expected: []string{"Text\n"},
+		},
+		{
+			name:     "no body section properties",
+			body:     `<w:p><w:pPr>` + sectPr + `</w:pPr><w:r><w:t>One</w:t></w:r></w:p><w:p><w:r><w:t>Two</w:t></w:r></w:p>`,
+			expected: []string{"One\n", "Two\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)})
+			count, err := doc.SectionCount()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.expected) {
+				t.Fatalf("expected %d sections, got %d", len(tt.expected), count)
+			}
+			for i, expected := range tt.expected {
+				text, err := doc.SectionText(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if text != expected {
+					t.Errorf("section %d: unexpected text %q, expected %q", i, text, expected)
+				}
+			}
+			if _, err := doc.SectionText(count); err == nil {
+				t.Error("expected an error for an invalid section index")
+			}
+		})
+	}
+}
+
+func TestDocument_PlainTextPreview(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>Сәлем</w:t><w:tab/><w:t>world</w:t></w:r></w:p><w:p><w:r><w:t>日本語</w:t></w:r></w:p>`),
+	})
+	tests := []struct {
+		maxChars int
+		expected string
+	}{
+		{0, ""},
+		{3, "Сәл"},
+		{6, "Сәлем\t"},
+		{13, "Сәлем\tworld\n日"},
+		{100, "Сәлем\tworld\n日本語\n"},
+	}
+	for _, tt := range tests {
+		if preview := doc.PlainTextPreview(tt.maxChars); preview != tt.expected {
+			t.Errorf("%d characters: want=%q, have=%q", tt.maxChars, tt.expected, preview)
+		}
+	}
+
+	// the scan stops before the invalid markup at the end
+	data := append(append([]byte(nil), doc.GetFile(DocumentXml)...), "<w:p></w:r>"...)
+	if err := doc.SetFile(DocumentXml, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := doc.PlainText(); err == nil {
+		t.Fatal("expected an error for the invalid markup")
+	}
+	if preview := doc.PlainTextPreview(5); preview != "Сәлем" {
+		t.Errorf("unexpected preview %q", preview)
+	}
+}
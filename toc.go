@@ -0,0 +1,271 @@
+package docx
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// UpdateFieldsTagRegex matches the setting which makes Word update all fields when the document is opened
+	UpdateFieldsTagRegex = regexp.MustCompile(`<w:updateFields(?:\s[^>]*)?/>`)
+
+	// tocStyleNameRegex matches the name of the style of a table of contents entry, the group contains the level
+	tocStyleNameRegex = regexp.MustCompile(`(?i)^toc\s*([1-9])$`)
+	// updateFieldsFollowers are the document settings which must occur after <w:updateFields> according to the
+	// schema, besides the followers of <w:compat>
+	updateFieldsFollowers = regexp.MustCompile(`<(?:w:hdrShapeDefaults|w:footnotePr|w:endnotePr|w:compat)[\s/>]`)
+)
+
+// TableOfContentsEntry is an entry of the cached result of a table of contents, as returned by TableOfContents.
+type TableOfContentsEntry struct {
+	// Level is the level of the entry, taken from its style ('toc 1'). It is 0 if the style is unknown.
+	Level int
+	// Text is the title of the entry, without its page number.
+	Text string
+	// Anchor is the name of the bookmark of the heading which the entry refers to, e.g. '_Toc123'.
+	Anchor string
+	// Page is the cached page number of the entry.
+	Page string
+}
+
+// tocEntry is an entry of a table of contents together with the runs which contain its title.
+type tocEntry struct {
+	TableOfContentsEntry
+	titleRuns []*Run
+}
+
+// tocField is a table of contents field of the main document.
+type tocField struct {
+	// dirty is the position of the tag which carries the w:dirty attribute of the field
+	dirty   Position
+	entries []tocEntry
+}
+
+// TableOfContents returns the entries of all tables of contents (TOC fields) of the main document, as they were
+// last generated by Word.
+func (d *Document) TableOfContents() ([]TableOfContentsEntry, error) {
+	fields, err := d.tocFields()
+	if err != nil {
+		return nil, err
+	}
+	var entries []TableOfContentsEntry
+	for _, f := range fields {
+		for _, entry := range f.entries {
+			entries = append(entries, entry.TableOfContentsEntry)
+		}
+	}
+	return entries, nil
+}
+
+// UpdateTableOfContents brings the tables of contents of the main document in line with the current headings, e.g.
+// after headings were changed by replacing placeholders. The title of every entry is replaced with the text of the
+// heading it refers to, the first run of the title gets the new text and the other runs of the title are emptied.
+// Page numbers cannot be computed, therefore all TOC fields are marked as dirty as well (see
+// MarkTableOfContentsDirty). The amount of changed entries is returned.
+func (d *Document) UpdateTableOfContents() (int, error) {
+	return d.updateTableOfContents(true)
+}
+
+// MarkTableOfContentsDirty marks all tables of contents (TOC fields) of the main document as dirty, so that Word
+// updates them when the document is opened. Word asks the user before updating. The amount of marked fields is
+// returned.
+func (d *Document) MarkTableOfContentsDirty() (int, error) {
+	return d.updateTableOfContents(false)
+}
+
+// updateTableOfContents marks all TOC fields as dirty and refreshes the titles of their entries if refresh is set.
+// The amount of changed entries is returned if refresh is set, the amount of fields otherwise.
+func (d *Document) updateTableOfContents(refresh bool) (int, error) {
+	fields, err := d.tocFields()
+	if err != nil || len(fields) == 0 {
+		return 0, err
+	}
+	data := d.GetFile(DocumentXml)
+	bookmarks := bookmarksByName(FindBookmarks(data))
+
+	var edits []edit
+	updated := 0
+	for _, f := range fields {
+		tag := string(data[f.dirty.Start:f.dirty.End])
+		edits = append(edits, edit{Position: f.dirty, Replacement: []byte(setAttribute(tag, "w:dirty", "true"))})
+		if !refresh {
+			continue
+		}
+		for _, entry := range f.entries {
+			bookmark, exists := bookmarks[entry.Anchor]
+			if !exists || len(entry.titleRuns) == 0 {
+				continue
+			}
+			content := bookmark.Content()
+			title := strings.TrimSpace(formText(data[content.Start:content.End]))
+			if title == "" || title == strings.TrimSpace(entry.Text) {
+				continue
+			}
+			for i, run := range entry.titleRuns {
+				text := ""
+				if i == 0 {
+					text = title
+				}
+				openTag := setAttribute(string(data[run.Text.OpenTag.Start:run.Text.OpenTag.End]), "xml:space", "preserve")
+				edits = append(edits, edit{
+					Position:    Position{Start: run.Text.OpenTag.Start, End: run.Text.CloseTag.Start},
+					Replacement: []byte(openTag + xmlEscape(text)),
+				})
+			}
+			updated++
+		}
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].Position.Start < edits[j].Position.Start })
+	if err := d.SetFile(DocumentXml, applyEdits(data, edits)); err != nil {
+		return 0, err
+	}
+	if err := d.parseFile(DocumentXml); err != nil {
+		return 0, err
+	}
+	if !refresh {
+		return len(fields), nil
+	}
+	return updated, nil
+}
+
+// SetUpdateFieldsOnOpen enables or disables the setting which makes Word update all fields of the document, including
+// tables of contents and page references, when the document is opened. Word asks the user before updating.
+// If the document has no settings and the setting is enabled, settings are added.
+func (d *Document) SetUpdateFieldsOnOpen(update bool) error {
+	if !update && !d.partExists(SettingsXml) {
+		return nil
+	}
+	settings, err := d.settings()
+	if err != nil {
+		return err
+	}
+	replacement := ""
+	if update {
+		replacement = `<w:updateFields w:val="true"/>`
+	}
+
+	var pos Position
+	if loc := UpdateFieldsTagRegex.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[1])}
+	} else if !update {
+		return nil
+	} else if loc := updateFieldsFollowers.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[0])}
+	} else if loc := compatFollowers.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[0])}
+	} else if loc := SettingsCloseTagRegex.FindIndex(settings); loc != nil {
+		pos = Position{Start: int64(loc[0]), End: int64(loc[0])}
+	} else {
+		return fmt.Errorf("invalid settings part %s", SettingsXml)
+	}
+	return d.setPart(SettingsXml, applyEdits(settings, []edit{{Position: pos, Replacement: []byte(replacement)}}))
+}
+
+// tocFields returns all TOC fields of the main document with the entries of their cached results.
+func (d *Document) tocFields() ([]tocField, error) {
+	data := d.GetFile(DocumentXml)
+	allFields := fields(data)
+	styles, err := d.styleDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []tocField
+	for _, f := range allFields {
+		arguments := strings.Fields(f.instruction)
+		if len(arguments) == 0 || strings.ToUpper(arguments[0]) != "TOC" {
+			continue
+		}
+		toc := tocField{dirty: f.elements[0]}
+		start, end, ok := fieldResult(data, f)
+		if !ok {
+			result = append(result, toc)
+			continue
+		}
+		for _, loc := range ParagraphRegex.FindAllIndex(data, -1) {
+			if int64(loc[1]) <= start || int64(loc[0]) >= end {
+				continue
+			}
+			segment := Position{Start: maxInt64(int64(loc[0]), start), End: int64(loc[1])}
+			if segment.End > end {
+				segment.End = end
+			}
+			if entry, ok := d.tocEntry(data, data[loc[0]:loc[1]], segment, allFields, styles); ok {
+				toc.entries = append(toc.entries, entry)
+			}
+		}
+		result = append(result, toc)
+	}
+	return result, nil
+}
+
+// tocEntry returns the entry of the table of contents inside the segment of the paragraph. False is returned if the
+// segment contains no entry.
+func (d *Document) tocEntry(data, paragraph []byte, segment Position, allFields []field, styles styleDefinitions) (tocEntry, bool) {
+	var entry tocEntry
+	titleEnd := segment.End
+	for _, f := range allFields {
+		arguments := strings.Fields(f.instruction)
+		if int64(f.start) < segment.Start || int64(f.start) >= segment.End || len(arguments) < 2 || strings.ToUpper(arguments[0]) != "PAGEREF" {
+			continue
+		}
+		entry.Anchor = arguments[1]
+		entry.Page = f.text
+		titleEnd = int64(f.start)
+		break
+	}
+	if entry.Anchor == "" {
+		for _, tag := range HyperlinkOpenTagRegex.FindAll(data[segment.Start:segment.End], -1) {
+			if anchor, ok := attributeValue(tag, "w:anchor"); ok {
+				entry.Anchor = html.UnescapeString(anchor)
+				break
+			}
+		}
+	}
+
+	for _, run := range d.runParsers[DocumentXml].Runs() {
+		if !run.HasText || run.Text.OpenTag.Start < segment.Start || run.Text.OpenTag.Start >= titleEnd {
+			continue
+		}
+		// field instructions are text of their runs if they are replaced as well
+		if bytes.HasPrefix(data[run.Text.OpenTag.Start:], []byte("<w:instrText")) {
+			continue
+		}
+		entry.titleRuns = append(entry.titleRuns, run)
+		entry.Text += html.UnescapeString(run.GetText(data))
+	}
+	if entry.Anchor == "" && len(entry.titleRuns) == 0 {
+		return tocEntry{}, false
+	}
+
+	if tag := ParagraphStyleTagRegex.Find(paragraph); tag != nil {
+		styleID, _ := attributeValue(tag, "w:val")
+		name := styleID
+		if style := styles.style(styleID); style != nil && style.Name.Val != "" {
+			name = style.Name.Val
+		}
+		if match := tocStyleNameRegex.FindStringSubmatch(name); match != nil {
+			entry.Level, _ = strconv.Atoi(match[1])
+		}
+	}
+	return entry, true
+}
+
+// fieldResult returns the position of the cached result of the field. False is returned if it has no result.
+func fieldResult(data []byte, f field) (int64, int64, bool) {
+	first, last := f.elements[0], f.elements[len(f.elements)-1]
+	if f.simple {
+		return first.End, last.Start, last != first
+	}
+	for _, element := range f.elements {
+		if fieldType, _ := attributeValue(data[element.Start:element.End], "w:fldCharType"); fieldType == "separate" {
+			return element.End, last.Start, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,110 @@
+package docx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTocEntry returns the markup of a hyperlinked entry of a table of contents as generated by Word.
+func testTocEntry(anchor, title, page string) string {
+	return `<w:hyperlink w:anchor="` + anchor + `" w:history="1"><w:r><w:t>` + title + `</w:t></w:r><w:r><w:tab/></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> PAGEREF ` + anchor + ` \h </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>` + page + `</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:hyperlink>`
+}
+
+// testTableOfContents returns a table of contents of two headings, the title of the first entry and heading are given.
+func testTableOfContents(entry, heading string) string {
+	return `<w:p><w:pPr><w:pStyle w:val="TOC1"/></w:pPr><w:r><w:fldChar w:fldCharType="begin"/></w:r>` +
+		`<w:r><w:instrText xml:space="preserve"> TOC \o "1-3" \h \z \u </w:instrText></w:r><w:r><w:fldChar w:fldCharType="separate"/></w:r>` +
+		testTocEntry("_Toc1", entry, "1") + `</w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="12"/></w:pPr>` + testTocEntry("_Toc2", "Payment", "2") + `</w:p>` +
+		`<w:p><w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:bookmarkStart w:id="1" w:name="_Toc1"/><w:r><w:t>` + heading + `</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:bookmarkStart w:id="2" w:name="_Toc2"/><w:r><w:t>Pay</w:t></w:r><w:r><w:t>ment</w:t></w:r><w:bookmarkEnd w:id="2"/></w:p>`
+}
+
+func TestDocument_TableOfContents(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(testTableOfContents("Scope", "Scope")),
+		StylesXml:   testStylesXml(`<w:style w:type="paragraph" w:styleId="12"><w:name w:val="toc 2"/></w:style>`),
+	})
+	entries, err := doc.TableOfContents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TableOfContentsEntry{
+		{Level: 1, Text: "Scope", Anchor: "_Toc1", Page: "1"},
+		{Level: 2, Text: "Payment", Anchor: "_Toc2", Page: "2"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+}
+
+func TestDocument_UpdateTableOfContents(t *testing.T) {
+	// the heading was changed after the table of contents was generated
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(testTableOfContents("Scope", "{scope}"))})
+	if err := doc.ReplaceAll(PlaceholderMap{"scope": "Scope &amp; terms"}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := doc.UpdateTableOfContents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 1 {
+		t.Errorf("expected 1 updated entry, got %d", updated)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{
+		`<w:fldChar w:fldCharType="begin" w:dirty="true"/></w:r><w:r><w:instrText xml:space="preserve"> TOC `,
+		`<w:hyperlink w:anchor="_Toc1" w:history="1"><w:r><w:t xml:space="preserve">Scope &amp; terms</w:t>`,
+		`<w:hyperlink w:anchor="_Toc2" w:history="1"><w:r><w:t>Payment</w:t>`,
+	} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+	if count := strings.Count(document, `w:dirty="true"`); count != 1 {
+		t.Errorf("expected only the TOC field to be dirty, got %d dirty fields", count)
+	}
+}
+
+func TestDocument_MarkTableOfContentsDirty(t *testing.T) {
+	body := `<w:p><w:fldSimple w:instr=" TOC \o &quot;1-3&quot; "><w:r><w:t>No entries</w:t></w:r></w:fldSimple></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	marked, err := doc.MarkTableOfContentsDirty()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<w:fldSimple w:instr=" TOC \o &quot;1-3&quot; " w:dirty="true">`
+	if document := string(doc.GetFile(DocumentXml)); marked != 1 || !strings.Contains(document, expected) {
+		t.Errorf("expected 1 marked field and %s in %s, got %d", expected, document, marked)
+	}
+}
+
+func TestDocument_SetUpdateFieldsOnOpen(t *testing.T) {
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p/>`),
+		SettingsXml: `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:zoom w:percent="100"/><w:compat/><w:rsids/></w:settings>`,
+	})
+	if err := doc.SetUpdateFieldsOnOpen(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.SetUpdateFieldsOnOpen(true); err != nil {
+		t.Fatal(err)
+	}
+	settings, _ := doc.getPart(SettingsXml)
+	if expected := `<w:zoom w:percent="100"/><w:updateFields w:val="true"/><w:compat/>`; !strings.Contains(string(settings), expected) {
+		t.Errorf("expected %s in %s", expected, settings)
+	}
+
+	if err := doc.SetUpdateFieldsOnOpen(false); err != nil {
+		t.Fatal(err)
+	}
+	if settings, _ := doc.getPart(SettingsXml); strings.Contains(string(settings), "updateFields") {
+		t.Errorf("the setting was not removed: %s", settings)
+	}
+}
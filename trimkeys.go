@@ -0,0 +1,127 @@
+package docx
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithKeyTrimming configures whether leading and trailing whitespace of placeholder keys is ignored. By default (true)
+// '{ name }' is the placeholder '{name}': the whitespace inside the delimiters is removed when the part is parsed,
+// so the delimiters and the spacing are replaced as a unit and the key matches 'name' of the PlaceholderMap.
+// If disabled, the key of '{ name }' is ' name '.
+//
+// Trimming is no modification: parts are written as they are (and not returned by ChangedParts) until they are
+// modified, e.g. by a replacement. Once a part is modified, its unreplaced placeholders are written without the
+// whitespace.
+//
+// Whitespace which is the only text of a run is kept if WithEmptyRunsBreakPlaceholders is enabled, as removing it
+// would break the placeholder.
+func WithKeyTrimming(trim bool) Option {
+	return func(d *Document) {
+		d.keepKeyWhitespace = !trim
+	}
+}
+
+// untrimmedPart is a parsed part before its placeholder keys were trimmed.
+type untrimmedPart struct {
+	data []byte
+	runs DocumentRuns
+}
+
+// keepUntrimmed records the data and the runs of the part before its placeholder keys are trimmed, they are written
+// until the part is modified. Only the parts as they were opened are recorded: the keys of a modified part, e.g. of
+// an edit which inserted '{ name }', are trimmed as part of the modification.
+func (d *Document) keepUntrimmed(name string, data []byte, runs DocumentRuns) {
+	if d.modifiedFiles[name] {
+		return
+	}
+	if d.untrimmedParts == nil {
+		d.untrimmedParts = make(map[string]untrimmedPart)
+	}
+	d.untrimmedParts[name] = untrimmedPart{data: data, runs: runs}
+}
+
+// trimKeyEdits returns the edits which remove the whitespace around the keys of the placeholders.
+func (d *Document) trimKeyEdits(data []byte, placeholders []*Placeholder) []edit {
+	if d.keepKeyWhitespace {
+		return nil
+	}
+	var edits []edit
+	for _, placeholder := range placeholders {
+		text := placeholder.Text(data)
+		delimiters, ok := d.placeholderDelimiters(text)
+		if !ok {
+			continue
+		}
+		start, end := len(delimiters.Open), len(text)-len(delimiters.Close)
+		keyStart, keyEnd := start, end
+		for keyStart < keyEnd {
+			r, size := utf8.DecodeRuneInString(text[keyStart:keyEnd])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			keyStart += size
+		}
+		for keyEnd > keyStart {
+			r, size := utf8.DecodeLastRuneInString(text[keyStart:keyEnd])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			keyEnd -= size
+		}
+		// placeholders without a key are kept as they are
+		if keyStart == keyEnd || (keyStart == start && keyEnd == end) {
+			continue
+		}
+		placeholderEdits, ok := d.removeText(placeholder, [][2]int{{start, keyStart}, {keyEnd, end}})
+		if ok {
+			edits = append(edits, placeholderEdits...)
+		}
+	}
+	return edits
+}
+
+// placeholderDelimiters returns the delimiters of the placeholder text, preferring the longest opening delimiter.
+func (d *Document) placeholderDelimiters(text string) (Delimiters, bool) {
+	var (
+		found  Delimiters
+		exists bool
+	)
+	for _, delimiters := range d.allDelimiters() {
+		if delimiters.Delimits(text) && (!exists || len(delimiters.Open) > len(found.Open)) {
+			found, exists = delimiters, true
+		}
+	}
+	return found, exists
+}
+
+// removeText returns the edits which remove the given ranges of the placeholder text from the fragments.
+// False is returned if a fragment would lose all its text while empty runs break placeholders.
+func (d *Document) removeText(placeholder *Placeholder, ranges [][2]int) ([]edit, bool) {
+	var edits []edit
+	offset := 0
+	for _, fragment := range placeholder.Fragments {
+		length := int(fragment.Position.End - fragment.Position.Start)
+		removed := 0
+		for _, r := range ranges {
+			start, end := r[0], r[1]
+			if start < offset {
+				start = offset
+			}
+			if end > offset+length {
+				end = offset + length
+			}
+			if start >= end {
+				continue
+			}
+			removed += end - start
+			base := fragment.Run.Text.OpenTag.End + fragment.Position.Start - int64(offset)
+			edits = append(edits, edit{Position: Position{Start: base + int64(start), End: base + int64(end)}})
+		}
+		if removed == length && d.emptyRunsBreakPlaceholders {
+			return nil, false
+		}
+		offset += length
+	}
+	return edits, true
+}
@@ -0,0 +1,138 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+// placeholderKeys returns the keys of the placeholders of the document in document order.
+func placeholderKeys(t *testing.T, doc *Document) []string {
+	locations, err := doc.PlaceholderLocations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, location := range locations {
+		keys = append(keys, location.Key)
+	}
+	return keys
+}
+
+func TestWithKeyTrimming(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		options  []Option
+		expected string
+	}{
+		{"unspaced", `<w:p><w:r><w:t>Dear {name},</w:t></w:r></w:p>`, nil,
+			`<w:t>Dear Jane,</w:t>`},
+		{"spaced", `<w:p><w:r><w:t xml:space="preserve">Dear { name },</w:t></w:r></w:p>`, nil,
+			`<w:t xml:space="preserve">Dear Jane,</w:t>`},
+		{"tabs and several spaces", "<w:p><w:r><w:t xml:space=\"preserve\">Dear {  name\t},</w:t></w:r></w:p>", nil,
+			`<w:t xml:space="preserve">Dear Jane,</w:t>`},
+		{"split into runs", `<w:p><w:r><w:t>Dear {</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r>` +
+			`<w:r><w:t xml:space="preserve">name }</w:t></w:r><w:r><w:t>,</w:t></w:r></w:p>`, nil,
+			`<w:t>Dear Jane</w:t></w:r><w:r><w:t xml:space="preserve"></w:t></w:r><w:r><w:t xml:space="preserve"></w:t></w:r><w:r><w:t>,</w:t>`},
+		{"additional delimiters", `<w:p><w:r><w:t xml:space="preserve">Dear ${ name },</w:t></w:r></w:p>`,
+			[]Option{WithDelimiters(Delimiters{Open: "${", Close: "}"})}, `<w:t xml:space="preserve">Dear Jane,</w:t>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(tt.body)}), tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := reopen(t, doc).getPart(DocumentXml); !strings.Contains(string(data), tt.expected) {
+				t.Errorf("expected %s in %s", tt.expected, data)
+			}
+		})
+	}
+
+	body := testDocumentXml(`<w:p><w:r><w:t xml:space="preserve">{ name } and { }</w:t></w:r></w:p>`)
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: body}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := placeholderKeys(t, doc); strings.Join(keys, ",") != "name, " {
+		t.Errorf("expected the trimmed key and the placeholder without key, got %q", keys)
+	}
+
+	doc, err = OpenBytes(newTestDocx(t, map[string]string{DocumentXml: body}), WithKeyTrimming(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := placeholderKeys(t, doc); strings.Join(keys, ",") != " name , " {
+		t.Errorf("expected the keys to keep their whitespace, got %q", keys)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{" name ": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := reopen(t, doc).getPart(DocumentXml); !strings.Contains(string(data), `>Jane and { }<`) {
+		t.Errorf("expected the spaced key to be replaced, got %s", data)
+	}
+
+	// whitespace which is the only text of a run is kept if empty runs break placeholders
+	split := testDocumentXml(`<w:p><w:r><w:t>{</w:t></w:r><w:r><w:t xml:space="preserve"> </w:t></w:r><w:r><w:t>name}</w:t></w:r></w:p>`)
+	doc, err = OpenBytes(newTestDocx(t, map[string]string{DocumentXml: split}), WithEmptyRunsBreakPlaceholders(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := placeholderKeys(t, doc); strings.Join(keys, ",") != " name" {
+		t.Errorf("expected the placeholder to be kept, got %q", keys)
+	}
+}
+
+func TestWithKeyTrimming_Unmodified(t *testing.T) {
+	body := testDocumentXml(`<w:p><w:r><w:t xml:space="preserve">Dear { name }, \{ literal \} { other }</w:t></w:r></w:p>`)
+	footer := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:r><w:t xml:space="preserve">{ page }</w:t></w:r></w:p></w:ftr>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: body, "word/footer1.xml": footer}), WithDelimiterEscape(`\`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := func(name string) string {
+		part, _ := doc.Snapshot().Part(name)
+		data, err := part.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// trimming the keys is no modification, the parts are written as they are
+	if changes := doc.ChangedParts(); len(changes) != 0 {
+		t.Errorf("expected no changed parts after opening, got %v", changes)
+	}
+	if data := written(DocumentXml); !strings.Contains(data, `>Dear { name }, { literal } { other }<`) {
+		t.Errorf("expected the document to be written as it is, got %s", data)
+	}
+	if data := written("word/footer1.xml"); data != footer {
+		t.Errorf("expected the footer to be written as it is, got %s", data)
+	}
+
+	if err := doc.Replace("name", "Jane"); err != nil {
+		t.Fatal(err)
+	}
+	changes := doc.ChangedParts()
+	// the unreplaced placeholders of a modified part are written trimmed
+	if len(changes) != 1 || !strings.Contains(string(changes[DocumentXml]), `>Dear Jane, { literal } {other}<`) {
+		t.Errorf("expected only the replaced document to be changed, got %q", changes)
+	}
+	if data := written("word/footer1.xml"); data != footer {
+		t.Errorf("expected the unmodified footer to be written as it is, got %s", data)
+	}
+
+	// the keys inserted by a modification are trimmed as well
+	data := doc.GetFile("word/footer1.xml")
+	end := int64(strings.Index(string(data), "</w:t>"))
+	if err := doc.ApplyEdits("word/footer1.xml", []Edit{{Position: Position{Start: end, End: end}, Replacement: []byte(" of { pages }")}}); err != nil {
+		t.Fatal(err)
+	}
+	if data := written("word/footer1.xml"); !strings.Contains(data, `>{page} of {pages}<`) {
+		t.Errorf("expected the modified footer to be written trimmed, got %s", data)
+	}
+}
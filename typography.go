@@ -0,0 +1,172 @@
+package docx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NoBreakSpace is the non-breaking space (U+00A0), a space at which Word never wraps the line.
+const NoBreakSpace = "\u00a0"
+
+var (
+	// NumberUnitRegex matches the space between a number and a following unit, currency or percent sign, e.g. '10 кг' or '500 ₸'
+	NumberUnitRegex = regexp.MustCompile(`\d( )(?:%|‰|°|кг|мг|г|т|км|см|мм|м|мл|л|ч|мин|сек|с|руб\.?|коп\.?|тг|тыс\.?|мың|млн|млрд|шт\.?|₽|₸|\$|€)(?:$|[^\p{L}])`)
+	// NumberSignRegex matches the space between a number sign or a section sign and the following number, e.g. '№ 5'
+	NumberSignRegex = regexp.MustCompile(`[№§]( )\d`)
+	// InitialsRegex matches the space after an initial which is followed by another initial or a surname, e.g. 'А. С. Пушкин'
+	InitialsRegex = regexp.MustCompile(`(?:^|[^\p{L}])\p{Lu}\.( )\p{Lu}`)
+	// SurnameInitialsRegex matches the space between a surname and the following initials, e.g. 'Пушкин А. С.'
+	SurnameInitialsRegex = regexp.MustCompile(`\p{Lu}\p{Ll}+( )\p{Lu}\.(?:$|[^\p{L}])`)
+	// RussianShortWordRegex matches the space after a short Russian preposition or conjunction, e.g. 'в доме'
+	RussianShortWordRegex = regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(?:а|без|в|во|для|до|за|и|из|к|ко|на|над|не|ни|о|об|от|по|под|при|про|с|со|у)( )[^\s]`)
+)
+
+// TypographyRule rewrites a text value according to a typographic rule and returns the new text together with
+// the number of substitutions.
+type TypographyRule interface {
+	Apply(text string) (string, int)
+}
+
+// TypographyRuleFunc adapts a function to a TypographyRule.
+type TypographyRuleFunc func(text string) (string, int)
+
+// Apply calls f.
+func (f TypographyRuleFunc) Apply(text string) (string, int) {
+	return f(text)
+}
+
+// NoBreakSpaceRule returns a rule which replaces the first capture group of every match of the regex by a
+// non-breaking space. The regex is applied until it no longer matches, so that adjacent matches like
+// 'в к дому' are all found.
+func NoBreakSpaceRule(regex *regexp.Regexp) TypographyRule {
+	return TypographyRuleFunc(func(text string) (string, int) {
+		substitutions := 0
+		for {
+			var result strings.Builder
+			last, replaced := 0, 0
+			for _, match := range regex.FindAllStringSubmatchIndex(text, -1) {
+				if len(match) < 4 || match[2] < 0 || text[match[2]:match[3]] == NoBreakSpace {
+					continue
+				}
+				result.WriteString(text[last:match[2]])
+				result.WriteString(NoBreakSpace)
+				last = match[3]
+				replaced++
+			}
+			if replaced == 0 {
+				return text, substitutions
+			}
+			result.WriteString(text[last:])
+			text = result.String()
+			substitutions += replaced
+		}
+	})
+}
+
+// TypographyRules are the rule sets used by WithTypography, by language.
+// Further languages can be added before documents using them are opened.
+var TypographyRules = map[string][]TypographyRule{
+	"ru": {
+		NoBreakSpaceRule(NumberUnitRegex),
+		NoBreakSpaceRule(NumberSignRegex),
+		NoBreakSpaceRule(InitialsRegex),
+		NoBreakSpaceRule(SurnameInitialsRegex),
+		NoBreakSpaceRule(RussianShortWordRegex),
+	},
+	"kk": {
+		NoBreakSpaceRule(NumberUnitRegex),
+		NoBreakSpaceRule(NumberSignRegex),
+		NoBreakSpaceRule(InitialsRegex),
+		NoBreakSpaceRule(SurnameInitialsRegex),
+	},
+}
+
+// WithTypography applies the typographic rules of the language to all text values before they are inserted,
+// e.g. to insert non-breaking spaces between numbers and units, between initials and surnames and after short
+// prepositions:
+//
+//	doc, err := docx.Open("template.docx", docx.WithTypography("ru"))
+//
+// The rules are taken from TypographyRules, additional rules are applied after them. Only the values are changed,
+// never the text of the template itself. Placeholders inside values are left as they are, as are markup values.
+// The number of substitutions is reported by TypographySubstitutions and in the ReplaceReport of Render.
+func WithTypography(language string, rules ...TypographyRule) Option {
+	return func(d *Document) {
+		d.typographyRules = append(append([]TypographyRule(nil), TypographyRules[language]...), rules...)
+	}
+}
+
+// TypographySubstitutions returns how many substitutions the typographic rules made in the value of every key,
+// summed up over all replacements so far. The keys are given without delimiters.
+func (d *Document) TypographySubstitutions() map[string]int {
+	substitutions := make(map[string]int, len(d.typographySubstitutions))
+	for key, count := range d.typographySubstitutions {
+		substitutions[key] = count
+	}
+	return substitutions
+}
+
+// applyTypography returns a copy of the map with the typographic rules applied to all text values and the number
+// of substitutions of every key, which is also added to the substitutions of the document.
+func (d *Document) applyTypography(placeholderMap PlaceholderMap) (PlaceholderMap, map[string]int) {
+	if len(d.typographyRules) == 0 || placeholderMap == nil {
+		return placeholderMap, nil
+	}
+	result := make(PlaceholderMap, len(placeholderMap))
+	counts := make(map[string]int)
+	for key, value := range placeholderMap {
+		text, count := d.typographyValue(value)
+		if count == 0 {
+			result[key] = value
+			continue
+		}
+		result[key] = text
+		counts[RemovePlaceholderDelimiter(key)] += count
+	}
+
+	if d.typographySubstitutions == nil {
+		d.typographySubstitutions = make(map[string]int)
+	}
+	for key, count := range counts {
+		d.typographySubstitutions[key] += count
+	}
+	return result, counts
+}
+
+// typographyValue applies the typographic rules to a text value, the text between the placeholders of the value
+// is rewritten piece by piece. Markup, i.e. values containing tags, is not changed.
+func (d *Document) typographyValue(value interface{}) (string, int) {
+	var text string
+	switch v := value.(type) {
+	case MarkupValue, BlockValue:
+		return "", 0
+	case string:
+		text = v
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		return "", 0
+	}
+	if strings.Contains(text, "<") {
+		return "", 0
+	}
+
+	var result strings.Builder
+	total, last := 0, 0
+	apply := func(piece string) {
+		for _, rule := range d.typographyRules {
+			var count int
+			piece, count = rule.Apply(piece)
+			total += count
+		}
+		result.WriteString(piece)
+	}
+	for _, ref := range d.nestedReferences(text) {
+		apply(text[last:ref.start])
+		result.WriteString(text[ref.start:ref.end])
+		last = ref.end
+	}
+	apply(text[last:])
+	return result.String(), total
+}
@@ -0,0 +1,95 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_typographyValue(t *testing.T) {
+	tests := []struct {
+		language      string
+		value         interface{}
+		expected      string
+		substitutions int
+	}{
+		{"ru", "Вес 10 кг, скидка 5 %", "Вес 10\u00a0кг, скидка 5\u00a0%", 2},
+		{"ru", "Договор № 15 от 1 мая", "Договор №\u00a015 от\u00a01 мая", 2},
+		{"ru", "А. С. Пушкин и Пушкин А. С.", "А.\u00a0С.\u00a0Пушкин и\u00a0Пушкин\u00a0А.\u00a0С.", 5},
+		{"ru", "В к дому, а не в сад", "В\u00a0к\u00a0дому, а\u00a0не\u00a0в\u00a0сад", 5},
+		{"ru", "СССР. Новый год", "СССР. Новый год", 0},
+		{"ru", "{в доме} и в {city} в доме", "{в доме} и\u00a0в {city} в\u00a0доме", 2},
+		{"kk", "Бағасы 500 ₸, в доме", "Бағасы 500\u00a0₸, в доме", 1},
+		{"ru", Currency(500, "₸"), "₸500.00", 0},
+		{"ru", CurrencyValue{Amount: 500, Symbol: "₸", SymbolAfter: true}, "500.00\u00a0₸", 1},
+		{"ru", "<w:br/>в доме", "", 0},
+		{"ru", 15, "", 0},
+	}
+	for _, tt := range tests {
+		doc := &Document{}
+		WithTypography(tt.language)(doc)
+		text, substitutions := doc.typographyValue(tt.value)
+		if substitutions != tt.substitutions || (substitutions > 0 && text != tt.expected) {
+			t.Errorf("%v: expected %q with %d substitutions, got %q with %d", tt.value, tt.expected, tt.substitutions, text, substitutions)
+		}
+	}
+}
+
+func TestDocument_ReplaceAll_Typography(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">Адрес в {address}: {street}, в доме</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithTypography("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"address": "г. Алматы", "street": "ул. Абая, дом 5 к 2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the text of the template keeps its regular spaces
+	expected := `<w:t xml:space="preserve">Адрес в г. Алматы: ул. Абая, дом 5 к` + NoBreakSpace + `2, в доме</w:t>`
+	if document := string(doc.GetFile(DocumentXml)); !strings.Contains(document, expected) {
+		t.Errorf("expected %s in %s", expected, document)
+	}
+	substitutions := doc.TypographySubstitutions()
+	if len(substitutions) != 1 || substitutions["street"] != 1 {
+		t.Errorf("unexpected substitutions %v", substitutions)
+	}
+}
+
+func TestDocument_Replace_Typography(t *testing.T) {
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{amount}</w:t></w:r></w:p>`),
+	}), WithTypography("kk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Replace("amount", "500 тг"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<w:t>500\u00a0тг</w:t>"; !strings.Contains(string(doc.GetFile(DocumentXml)), expected) {
+		t.Errorf("expected %s in %s", expected, doc.GetFile(DocumentXml))
+	}
+	if substitutions := doc.TypographySubstitutions(); substitutions["amount"] != 1 {
+		t.Errorf("unexpected substitutions %v", substitutions)
+	}
+}
+
+func TestRender_Typography(t *testing.T) {
+	body := `<w:p><w:r><w:t>{signature}</w:t></w:r></w:p><w:p><w:r><w:t>{total}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithTypography("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.render("contract", map[string]interface{}{"signature": "И. И. Иванов", "total": "1 200 руб. & 50 коп."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := string(doc.GetFile(DocumentXml))
+	for _, expected := range []string{"<w:t>И.\u00a0И.\u00a0Иванов</w:t>", "<w:t>1 200\u00a0руб. &amp; 50\u00a0коп.</w:t>"} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("expected %s in %s", expected, document)
+		}
+	}
+	if report.Typography["signature"] != 2 || report.Typography["total"] != 2 {
+		t.Errorf("unexpected substitutions %v", report.Typography)
+	}
+}
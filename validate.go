@@ -0,0 +1,64 @@
+package docx
+
+import (
+	"fmt"
+	"path"
+	"sort"
+)
+
+// Validator checks the document after all modifications were made, e.g. to enforce organizational rules.
+type Validator func(doc *Document) error
+
+// AddValidator registers a validator which is invoked by Finalize.
+// Validators are invoked in the order in which they were added.
+func (d *Document) AddValidator(validator Validator) {
+	d.validators = append(d.validators, validator)
+}
+
+// Validate performs the built-in validation of the document: all parsed files (document, headers, footers and notes)
+// as well as all modified or added XML parts must be well-formed, and all of their ignorable namespaces (mc:Ignorable)
+// must be declared. If WithRoundTripCheck is used, CheckRoundTrip is performed as well.
+func (d *Document) Validate() error {
+	var names []string
+	for name := range d.files {
+		names = append(names, name)
+	}
+	for name := range d.modifiedParts {
+		if ext := path.Ext(name); ext == ".xml" || ext == ".rels" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := d.getPart(name)
+		if err != nil {
+			return err
+		}
+		if err := checkWellFormed(data); err != nil {
+			return fmt.Errorf("%s is not well-formed: %w", name, err)
+		}
+		if err := checkIgnorableNamespaces(data); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if d.roundTripCheck {
+		return d.CheckRoundTrip()
+	}
+	return nil
+}
+
+// Finalize validates the document using Validate, followed by all validators added with AddValidator.
+// It should be called after all replacements and before the document is written.
+// The first failing validation aborts and its error is returned.
+func (d *Document) Finalize() error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	for i, validator := range d.validators {
+		if err := validator(d); err != nil {
+			return fmt.Errorf("validator %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDocument_Finalize(t *testing.T) {
+	errPlaceholderLeft := errors.New("placeholder left")
+	noPlaceholders := func(doc *Document) error {
+		if bytes.Contains(doc.GetFile(DocumentXml), []byte("{")) {
+			return errPlaceholderLeft
+		}
+		return nil
+	}
+
+	doc := openTestDocx(t, map[string]string{
+		DocumentXml: testDocumentXml(`<w:p><w:r><w:t>{name}</w:t></w:r></w:p>`),
+	})
+	var invoked []int
+	doc.AddValidator(func(*Document) error {
+		invoked = append(invoked, 1)
+		return nil
+	})
+	doc.AddValidator(noPlaceholders)
+
+	if err := doc.Finalize(); !errors.Is(err, errPlaceholderLeft) {
+		t.Errorf("expected the custom validator to fail, have=%v", err)
+	}
+	if err := doc.ReplaceAll(PlaceholderMap{"name": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Finalize(); err != nil {
+		t.Error(err)
+	}
+	if len(invoked) != 2 {
+		t.Errorf("validators were not invoked in every run, have=%d", len(invoked))
+	}
+
+	// the built-in validation runs first
+	if err := doc.SetFile(DocumentXml, []byte("<w:document>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Finalize(); err == nil {
+		t.Error("expected the built-in validation to fail")
+	}
+	if len(invoked) != 2 {
+		t.Error("validators must not run if the built-in validation fails")
+	}
+}
@@ -0,0 +1,154 @@
+package docx
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alignment is the horizontal alignment of a paragraph (<w:jc>).
+type Alignment string
+
+const (
+	// AlignLeft aligns the paragraph to the left
+	AlignLeft Alignment = "left"
+	// AlignCenter centers the paragraph
+	AlignCenter Alignment = "center"
+	// AlignRight aligns the paragraph to the right
+	AlignRight Alignment = "right"
+	// AlignJustify justifies the paragraph
+	AlignJustify Alignment = "both"
+)
+
+// DefaultDateLayout is the layout used by DateValue if no layout is given.
+const DefaultDateLayout = "2006-01-02"
+
+// AlignedValue is a replacement value which has a natural alignment, e.g. numbers are right-aligned.
+// The alignment is applied where the value controls a whole paragraph, e.g. table cells filled by ExpandTableRow.
+type AlignedValue interface {
+	DefaultAlignment() Alignment
+}
+
+// NumberFormat defines how numbers are formatted.
+type NumberFormat struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+}
+
+// DefaultNumberFormat formats numbers like '1,234.56'.
+var DefaultNumberFormat = NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ","}
+
+// Format returns the given value with the given amount of decimals.
+func (f NumberFormat) Format(value float64, decimals int) string {
+	if f == (NumberFormat{}) {
+		f = DefaultNumberFormat
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	formatted := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	integer, fraction := formatted, ""
+	if i := strings.Index(formatted, "."); i >= 0 {
+		integer, fraction = formatted[:i], formatted[i+1:]
+	}
+
+	var result strings.Builder
+	if value < 0 && strings.Trim(formatted, "0.") != "" {
+		result.WriteString("-")
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			result.WriteString(f.ThousandsSeparator)
+		}
+		result.WriteRune(digit)
+	}
+	if fraction != "" {
+		result.WriteString(f.DecimalSeparator)
+		result.WriteString(fraction)
+	}
+	return result.String()
+}
+
+// NumberValue is a replacement value which formats a number.
+type NumberValue struct {
+	Value    float64
+	Decimals int
+	// Format defaults to DefaultNumberFormat
+	Format NumberFormat
+}
+
+// Number returns a NumberValue with the given amount of decimals.
+func Number(value float64, decimals int) NumberValue {
+	return NumberValue{Value: value, Decimals: decimals}
+}
+
+// String returns the formatted number.
+func (n NumberValue) String() string {
+	return n.Format.Format(n.Value, n.Decimals)
+}
+
+// DefaultAlignment implements the AlignedValue interface, numbers are right-aligned.
+func (n NumberValue) DefaultAlignment() Alignment {
+	return AlignRight
+}
+
+// CurrencyValue is a replacement value which formats an amount of money with two decimals.
+type CurrencyValue struct {
+	Amount float64
+	Symbol string
+	// SymbolAfter places the symbol after the amount, separated by a space (e.g. '10.00 €').
+	// By default the symbol is placed right before the amount (e.g. '$10.00').
+	SymbolAfter bool
+	// Format defaults to DefaultNumberFormat
+	Format NumberFormat
+}
+
+// Currency returns a CurrencyValue with the symbol placed before the amount.
+func Currency(amount float64, symbol string) CurrencyValue {
+	return CurrencyValue{Amount: amount, Symbol: symbol}
+}
+
+// String returns the formatted amount including the symbol.
+func (c CurrencyValue) String() string {
+	amount := c.Format.Format(c.Amount, 2)
+	if c.Symbol == "" {
+		return amount
+	}
+	if c.SymbolAfter {
+		return amount + " " + c.Symbol
+	}
+	return c.Symbol + amount
+}
+
+// DefaultAlignment implements the AlignedValue interface, amounts are right-aligned.
+func (c CurrencyValue) DefaultAlignment() Alignment {
+	return AlignRight
+}
+
+// DateValue is a replacement value which formats a point in time.
+type DateValue struct {
+	Time time.Time
+	// Layout as used by time.Format, defaults to DefaultDateLayout
+	Layout string
+}
+
+// Date returns a DateValue using the given layout. If the layout is empty, DefaultDateLayout is used.
+func Date(t time.Time, layout string) DateValue {
+	return DateValue{Time: t, Layout: layout}
+}
+
+// String returns the formatted date.
+func (d DateValue) String() string {
+	layout := d.Layout
+	if layout == "" {
+		layout = DefaultDateLayout
+	}
+	return d.Time.Format(layout)
+}
+
+// DefaultAlignment implements the AlignedValue interface, dates are left-aligned.
+func (d DateValue) DefaultAlignment() Alignment {
+	return AlignLeft
+}
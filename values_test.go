@@ -0,0 +1,30 @@
+package docx
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestValues_String(t *testing.T) {
+	tests := []struct {
+		value    fmt.Stringer
+		expected string
+	}{
+		{Number(1234567.891, 2), "1,234,567.89"},
+		{Number(-999.5, 0), "-1,000"},
+		{Number(-0.001, 2), "0.00"},
+		{NumberValue{Value: 1234.5, Decimals: 1, Format: NumberFormat{DecimalSeparator: ",", ThousandsSeparator: "."}}, "1.234,5"},
+		{Currency(1234.5, "$"), "$1,234.50"},
+		{CurrencyValue{Amount: 10, Symbol: "€", SymbolAfter: true}, "10.00 €"},
+		{Date(time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC), ""), "2021-03-14"},
+		{Date(time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC), "02.01.2006"), "14.03.2021"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if value := tt.value.String(); value != tt.expected {
+				t.Errorf("unexpected value, want=%s, have=%s", tt.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package docx
+
+import (
+	"strings"
+)
+
+// LanguageSeparator separates the key of a placeholder from the language of its value, e.g. '{name@kk}'.
+const LanguageSeparator = "@"
+
+// WithLanguageVariants enables language suffixes of placeholder keys: '{name@kk}' and '{name@ru}' are replaced by
+// the languages of a nested value, e.g. PlaceholderMap{"name": map[string]string{"kk": "...", "ru": "..."}}.
+// The language of a suffix like 'kk-KZ' falls back to its primary language 'kk'. If the value has no variant of
+// the language, or the placeholder has no suffix, the variant of the default language is used.
+// Keys of the map which contain the full suffix (e.g. "name@kk") still take precedence.
+func WithLanguageVariants(defaultLanguage string) Option {
+	return func(d *Document) {
+		d.languageVariants = true
+		d.defaultLanguage = defaultLanguage
+	}
+}
+
+// resolveLanguageVariants returns a copy of the map which contains the language variant of every placeholder of the
+// document whose value has language variants, and the language each of these placeholder keys received.
+// Placeholders without a variant in their language or the default language are left unresolved.
+func (d *Document) resolveLanguageVariants(placeholderMap PlaceholderMap) (PlaceholderMap, map[string]string) {
+	resolved := make(PlaceholderMap, len(placeholderMap))
+	for key, value := range placeholderMap {
+		resolved[key] = value
+	}
+	languages := make(map[string]string)
+
+	for _, key := range d.placeholderKeys() {
+		if value, exists := lookupValue(placeholderMap, key); exists {
+			if _, isVariants := languageValues(value); !isVariants {
+				continue
+			}
+		}
+		base, language := splitLanguage(key)
+		value, exists := lookupValue(placeholderMap, base)
+		if !exists {
+			continue
+		}
+		variants, isVariants := languageValues(value)
+		if !isVariants {
+			continue
+		}
+		for _, candidate := range []string{language, primaryLanguage(language), d.defaultLanguage} {
+			if variant, exists := variants[candidate]; exists && candidate != "" {
+				resolved[key] = variant
+				languages[key] = candidate
+				break
+			}
+		}
+	}
+	return resolved, languages
+}
+
+// languageBaseKeys returns the distinct base keys of the given keys, i.e. the keys without language suffix.
+func languageBaseKeys(keys []string) []string {
+	var bases []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		base, _ := splitLanguage(key)
+		if !seen[base] {
+			seen[base] = true
+			bases = append(bases, base)
+		}
+	}
+	return bases
+}
+
+// splitLanguage splits the placeholder key (without delimiters) into its base key and its language suffix.
+func splitLanguage(key string) (base, language string) {
+	i := strings.LastIndex(key, LanguageSeparator)
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i], key[i+len(LanguageSeparator):]
+}
+
+// primaryLanguage returns the primary language of the language tag, e.g. 'kk' for 'kk-KZ'.
+func primaryLanguage(language string) string {
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		return language[:i]
+	}
+	return language
+}
+
+// languageValues returns the language variants of a value, which is a map from languages to values.
+func languageValues(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case PlaceholderMap:
+		return v, true
+	case map[string]string:
+		variants := make(map[string]interface{}, len(v))
+		for language, text := range v {
+			variants[language] = text
+		}
+		return variants, true
+	}
+	return nil, false
+}
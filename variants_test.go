@@ -0,0 +1,98 @@
+package docx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ReplaceAll_LanguageVariants(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{name@kk} | {name@ru} | {name@en} | {city@ru-RU} | {title} | {email@work}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithLanguageVariants("ru"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.ReplaceAll(PlaceholderMap{
+		"name":       map[string]string{"kk": "Айгүл", "ru": "Айгуль"},
+		"city":       map[string]interface{}{"ru": "Алматы"},
+		"title":      PlaceholderMap{"kk": "Өтініш", "ru": "Заявление"},
+		"email@work": "a@example.kz",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Айгүл | Айгуль | Айгуль | Алматы | Заявление | a@example.kz`
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, expected) {
+		t.Errorf("unexpected document\nwant=%s\nhave=%s", expected, result)
+	}
+}
+
+func TestDocument_ReplaceAll_LanguageVariantsDisabled(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{name@kk}</w:t></w:r></w:p>`
+	doc := openTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)})
+	if err := doc.ReplaceAll(PlaceholderMap{"name": map[string]string{"kk": "Айгүл"}}); err != nil {
+		t.Fatal(err)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "{name@kk}") {
+		t.Errorf("expected the placeholder to remain, got %s", result)
+	}
+}
+
+func TestDocument_render_LanguageVariants(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{name@kk} {name@ru} {name@en}</w:t></w:r></w:p>`
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithLanguageVariants("kk"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := doc.render("template.docx", map[string]interface{}{
+		"name": map[string]string{"kk": "Айгүл", "ru": "Айгуль"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	languages := map[string]string{"name@kk": "kk", "name@ru": "ru", "name@en": "kk"}
+	if !reflect.DeepEqual(report.Languages, languages) {
+		t.Errorf("unexpected languages %v, expected %v", report.Languages, languages)
+	}
+	if len(report.Unused) > 0 {
+		t.Errorf("unexpected unused keys %v", report.Unused)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "Айгүл Айгуль Айгүл") {
+		t.Errorf("unexpected document %s", result)
+	}
+
+	doc, err = OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}), WithLanguageVariants("de"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = doc.render("template.docx", map[string]interface{}{"name": map[string]string{"kk": "Айгүл", "ru": "Айгуль"}})
+	if err == nil || !strings.Contains(err.Error(), "name@en") {
+		t.Errorf("expected missing value for name@en, got %v", err)
+	}
+}
+
+func TestDocument_ReplaceResolved_LanguageVariants(t *testing.T) {
+	body := `<w:p><w:r><w:t xml:space="preserve">{name@kk} {name@ru}</w:t></w:r></w:p>`
+	var keys []string
+	resolver := func(ctx context.Context, key string) (interface{}, error) {
+		keys = append(keys, key)
+		return map[string]string{"kk": "Айгүл", "ru": "Айгуль"}, nil
+	}
+	doc, err := OpenBytes(newTestDocx(t, map[string]string{DocumentXml: testDocumentXml(body)}),
+		WithLanguageVariants("kk"), WithResolver(resolver))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.ReplaceResolved(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"name"}) {
+		t.Errorf("unexpected resolved keys %v", keys)
+	}
+	if result := string(doc.GetFile(DocumentXml)); !strings.Contains(result, "Айгүл Айгуль") {
+		t.Errorf("unexpected document %s", result)
+	}
+}